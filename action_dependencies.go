@@ -0,0 +1,83 @@
+package rep
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"code.cloudfoundry.org/bbs/models"
+)
+
+// DependencyCheck is a heuristic check for the run actions of a desired LRP
+// that use files nothing downloads. Paths lists the directories that are only
+// ever populated by downloads and cached dependencies, such as the lifecycle
+// directory. A run action whose path lies under one of them must have a cached
+// dependency, or a download earlier in the LRP, that writes to that path.
+// Relative paths cannot be resolved and are not checked. An empty Paths
+// disables the check.
+type DependencyCheck struct {
+	Paths []string
+}
+
+// Check returns an error naming the first run action in the desired LRP whose
+// path is not populated by a download.
+func (c DependencyCheck) Check(desired *models.DesiredLRP) error {
+	if len(c.Paths) == 0 {
+		return nil
+	}
+
+	var downloaded []string
+	for _, dependency := range desired.CachedDependencies {
+		downloaded = appendAbsolute(downloaded, dependency.To)
+	}
+
+	for _, action := range []*models.Action{desired.Setup, desired.Action} {
+		err := walkActions(action, func(action models.ActionInterface) error {
+			switch a := action.(type) {
+			case *models.DownloadAction:
+				downloaded = appendAbsolute(downloaded, a.To)
+			case *models.RunAction:
+				return c.checkRun(a, downloaded)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c DependencyCheck) checkRun(run *models.RunAction, downloaded []string) error {
+	runPath := path.Clean(run.Path)
+	if !path.IsAbs(runPath) {
+		return nil
+	}
+
+	for _, dependencyPath := range c.Paths {
+		if !underPath(runPath, path.Clean(dependencyPath)) {
+			continue
+		}
+		for _, target := range downloaded {
+			if underPath(runPath, target) {
+				return nil
+			}
+		}
+		return fmt.Errorf("run action %q is under %q, but no download populates it", run.Path, dependencyPath)
+	}
+
+	return nil
+}
+
+func appendAbsolute(paths []string, p string) []string {
+	p = path.Clean(p)
+	if !path.IsAbs(p) {
+		return paths
+	}
+	return append(paths, p)
+}
+
+func underPath(p, dir string) bool {
+	return p == dir || dir == "/" || strings.HasPrefix(p, dir+"/")
+}
@@ -0,0 +1,76 @@
+package rep_test
+
+import (
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/rep"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DependencyCheck", func() {
+	var (
+		check   rep.DependencyCheck
+		desired *models.DesiredLRP
+	)
+
+	launcher := models.WrapAction(&models.RunAction{Path: "/tmp/lifecycle/launcher", User: "vcap"})
+
+	BeforeEach(func() {
+		check = rep.DependencyCheck{Paths: []string{"/tmp/lifecycle"}}
+		desired = &models.DesiredLRP{ProcessGuid: "process-guid"}
+	})
+
+	It("accepts a run action whose path is populated by an earlier download", func() {
+		desired.Setup = models.WrapAction(&models.DownloadAction{From: "http://example.com/lifecycle.tgz", To: "/tmp/lifecycle", User: "vcap"})
+		desired.Action = launcher
+		Expect(check.Check(desired)).To(Succeed())
+	})
+
+	It("accepts a run action whose path is populated by a cached dependency", func() {
+		desired.CachedDependencies = []*models.CachedDependency{
+			{From: "http://example.com/lifecycle.tgz", To: "/tmp/lifecycle", CacheKey: "lifecycle"},
+		}
+		desired.Action = launcher
+		Expect(check.Check(desired)).To(Succeed())
+	})
+
+	It("looks inside nested actions", func() {
+		desired.Action = models.WrapAction(models.Serial(
+			&models.DownloadAction{From: "http://example.com/lifecycle.tgz", To: "/tmp/lifecycle", User: "vcap"},
+			models.Timeout(&models.RunAction{Path: "/tmp/lifecycle/launcher", User: "vcap"}, 0),
+		))
+		Expect(check.Check(desired)).To(Succeed())
+	})
+
+	It("rejects a run action whose path nothing downloads", func() {
+		desired.Action = launcher
+		Expect(check.Check(desired)).To(MatchError(ContainSubstring(`"/tmp/lifecycle/launcher"`)))
+	})
+
+	It("rejects a run action that comes before the download it needs", func() {
+		desired.Action = models.WrapAction(models.Serial(
+			&models.RunAction{Path: "/tmp/lifecycle/launcher", User: "vcap"},
+			&models.DownloadAction{From: "http://example.com/lifecycle.tgz", To: "/tmp/lifecycle", User: "vcap"},
+		))
+		Expect(check.Check(desired)).To(HaveOccurred())
+	})
+
+	It("ignores run actions outside the dependency paths and relative paths", func() {
+		desired.Action = models.WrapAction(models.Parallel(
+			&models.RunAction{Path: "/bin/sh", User: "vcap"},
+			&models.RunAction{Path: "lifecycle/launcher", User: "vcap"},
+		))
+		Expect(check.Check(desired)).To(Succeed())
+	})
+
+	Context("when no dependency paths are configured", func() {
+		BeforeEach(func() {
+			check = rep.DependencyCheck{}
+		})
+
+		It("accepts every LRP", func() {
+			desired.Action = launcher
+			Expect(check.Check(desired)).To(Succeed())
+		})
+	})
+})
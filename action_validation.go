@@ -0,0 +1,39 @@
+package rep
+
+import (
+	"errors"
+	"fmt"
+
+	"code.cloudfoundry.org/bbs/models"
+)
+
+// ValidateActions returns an error giving the reason the actions of the
+// desired LRP cannot be run, so the LRP can be rejected before the executor
+// is asked to run it. An LRP must have an action, and the setup, action and
+// monitor must each pass the BBS model's own validation.
+func ValidateActions(desired *models.DesiredLRP) error {
+	if desired.Action == nil || models.UnwrapAction(desired.Action) == nil {
+		return errors.New("desired LRP has no action")
+	}
+
+	actions := []struct {
+		name   string
+		action *models.Action
+	}{
+		{"setup", desired.Setup},
+		{"action", desired.Action},
+		{"monitor", desired.Monitor},
+	}
+
+	for _, a := range actions {
+		if a.action == nil {
+			continue
+		}
+		err := a.action.Validate()
+		if err != nil {
+			return fmt.Errorf("invalid %s: %s", a.name, err)
+		}
+	}
+
+	return nil
+}
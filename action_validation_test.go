@@ -0,0 +1,51 @@
+package rep_test
+
+import (
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/rep"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidateActions", func() {
+	var desired *models.DesiredLRP
+
+	BeforeEach(func() {
+		desired = &models.DesiredLRP{
+			ProcessGuid: "process-guid",
+			Action:      models.WrapAction(&models.RunAction{Path: "/bin/sh", User: "vcap"}),
+		}
+	})
+
+	It("accepts an LRP with a runnable action", func() {
+		Expect(rep.ValidateActions(desired)).To(Succeed())
+	})
+
+	It("rejects an LRP with no action", func() {
+		desired.Action = nil
+		Expect(rep.ValidateActions(desired)).To(MatchError("desired LRP has no action"))
+	})
+
+	It("rejects a run action with no path", func() {
+		desired.Action = models.WrapAction(models.Serial(
+			&models.DownloadAction{From: "http://example.com/app.tgz", To: "/app", User: "vcap"},
+			&models.RunAction{User: "vcap"},
+		))
+		err := rep.ValidateActions(desired)
+		Expect(err).To(MatchError(HavePrefix("invalid action: ")))
+		Expect(err).To(MatchError(ContainSubstring(models.ErrInvalidField{Field: "path"}.Error())))
+	})
+
+	It("rejects an empty parallel action", func() {
+		desired.Action = models.WrapAction(models.Parallel())
+		err := rep.ValidateActions(desired)
+		Expect(err).To(MatchError(ContainSubstring(models.ErrInvalidField{Field: "actions"}.Error())))
+	})
+
+	It("checks the setup and monitor actions too", func() {
+		desired.Monitor = models.WrapAction(&models.RunAction{User: "vcap"})
+		err := rep.ValidateActions(desired)
+		Expect(err).To(MatchError(HavePrefix("invalid monitor: ")))
+		Expect(err).To(MatchError(ContainSubstring(models.ErrInvalidField{Field: "path"}.Error())))
+	})
+})
@@ -0,0 +1,43 @@
+package rep
+
+import "code.cloudfoundry.org/bbs/models"
+
+// walkActions calls visit with every action in the tree rooted at action, in
+// the order they appear, parents before their children. It stops at the first
+// error visit returns.
+func walkActions(action *models.Action, visit func(models.ActionInterface) error) error {
+	if action == nil {
+		return nil
+	}
+
+	a := models.UnwrapAction(action)
+	err := visit(a)
+	if err != nil {
+		return err
+	}
+
+	var children []*models.Action
+	switch a := a.(type) {
+	case *models.SerialAction:
+		children = a.Actions
+	case *models.ParallelAction:
+		children = a.Actions
+	case *models.CodependentAction:
+		children = a.Actions
+	case *models.TimeoutAction:
+		children = []*models.Action{a.Action}
+	case *models.EmitProgressAction:
+		children = []*models.Action{a.Action}
+	case *models.TryAction:
+		children = []*models.Action{a.Action}
+	}
+
+	for _, child := range children {
+		err := walkActions(child, visit)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package auctioncellrep
+
+import "code.cloudfoundry.org/lager"
+
+// Starting returns the number of containers on the cell that are reserved,
+// initializing or created but not yet running. It returns zero when the
+// executor cannot list its containers, so that a failing executor never holds
+// up work that waits for the cell to go quiet.
+func (a *AuctionCellRep) Starting(logger lager.Logger) int {
+	containers, err := a.client.ListContainers(logger)
+	if err != nil {
+		logger.Error("failed-to-list-containers", err)
+		return 0
+	}
+
+	starting := 0
+	for i := range containers {
+		if containerIsStarting(&containers[i]) {
+			starting++
+		}
+	}
+	return starting
+}
@@ -0,0 +1,85 @@
+package auctioncellrep
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+//go:generate counterfeiter . AllocationDecorator
+
+// AllocationDecorator can add cell-specific labels to an allocation request
+// before it is sent to the executor.
+type AllocationDecorator interface {
+	Decorate(logger lager.Logger, request *executor.AllocationRequest) error
+}
+
+type noopAllocationDecorator struct{}
+
+func NewNoopAllocationDecorator() AllocationDecorator {
+	return noopAllocationDecorator{}
+}
+
+func (noopAllocationDecorator) Decorate(logger lager.Logger, request *executor.AllocationRequest) error {
+	return nil
+}
+
+type labelAllocationDecorator struct {
+	labels map[string]string
+}
+
+// NewLabelAllocationDecorator returns an AllocationDecorator that adds the
+// given labels to the tags of every allocation request.
+func NewLabelAllocationDecorator(labels map[string]string) AllocationDecorator {
+	return &labelAllocationDecorator{
+		labels: labels,
+	}
+}
+
+func (d *labelAllocationDecorator) Decorate(logger lager.Logger, request *executor.AllocationRequest) error {
+	if request.Tags == nil {
+		request.Tags = executor.Tags{}
+	}
+	for key, value := range d.labels {
+		request.Tags[key] = value
+	}
+	return nil
+}
+
+// reservedTags are the tags the rep relies on to track its containers. A
+// decorator may not change them.
+var reservedTags = []string{
+	rep.LifecycleTag,
+	rep.DomainTag,
+	rep.ProcessGuidTag,
+	rep.ProcessIndexTag,
+	rep.InstanceGuidTag,
+	rep.ResultFileTag,
+}
+
+func (a *AuctionCellRep) decorateAllocationRequest(logger lager.Logger, request *executor.AllocationRequest) error {
+	guid := request.Guid
+	originalTags := executor.Tags{}
+	for key, value := range request.Tags {
+		originalTags[key] = value
+	}
+
+	err := a.allocationDecorator.Decorate(logger, request)
+	if err != nil {
+		return err
+	}
+
+	if request.Guid != guid {
+		return fmt.Errorf("decorated allocation request changed guid from %q to %q", guid, request.Guid)
+	}
+
+	for _, tag := range reservedTags {
+		if request.Tags[tag] != originalTags[tag] {
+			return fmt.Errorf("decorated allocation request changed reserved tag %q", tag)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package auctioncellrep_test
+
+import (
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AllocationDecorator", func() {
+	var (
+		logger  *lagertest.TestLogger
+		request executor.AllocationRequest
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		resource := executor.NewResource(10, 20, 30, "rootfs")
+		request = executor.NewAllocationRequest("guid", &resource, executor.Tags{"lifecycle": "lrp"})
+	})
+
+	Describe("NoopAllocationDecorator", func() {
+		It("leaves the request unchanged", func() {
+			original := request
+			err := auctioncellrep.NewNoopAllocationDecorator().Decorate(logger, &request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(request).To(Equal(original))
+		})
+	})
+
+	Describe("LabelAllocationDecorator", func() {
+		It("adds the labels to the request's tags", func() {
+			decorator := auctioncellrep.NewLabelAllocationDecorator(map[string]string{
+				"datacenter":     "dc1",
+				"hardware-class": "large",
+			})
+
+			err := decorator.Decorate(logger, &request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(request.Tags).To(Equal(executor.Tags{
+				"lifecycle":      "lrp",
+				"datacenter":     "dc1",
+				"hardware-class": "large",
+			}))
+		})
+
+		It("adds labels to a request without tags", func() {
+			request.Tags = nil
+			decorator := auctioncellrep.NewLabelAllocationDecorator(map[string]string{"datacenter": "dc1"})
+
+			err := decorator.Decorate(logger, &request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(request.Tags).To(Equal(executor.Tags{"datacenter": "dc1"}))
+		})
+	})
+})
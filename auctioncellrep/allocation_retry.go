@@ -0,0 +1,64 @@
+package auctioncellrep
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+)
+
+// AllocationRetryConfig controls how often the cell asks the executor again
+// when a request to allocate containers fails outright, as it does while the
+// executor is briefly busy. The first retry waits Backoff, and every further
+// retry waits twice as long as the one before. A MaxAttempts of one or less
+// disables the retries.
+//
+// Retries stop as soon as the cell starts evacuating, so that a cell which is
+// shutting down does not hold on to work it is about to give back. They also
+// stop once the next wait would take the total beyond maxAllocationRetryWait.
+type AllocationRetryConfig struct {
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+// maxAllocationRetryWait bounds the time a single auction spends waiting to
+// retry. Perform runs while the auctioneer waits for its response, so the
+// bound is kept well below the auctioneer's default communication timeout of
+// ten seconds: work the cell cannot allocate by then is handed back as failed
+// and re-auctioned, rather than being placed elsewhere while this cell is
+// still trying to allocate it.
+const maxAllocationRetryWait = 3 * time.Second
+
+func (a *AuctionCellRep) allocateWithRetry(logger lager.Logger, requests []executor.AllocationRequest) ([]executor.AllocationFailure, error) {
+	failures, err := a.client.AllocateContainers(logger, requests)
+
+	backoff := a.allocationRetryConfig.Backoff
+	var waited time.Duration
+	for attempt := 1; err != nil && attempt < a.allocationRetryConfig.MaxAttempts; attempt++ {
+		if waited+backoff > maxAllocationRetryWait {
+			logger.Info("abandoning-container-allocation-after-max-wait", lager.Data{
+				"attempt": attempt,
+				"waited":  waited.String(),
+			})
+			break
+		}
+
+		logger.Info("retrying-container-allocation", lager.Data{
+			"attempt": attempt,
+			"backoff": backoff.String(),
+			"error":   err.Error(),
+		})
+		a.clock.Sleep(backoff)
+		waited += backoff
+
+		if a.evacuationReporter.Evacuating() {
+			logger.Info("abandoning-container-allocation-while-evacuating")
+			break
+		}
+
+		failures, err = a.client.AllocateContainers(logger, requests)
+		backoff *= 2
+	}
+
+	return failures, err
+}
@@ -0,0 +1,443 @@
+package auctioncellrep
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+var ErrAllocationQueueStopped = errors.New("the cell stopped allocating queued containers")
+
+// allocationSlotPollInterval is how often the allocation queue checks whether
+// the executor has finished starting the containers holding slots.
+const allocationSlotPollInterval = 250 * time.Millisecond
+
+// allocationSlots bounds how many containers are being started on the cell at
+// once. A container holds a slot from the moment the rep asks the executor to
+// allocate it until the executor reports that it is no longer reserved,
+// initializing or created, so that the bound covers running and initializing
+// the container as well as reserving it.
+//
+// Requests beyond the bound wait in a queue, in the order they arrived, and
+// are allocated one slot at a time as earlier containers start. Perform does
+// not wait for them: the auctioneer treats queued work as placed on the cell.
+// Work that is dropped from the queue, because the cell stopped or started
+// evacuating, is left unclaimed in the BBS, which auctions it again.
+type allocationSlots struct {
+	limit int
+
+	lock       sync.Mutex
+	allocating map[string]struct{}
+	starting   map[string]struct{}
+	queue      []queuedAllocation
+	running    bool
+	stopped    bool
+	stop       chan struct{}
+	workers    sync.WaitGroup
+}
+
+// queuedAllocation is an allocation request waiting for a slot. done is called
+// once the request has been allocated, with nil, or has failed or been
+// dropped, with the reason.
+type queuedAllocation struct {
+	request executor.AllocationRequest
+	done    func(error)
+}
+
+func newAllocationSlots(limit int) *allocationSlots {
+	return &allocationSlots{
+		limit:      limit,
+		allocating: make(map[string]struct{}),
+		starting:   make(map[string]struct{}),
+		stop:       make(chan struct{}),
+	}
+}
+
+// take takes free slots for as many of the guids as it can, in order, and
+// returns how many it took. It takes none while other requests are queued, so
+// that the queued requests are allocated first.
+func (s *allocationSlots) take(guids []string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.queue) > 0 {
+		return 0
+	}
+	return s.takeLocked(guids)
+}
+
+func (s *allocationSlots) takeLocked(guids []string) int {
+	taken := 0
+	for _, guid := range guids {
+		if len(s.allocating)+len(s.starting) >= s.limit {
+			break
+		}
+		s.allocating[guid] = struct{}{}
+		taken++
+	}
+	return taken
+}
+
+// release gives up the slots of containers that are not going to start.
+func (s *allocationSlots) release(guids []string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, guid := range guids {
+		delete(s.allocating, guid)
+	}
+}
+
+// allocated keeps the slots of containers the executor has reserved until
+// they have started.
+func (s *allocationSlots) allocated(guids []string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, guid := range guids {
+		delete(s.allocating, guid)
+		s.starting[guid] = struct{}{}
+	}
+}
+
+// sync frees the slots of containers that are running, completed or gone. It
+// returns whether any slot was freed.
+func (s *allocationSlots) sync(containers []executor.Container) bool {
+	stillStarting := make(map[string]struct{}, len(containers))
+	for i := range containers {
+		if containerIsStarting(&containers[i]) {
+			stillStarting[containers[i].Guid] = struct{}{}
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	freed := false
+	for guid := range s.starting {
+		if _, ok := stillStarting[guid]; !ok {
+			delete(s.starting, guid)
+			freed = true
+		}
+	}
+	return freed
+}
+
+// enqueue adds the allocations to the back of the queue. It returns whether a
+// worker has to be started to allocate them, and false for queued if the
+// queue has been stopped and the allocations were not added.
+func (s *allocationSlots) enqueue(allocations []queuedAllocation) (startWorker bool, queued bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.stopped {
+		return false, false
+	}
+	s.queue = append(s.queue, allocations...)
+	if s.running {
+		return false, true
+	}
+	s.running = true
+	s.workers.Add(1)
+	return true, true
+}
+
+// dequeue takes slots for as many of the queued allocations as it can and
+// returns them. It returns false once the queue is empty or stopped, when the
+// worker should exit.
+func (s *allocationSlots) dequeue() ([]queuedAllocation, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.stopped || len(s.queue) == 0 {
+		s.running = false
+		return nil, false
+	}
+
+	guids := make([]string, len(s.queue))
+	for i := range s.queue {
+		guids[i] = s.queue[i].request.Guid
+	}
+	taken := s.takeLocked(guids)
+
+	allocations := s.queue[:taken:taken]
+	s.queue = s.queue[taken:]
+	return allocations, true
+}
+
+// drop empties the queue and returns what was in it.
+func (s *allocationSlots) drop() []queuedAllocation {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	dropped := s.queue
+	s.queue = nil
+	return dropped
+}
+
+// stopQueue stops the queue, waits for the worker to finish the allocation it
+// is making, and returns what was still queued.
+func (s *allocationSlots) stopQueue() []queuedAllocation {
+	s.lock.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.stop)
+	}
+	dropped := s.queue
+	s.queue = nil
+	s.lock.Unlock()
+
+	s.workers.Wait()
+	return dropped
+}
+
+func (s *allocationSlots) usage() rep.LimitUsage {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return rep.LimitUsage{
+		ConcurrencyUsed:  len(s.allocating) + len(s.starting),
+		ConcurrencyLimit: s.limit,
+	}
+}
+
+// addGuids adds the guids of the containers holding slots, and of those
+// queued for one, to guids.
+func (s *allocationSlots) addGuids(guids map[string]struct{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for guid := range s.allocating {
+		guids[guid] = struct{}{}
+	}
+	for guid := range s.starting {
+		guids[guid] = struct{}{}
+	}
+	for i := range s.queue {
+		guids[s.queue[i].request.Guid] = struct{}{}
+	}
+}
+
+// allocateInSlots allocates as many of the requests as there are free slots
+// for, and queues the rest to be allocated as earlier containers start. It
+// returns the failures of the requests it allocated straight away.
+func (a *AuctionCellRep) allocateInSlots(logger lager.Logger, requests []executor.AllocationRequest, queued func(*executor.AllocationRequest) func(error)) []executor.AllocationFailure {
+	guids := allocationRequestGuids(requests)
+	taken := a.allocationSlots.take(guids)
+	if taken < len(guids) && a.syncAllocationSlots(logger) {
+		taken += a.allocationSlots.take(guids[taken:])
+	}
+
+	failures := []executor.AllocationFailure{}
+	if taken > 0 {
+		failures = a.allocateInTakenSlots(logger, requests[:taken])
+	}
+
+	if taken < len(requests) {
+		allocations := make([]queuedAllocation, 0, len(requests)-taken)
+		for i := taken; i < len(requests); i++ {
+			allocations = append(allocations, queuedAllocation{request: requests[i], done: queued(&requests[i])})
+		}
+		a.queueAllocations(logger, allocations)
+	}
+
+	return failures
+}
+
+// allocateInTakenSlots allocates requests that already hold slots, and keeps
+// the slots of those the executor reserved until they have started.
+func (a *AuctionCellRep) allocateInTakenSlots(logger lager.Logger, requests []executor.AllocationRequest) []executor.AllocationFailure {
+	failures, err := a.allocateBatch(logger, requests)
+	if err != nil {
+		logger.Error("failed-to-allocate-batch", err, lager.Data{"batch-size": len(requests)})
+		for i := range requests {
+			failures = append(failures, executor.NewAllocationFailure(&requests[i], err.Error()))
+		}
+	}
+	a.holdAllocatedSlots(allocationRequestGuids(requests), failures)
+	return failures
+}
+
+func (a *AuctionCellRep) queueAllocations(logger lager.Logger, allocations []queuedAllocation) {
+	startWorker, queued := a.allocationSlots.enqueue(allocations)
+	if !queued {
+		logger.Info("dropping-allocations-after-stop", lager.Data{"num-dropped": len(allocations)})
+		for i := range allocations {
+			allocations[i].done(ErrAllocationQueueStopped)
+		}
+		return
+	}
+
+	logger.Info("queued-container-allocations", lager.Data{"num-queued": len(allocations)})
+	if startWorker {
+		go a.runAllocationQueue(logger.Session("allocation-queue"))
+	}
+}
+
+// runAllocationQueue allocates the queued requests as slots free up, until
+// the queue is empty or stopped. The queue is dropped if the cell starts
+// evacuating.
+func (a *AuctionCellRep) runAllocationQueue(logger lager.Logger) {
+	defer a.allocationSlots.workers.Done()
+
+	for {
+		if a.evacuationReporter.Evacuating() {
+			dropped := a.allocationSlots.drop()
+			logger.Info("dropping-queued-allocations-while-evacuating", lager.Data{"num-dropped": len(dropped)})
+			for i := range dropped {
+				dropped[i].done(ErrAllocationQueueStopped)
+			}
+		}
+
+		allocations, ok := a.allocationSlots.dequeue()
+		if !ok {
+			return
+		}
+
+		if len(allocations) > 0 {
+			a.allocateQueued(logger, allocations)
+			continue
+		}
+
+		if a.syncAllocationSlots(logger) {
+			continue
+		}
+
+		timer := a.clock.NewTimer(allocationSlotPollInterval)
+		select {
+		case <-timer.C():
+		case <-a.allocationSlots.stop:
+			timer.Stop()
+		}
+	}
+}
+
+func (a *AuctionCellRep) allocateQueued(logger lager.Logger, allocations []queuedAllocation) {
+	requests := make([]executor.AllocationRequest, len(allocations))
+	for i := range allocations {
+		requests[i] = allocations[i].request
+	}
+
+	failures := a.allocateInTakenSlots(logger, requests)
+	failed := make(map[string]error, len(failures))
+	for i := range failures {
+		failed[failures[i].Guid] = &failures[i]
+	}
+
+	for i := range allocations {
+		allocations[i].done(failed[allocations[i].request.Guid])
+	}
+}
+
+// Stop drops the allocations still waiting for a slot, and waits for any
+// queued allocation already under way to finish. Dropped LRPs and tasks are
+// left unclaimed in the BBS, which auctions them again.
+func (a *AuctionCellRep) Stop(logger lager.Logger) {
+	if a.allocationSlots == nil {
+		return
+	}
+
+	dropped := a.allocationSlots.stopQueue()
+	logger.Session("stop").Info("dropped-queued-allocations", lager.Data{"num-dropped": len(dropped)})
+	for i := range dropped {
+		dropped[i].done(ErrAllocationQueueStopped)
+	}
+}
+
+var _ rep.LimitReporter = new(AuctionCellRep)
+
+// LimitUsage reports how many of the allocation slots are in use. It reports
+// no limit when concurrent allocations are not limited.
+func (a *AuctionCellRep) LimitUsage() rep.LimitUsage {
+	if a.allocationSlots == nil {
+		return rep.LimitUsage{}
+	}
+	return a.allocationSlots.usage()
+}
+
+func (a *AuctionCellRep) syncAllocationSlots(logger lager.Logger) bool {
+	containers, err := a.client.ListContainers(logger)
+	if err != nil {
+		logger.Error("failed-to-list-containers", err)
+		return false
+	}
+	return a.allocationSlots.sync(containers)
+}
+
+func (a *AuctionCellRep) holdAllocatedSlots(guids []string, failures []executor.AllocationFailure) {
+	failed := make(map[string]struct{}, len(failures))
+	for i := range failures {
+		failed[failures[i].Guid] = struct{}{}
+	}
+
+	allocated := make([]string, 0, len(guids))
+	notAllocated := make([]string, 0, len(failures))
+	for _, guid := range guids {
+		if _, ok := failed[guid]; ok {
+			notAllocated = append(notAllocated, guid)
+		} else {
+			allocated = append(allocated, guid)
+		}
+	}
+
+	a.allocationSlots.release(notAllocated)
+	a.allocationSlots.allocated(allocated)
+}
+
+// queuedLRPAllocation keeps a queued LRP instance marked as being placed
+// until its container has been allocated, and reports the allocation failing
+// as allocateLRPs does.
+func (a *AuctionCellRep) queuedLRPAllocation(logger lager.Logger, lrpMap map[string]*rep.LRP) func(*executor.AllocationRequest) func(error) {
+	return func(request *executor.AllocationRequest) func(error) {
+		guid := request.Guid
+		lrp := lrpMap[guid]
+		release := a.holdScheduling(lrp)
+
+		return func(err error) {
+			defer release()
+
+			if err == nil {
+				return
+			}
+			data := lager.Data{"container-guid": guid, "process-guid": lrp.ProcessGuid, "index": lrp.Index}
+			if err == ErrAllocationQueueStopped {
+				logger.Info("dropped-queued-container-allocation", data)
+				return
+			}
+			logger.Error("queued-container-allocation-failure", err, data)
+			a.reportAllocationError(lrp, guid, err)
+		}
+	}
+}
+
+// queuedTaskAllocation logs a queued task failing to be allocated.
+func (a *AuctionCellRep) queuedTaskAllocation(logger lager.Logger, taskMap map[string]*rep.Task) func(*executor.AllocationRequest) func(error) {
+	return func(request *executor.AllocationRequest) func(error) {
+		guid := request.Guid
+		task := taskMap[guid]
+
+		return func(err error) {
+			if err == nil {
+				return
+			}
+			data := lager.Data{"container-guid": guid, "task-guid": task.TaskGuid}
+			if err == ErrAllocationQueueStopped {
+				logger.Info("dropped-queued-container-allocation", data)
+				return
+			}
+			logger.Error("queued-container-allocation-failure", err, data)
+		}
+	}
+}
+
+func allocationRequestGuids(requests []executor.AllocationRequest) []string {
+	guids := make([]string, len(requests))
+	for i := range requests {
+		guids[i] = requests[i].Guid
+	}
+	return guids
+}
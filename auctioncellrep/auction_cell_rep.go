@@ -3,10 +3,15 @@ package auctioncellrep
 import (
 	"errors"
 	"net/url"
+	"sort"
 	"strconv"
+	"sync"
+	"time"
 
 	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/go-loggregator/loggregator_v2"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
@@ -18,8 +23,15 @@ type AuctionCellClient interface {
 	State(logger lager.Logger) (rep.CellState, bool, error)
 	Perform(logger lager.Logger, work rep.Work) (rep.Work, error)
 	Reset() error
+	History(since time.Time, guid string) []rep.ProvisioningEvent
+	InFlight() []string
 }
 
+const (
+	repEventsDeduplicated        = "RepEventsDeduplicated"
+	repContainerAllocationFailed = "RepContainerAllocationFailed"
+)
+
 var ErrPreloadedRootFSNotFound = errors.New("preloaded rootfs path not found")
 var ErrCellUnhealthy = errors.New("internal cell healthcheck failed")
 
@@ -27,45 +39,152 @@ type AuctionCellRep struct {
 	cellID                string
 	stackPathMap          rep.StackPathMap
 	rootFSProviders       rep.RootFSProviders
+	matchAnyStack         bool
+	anyStackPath          string
+	history               *provisioningHistory
+	sickCell              *sickCellDetector
 	stack                 string
 	zone                  string
-	generateInstanceGuid  func() (string, error)
+	generateInstanceGuid  InstanceGuidGenerator
 	client                executor.Client
 	evacuationReporter    evacuation_context.EvacuationReporter
 	placementTags         []string
 	optionalPlacementTags []string
+	clock                 clock.Clock
+	startTime             time.Time
+	diskPressureChecker   DiskPressureChecker
+	startupQuietPeriod    time.Duration
+	crashBackoffConfig    CrashBackoffConfig
+	allocationDecorator   AllocationDecorator
+	metronClient          loggregator_v2.Client
+	bbsOutageConfig       BBSOutageConfig
+	maxLRPMemoryFraction  float64
+	validateLRPResources  bool
+	allocationRetryConfig AllocationRetryConfig
+	defaultResources      DefaultResourceConfig
+	rejectBeyondCapacity  bool
+	lifecycleErrors       rep.LifecycleErrorHandler
+	reportStackMismatches bool
+	dryRun                bool
+	loadWeightConfig      LoadWeightConfig
+
+	allocationSlots *allocationSlots
+
+	drainLock  sync.Mutex
+	draining   bool
+	performing int
+	drained    chan struct{}
+
+	lrpFilterLock sync.Mutex
+	lrpFilter     LRPFilter
+
+	schedulingLock sync.Mutex
+	scheduling     map[string]int
+
+	crashBackoffLock sync.Mutex
+	crashBackoffs    map[string]*crashBackoff
+
+	statsLock    sync.Mutex
+	provisioned  int
+	failed       int
+	evicted      int
+	inFlight     map[string]struct{}
+	peakInFlight int
+}
+
+// Config holds the settings of an AuctionCellRep that come from the rep's
+// configuration. The zero value of each of the optional settings disables
+// the behaviour it controls.
+type Config struct {
+	CellID                   string
+	PreloadedStackPathMap    rep.StackPathMap
+	ArbitraryRootFSes        []string
+	Zone                     string
+	PlacementTags            []string
+	OptionalPlacementTags    []string
+	StartupQuietPeriod       time.Duration
+	CrashBackoff             CrashBackoffConfig
+	BBSOutage                BBSOutageConfig
+	MatchAnyStack            bool
+	History                  ProvisioningHistoryConfig
+	MaxLRPMemoryFraction     float64
+	ValidateLRPResources     bool
+	AllocationRetry          AllocationRetryConfig
+	DefaultResources         DefaultResourceConfig
+	MaxConcurrentAllocations int
+	SickCell                 SickCellConfig
+	RejectBeyondCapacity     bool
+	ReportStackMismatches    bool
+	DryRun                   bool
+	LoadWeight               LoadWeightConfig
+	LifecycleErrors          rep.LifecycleErrorHandler
 }
 
 func New(
-	cellID string,
-	preloadedStackPathMap rep.StackPathMap,
-	arbitraryRootFSes []string,
-	zone string,
-	generateInstanceGuid func() (string, error),
+	config Config,
+	generateInstanceGuid InstanceGuidGenerator,
 	client executor.Client,
 	evacuationReporter evacuation_context.EvacuationReporter,
-	placementTags []string,
-	optionalPlacementTags []string,
+	clock clock.Clock,
+	diskPressureChecker DiskPressureChecker,
+	allocationDecorator AllocationDecorator,
+	metronClient loggregator_v2.Client,
 ) *AuctionCellRep {
+	var allocationSlots *allocationSlots
+	if config.MaxConcurrentAllocations > 0 {
+		allocationSlots = newAllocationSlots(config.MaxConcurrentAllocations)
+	}
+
 	return &AuctionCellRep{
-		cellID:                cellID,
-		stackPathMap:          preloadedStackPathMap,
-		rootFSProviders:       rootFSProviders(preloadedStackPathMap, arbitraryRootFSes),
-		zone:                  zone,
+		cellID:                config.CellID,
+		stackPathMap:          config.PreloadedStackPathMap,
+		rootFSProviders:       rootFSProviders(config.PreloadedStackPathMap, config.ArbitraryRootFSes, config.MatchAnyStack),
+		matchAnyStack:         config.MatchAnyStack,
+		anyStackPath:          anyStackPath(config.PreloadedStackPathMap),
+		history:               newProvisioningHistory(config.History, clock),
+		sickCell:              newSickCellDetector(config.SickCell, clock),
+		zone:                  config.Zone,
 		generateInstanceGuid:  generateInstanceGuid,
 		client:                client,
 		evacuationReporter:    evacuationReporter,
-		placementTags:         placementTags,
-		optionalPlacementTags: optionalPlacementTags,
+		placementTags:         config.PlacementTags,
+		optionalPlacementTags: config.OptionalPlacementTags,
+		clock:                 clock,
+		startTime:             clock.Now(),
+		diskPressureChecker:   diskPressureChecker,
+		startupQuietPeriod:    config.StartupQuietPeriod,
+		crashBackoffConfig:    config.CrashBackoff.withDefaults(),
+		crashBackoffs:         make(map[string]*crashBackoff),
+		allocationDecorator:   allocationDecorator,
+		metronClient:          metronClient,
+		bbsOutageConfig:       config.BBSOutage,
+		maxLRPMemoryFraction:  config.MaxLRPMemoryFraction,
+		validateLRPResources:  config.ValidateLRPResources,
+		allocationRetryConfig: config.AllocationRetry,
+		defaultResources:      config.DefaultResources,
+		rejectBeyondCapacity:  config.RejectBeyondCapacity,
+		lifecycleErrors:       config.LifecycleErrors,
+		reportStackMismatches: config.ReportStackMismatches,
+		dryRun:                config.DryRun,
+		loadWeightConfig:      config.LoadWeight,
+		allocationSlots:       allocationSlots,
+		inFlight:              make(map[string]struct{}),
+		scheduling:            make(map[string]int),
 	}
 }
 
-func rootFSProviders(preloaded rep.StackPathMap, arbitrary []string) rep.RootFSProviders {
+func rootFSProviders(preloaded rep.StackPathMap, arbitrary []string, matchAnyStack bool) rep.RootFSProviders {
 	rootFSProviders := rep.RootFSProviders{}
 	for _, scheme := range arbitrary {
 		rootFSProviders[scheme] = rep.ArbitraryRootFSProvider{}
 	}
 
+	// A cell without a preloaded stack has no rootfs to run other stacks on.
+	if matchAnyStack && len(preloaded) > 0 {
+		rootFSProviders["preloaded"] = rep.ArbitraryRootFSProvider{}
+		return rootFSProviders
+	}
+
 	stacks := make([]string, 0, len(preloaded))
 	for stack, _ := range preloaded {
 		stacks = append(stacks, stack)
@@ -119,6 +238,8 @@ func (a *AuctionCellRep) State(logger lager.Logger) (rep.CellState, bool, error)
 		logger.Error("failed-to-get-remaining-resource", err)
 		return rep.CellState{}, false, err
 	}
+	availableResources = a.clampNegativeResources(logger, availableResources)
+	availableResources = a.rampAvailableResources(logger, availableResources)
 
 	volumeDrivers, err := a.client.VolumeDrivers(logger)
 	if err != nil {
@@ -174,18 +295,34 @@ func (a *AuctionCellRep) State(logger lager.Logger) (rep.CellState, bool, error)
 		a.placementTags,
 		a.optionalPlacementTags,
 	)
+	state.CrashBackoffs = a.currentCrashBackoffs()
+	state.CellID = a.cellID
+	state.RepVersion = rep.Version
+	state.LoadPenalty = a.loadPenalty(totalResources, availableResources)
+	state.Degraded = a.bbsOutage() != ""
+	if a.isDraining() {
+		// The auctioneer does not place work on evacuating cells, so a
+		// draining cell reports itself as evacuating too.
+		state.Draining = true
+		state.Evacuating = true
+	}
 
 	healthy := a.client.Healthy(logger)
 	if !healthy {
 		logger.Error("failed-garden-health-check", nil)
 	}
 
+	if a.sickCell.check(logger) {
+		healthy = false
+	}
+
 	logger.Info("provided", lager.Data{
 		"available-resources": state.AvailableResources,
 		"total-resources":     state.TotalResources,
 		"num-lrps":            len(state.LRPs),
 		"zone":                state.Zone,
 		"evacuating":          state.Evacuating,
+		"degraded":            state.Degraded,
 	})
 
 	return state, healthy, nil
@@ -205,66 +342,348 @@ func (a *AuctionCellRep) Perform(logger lager.Logger, work rep.Work) (rep.Work,
 		"tasks":      len(work.Tasks),
 	})
 
+	if !a.startPerforming() {
+		logger.Info("rejecting-work-while-draining")
+		a.recordEvicted(len(work.LRPs) + len(work.Tasks))
+		return work, nil
+	}
+	defer a.finishPerforming()
+
 	if a.evacuationReporter.Evacuating() {
+		a.recordEvicted(len(work.LRPs) + len(work.Tasks))
+		return work, nil
+	}
+
+	if a.diskPressureChecker.UnderPressure(logger) {
+		logger.Info("rejecting-work-due-to-disk-pressure")
+		a.recordFailed(len(work.LRPs) + len(work.Tasks))
+		return work, nil
+	}
+
+	if a.rejectForBBSOutage(logger) {
+		a.recordFailed(len(work.LRPs) + len(work.Tasks))
 		return work, nil
 	}
 
 	if len(work.LRPs) > 0 {
 		lrpLogger := logger.Session("lrp-allocate-instances")
 
-		requests, lrpMap, untranslatedLRPs := a.lrpsToAllocationRequest(work.LRPs)
+		lrps, release := a.withoutSchedulingLRPs(lrpLogger, work.LRPs)
+		defer release()
+
+		lrps = a.withoutExistingLRPs(lrpLogger, lrps)
+		lrps = a.withDefaultResources(lrpLogger, lrps)
+
+		var rejectedLRPs []rep.LRP
+		for _, without := range []func(lager.Logger, []rep.LRP) ([]rep.LRP, []rep.LRP){
+			a.withoutFilteredLRPs,
+			a.withoutBackedOffLRPs,
+			a.withoutInvalidResourceLRPs,
+			a.withoutOutsizedLRPs,
+			a.withoutUnfittingLRPs,
+		} {
+			var rejected []rep.LRP
+			lrps, rejected = without(lrpLogger, lrps)
+			rejectedLRPs = append(rejectedLRPs, rejected...)
+		}
+		if len(rejectedLRPs) > 0 {
+			failedWork.LRPs = rejectedLRPs
+			a.recordFailed(len(rejectedLRPs))
+		}
+
+		requests, lrpMap, untranslatedLRPs := a.lrpsToAllocationRequest(lrpLogger, lrps, a.clock.Now())
 		if len(untranslatedLRPs) > 0 {
 			lrpLogger.Info("failed-to-translate-lrps-to-containers", lager.Data{"num-failed-to-translate": len(untranslatedLRPs)})
-			failedWork.LRPs = untranslatedLRPs
+			failedWork.LRPs = append(failedWork.LRPs, untranslatedLRPs...)
+			a.recordFailed(len(untranslatedLRPs))
 		}
 
-		lrpLogger.Info("requesting-container-allocation", lager.Data{"num-requesting-allocation": len(requests)})
-		failures, err := a.client.AllocateContainers(logger, requests)
-		if err != nil {
-			lrpLogger.Error("failed-requesting-container-allocation", err)
-			failedWork.LRPs = work.LRPs
+		if a.dryRun {
+			failedWork.LRPs = append(failedWork.LRPs, a.dryRunLRPs(lrpLogger, requests, lrpMap)...)
 		} else {
-			lrpLogger.Info("succeeded-requesting-container-allocation", lager.Data{"num-failed-to-allocate": len(failures)})
-			for i := range failures {
-				failure := &failures[i]
-				lrpLogger.Error("container-allocation-failure", failure, lager.Data{"failed-request": &failure.AllocationRequest})
-				if lrp, found := lrpMap[failure.Guid]; found {
-					failedWork.LRPs = append(failedWork.LRPs, *lrp)
-				}
-			}
+			failedWork.LRPs = append(failedWork.LRPs, a.allocateLRPs(logger, lrpLogger, requests, lrpMap)...)
 		}
 	}
 
 	if len(work.Tasks) > 0 {
 		taskLogger := logger.Session("task-allocate-instances")
 
-		requests, taskMap, failedTasks := a.tasksToAllocationRequests(work.Tasks)
+		requests, taskMap, failedTasks := a.tasksToAllocationRequests(taskLogger, work.Tasks)
 		if len(failedTasks) > 0 {
 			taskLogger.Info("failed-to-translate-tasks-to-containers", lager.Data{"num-failed-to-translate": len(failedTasks)})
 			failedWork.Tasks = failedTasks
+			a.recordFailed(len(failedTasks))
 		}
 
-		taskLogger.Info("requesting-container-allocation", lager.Data{"num-requesting-allocation": len(requests)})
-		failures, err := a.client.AllocateContainers(logger, requests)
-		if err != nil {
-			taskLogger.Error("failed-requesting-container-allocation", err)
-			failedWork.Tasks = work.Tasks
+		if a.dryRun {
+			failedWork.Tasks = append(failedWork.Tasks, a.dryRunTasks(taskLogger, requests, taskMap)...)
 		} else {
-			taskLogger.Info("succeeded-requesting-container-allocation", lager.Data{"num-failed-to-allocate": len(failures)})
-			for i := range failures {
-				failure := &failures[i]
-				taskLogger.Error("container-allocation-failure", failure, lager.Data{"failed-request": &failure.AllocationRequest})
-				if task, found := taskMap[failure.Guid]; found {
-					failedWork.Tasks = append(failedWork.Tasks, *task)
-				}
-			}
+			failedWork.Tasks = append(failedWork.Tasks, a.allocateTasks(logger, taskLogger, requests, taskMap)...)
 		}
 	}
 
 	return failedWork, nil
 }
 
-func (a *AuctionCellRep) lrpsToAllocationRequest(lrps []rep.LRP) ([]executor.AllocationRequest, map[string]*rep.LRP, []rep.LRP) {
+// allocateLRPs requests containers for the LRP allocation requests and returns
+// the LRPs that could not be allocated.
+func (a *AuctionCellRep) allocateLRPs(logger, lrpLogger lager.Logger, requests []executor.AllocationRequest, lrpMap map[string]*rep.LRP) []rep.LRP {
+	lrpLogger.Info("requesting-container-allocation", lager.Data{"num-requesting-allocation": len(requests)})
+	failures, err := a.allocateContainers(logger, requests, a.queuedLRPAllocation(lrpLogger, lrpMap))
+	if err != nil {
+		lrpLogger.Error("failed-requesting-container-allocation", err)
+		failedLRPs := make([]rep.LRP, 0, len(requests))
+		for i := range requests {
+			lrp := lrpMap[requests[i].Guid]
+			a.reportAllocationError(lrp, requests[i].Guid, err)
+			failedLRPs = append(failedLRPs, *lrp)
+		}
+		return failedLRPs
+	}
+
+	lrpLogger.Info("succeeded-requesting-container-allocation", lager.Data{"num-failed-to-allocate": len(failures)})
+	var failedLRPs []rep.LRP
+	for i := range failures {
+		failure := &failures[i]
+		data := lager.Data{"failed-request": &failure.AllocationRequest, "container-guid": failure.Guid}
+		lrp, found := lrpMap[failure.Guid]
+		if found {
+			data["process-guid"] = lrp.ProcessGuid
+			data["index"] = lrp.Index
+		}
+		lrpLogger.Error("container-allocation-failure", failure, data)
+		if found {
+			failedLRPs = append(failedLRPs, *lrp)
+			a.reportAllocationError(lrp, failure.Guid, failure)
+		}
+	}
+	return failedLRPs
+}
+
+// allocateTasks requests containers for the task allocation requests and
+// returns the tasks that could not be allocated.
+func (a *AuctionCellRep) allocateTasks(logger, taskLogger lager.Logger, requests []executor.AllocationRequest, taskMap map[string]*rep.Task) []rep.Task {
+	taskLogger.Info("requesting-container-allocation", lager.Data{"num-requesting-allocation": len(requests)})
+	failures, err := a.allocateContainers(logger, requests, a.queuedTaskAllocation(taskLogger, taskMap))
+	if err != nil {
+		taskLogger.Error("failed-requesting-container-allocation", err)
+		failedTasks := make([]rep.Task, 0, len(requests))
+		for i := range requests {
+			failedTasks = append(failedTasks, *taskMap[requests[i].Guid])
+		}
+		return failedTasks
+	}
+
+	taskLogger.Info("succeeded-requesting-container-allocation", lager.Data{"num-failed-to-allocate": len(failures)})
+	var failedTasks []rep.Task
+	for i := range failures {
+		failure := &failures[i]
+		taskLogger.Error("container-allocation-failure", failure, lager.Data{"failed-request": &failure.AllocationRequest})
+		if task, found := taskMap[failure.Guid]; found {
+			failedTasks = append(failedTasks, *task)
+		}
+	}
+	return failedTasks
+}
+
+// allocateContainers requests containers for the allocation requests. When
+// too many containers are already starting, some of the requests are queued
+// instead; queued calls once for each of those, and the function it returns is
+// called once the request has been allocated, has failed or has been dropped.
+func (a *AuctionCellRep) allocateContainers(logger lager.Logger, requests []executor.AllocationRequest, queued func(*executor.AllocationRequest) func(error)) ([]executor.AllocationFailure, error) {
+	if a.allocationSlots == nil {
+		return a.allocateBatch(logger, requests)
+	}
+	return a.allocateInSlots(logger, requests, queued), nil
+}
+
+func (a *AuctionCellRep) allocateBatch(logger lager.Logger, requests []executor.AllocationRequest) ([]executor.AllocationFailure, error) {
+	a.statsLock.Lock()
+	for i := range requests {
+		a.inFlight[requests[i].Guid] = struct{}{}
+	}
+	if len(a.inFlight) > a.peakInFlight {
+		a.peakInFlight = len(a.inFlight)
+	}
+	a.statsLock.Unlock()
+
+	failures, err := a.allocateWithRetry(logger, requests)
+	a.history.record(requests, failures, err)
+	a.sickCell.recordAllocationFailures(requests, failures, err)
+	a.countAllocationFailures(logger, requests, failures, err)
+
+	a.statsLock.Lock()
+	defer a.statsLock.Unlock()
+	for i := range requests {
+		delete(a.inFlight, requests[i].Guid)
+	}
+	if err != nil {
+		a.failed += len(requests)
+	} else {
+		a.failed += len(failures)
+		a.provisioned += len(requests) - len(failures)
+	}
+
+	return failures, err
+}
+
+func (a *AuctionCellRep) reportAllocationError(lrp *rep.LRP, containerGuid string, err error) {
+	if lrp == nil {
+		return
+	}
+	a.lifecycleErrors.Report(rep.LifecycleError{
+		ProcessGuid:   lrp.ProcessGuid,
+		Index:         lrp.Index,
+		ContainerGuid: containerGuid,
+		Phase:         rep.LifecyclePhaseAllocate,
+		Err:           err,
+	})
+}
+
+// countAllocationFailures increments the allocation failure counter once for
+// every container the executor failed to allocate.
+func (a *AuctionCellRep) countAllocationFailures(logger lager.Logger, requests []executor.AllocationRequest, failures []executor.AllocationFailure, err error) {
+	failed := len(failures)
+	if err != nil {
+		failed = len(requests)
+	}
+
+	for i := 0; i < failed; i++ {
+		sendErr := a.metronClient.IncrementCounter(repContainerAllocationFailed)
+		if sendErr != nil {
+			logger.Error("failed-to-increment-allocation-failure-counter", sendErr)
+			return
+		}
+	}
+}
+
+func (a *AuctionCellRep) recordFailed(count int) {
+	a.statsLock.Lock()
+	defer a.statsLock.Unlock()
+	a.failed += count
+}
+
+func (a *AuctionCellRep) recordEvicted(count int) {
+	a.statsLock.Lock()
+	defer a.statsLock.Unlock()
+	a.evicted += count
+}
+
+// History returns the provisioning events since the given time, optionally
+// limited to those for a single container or process guid.
+func (a *AuctionCellRep) History(since time.Time, guid string) []rep.ProvisioningEvent {
+	return a.history.query(since, guid)
+}
+
+// Summary reports the work performed by the rep since it was created.
+func (a *AuctionCellRep) Summary() Summary {
+	a.statsLock.Lock()
+	defer a.statsLock.Unlock()
+
+	return Summary{
+		Provisioned:  a.provisioned,
+		Failed:       a.failed,
+		Evicted:      a.evicted,
+		PeakInFlight: a.peakInFlight,
+		Uptime:       a.clock.Since(a.startTime),
+	}
+}
+
+// InFlight returns the guids of the containers the rep is asking the executor
+// to allocate right now, in sorted order. When the cell bounds how many
+// containers start at once, it also returns those holding a slot, which have
+// been allocated but not yet started.
+func (a *AuctionCellRep) InFlight() []string {
+	inFlight := map[string]struct{}{}
+
+	a.statsLock.Lock()
+	for guid := range a.inFlight {
+		inFlight[guid] = struct{}{}
+	}
+	a.statsLock.Unlock()
+
+	if a.allocationSlots != nil {
+		a.allocationSlots.addGuids(inFlight)
+	}
+
+	guids := make([]string, 0, len(inFlight))
+	for guid := range inFlight {
+		guids = append(guids, guid)
+	}
+	sort.Strings(guids)
+	return guids
+}
+
+// withoutExistingLRPs filters out LRP instances that already have a container
+// on this cell, so that work delivered more than once does not result in
+// duplicate containers.
+func (a *AuctionCellRep) withoutExistingLRPs(logger lager.Logger, lrps []rep.LRP) []rep.LRP {
+	containers, err := a.client.ListContainers(logger)
+	if err != nil {
+		logger.Error("failed-to-list-containers", err)
+		return lrps
+	}
+
+	existing := make(map[string]struct{}, len(containers))
+	for i := range containers {
+		container := &containers[i]
+		if container.State == executor.StateCompleted || container.Tags[rep.LifecycleTag] != rep.LRPLifecycle {
+			continue
+		}
+
+		key, err := rep.ActualLRPKeyFromTags(container.Tags)
+		if err != nil {
+			continue
+		}
+		lrp := rep.LRP{ActualLRPKey: *key}
+		existing[lrp.Identifier()] = struct{}{}
+	}
+
+	missingLRPs := make([]rep.LRP, 0, len(lrps))
+	for i := range lrps {
+		if _, found := existing[lrps[i].Identifier()]; found {
+			continue
+		}
+		missingLRPs = append(missingLRPs, lrps[i])
+	}
+
+	if skipped := len(lrps) - len(missingLRPs); skipped > 0 {
+		logger.Info("skipping-lrps-with-existing-containers", lager.Data{"num-skipped": skipped})
+		for i := 0; i < skipped; i++ {
+			err := a.metronClient.IncrementCounter(repEventsDeduplicated)
+			if err != nil {
+				logger.Error("failed-to-increment-events-deduplicated-counter", err)
+				break
+			}
+		}
+	}
+
+	return missingLRPs
+}
+
+// rampAvailableResources scales the available resources linearly from zero at
+// startup to their full value at the end of the startup quiet period, so that
+// the cell is not flooded with work before the executor has settled.
+func (a *AuctionCellRep) rampAvailableResources(logger lager.Logger, resources executor.ExecutorResources) executor.ExecutorResources {
+	elapsed := a.clock.Since(a.startTime)
+	if elapsed >= a.startupQuietPeriod {
+		return resources
+	}
+
+	fraction := float64(elapsed) / float64(a.startupQuietPeriod)
+	logger.Info("reducing-available-resources-during-startup", lager.Data{
+		"fraction":             fraction,
+		"startup-quiet-period": a.startupQuietPeriod.String(),
+	})
+
+	return executor.ExecutorResources{
+		MemoryMB:   int(float64(resources.MemoryMB) * fraction),
+		DiskMB:     int(float64(resources.DiskMB) * fraction),
+		Containers: int(float64(resources.Containers) * fraction),
+	}
+}
+
+func (a *AuctionCellRep) lrpsToAllocationRequest(logger lager.Logger, lrps []rep.LRP, auctionedAt time.Time) ([]executor.AllocationRequest, map[string]*rep.LRP, []rep.LRP) {
 	requests := make([]executor.AllocationRequest, 0, len(lrps))
 	untranslatedLRPs := make([]rep.LRP, 0)
 	lrpMap := make(map[string]*rep.LRP, len(lrps))
@@ -272,7 +691,7 @@ func (a *AuctionCellRep) lrpsToAllocationRequest(lrps []rep.LRP) ([]executor.All
 		lrp := &lrps[i]
 		tags := executor.Tags{}
 
-		instanceGuid, err := a.generateInstanceGuid()
+		instanceGuid, err := a.generateInstanceGuid(lrp, auctionedAt)
 		if err != nil {
 			untranslatedLRPs = append(untranslatedLRPs, *lrp)
 			continue
@@ -284,23 +703,34 @@ func (a *AuctionCellRep) lrpsToAllocationRequest(lrps []rep.LRP) ([]executor.All
 		tags[rep.LifecycleTag] = rep.LRPLifecycle
 		tags[rep.InstanceGuidTag] = instanceGuid
 
-		rootFSPath, err := PathForRootFS(lrp.RootFs, a.stackPathMap)
+		containerGuid := rep.LRPContainerGuid(lrp.ProcessGuid, instanceGuid)
+
+		rootFSPath, err := a.pathForRootFS(lrp.RootFs)
 		if err != nil {
+			if err == ErrPreloadedRootFSNotFound {
+				a.reportStackMismatch(logger, lrp, containerGuid)
+			}
 			untranslatedLRPs = append(untranslatedLRPs, *lrp)
 			continue
 		}
 
-		containerGuid := rep.LRPContainerGuid(lrp.ProcessGuid, instanceGuid)
-		lrpMap[containerGuid] = lrp
-
 		resource := executor.NewResource(int(lrp.MemoryMB), int(lrp.DiskMB), int(lrp.MaxPids), rootFSPath)
-		requests = append(requests, executor.NewAllocationRequest(containerGuid, &resource, tags))
+		request := executor.NewAllocationRequest(containerGuid, &resource, tags)
+		err = a.decorateAllocationRequest(logger, &request)
+		if err != nil {
+			logger.Error("failed-to-decorate-allocation-request", err, lager.Data{"container-guid": containerGuid})
+			untranslatedLRPs = append(untranslatedLRPs, *lrp)
+			continue
+		}
+
+		lrpMap[containerGuid] = lrp
+		requests = append(requests, request)
 	}
 
 	return requests, lrpMap, untranslatedLRPs
 }
 
-func (a *AuctionCellRep) tasksToAllocationRequests(tasks []rep.Task) ([]executor.AllocationRequest, map[string]*rep.Task, []rep.Task) {
+func (a *AuctionCellRep) tasksToAllocationRequests(logger lager.Logger, tasks []rep.Task) ([]executor.AllocationRequest, map[string]*rep.Task, []rep.Task) {
 	failedTasks := make([]rep.Task, 0)
 	taskMap := make(map[string]*rep.Task, len(tasks))
 	requests := make([]executor.AllocationRequest, 0, len(tasks))
@@ -308,7 +738,7 @@ func (a *AuctionCellRep) tasksToAllocationRequests(tasks []rep.Task) ([]executor
 	for i := range tasks {
 		task := &tasks[i]
 		taskMap[task.TaskGuid] = task
-		rootFSPath, err := PathForRootFS(task.RootFs, a.stackPathMap)
+		rootFSPath, err := a.pathForRootFS(task.RootFs)
 		if err != nil {
 			failedTasks = append(failedTasks, *task)
 			continue
@@ -318,7 +748,15 @@ func (a *AuctionCellRep) tasksToAllocationRequests(tasks []rep.Task) ([]executor
 		tags[rep.DomainTag] = task.Domain
 
 		resource := executor.NewResource(int(task.MemoryMB), int(task.DiskMB), int(task.MaxPids), rootFSPath)
-		requests = append(requests, executor.NewAllocationRequest(task.TaskGuid, &resource, tags))
+		request := executor.NewAllocationRequest(task.TaskGuid, &resource, tags)
+		err = a.decorateAllocationRequest(logger, &request)
+		if err != nil {
+			logger.Error("failed-to-decorate-allocation-request", err, lager.Data{"task-guid": task.TaskGuid})
+			failedTasks = append(failedTasks, *task)
+			continue
+		}
+
+		requests = append(requests, request)
 	}
 
 	return requests, taskMap, failedTasks
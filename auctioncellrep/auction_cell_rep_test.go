@@ -2,17 +2,28 @@ package auctioncellrep_test
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
 	"code.cloudfoundry.org/executor"
 	fake_client "code.cloudfoundry.org/executor/fakes"
+	mfakes "code.cloudfoundry.org/go-loggregator/loggregator_v2/fakes"
+	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/auctioncellrep"
+	"code.cloudfoundry.org/rep/auctioncellrep/auctioncellrepfakes"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
+	"code.cloudfoundry.org/rep/repfakes"
+	"code.cloudfoundry.org/rep/schedulertest"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 )
 
 var _ = Describe("AuctionCellRep", func() {
@@ -24,26 +35,51 @@ var _ = Describe("AuctionCellRep", func() {
 
 	var (
 		cellRep            auctioncellrep.AuctionCellClient
+		config             auctioncellrep.Config
 		client             *fake_client.FakeClient
 		logger             *lagertest.TestLogger
 		evacuationReporter *fake_evacuation_context.FakeEvacuationReporter
+		fakeClock          *fakeclock.FakeClock
+		diskChecker        *auctioncellrepfakes.FakeDiskPressureChecker
+		decorator          *auctioncellrepfakes.FakeAllocationDecorator
+		fakeMetronClient   *mfakes.FakeClient
+		bbsHealth          *repfakes.FakeBBSHealth
+		lifecycleErrors    []rep.LifecycleError
 
 		expectedGuid, linuxRootFSURL string
 		commonErr, expectedGuidError error
 
-		fakeGenerateContainerGuid func() (string, error)
-
-		placementTags, optionalPlacementTags []string
+		fakeGenerateContainerGuid auctioncellrep.InstanceGuidGenerator
 	)
 
 	BeforeEach(func() {
 		client = new(fake_client.FakeClient)
 		logger = lagertest.NewTestLogger("test")
 		evacuationReporter = &fake_evacuation_context.FakeEvacuationReporter{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		diskChecker = new(auctioncellrepfakes.FakeDiskPressureChecker)
+		decorator = new(auctioncellrepfakes.FakeAllocationDecorator)
+		fakeMetronClient = new(mfakes.FakeClient)
+		bbsHealth = new(repfakes.FakeBBSHealth)
+		lifecycleErrors = nil
+		config = auctioncellrep.Config{
+			CellID:                expectedCellID,
+			PreloadedStackPathMap: rep.StackPathMap{linuxStack: linuxPath},
+			ArbitraryRootFSes:     []string{"docker"},
+			Zone:                  "the-zone",
+			BBSOutage: auctioncellrep.BBSOutageConfig{
+				Health:         bbsHealth,
+				OnReadFailure:  auctioncellrep.BBSOutagePolicyContinue,
+				OnWriteFailure: auctioncellrep.BBSOutagePolicyContinue,
+			},
+			LifecycleErrors: func(err rep.LifecycleError) {
+				lifecycleErrors = append(lifecycleErrors, err)
+			},
+		}
 
 		expectedGuid = "container-guid"
 		expectedGuidError = nil
-		fakeGenerateContainerGuid = func() (string, error) {
+		fakeGenerateContainerGuid = func(*rep.LRP, time.Time) (string, error) {
 			return expectedGuid, expectedGuidError
 		}
 		linuxRootFSURL = models.PreloadedRootFS(linuxStack)
@@ -54,15 +90,14 @@ var _ = Describe("AuctionCellRep", func() {
 
 	JustBeforeEach(func() {
 		cellRep = auctioncellrep.New(
-			expectedCellID,
-			rep.StackPathMap{linuxStack: linuxPath},
-			[]string{"docker"},
-			"the-zone",
+			config,
 			fakeGenerateContainerGuid,
 			client,
 			evacuationReporter,
-			placementTags,
-			optionalPlacementTags,
+			fakeClock,
+			diskChecker,
+			decorator,
+			fakeMetronClient,
 		)
 	})
 
@@ -128,8 +163,8 @@ var _ = Describe("AuctionCellRep", func() {
 			}
 
 			volumeDrivers = []string{"lewis", "nico", "sebastian", "felipe"}
-			placementTags = []string{}
-			optionalPlacementTags = []string{}
+			config.PlacementTags = []string{}
+			config.OptionalPlacementTags = []string{}
 
 			client.TotalResourcesReturns(totalResources, nil)
 			client.RemainingResourcesReturns(availableResources, nil)
@@ -186,6 +221,87 @@ var _ = Describe("AuctionCellRep", func() {
 			Expect(state.StartingContainerCount).To(Equal(3))
 
 			Expect(state.VolumeDrivers).To(ConsistOf(volumeDrivers))
+
+			Expect(state.CellID).To(Equal(expectedCellID))
+			Expect(state.RepVersion).To(Equal(rep.Version))
+		})
+
+		Context("when the executor reports negative remaining resources", func() {
+			BeforeEach(func() {
+				client.RemainingResourcesReturns(executor.ExecutorResources{
+					MemoryMB:   -128,
+					DiskMB:     256,
+					Containers: -1,
+				}, nil)
+			})
+
+			It("reports them as zero", func() {
+				state, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(state.AvailableResources).To(Equal(rep.Resources{
+					MemoryMB:   0,
+					DiskMB:     256,
+					Containers: 0,
+				}))
+			})
+
+			It("logs the anomaly and emits the number of negative resources", func() {
+				_, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger).To(gbytes.Say("clamping-negative-remaining-resources"))
+				Expect(fakeMetronClient.SendMetricCallCount()).To(Equal(1))
+				name, value := fakeMetronClient.SendMetricArgsForCall(0)
+				Expect(name).To(Equal("RepNegativeRemainingResources"))
+				Expect(value).To(Equal(2))
+			})
+		})
+
+		Context("when a startup quiet period is configured", func() {
+			BeforeEach(func() {
+				config.StartupQuietPeriod = 10 * time.Second
+			})
+
+			It("reports no available resources immediately after startup", func() {
+				state, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.AvailableResources).To(Equal(rep.Resources{}))
+			})
+
+			It("ramps the available resources up over the quiet period", func() {
+				fakeClock.Increment(5 * time.Second)
+
+				state, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.AvailableResources).To(Equal(rep.Resources{
+					MemoryMB:   int32(availableResources.MemoryMB / 2),
+					DiskMB:     int32(availableResources.DiskMB / 2),
+					Containers: availableResources.Containers / 2,
+				}))
+			})
+
+			It("does not reduce the total resources", func() {
+				state, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.TotalResources).To(Equal(rep.Resources{
+					MemoryMB:   int32(totalResources.MemoryMB),
+					DiskMB:     int32(totalResources.DiskMB),
+					Containers: totalResources.Containers,
+				}))
+			})
+
+			It("reports the real available resources once the quiet period has elapsed", func() {
+				fakeClock.Increment(10 * time.Second)
+
+				state, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.AvailableResources).To(Equal(rep.Resources{
+					MemoryMB:   int32(availableResources.MemoryMB),
+					DiskMB:     int32(availableResources.DiskMB),
+					Containers: availableResources.Containers,
+				}))
+			})
 		})
 
 		Context("when the cell is not healthy", func() {
@@ -235,27 +351,103 @@ var _ = Describe("AuctionCellRep", func() {
 
 		Context("when placement tags have been set", func() {
 			BeforeEach(func() {
-				placementTags = []string{"quack", "oink"}
+				config.PlacementTags = []string{"quack", "oink"}
 			})
 
 			It("returns the tags as part of the state", func() {
 				state, healthy, err := cellRep.State(logger)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(healthy).To(BeTrue())
-				Expect(state.PlacementTags).To(ConsistOf(placementTags))
+				Expect(state.PlacementTags).To(ConsistOf(config.PlacementTags))
 			})
 		})
 
 		Context("when optional placement tags have been set", func() {
 			BeforeEach(func() {
-				optionalPlacementTags = []string{"baa", "cluck"}
+				config.OptionalPlacementTags = []string{"baa", "cluck"}
 			})
 
 			It("returns the tags as part of the state", func() {
 				state, healthy, err := cellRep.State(logger)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(healthy).To(BeTrue())
-				Expect(state.OptionalPlacementTags).To(ConsistOf(optionalPlacementTags))
+				Expect(state.OptionalPlacementTags).To(ConsistOf(config.OptionalPlacementTags))
+			})
+		})
+
+		Context("when the auction score is weighted for load", func() {
+			BeforeEach(func() {
+				config.LoadWeight = auctioncellrep.LoadWeightConfig{Weight: 1}
+				client.TotalResourcesReturns(executor.ExecutorResources{MemoryMB: 1000, DiskMB: 1000, Containers: 10}, nil)
+			})
+
+			placementScore := func() float64 {
+				score, err := cellRep.(*auctioncellrep.AuctionCellRep).PlacementScore(logger)
+				Expect(err).NotTo(HaveOccurred())
+				return score
+			}
+
+			loadPenalty := func() float64 {
+				state, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				return state.LoadPenalty
+			}
+
+			Context("when the cell is heavily loaded", func() {
+				BeforeEach(func() {
+					client.RemainingResourcesReturns(executor.ExecutorResources{MemoryMB: 100, DiskMB: 500, Containers: 5}, nil)
+				})
+
+				It("reports a low placement score", func() {
+					Expect(placementScore()).To(BeNumerically("~", 0.1, 0.001))
+				})
+
+				It("reports a large load penalty", func() {
+					Expect(loadPenalty()).To(BeNumerically("~", 0.9, 0.001))
+				})
+			})
+
+			Context("when the cell is lightly loaded", func() {
+				BeforeEach(func() {
+					client.RemainingResourcesReturns(executor.ExecutorResources{MemoryMB: 900, DiskMB: 950, Containers: 9}, nil)
+				})
+
+				It("reports a high placement score", func() {
+					Expect(placementScore()).To(BeNumerically("~", 0.9, 0.001))
+				})
+
+				It("reports a small load penalty", func() {
+					Expect(loadPenalty()).To(BeNumerically("~", 0.1, 0.001))
+				})
+			})
+
+			Context("when a scorer is configured", func() {
+				BeforeEach(func() {
+					config.LoadWeight.Scorer = func(total, remaining executor.ExecutorResources) float64 {
+						return 0.5
+					}
+				})
+
+				It("scores and weights by it", func() {
+					Expect(placementScore()).To(Equal(0.5))
+					Expect(loadPenalty()).To(Equal(0.5))
+				})
+			})
+
+			Context("when the weight is zero", func() {
+				BeforeEach(func() {
+					config.LoadWeight.Weight = 0
+				})
+
+				It("reports no load penalty", func() {
+					Expect(loadPenalty()).To(BeZero())
+				})
+			})
+
+			It("does not ask the executor for resources again", func() {
+				loadPenalty()
+				Expect(client.TotalResourcesCallCount()).To(Equal(1))
+				Expect(client.RemainingResourcesCallCount()).To(Equal(1))
 			})
 		})
 	})
@@ -272,11 +464,7 @@ var _ = Describe("AuctionCellRep", func() {
 			BeforeEach(func() {
 				evacuationReporter.EvacuatingReturns(true)
 
-				lrp := rep.NewLRP(
-					models.NewActualLRPKey("process-guid", int32(expectedIndex), "tests"),
-					rep.NewResource(2048, 1024, 100),
-					rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}),
-				)
+				lrp := schedulertest.NewLRP("process-guid", int32(expectedIndex)).WithResource(2048, 1024).Build()
 
 				task := rep.NewTask(
 					"the-task-guid",
@@ -296,6 +484,221 @@ var _ = Describe("AuctionCellRep", func() {
 			})
 		})
 
+		Context("when in dry-run mode", func() {
+			BeforeEach(func() {
+				config.DryRun = true
+
+				work = rep.Work{
+					LRPs: []rep.LRP{schedulertest.NewLRP("process-guid", int32(expectedIndex)).WithResource(2048, 1024).Build()},
+					Tasks: []rep.Task{rep.NewTask(
+						"the-task-guid",
+						"tests",
+						rep.NewResource(2048, 1024, 100),
+						rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}),
+					)},
+				}
+			})
+
+			It("logs the containers it would allocate and hands all the work back", func() {
+				Expect(cellRep.Perform(logger, work)).To(Equal(work))
+				Expect(logger).To(gbytes.Say("lrp-allocate-instances.dry-run-would-allocate-container.*process-guid"))
+				Expect(logger).To(gbytes.Say("task-allocate-instances.dry-run-would-allocate-container.*the-task-guid"))
+			})
+
+			It("does not allocate any containers", func() {
+				cellRep.Perform(logger, work)
+				Expect(client.AllocateContainersCallCount()).To(BeZero())
+				Expect(cellRep.(*auctioncellrep.AuctionCellRep).InFlight()).To(BeEmpty())
+			})
+
+			It("stays in the auction and hands back the work it is then given", func() {
+				state, healthy, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(healthy).To(BeTrue())
+				Expect(state.Evacuating).To(BeFalse())
+				Expect(state.Draining).To(BeFalse())
+
+				Expect(cellRep.Perform(logger, work)).To(Equal(work))
+				Expect(logger).To(gbytes.Say("dry-run-would-allocate-container"))
+				Expect(client.AllocateContainersCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the disk is under pressure", func() {
+			BeforeEach(func() {
+				diskChecker.UnderPressureReturns(true)
+
+				work = rep.Work{
+					LRPs: []rep.LRP{schedulertest.NewLRP("process-guid", int32(expectedIndex)).WithResource(2048, 1024).Build()},
+					Tasks: []rep.Task{rep.NewTask(
+						"the-task-guid",
+						"tests",
+						rep.NewResource(2048, 1024, 100),
+						rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}),
+					)},
+				}
+			})
+
+			It("returns all work it was given without allocating containers", func() {
+				Expect(cellRep.Perform(logger, work)).To(Equal(work))
+				Expect(client.AllocateContainersCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when BBS writes are failing", func() {
+			BeforeEach(func() {
+				bbsHealth.WritesFailingReturns(true)
+
+				work = rep.Work{
+					LRPs: []rep.LRP{schedulertest.NewLRP("process-guid", int32(expectedIndex)).WithResource(2048, 1024).Build()},
+				}
+				client.AllocateContainersReturns([]executor.AllocationFailure{}, nil)
+			})
+
+			Context("and the policy is to reject work", func() {
+				BeforeEach(func() {
+					config.BBSOutage.OnWriteFailure = auctioncellrep.BBSOutagePolicyReject
+				})
+
+				It("returns all work it was given without allocating containers", func() {
+					Expect(cellRep.Perform(logger, work)).To(Equal(work))
+					Expect(client.AllocateContainersCallCount()).To(Equal(0))
+					Expect(logger).To(gbytes.Say("rejecting-work-while-bbs-writes-fail"))
+				})
+
+				It("reports the cell as degraded", func() {
+					state, _, err := cellRep.State(logger)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(state.Degraded).To(BeTrue())
+
+					bbsHealth.WritesFailingReturns(false)
+					state, _, err = cellRep.State(logger)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(state.Degraded).To(BeFalse())
+				})
+			})
+
+			Context("and the policy is to continue", func() {
+				It("allocates containers as usual", func() {
+					Expect(cellRep.Perform(logger, work)).To(BeZero())
+					Expect(client.AllocateContainersCallCount()).To(Equal(1))
+				})
+
+				It("does not report the cell as degraded", func() {
+					state, _, err := cellRep.State(logger)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(state.Degraded).To(BeFalse())
+				})
+			})
+		})
+
+		Context("when BBS reads are failing and the policy is to reject work", func() {
+			BeforeEach(func() {
+				bbsHealth.ReadsFailingReturns(true)
+				config.BBSOutage.OnReadFailure = auctioncellrep.BBSOutagePolicyReject
+
+				work = rep.Work{
+					Tasks: []rep.Task{rep.NewTask(
+						"the-task-guid",
+						"tests",
+						rep.NewResource(2048, 1024, 100),
+						rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}),
+					)},
+				}
+			})
+
+			It("returns all work it was given without allocating containers", func() {
+				Expect(cellRep.Perform(logger, work)).To(Equal(work))
+				Expect(client.AllocateContainersCallCount()).To(Equal(0))
+				Expect(logger).To(gbytes.Say("rejecting-work-while-bbs-reads-fail"))
+			})
+		})
+
+		Context("when an allocation decorator is configured", func() {
+			var lrp rep.LRP
+
+			BeforeEach(func() {
+				lrp = schedulertest.NewLRP("process-guid", int32(expectedIndex)).WithResource(2048, 1024).Build()
+				task = rep.NewTask(
+					"the-task-guid",
+					"tests",
+					rep.NewResource(2048, 1024, 100),
+					rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}),
+				)
+				work = rep.Work{LRPs: []rep.LRP{lrp}, Tasks: []rep.Task{task}}
+
+				client.AllocateContainersReturns([]executor.AllocationFailure{}, nil)
+			})
+
+			It("sends the decorated allocation requests to the executor", func() {
+				decorator.DecorateStub = func(_ lager.Logger, request *executor.AllocationRequest) error {
+					request.Tags["datacenter"] = "dc1"
+					return nil
+				}
+
+				failedWork, err := cellRep.Perform(logger, work)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork).To(BeZero())
+
+				Expect(decorator.DecorateCallCount()).To(Equal(2))
+				Expect(client.AllocateContainersCallCount()).To(Equal(2))
+				for i := 0; i < 2; i++ {
+					_, requests := client.AllocateContainersArgsForCall(i)
+					Expect(requests).To(HaveLen(1))
+					Expect(requests[0].Tags).To(HaveKeyWithValue("datacenter", "dc1"))
+				}
+			})
+
+			Context("when decorating fails", func() {
+				BeforeEach(func() {
+					decorator.DecorateStub = func(_ lager.Logger, request *executor.AllocationRequest) error {
+						if request.Tags[rep.LifecycleTag] == rep.LRPLifecycle {
+							return errors.New("boom")
+						}
+						return nil
+					}
+				})
+
+				It("fails only the work that could not be decorated", func() {
+					failedWork, err := cellRep.Perform(logger, work)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.LRPs).To(ConsistOf(lrp))
+					Expect(failedWork.Tasks).To(BeEmpty())
+				})
+			})
+
+			Context("when the decorator changes a reserved tag", func() {
+				BeforeEach(func() {
+					decorator.DecorateStub = func(_ lager.Logger, request *executor.AllocationRequest) error {
+						request.Tags[rep.DomainTag] = "another-domain"
+						return nil
+					}
+				})
+
+				It("rejects the decorated requests", func() {
+					failedWork, err := cellRep.Perform(logger, work)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork).To(Equal(work))
+					Expect(logger).To(gbytes.Say("failed-to-decorate-allocation-request"))
+				})
+			})
+
+			Context("when the decorator changes the guid", func() {
+				BeforeEach(func() {
+					decorator.DecorateStub = func(_ lager.Logger, request *executor.AllocationRequest) error {
+						request.Guid = "another-guid"
+						return nil
+					}
+				})
+
+				It("rejects the decorated requests", func() {
+					failedWork, err := cellRep.Perform(logger, work)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork).To(Equal(work))
+				})
+			})
+		})
+
 		Describe("performing starts", func() {
 			const (
 				expectedIndexOneString = "1"
@@ -315,7 +718,7 @@ var _ = Describe("AuctionCellRep", func() {
 				guidChan <- expectedGuidOne
 				guidChan <- expectedGuidTwo
 
-				fakeGenerateContainerGuid = func() (string, error) {
+				fakeGenerateContainerGuid = func(*rep.LRP, time.Time) (string, error) {
 					return <-guidChan, nil
 				}
 
@@ -402,95 +805,282 @@ var _ = Describe("AuctionCellRep", func() {
 						Expect(err).NotTo(HaveOccurred())
 						Expect(failedWork.LRPs).To(ConsistOf(lrpAuctionOne))
 					})
+
+					It("logs the failure with the container and process guids", func() {
+						_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne, lrpAuctionTwo}})
+						Expect(err).NotTo(HaveOccurred())
+
+						var failureLogs []lager.LogFormat
+						for _, log := range logger.Logs() {
+							if strings.HasSuffix(log.Message, ".container-allocation-failure") {
+								failureLogs = append(failureLogs, log)
+							}
+						}
+						Expect(failureLogs).To(HaveLen(1))
+						Expect(failureLogs[0].Data["container-guid"]).To(Equal(rep.LRPContainerGuid(lrpAuctionOne.ProcessGuid, expectedGuidOne)))
+						Expect(failureLogs[0].Data["process-guid"]).To(Equal(lrpAuctionOne.ProcessGuid))
+					})
+
+					It("reports an allocate error for the LRP", func() {
+						_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne, lrpAuctionTwo}})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(lifecycleErrors).To(HaveLen(1))
+						Expect(lifecycleErrors[0].Phase).To(Equal(rep.LifecyclePhaseAllocate))
+						Expect(lifecycleErrors[0].ProcessGuid).To(Equal(lrpAuctionOne.ProcessGuid))
+						Expect(lifecycleErrors[0].Index).To(Equal(lrpAuctionOne.Index))
+						Expect(lifecycleErrors[0].ContainerGuid).To(Equal(rep.LRPContainerGuid(lrpAuctionOne.ProcessGuid, expectedGuidOne)))
+					})
 				})
 			})
 
-			Context("when an LRP Auction specifies a preloaded RootFSes for which it cannot determine a RootFS path", func() {
+			Context("when the cell has several preloaded stacks", func() {
+				const windowsPath = "/data/rootfs/windows"
+
 				BeforeEach(func() {
-					lrpAuctionOne.RootFs = linuxRootFSURL
-					lrpAuctionTwo.RootFs = "preloaded:not-on-cell"
+					config.PreloadedStackPathMap = rep.StackPathMap{linuxStack: linuxPath, "windows2016": windowsPath}
+					client.AllocateContainersReturns([]executor.AllocationFailure{}, nil)
 				})
 
-				It("only makes container allocation requests for the remaining LRP Auctions", func() {
-					_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne, lrpAuctionTwo}})
+				It("allocates an LRP whose stack is one of them", func() {
+					lrpAuctionOne.RootFs = models.PreloadedRootFS("windows2016")
+					failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne}})
 					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork).To(BeZero())
 
 					Expect(client.AllocateContainersCallCount()).To(Equal(1))
-					_, arg := client.AllocateContainersArgsForCall(0)
-					Expect(arg).To(ConsistOf(
-						executor.AllocationRequest{
-							Guid: rep.LRPContainerGuid(lrpAuctionOne.ProcessGuid, expectedGuidOne),
-							Tags: executor.Tags{
-								rep.LifecycleTag:    rep.LRPLifecycle,
-								rep.DomainTag:       lrpAuctionOne.Domain,
-								rep.ProcessGuidTag:  lrpAuctionOne.ProcessGuid,
-								rep.InstanceGuidTag: expectedGuidOne,
-								rep.ProcessIndexTag: expectedIndexOneString,
-							},
-							Resource: executor.NewResource(int(lrpAuctionOne.MemoryMB), int(lrpAuctionOne.DiskMB), int(lrpAuctionOne.MaxPids), linuxPath),
-						},
-					))
+					_, requests := client.AllocateContainersArgsForCall(0)
+					Expect(requests).To(HaveLen(1))
+					Expect(requests[0].RootFSPath).To(Equal(windowsPath))
 				})
 
-				It("marks the LRP Auction as failed", func() {
+				It("does not allocate an LRP whose stack is none of them", func() {
+					lrpAuctionOne.RootFs = linuxRootFSURL
+					lrpAuctionTwo.RootFs = models.PreloadedRootFS("cflinuxfs2")
 					failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne, lrpAuctionTwo}})
 					Expect(err).NotTo(HaveOccurred())
-					Expect(failedWork.LRPs).To(ContainElement(lrpAuctionTwo))
+					Expect(failedWork.LRPs).To(ConsistOf(lrpAuctionTwo))
+
+					Expect(client.AllocateContainersCallCount()).To(Equal(1))
+					_, requests := client.AllocateContainersArgsForCall(0)
+					Expect(requests).To(HaveLen(1))
+					Expect(requests[0].RootFSPath).To(Equal(linuxPath))
 				})
 
-				Context("when all remaining containers can be successfully allocated", func() {
+				It("does not report the stack mismatch by default", func() {
+					lrpAuctionOne.RootFs = models.PreloadedRootFS("cflinuxfs2")
+					_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne}})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(lifecycleErrors).To(BeEmpty())
+					Expect(logger).NotTo(gbytes.Say("lrp-stack-not-supported"))
+				})
+
+				Context("when stack mismatches are reported", func() {
 					BeforeEach(func() {
-						client.AllocateContainersReturns([]executor.AllocationFailure{}, nil)
+						config.ReportStackMismatches = true
 					})
 
-					It("does not mark any additional LRP Auctions as failed", func() {
-						failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne, lrpAuctionTwo}})
+					It("reports an LRP whose stack is none of them", func() {
+						lrpAuctionOne.RootFs = models.PreloadedRootFS("cflinuxfs2")
+						failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne}})
 						Expect(err).NotTo(HaveOccurred())
-						Expect(failedWork.LRPs).To(ConsistOf(lrpAuctionTwo))
+						Expect(failedWork.LRPs).To(ConsistOf(lrpAuctionOne))
+
+						Expect(logger).To(gbytes.Say("lrp-stack-not-supported"))
+						Expect(lifecycleErrors).To(HaveLen(1))
+						Expect(lifecycleErrors[0].Phase).To(Equal(rep.LifecyclePhasePlace))
+						Expect(lifecycleErrors[0].ProcessGuid).To(Equal(lrpAuctionOne.ProcessGuid))
+						Expect(lifecycleErrors[0].Err).To(Equal(auctioncellrep.ErrPreloadedRootFSNotFound))
 					})
-				})
 
-				Context("when a remaining container fails to be allocated", func() {
-					BeforeEach(func() {
-						resource := executor.NewResource(int(lrpAuctionOne.MemoryMB), int(lrpAuctionOne.DiskMB), int(lrpAuctionOne.MaxPids), "rootfs")
-						tags := executor.Tags{}
-						tags[rep.ProcessGuidTag] = lrpAuctionOne.ProcessGuid
-						allocationRequest := executor.NewAllocationRequest(
-							rep.LRPContainerGuid(lrpAuctionOne.ProcessGuid, expectedGuidOne),
-							&resource,
-							tags,
-						)
-						allocationFailure := executor.NewAllocationFailure(&allocationRequest, commonErr.Error())
-						client.AllocateContainersReturns([]executor.AllocationFailure{allocationFailure}, nil)
+					It("counts the mismatch", func() {
+						lrpAuctionOne.RootFs = models.PreloadedRootFS("cflinuxfs2")
+						_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne}})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+						Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("RepStackMismatches"))
 					})
 
-					It("marks the corresponding LRP Auctions as failed", func() {
-						failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne, lrpAuctionTwo}})
+					It("does not report an LRP on one of them", func() {
+						lrpAuctionOne.RootFs = models.PreloadedRootFS("windows2016")
+						_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne}})
 						Expect(err).NotTo(HaveOccurred())
-						Expect(failedWork.LRPs).To(ConsistOf(lrpAuctionOne, lrpAuctionTwo))
+						Expect(lifecycleErrors).To(BeEmpty())
+						Expect(fakeMetronClient.IncrementCounterCallCount()).To(BeZero())
 					})
 				})
 			})
 
-			Context("when an LRP Auction specifies a blank RootFS URL", func() {
+			Context("when an LRP Auction is already running on the cell", func() {
 				BeforeEach(func() {
-					lrpAuctionOne.RootFs = ""
-				})
-
-				It("makes the correct allocation request for it, passing along the blank path to the executor client", func() {
-					_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne}})
-					Expect(err).NotTo(HaveOccurred())
+					lrpAuctionOne.RootFs = linuxRootFSURL
+					lrpAuctionTwo.RootFs = linuxRootFSURL
 
-					Expect(client.AllocateContainersCallCount()).To(Equal(1))
-					_, arg := client.AllocateContainersArgsForCall(0)
-					Expect(arg).To(ConsistOf(
-						executor.AllocationRequest{
-							Guid: rep.LRPContainerGuid(lrpAuctionOne.ProcessGuid, expectedGuidOne),
+					client.ListContainersReturns([]executor.Container{
+						{
+							Guid:  "existing-guid",
+							State: executor.StateRunning,
 							Tags: executor.Tags{
 								rep.LifecycleTag:    rep.LRPLifecycle,
 								rep.DomainTag:       lrpAuctionOne.Domain,
 								rep.ProcessGuidTag:  lrpAuctionOne.ProcessGuid,
-								rep.InstanceGuidTag: expectedGuidOne,
+								rep.ProcessIndexTag: expectedIndexOneString,
+								rep.InstanceGuidTag: "existing-guid",
+							},
+						},
+					}, nil)
+					client.AllocateContainersReturns([]executor.AllocationFailure{}, nil)
+				})
+
+				It("only allocates containers for the missing LRP Auctions", func() {
+					failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne, lrpAuctionTwo}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork).To(BeZero())
+
+					Expect(client.AllocateContainersCallCount()).To(Equal(1))
+					_, arg := client.AllocateContainersArgsForCall(0)
+					Expect(arg).To(HaveLen(1))
+					Expect(arg[0].Tags[rep.ProcessIndexTag]).To(Equal(expectedIndexTwoString))
+				})
+
+				Context("when the LRP Auction is delivered again", func() {
+					It("does not allocate a duplicate container", func() {
+						_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne}})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(client.AllocateContainersCallCount()).To(Equal(1))
+						_, arg := client.AllocateContainersArgsForCall(0)
+						Expect(arg).To(BeEmpty())
+					})
+
+					It("counts the duplicate delivery", func() {
+						_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne}})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+						Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("RepEventsDeduplicated"))
+					})
+				})
+
+				Context("when listing containers fails", func() {
+					BeforeEach(func() {
+						client.ListContainersReturns(nil, errors.New("boom"))
+					})
+
+					It("allocates containers for all the LRP Auctions", func() {
+						_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne, lrpAuctionTwo}})
+						Expect(err).NotTo(HaveOccurred())
+
+						_, arg := client.AllocateContainersArgsForCall(0)
+						Expect(arg).To(HaveLen(2))
+					})
+				})
+
+				Context("when the existing container has completed", func() {
+					BeforeEach(func() {
+						containers, _ := client.ListContainers(logger)
+						containers[0].State = executor.StateCompleted
+						client.ListContainersReturns(containers, nil)
+					})
+
+					It("allocates a new container for it", func() {
+						_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne}})
+						Expect(err).NotTo(HaveOccurred())
+
+						_, arg := client.AllocateContainersArgsForCall(0)
+						Expect(arg).To(HaveLen(1))
+					})
+				})
+			})
+
+			Context("when an LRP Auction specifies a preloaded RootFSes for which it cannot determine a RootFS path", func() {
+				BeforeEach(func() {
+					lrpAuctionOne.RootFs = linuxRootFSURL
+					lrpAuctionTwo.RootFs = "preloaded:not-on-cell"
+				})
+
+				It("only makes container allocation requests for the remaining LRP Auctions", func() {
+					_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne, lrpAuctionTwo}})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(client.AllocateContainersCallCount()).To(Equal(1))
+					_, arg := client.AllocateContainersArgsForCall(0)
+					Expect(arg).To(ConsistOf(
+						executor.AllocationRequest{
+							Guid: rep.LRPContainerGuid(lrpAuctionOne.ProcessGuid, expectedGuidOne),
+							Tags: executor.Tags{
+								rep.LifecycleTag:    rep.LRPLifecycle,
+								rep.DomainTag:       lrpAuctionOne.Domain,
+								rep.ProcessGuidTag:  lrpAuctionOne.ProcessGuid,
+								rep.InstanceGuidTag: expectedGuidOne,
+								rep.ProcessIndexTag: expectedIndexOneString,
+							},
+							Resource: executor.NewResource(int(lrpAuctionOne.MemoryMB), int(lrpAuctionOne.DiskMB), int(lrpAuctionOne.MaxPids), linuxPath),
+						},
+					))
+				})
+
+				It("marks the LRP Auction as failed", func() {
+					failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne, lrpAuctionTwo}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.LRPs).To(ContainElement(lrpAuctionTwo))
+				})
+
+				Context("when all remaining containers can be successfully allocated", func() {
+					BeforeEach(func() {
+						client.AllocateContainersReturns([]executor.AllocationFailure{}, nil)
+					})
+
+					It("does not mark any additional LRP Auctions as failed", func() {
+						failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne, lrpAuctionTwo}})
+						Expect(err).NotTo(HaveOccurred())
+						Expect(failedWork.LRPs).To(ConsistOf(lrpAuctionTwo))
+					})
+				})
+
+				Context("when a remaining container fails to be allocated", func() {
+					BeforeEach(func() {
+						resource := executor.NewResource(int(lrpAuctionOne.MemoryMB), int(lrpAuctionOne.DiskMB), int(lrpAuctionOne.MaxPids), "rootfs")
+						tags := executor.Tags{}
+						tags[rep.ProcessGuidTag] = lrpAuctionOne.ProcessGuid
+						allocationRequest := executor.NewAllocationRequest(
+							rep.LRPContainerGuid(lrpAuctionOne.ProcessGuid, expectedGuidOne),
+							&resource,
+							tags,
+						)
+						allocationFailure := executor.NewAllocationFailure(&allocationRequest, commonErr.Error())
+						client.AllocateContainersReturns([]executor.AllocationFailure{allocationFailure}, nil)
+					})
+
+					It("marks the corresponding LRP Auctions as failed", func() {
+						failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne, lrpAuctionTwo}})
+						Expect(err).NotTo(HaveOccurred())
+						Expect(failedWork.LRPs).To(ConsistOf(lrpAuctionOne, lrpAuctionTwo))
+					})
+				})
+			})
+
+			Context("when an LRP Auction specifies a blank RootFS URL", func() {
+				BeforeEach(func() {
+					lrpAuctionOne.RootFs = ""
+				})
+
+				It("makes the correct allocation request for it, passing along the blank path to the executor client", func() {
+					_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne}})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(client.AllocateContainersCallCount()).To(Equal(1))
+					_, arg := client.AllocateContainersArgsForCall(0)
+					Expect(arg).To(ConsistOf(
+						executor.AllocationRequest{
+							Guid: rep.LRPContainerGuid(lrpAuctionOne.ProcessGuid, expectedGuidOne),
+							Tags: executor.Tags{
+								rep.LifecycleTag:    rep.LRPLifecycle,
+								rep.DomainTag:       lrpAuctionOne.Domain,
+								rep.ProcessGuidTag:  lrpAuctionOne.ProcessGuid,
+								rep.InstanceGuidTag: expectedGuidOne,
 								rep.ProcessIndexTag: expectedIndexOneString,
 							},
 							Resource: executor.NewResource(int(lrpAuctionOne.MemoryMB), int(lrpAuctionOne.DiskMB), int(lrpAuctionOne.MaxPids), ""),
@@ -751,6 +1341,1395 @@ var _ = Describe("AuctionCellRep", func() {
 			})
 		})
 	})
+
+	Describe("Summary", func() {
+		var lrpOne, lrpTwo rep.LRP
+
+		BeforeEach(func() {
+			lrpOne = schedulertest.NewLRP("process-guid", 1).WithResource(2048, 1024).Build()
+			lrpTwo = schedulertest.NewLRP("process-guid", 2).WithResource(2048, 1024).WithRootFS("preloaded:not-on-cell").Build()
+		})
+
+		It("reports the uptime of the rep", func() {
+			fakeClock.Increment(time.Hour)
+			Expect(cellRep.(*auctioncellrep.AuctionCellRep).Summary().Uptime).To(Equal(time.Hour))
+		})
+
+		Context("when work is performed", func() {
+			BeforeEach(func() {
+				client.AllocateContainersReturns([]executor.AllocationFailure{}, nil)
+			})
+
+			It("counts provisioned and failed work", func() {
+				_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpOne, lrpTwo}})
+				Expect(err).NotTo(HaveOccurred())
+
+				summary := cellRep.(*auctioncellrep.AuctionCellRep).Summary()
+				Expect(summary.Provisioned).To(Equal(1))
+				Expect(summary.Failed).To(Equal(1))
+				Expect(summary.Evicted).To(Equal(0))
+				Expect(summary.PeakInFlight).To(Equal(1))
+			})
+		})
+
+		Context("when the allocation request fails", func() {
+			BeforeEach(func() {
+				client.AllocateContainersReturns(nil, commonErr)
+			})
+
+			It("counts all of the work as failed", func() {
+				_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpOne, lrpTwo}})
+				Expect(err).NotTo(HaveOccurred())
+
+				summary := cellRep.(*auctioncellrep.AuctionCellRep).Summary()
+				Expect(summary.Provisioned).To(Equal(0))
+				Expect(summary.Failed).To(Equal(2))
+			})
+
+			It("counts every container the executor failed to allocate", func() {
+				_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpOne, lrpTwo}})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+				Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("RepContainerAllocationFailed"))
+			})
+		})
+
+		Context("while an allocation is in flight", func() {
+			var unblock chan struct{}
+
+			BeforeEach(func() {
+				unblock = make(chan struct{})
+				client.AllocateContainersStub = func(lager.Logger, []executor.AllocationRequest) ([]executor.AllocationFailure, error) {
+					<-unblock
+					return []executor.AllocationFailure{}, nil
+				}
+			})
+
+			It("reports the guids being allocated until the allocation finishes", func() {
+				cell := cellRep.(*auctioncellrep.AuctionCellRep)
+				Expect(cell.InFlight()).To(BeEmpty())
+
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					defer close(done)
+					_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpOne}})
+					Expect(err).NotTo(HaveOccurred())
+				}()
+
+				Eventually(cell.InFlight).Should(ConsistOf(expectedGuid))
+
+				close(unblock)
+				Eventually(done).Should(BeClosed())
+				Expect(cell.InFlight()).To(BeEmpty())
+			})
+		})
+
+		Context("when work is performed while evacuating", func() {
+			BeforeEach(func() {
+				evacuationReporter.EvacuatingReturns(true)
+			})
+
+			It("counts the returned work as evicted", func() {
+				_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpOne, lrpTwo}})
+				Expect(err).NotTo(HaveOccurred())
+
+				summary := cellRep.(*auctioncellrep.AuctionCellRep).Summary()
+				Expect(summary.Evicted).To(Equal(2))
+				Expect(summary.Provisioned).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("Starting", func() {
+		It("counts the containers that are not yet running", func() {
+			client.ListContainersReturns([]executor.Container{
+				{Guid: "reserved", State: executor.StateReserved},
+				{Guid: "initializing", State: executor.StateInitializing},
+				{Guid: "created", State: executor.StateCreated},
+				{Guid: "running", State: executor.StateRunning},
+				{Guid: "completed", State: executor.StateCompleted},
+			}, nil)
+
+			Expect(cellRep.(*auctioncellrep.AuctionCellRep).Starting(logger)).To(Equal(3))
+		})
+
+		Context("when listing the containers fails", func() {
+			BeforeEach(func() {
+				client.ListContainersReturns(nil, commonErr)
+			})
+
+			It("reports no starting containers", func() {
+				Expect(cellRep.(*auctioncellrep.AuctionCellRep).Starting(logger)).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("generating instance guids", func() {
+		var lrp rep.LRP
+
+		BeforeEach(func() {
+			lrp = schedulertest.NewLRP("process-guid", 3).WithResource(64, 64).Build()
+			fakeGenerateContainerGuid = auctioncellrep.DeterministicInstanceGuid
+		})
+
+		It("allocates the container with the guid generated for the LRP", func() {
+			expectedInstanceGuid, err := auctioncellrep.DeterministicInstanceGuid(&lrp, fakeClock.Now())
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.AllocateContainersCallCount()).To(Equal(1))
+			_, requests := client.AllocateContainersArgsForCall(0)
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].Guid).To(Equal(rep.LRPContainerGuid(lrp.ProcessGuid, expectedInstanceGuid)))
+			Expect(requests[0].Tags[rep.InstanceGuidTag]).To(Equal(expectedInstanceGuid))
+		})
+
+		It("gives a later auction of the same instance a different guid", func() {
+			_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp}})
+			Expect(err).NotTo(HaveOccurred())
+			fakeClock.Increment(time.Minute)
+			_, err = cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.AllocateContainersCallCount()).To(Equal(2))
+			_, first := client.AllocateContainersArgsForCall(0)
+			_, second := client.AllocateContainersArgsForCall(1)
+			Expect(second[0].Guid).NotTo(Equal(first[0].Guid))
+		})
+	})
+
+	Describe("Drain", func() {
+		var (
+			cell *auctioncellrep.AuctionCellRep
+			lrp  rep.LRP
+		)
+
+		BeforeEach(func() {
+			lrp = schedulertest.NewLRP("process-guid", 0).WithResource(64, 64).Build()
+		})
+
+		JustBeforeEach(func() {
+			cell = cellRep.(*auctioncellrep.AuctionCellRep)
+		})
+
+		It("returns immediately when no work is in flight", func() {
+			Expect(cell.Drain(logger, time.Minute)).To(Succeed())
+		})
+
+		It("hands back work that arrives while draining", func() {
+			Expect(cell.Drain(logger, time.Minute)).To(Succeed())
+
+			work := rep.Work{LRPs: []rep.LRP{lrp}}
+			failedWork, err := cellRep.Perform(logger, work)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failedWork).To(Equal(work))
+			Expect(client.AllocateContainersCallCount()).To(Equal(0))
+		})
+
+		It("reports the cell as draining, and evacuating so that it is not given work", func() {
+			state, _, err := cellRep.State(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Draining).To(BeFalse())
+			Expect(state.Evacuating).To(BeFalse())
+
+			Expect(cell.Drain(logger, time.Minute)).To(Succeed())
+
+			state, _, err = cellRep.State(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Draining).To(BeTrue())
+			Expect(state.Evacuating).To(BeTrue())
+		})
+
+		It("accepts work again once undrained", func() {
+			Expect(cell.Drain(logger, time.Minute)).To(Succeed())
+			cell.Undrain(logger)
+
+			state, _, err := cellRep.State(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Draining).To(BeFalse())
+			Expect(state.Evacuating).To(BeFalse())
+
+			failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failedWork.LRPs).To(BeEmpty())
+			Expect(client.AllocateContainersCallCount()).To(Equal(1))
+		})
+
+		It("leaves the LRPs already on the cell running", func() {
+			client.ListContainersReturns([]executor.Container{
+				{
+					Guid:     "running-guid",
+					Resource: executor.NewResource(20, 10, 100, linuxRootFSURL),
+					Tags: executor.Tags{
+						rep.LifecycleTag:    rep.LRPLifecycle,
+						rep.ProcessGuidTag:  "running-process-guid",
+						rep.ProcessIndexTag: "0",
+						rep.DomainTag:       "tests",
+					},
+					State: executor.StateRunning,
+				},
+			}, nil)
+
+			Expect(cell.Drain(logger, time.Minute)).To(Succeed())
+
+			failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failedWork.LRPs).To(ConsistOf(lrp))
+			Expect(client.AllocateContainersCallCount()).To(Equal(0))
+
+			state, _, err := cellRep.State(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.LRPs).To(HaveLen(1))
+			Expect(state.LRPs[0].ProcessGuid).To(Equal("running-process-guid"))
+
+			Expect(client.StopContainerCallCount()).To(Equal(0))
+			Expect(client.DeleteContainerCallCount()).To(Equal(0))
+		})
+
+		Context("when an allocation is in flight", func() {
+			var (
+				unblock   chan struct{}
+				performed chan struct{}
+			)
+
+			BeforeEach(func() {
+				unblock = make(chan struct{})
+				performed = make(chan struct{})
+				client.AllocateContainersStub = func(_ lager.Logger, requests []executor.AllocationRequest) ([]executor.AllocationFailure, error) {
+					<-unblock
+					return []executor.AllocationFailure{}, nil
+				}
+			})
+
+			JustBeforeEach(func() {
+				go func() {
+					defer GinkgoRecover()
+					defer close(performed)
+					_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp}})
+					Expect(err).NotTo(HaveOccurred())
+				}()
+				Eventually(client.AllocateContainersCallCount).Should(Equal(1))
+			})
+
+			It("waits for the allocation to finish", func() {
+				drainErr := make(chan error)
+				go func() {
+					drainErr <- cell.Drain(logger, time.Minute)
+				}()
+				Consistently(drainErr).ShouldNot(Receive())
+
+				close(unblock)
+				Eventually(drainErr).Should(Receive(BeNil()))
+				Expect(performed).To(BeClosed())
+				Expect(cell.InFlight()).To(BeEmpty())
+			})
+
+			It("gives up once the timeout expires", func() {
+				drainErr := make(chan error)
+				go func() {
+					drainErr <- cell.Drain(logger, time.Minute)
+				}()
+				Eventually(fakeClock.WatcherCount).Should(Equal(1))
+
+				fakeClock.Increment(time.Minute)
+				Eventually(drainErr).Should(Receive(Equal(auctioncellrep.ErrDrainTimedOut)))
+				Expect(cell.InFlight()).To(ConsistOf(expectedGuid))
+
+				close(unblock)
+				Eventually(performed).Should(BeClosed())
+			})
+		})
+	})
+
+	Describe("deduplicating LRPs that are already being scheduled", func() {
+		var lrp rep.LRP
+
+		BeforeEach(func() {
+			lrp = schedulertest.NewLRP("process-guid", 0).WithResource(64, 64).Build()
+		})
+
+		It("allocates each index of the same process guid separately", func() {
+			otherIndex := lrp
+			otherIndex.Index = 1
+
+			_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp, otherIndex}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.AllocateContainersCallCount()).To(Equal(1))
+			_, requests := client.AllocateContainersArgsForCall(0)
+			Expect(requests).To(HaveLen(2))
+			Expect([]string{requests[0].Tags[rep.ProcessIndexTag], requests[1].Tags[rep.ProcessIndexTag]}).To(ConsistOf("0", "1"))
+		})
+
+		It("allocates an LRP delivered twice in the same auction once", func() {
+			failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp, lrp}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failedWork.LRPs).To(BeEmpty())
+
+			Expect(client.AllocateContainersCallCount()).To(Equal(1))
+			_, requests := client.AllocateContainersArgsForCall(0)
+			Expect(requests).To(HaveLen(1))
+			Expect(logger).To(gbytes.Say("skipping-lrps-already-being-scheduled"))
+		})
+
+		Context("when another auction is allocating the same LRP", func() {
+			var unblock chan struct{}
+
+			BeforeEach(func() {
+				unblock = make(chan struct{})
+				client.AllocateContainersStub = func(_ lager.Logger, requests []executor.AllocationRequest) ([]executor.AllocationFailure, error) {
+					if len(requests) > 0 {
+						<-unblock
+					}
+					return []executor.AllocationFailure{}, nil
+				}
+			})
+
+			It("drops the duplicate until the first allocation finishes", func() {
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					defer close(done)
+					_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp}})
+					Expect(err).NotTo(HaveOccurred())
+				}()
+				Eventually(client.AllocateContainersCallCount).Should(Equal(1))
+
+				failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(BeEmpty())
+				Expect(client.AllocateContainersCallCount()).To(Equal(2))
+				_, requests := client.AllocateContainersArgsForCall(1)
+				Expect(requests).To(BeEmpty())
+
+				close(unblock)
+				Eventually(done).Should(BeClosed())
+
+				_, err = cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client.AllocateContainersCallCount()).To(Equal(3))
+				_, requests = client.AllocateContainersArgsForCall(2)
+				Expect(requests).To(HaveLen(1))
+			})
+		})
+	})
+
+	Describe("detecting a sick cell", func() {
+		perform := func(count int) {
+			lrps := make([]rep.LRP, count)
+			for i := range lrps {
+				lrps[i] = schedulertest.NewLRP(fmt.Sprintf("process-guid-%d", i), 0).WithResource(64, 64).Build()
+			}
+			_, err := cellRep.Perform(logger, rep.Work{LRPs: lrps})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		healthy := func() bool {
+			_, healthy, err := cellRep.State(logger)
+			Expect(err).NotTo(HaveOccurred())
+			return healthy
+		}
+
+		BeforeEach(func() {
+			config.SickCell = auctioncellrep.SickCellConfig{
+				Window:          time.Minute,
+				MinAttempts:     4,
+				MaxFailureRatio: 0.5,
+			}
+			client.AllocateContainersReturns(nil, commonErr)
+		})
+
+		It("reports the cell unhealthy and sick while most recent allocations have failed", func() {
+			perform(4)
+			Expect(healthy()).To(BeFalse())
+			Expect(cellRep.Sick(logger)).To(BeTrue())
+			Expect(logger).To(gbytes.Say("cell-became-sick"))
+		})
+
+		It("recovers once the failures age out of the window", func() {
+			perform(4)
+			Expect(healthy()).To(BeFalse())
+
+			fakeClock.Increment(time.Minute + time.Second)
+			Expect(healthy()).To(BeTrue())
+			Expect(cellRep.Sick(logger)).To(BeFalse())
+			Expect(logger).To(gbytes.Say("cell-recovered"))
+		})
+
+		It("recovers once started instances catch up with the failures", func() {
+			perform(4)
+			Expect(healthy()).To(BeFalse())
+
+			for i := 0; i < 4; i++ {
+				cellRep.RecordProvisioningOutcome(logger, nil)
+			}
+			Expect(healthy()).To(BeTrue())
+		})
+
+		It("counts failures to run and start instances", func() {
+			for i := 0; i < 4; i++ {
+				cellRep.RecordProvisioningOutcome(logger, errors.New("failed to create container"))
+			}
+			Expect(healthy()).To(BeFalse())
+		})
+
+		It("does not count allocations refused for lack of capacity", func() {
+			client.AllocateContainersStub = func(_ lager.Logger, requests []executor.AllocationRequest) ([]executor.AllocationFailure, error) {
+				failures := []executor.AllocationFailure{}
+				for i := range requests {
+					failures = append(failures, executor.NewAllocationFailure(&requests[i], executor.ErrInsufficientResourcesAvailable.Error()))
+				}
+				return failures, nil
+			}
+
+			perform(10)
+			Expect(healthy()).To(BeTrue())
+		})
+
+		It("does not mark the cell sick on too few attempts", func() {
+			perform(3)
+			Expect(healthy()).To(BeTrue())
+		})
+
+		Context("when the check is disabled", func() {
+			BeforeEach(func() {
+				config.SickCell = auctioncellrep.SickCellConfig{}
+			})
+
+			It("never reports the cell unhealthy", func() {
+				perform(10)
+				Expect(healthy()).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("limiting concurrent allocations", func() {
+		var (
+			lock  sync.Mutex
+			guids int
+		)
+
+		newLRPs := func(count int) []rep.LRP {
+			lrps := make([]rep.LRP, count)
+			for i := range lrps {
+				lrps[i] = schedulertest.NewLRP(fmt.Sprintf("process-guid-%d", i), 0).WithResource(64, 64).Build()
+			}
+			return lrps
+		}
+
+		BeforeEach(func() {
+			config.MaxConcurrentAllocations = 3
+			guids = 0
+
+			fakeGenerateContainerGuid = func(*rep.LRP, time.Time) (string, error) {
+				lock.Lock()
+				defer lock.Unlock()
+				guids++
+				return fmt.Sprintf("container-guid-%d", guids), nil
+			}
+		})
+
+		AfterEach(func() {
+			cellRep.(*auctioncellrep.AuctionCellRep).Stop(logger)
+		})
+
+		Context("when the executor is slow to start the containers", func() {
+			var (
+				fakeExecutor *schedulertest.FakeExecutor
+				maxStarting  int
+				performed    chan rep.Work
+			)
+
+			starting := func() []string {
+				containers, err := fakeExecutor.ListContainersStub(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				guids := []string{}
+				for _, container := range containers {
+					if container.State == executor.StateReserved {
+						guids = append(guids, container.Guid)
+					}
+				}
+				return guids
+			}
+
+			BeforeEach(func() {
+				fakeExecutor = schedulertest.NewFakeExecutor(executor.ExecutorResources{MemoryMB: 4096, DiskMB: 4096, Containers: 50})
+				client = fakeExecutor.FakeClient
+				maxStarting = 0
+				performed = make(chan rep.Work, 1)
+
+				allocate := client.AllocateContainersStub
+				client.AllocateContainersStub = func(logger lager.Logger, requests []executor.AllocationRequest) ([]executor.AllocationFailure, error) {
+					failures, err := allocate(logger, requests)
+					lock.Lock()
+					defer lock.Unlock()
+					if count := len(starting()); count > maxStarting {
+						maxStarting = count
+					}
+					return failures, err
+				}
+			})
+
+			JustBeforeEach(func() {
+				go func() {
+					defer GinkgoRecover()
+					failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: newLRPs(20)})
+					Expect(err).NotTo(HaveOccurred())
+					performed <- failedWork
+				}()
+			})
+
+			It("never has more containers starting at once than the limit", func() {
+				var failedWork rep.Work
+				Eventually(performed).Should(Receive(&failedWork))
+				Expect(failedWork.LRPs).To(BeEmpty())
+
+				for batch := 1; batch < 7; batch++ {
+					Eventually(client.AllocateContainersCallCount).Should(Equal(batch))
+					Eventually(fakeClock.WatcherCount).Should(Equal(1))
+					Expect(client.AllocateContainersCallCount()).To(Equal(batch))
+
+					for _, guid := range starting() {
+						fakeExecutor.SetState(guid, executor.StateRunning)
+					}
+					fakeClock.Increment(250 * time.Millisecond)
+				}
+
+				Eventually(client.AllocateContainersCallCount).Should(Equal(7))
+
+				lock.Lock()
+				defer lock.Unlock()
+				Expect(maxStarting).To(Equal(3))
+			})
+
+			It("reports the queued containers and those holding slots as in flight until they start", func() {
+				cell := cellRep.(*auctioncellrep.AuctionCellRep)
+
+				Eventually(performed).Should(Receive())
+				Eventually(fakeClock.WatcherCount).Should(Equal(1))
+				Expect(cell.InFlight()).To(HaveLen(20))
+				Expect(cell.InFlight()).To(ContainElement("container-guid-1"))
+
+				for _, guid := range starting() {
+					fakeExecutor.SetState(guid, executor.StateRunning)
+				}
+				fakeClock.Increment(250 * time.Millisecond)
+
+				Eventually(client.AllocateContainersCallCount).Should(Equal(2))
+				Expect(cell.InFlight()).To(HaveLen(17))
+				Expect(cell.InFlight()).NotTo(ContainElement("container-guid-1"))
+			})
+
+			It("reports the allocation slots in use against the limit", func() {
+				cell := cellRep.(*auctioncellrep.AuctionCellRep)
+
+				Eventually(performed).Should(Receive())
+				Expect(cell.LimitUsage()).To(Equal(rep.LimitUsage{ConcurrencyUsed: 3, ConcurrencyLimit: 3}))
+			})
+
+			It("drops the queued LRP when it is delivered again", func() {
+				Eventually(performed).Should(Receive())
+
+				failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: newLRPs(20)[19:]})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(BeEmpty())
+				Expect(logger).To(gbytes.Say("skipping-lrps-already-being-scheduled"))
+				Expect(client.AllocateContainersCallCount()).To(Equal(1))
+			})
+
+			It("drops the queued allocations when it is stopped", func() {
+				Eventually(performed).Should(Receive())
+				Eventually(fakeClock.WatcherCount).Should(Equal(1))
+
+				cellRep.(*auctioncellrep.AuctionCellRep).Stop(logger)
+				Expect(logger).To(gbytes.Say("dropped-queued-allocations"))
+				Expect(cellRep.(*auctioncellrep.AuctionCellRep).InFlight()).To(HaveLen(3))
+
+				fakeClock.Increment(time.Second)
+				Consistently(client.AllocateContainersCallCount).Should(Equal(1))
+			})
+
+			It("lets a dropped LRP be placed again", func() {
+				Eventually(performed).Should(Receive())
+				cellRep.(*auctioncellrep.AuctionCellRep).Stop(logger)
+
+				_, err := cellRep.Perform(logger, rep.Work{LRPs: newLRPs(20)[19:]})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(logger).NotTo(gbytes.Say("skipping-lrps-already-being-scheduled"))
+			})
+
+			It("drops the queued allocations when the cell starts evacuating", func() {
+				Eventually(performed).Should(Receive())
+				Eventually(fakeClock.WatcherCount).Should(Equal(1))
+
+				evacuationReporter.EvacuatingReturns(true)
+				fakeClock.Increment(250 * time.Millisecond)
+
+				Eventually(logger).Should(gbytes.Say("dropping-queued-allocations-while-evacuating"))
+				Expect(client.AllocateContainersCallCount()).To(Equal(1))
+			})
+		})
+
+		It("allocates every LRP of a large auction in turn", func() {
+			failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: newLRPs(20)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failedWork.LRPs).To(BeEmpty())
+
+			Eventually(client.AllocateContainersCallCount).Should(Equal(7))
+			allocated := 0
+			for i := 0; i < client.AllocateContainersCallCount(); i++ {
+				_, requests := client.AllocateContainersArgsForCall(i)
+				Expect(len(requests)).To(BeNumerically("<=", 3))
+				allocated += len(requests)
+			}
+			Expect(allocated).To(Equal(20))
+		})
+
+		Context("when a batch fails outright", func() {
+			var failingCall int
+
+			BeforeEach(func() {
+				client.AllocateContainersStub = func(_ lager.Logger, requests []executor.AllocationRequest) ([]executor.AllocationFailure, error) {
+					if client.AllocateContainersCallCount() == failingCall {
+						return nil, commonErr
+					}
+					return []executor.AllocationFailure{}, nil
+				}
+			})
+
+			Context("when it is allocated straight away", func() {
+				BeforeEach(func() {
+					failingCall = 1
+				})
+
+				It("rejects only the LRPs in that batch and still allocates the queued ones", func() {
+					failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: newLRPs(7)})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.LRPs).To(HaveLen(3))
+
+					Eventually(client.AllocateContainersCallCount).Should(Equal(3))
+				})
+			})
+
+			Context("when it was queued", func() {
+				BeforeEach(func() {
+					failingCall = 2
+				})
+
+				It("logs the failures and allocates the rest of the queue", func() {
+					failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: newLRPs(7)})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.LRPs).To(BeEmpty())
+
+					Eventually(client.AllocateContainersCallCount).Should(Equal(3))
+					Eventually(logger).Should(gbytes.Say("queued-container-allocation-failure"))
+				})
+			})
+		})
+	})
+
+	Describe("retrying failed allocations", func() {
+		var (
+			lrp       rep.LRP
+			performed chan rep.Work
+		)
+
+		BeforeEach(func() {
+			config.AllocationRetry = auctioncellrep.AllocationRetryConfig{
+				MaxAttempts: 3,
+				Backoff:     time.Second,
+			}
+			lrp = schedulertest.NewLRP("process-guid", 1).WithResource(2048, 1024).Build()
+			client.AllocateContainersReturns(nil, commonErr)
+		})
+
+		JustBeforeEach(func() {
+			performed = make(chan rep.Work, 1)
+			go func() {
+				defer GinkgoRecover()
+				failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp}})
+				Expect(err).NotTo(HaveOccurred())
+				performed <- failedWork
+			}()
+			Eventually(client.AllocateContainersCallCount).Should(Equal(1))
+		})
+
+		It("waits twice as long before every further attempt", func() {
+			fakeClock.WaitForWatcherAndIncrement(time.Second - time.Millisecond)
+			Consistently(client.AllocateContainersCallCount).Should(Equal(1))
+			fakeClock.Increment(time.Millisecond)
+			Eventually(client.AllocateContainersCallCount).Should(Equal(2))
+
+			fakeClock.WaitForWatcherAndIncrement(2*time.Second - time.Millisecond)
+			Consistently(client.AllocateContainersCallCount).Should(Equal(2))
+			fakeClock.Increment(time.Millisecond)
+			Eventually(client.AllocateContainersCallCount).Should(Equal(3))
+
+			Eventually(performed).Should(Receive())
+		})
+
+		It("rejects the work once every attempt has failed", func() {
+			fakeClock.WaitForWatcherAndIncrement(time.Second)
+			fakeClock.WaitForWatcherAndIncrement(2 * time.Second)
+
+			var failedWork rep.Work
+			Eventually(performed).Should(Receive(&failedWork))
+			Expect(failedWork.LRPs).To(ConsistOf(lrp))
+			Expect(client.AllocateContainersCallCount()).To(Equal(3))
+			Expect(logger).To(gbytes.Say("failed-requesting-container-allocation"))
+		})
+
+		Context("when an attempt succeeds", func() {
+			BeforeEach(func() {
+				client.AllocateContainersStub = func(lager.Logger, []executor.AllocationRequest) ([]executor.AllocationFailure, error) {
+					if client.AllocateContainersCallCount() < 2 {
+						return nil, commonErr
+					}
+					return []executor.AllocationFailure{}, nil
+				}
+			})
+
+			It("accepts the work", func() {
+				fakeClock.WaitForWatcherAndIncrement(time.Second)
+
+				var failedWork rep.Work
+				Eventually(performed).Should(Receive(&failedWork))
+				Expect(failedWork.LRPs).To(BeEmpty())
+				Expect(client.AllocateContainersCallCount()).To(Equal(2))
+			})
+		})
+
+		Context("when the cell starts evacuating between attempts", func() {
+			It("stops retrying", func() {
+				evacuationReporter.EvacuatingReturns(true)
+				fakeClock.WaitForWatcherAndIncrement(time.Second)
+
+				var failedWork rep.Work
+				Eventually(performed).Should(Receive(&failedWork))
+				Expect(failedWork.LRPs).To(ConsistOf(lrp))
+				Expect(client.AllocateContainersCallCount()).To(Equal(1))
+				Expect(logger).To(gbytes.Say("abandoning-container-allocation-while-evacuating"))
+			})
+		})
+
+		Context("when further attempts would wait too long", func() {
+			BeforeEach(func() {
+				config.AllocationRetry.MaxAttempts = 10
+			})
+
+			It("rejects the work instead of waiting", func() {
+				fakeClock.WaitForWatcherAndIncrement(time.Second)
+				fakeClock.WaitForWatcherAndIncrement(2 * time.Second)
+
+				var failedWork rep.Work
+				Eventually(performed).Should(Receive(&failedWork))
+				Expect(failedWork.LRPs).To(ConsistOf(lrp))
+				Expect(client.AllocateContainersCallCount()).To(Equal(3))
+				Expect(logger).To(gbytes.Say("abandoning-container-allocation-after-max-wait"))
+			})
+		})
+
+		Context("when concurrent allocations are limited", func() {
+			var otherLRP rep.LRP
+
+			BeforeEach(func() {
+				config.MaxConcurrentAllocations = 1
+				otherLRP = schedulertest.NewLRP("other-process-guid", 1).WithResource(2048, 1024).Build()
+				client.AllocateContainersStub = func(_ lager.Logger, requests []executor.AllocationRequest) ([]executor.AllocationFailure, error) {
+					if requests[0].Tags[rep.ProcessGuidTag] == lrp.ProcessGuid {
+						return nil, commonErr
+					}
+					return []executor.AllocationFailure{}, nil
+				}
+			})
+
+			AfterEach(func() {
+				cellRep.(*auctioncellrep.AuctionCellRep).Stop(logger)
+			})
+
+			It("queues the work of other auctions until it gives up its slot", func() {
+				Eventually(fakeClock.WatcherCount).Should(Equal(1))
+
+				failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{otherLRP}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(BeEmpty())
+				Expect(client.AllocateContainersCallCount()).To(Equal(1))
+
+				fakeClock.WaitForWatcherAndIncrement(time.Second)
+				fakeClock.WaitForWatcherAndIncrement(2 * time.Second)
+				Eventually(performed).Should(Receive())
+
+				Eventually(func() int {
+					fakeClock.Increment(250 * time.Millisecond)
+					return client.AllocateContainersCallCount()
+				}).Should(Equal(4))
+				_, requests := client.AllocateContainersArgsForCall(3)
+				Expect(requests[0].Tags[rep.ProcessGuidTag]).To(Equal(otherLRP.ProcessGuid))
+			})
+		})
+	})
+
+	Describe("matching any stack", func() {
+		var lrp rep.LRP
+
+		BeforeEach(func() {
+			config.MatchAnyStack = true
+			lrp = schedulertest.NewLRP("process-guid", 1).WithResource(2048, 1024).WithRootFS(models.PreloadedRootFS("some-other-stack")).Build()
+			client.AllocateContainersReturns([]executor.AllocationFailure{}, nil)
+		})
+
+		It("advertises that it accepts any preloaded stack", func() {
+			state, _, err := cellRep.State(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.RootFSProviders[models.PreloadedRootFSScheme]).To(Equal(rep.ArbitraryRootFSProvider{}))
+			Expect(state.MatchRootFS(models.PreloadedRootFS("some-other-stack"))).To(BeTrue())
+		})
+
+		It("runs stacks it has no rootfs for on a preloaded rootfs", func() {
+			failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failedWork.LRPs).To(BeEmpty())
+
+			_, requests := client.AllocateContainersArgsForCall(0)
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].RootFSPath).To(Equal(linuxPath))
+		})
+
+		Context("when it is disabled", func() {
+			BeforeEach(func() {
+				config.MatchAnyStack = false
+			})
+
+			It("rejects stacks it has no rootfs for", func() {
+				failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(ConsistOf(lrp))
+			})
+		})
+
+		Context("when the cell has no preloaded stacks", func() {
+			BeforeEach(func() {
+				config.PreloadedStackPathMap = rep.StackPathMap{}
+			})
+
+			It("does not advertise that it accepts any preloaded stack", func() {
+				state, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.MatchRootFS(models.PreloadedRootFS("some-other-stack"))).To(BeFalse())
+			})
+
+			It("rejects LRPs on a preloaded stack", func() {
+				failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(ConsistOf(lrp))
+			})
+		})
+	})
+
+	Describe("LRPs without memory or disk limits", func() {
+		allocatedResource := func(resource rep.Resource) executor.Resource {
+			lrp := schedulertest.NewLRP("process-guid", 0).WithResource(resource.MemoryMB, resource.DiskMB).Build()
+			_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.AllocateContainersCallCount()).To(Equal(1))
+			_, requests := client.AllocateContainersArgsForCall(0)
+			Expect(requests).To(HaveLen(1))
+			return requests[0].Resource
+		}
+
+		It("passes zero limits through when no defaults are configured", func() {
+			resource := allocatedResource(rep.NewResource(0, 0, 100))
+			Expect(resource.MemoryMB).To(Equal(0))
+			Expect(resource.DiskMB).To(Equal(0))
+		})
+
+		Context("when defaults are configured", func() {
+			BeforeEach(func() {
+				config.DefaultResources = auctioncellrep.DefaultResourceConfig{MemoryMB: 256, DiskMB: 1024}
+			})
+
+			It("allocates the defaults in place of zero limits", func() {
+				resource := allocatedResource(rep.NewResource(0, 0, 100))
+				Expect(resource.MemoryMB).To(Equal(256))
+				Expect(resource.DiskMB).To(Equal(1024))
+				Expect(logger).To(gbytes.Say("substituting-default-lrp-resources"))
+			})
+
+			It("keeps small explicit limits", func() {
+				resource := allocatedResource(rep.NewResource(1, 1, 100))
+				Expect(resource.MemoryMB).To(Equal(1))
+				Expect(resource.DiskMB).To(Equal(1))
+			})
+
+			It("replaces each zero limit on its own", func() {
+				resource := allocatedResource(rep.NewResource(0, 512, 100))
+				Expect(resource.MemoryMB).To(Equal(256))
+				Expect(resource.DiskMB).To(Equal(512))
+			})
+
+			Context("when the defaults do not fit on the cell", func() {
+				BeforeEach(func() {
+					config.RejectBeyondCapacity = true
+					client.RemainingResourcesReturns(executor.ExecutorResources{MemoryMB: 128, DiskMB: 4096, Containers: 10}, nil)
+				})
+
+				It("rejects the LRP without allocating it", func() {
+					lrp := schedulertest.NewLRP("process-guid", 0).WithResource(0, 0).Build()
+					failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.LRPs).To(HaveLen(1))
+					Expect(failedWork.LRPs[0].ActualLRPKey).To(Equal(lrp.ActualLRPKey))
+					Expect(client.AllocateContainersCallCount()).To(Equal(0))
+				})
+			})
+		})
+	})
+
+	Describe("LRPs with invalid resources", func() {
+		var validLRP rep.LRP
+
+		newLRP := func(guid string, memoryMB, diskMB, maxPids int32) rep.LRP {
+			return schedulertest.NewLRP(guid, 0).WithResource(memoryMB, diskMB).WithMaxPids(maxPids).Build()
+		}
+
+		BeforeEach(func() {
+			config.ValidateLRPResources = true
+			client.TotalResourcesReturns(executor.ExecutorResources{MemoryMB: 4096, DiskMB: 8192, Containers: 10}, nil)
+			client.AllocateContainersReturns([]executor.AllocationFailure{}, nil)
+			validLRP = newLRP("valid-guid", 4096, 8192, 100)
+		})
+
+		itRejects := func(lrp func() rep.LRP, reason string) {
+			It("rejects the LRP with a reason", func() {
+				invalidLRP := lrp()
+				failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{validLRP, invalidLRP}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(ConsistOf(invalidLRP))
+
+				Expect(client.AllocateContainersCallCount()).To(Equal(1))
+				_, requests := client.AllocateContainersArgsForCall(0)
+				Expect(requests).To(HaveLen(1))
+				Expect(requests[0].Tags[rep.ProcessGuidTag]).To(Equal("valid-guid"))
+
+				Expect(logger).To(gbytes.Say("rejecting-lrp-with-invalid-resources"))
+				Expect(logger).To(gbytes.Say(reason))
+			})
+		}
+
+		Context("when the memory limit is negative", func() {
+			itRejects(func() rep.LRP { return newLRP("bad-guid", -1, 1024, 100) }, "memory limit -1 MB is negative")
+		})
+
+		Context("when the disk limit is negative", func() {
+			itRejects(func() rep.LRP { return newLRP("bad-guid", 1024, -1, 100) }, "disk limit -1 MB is negative")
+		})
+
+		Context("when the pid limit is negative", func() {
+			itRejects(func() rep.LRP { return newLRP("bad-guid", 1024, 1024, -1) }, "pid limit -1 is negative")
+		})
+
+		Context("when the memory limit exceeds the cell's total", func() {
+			itRejects(func() rep.LRP { return newLRP("bad-guid", 4097, 1024, 100) }, "memory limit 4097 MB exceeds the cell's total of 4096 MB")
+		})
+
+		Context("when the disk limit exceeds the cell's total", func() {
+			itRejects(func() rep.LRP { return newLRP("bad-guid", 1024, 8193, 100) }, "disk limit 8193 MB exceeds the cell's total of 8192 MB")
+		})
+
+		Context("when validation is disabled", func() {
+			BeforeEach(func() {
+				config.ValidateLRPResources = false
+			})
+
+			It("does not reject any LRPs", func() {
+				failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{newLRP("bad-guid", -1, 1024, 100)}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("outsized LRPs", func() {
+		var smallLRP, largeLRP rep.LRP
+
+		BeforeEach(func() {
+			config.MaxLRPMemoryFraction = 0.5
+			client.TotalResourcesReturns(executor.ExecutorResources{MemoryMB: 4096, DiskMB: 4096, Containers: 10}, nil)
+			client.AllocateContainersReturns([]executor.AllocationFailure{}, nil)
+
+			smallLRP = schedulertest.NewLRP("small-guid", 0).WithResource(2048, 1024).Build()
+			largeLRP = schedulertest.NewLRP("large-guid", 0).WithResource(3072, 1024).Build()
+		})
+
+		It("rejects LRPs asking for more than the configured fraction of the cell's memory", func() {
+			failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{smallLRP, largeLRP}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failedWork.LRPs).To(ConsistOf(largeLRP))
+
+			Expect(client.AllocateContainersCallCount()).To(Equal(1))
+			_, requests := client.AllocateContainersArgsForCall(0)
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].Tags[rep.ProcessGuidTag]).To(Equal("small-guid"))
+
+			Expect(logger).To(gbytes.Say("rejecting-outsized-lrps"))
+			Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+			Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("RepOutsizedLRPsRejected"))
+		})
+
+		Context("when the counter cannot be incremented", func() {
+			BeforeEach(func() {
+				fakeMetronClient.IncrementCounterReturns(commonErr)
+			})
+
+			It("stops counting after the first error", func() {
+				otherLargeLRP := schedulertest.NewLRP("other-large-guid", 0).WithResource(3072, 1024).Build()
+				failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{largeLRP, otherLargeLRP}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(ConsistOf(largeLRP, otherLargeLRP))
+
+				Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+				Expect(logger).To(gbytes.Say("failed-to-increment-outsized-lrps-counter"))
+			})
+		})
+
+		Context("when fetching the total resources fails", func() {
+			BeforeEach(func() {
+				client.TotalResourcesReturns(executor.ExecutorResources{}, commonErr)
+			})
+
+			It("does not reject any LRPs", func() {
+				failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{smallLRP, largeLRP}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(BeEmpty())
+			})
+		})
+
+		Context("when the fraction is zero", func() {
+			BeforeEach(func() {
+				config.MaxLRPMemoryFraction = 0
+			})
+
+			It("does not reject any LRPs", func() {
+				failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{smallLRP, largeLRP}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(BeEmpty())
+				Expect(client.TotalResourcesCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("filtering LRPs", func() {
+		var acceptedLRP, rejectedLRP rep.LRP
+
+		BeforeEach(func() {
+			client.AllocateContainersReturns([]executor.AllocationFailure{}, nil)
+
+			acceptedLRP = schedulertest.NewLRP("accepted-guid", 0).WithResource(64, 64).Build()
+			rejectedLRP = schedulertest.NewLRP("rejected-guid", 0).WithResource(64, 64).Build()
+		})
+
+		JustBeforeEach(func() {
+			cellRep.(*auctioncellrep.AuctionCellRep).SetLRPFilter(func(lrp *rep.LRP) bool {
+				return lrp.ProcessGuid != "rejected-guid"
+			})
+		})
+
+		It("never allocates the LRPs the filter rejects", func() {
+			failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{acceptedLRP, rejectedLRP}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failedWork.LRPs).To(ConsistOf(rejectedLRP))
+
+			Expect(client.AllocateContainersCallCount()).To(Equal(1))
+			_, requests := client.AllocateContainersArgsForCall(0)
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].Tags[rep.ProcessGuidTag]).To(Equal("accepted-guid"))
+			Expect(logger).To(gbytes.Say("rejecting-filtered-lrps"))
+		})
+
+		It("accepts every LRP again once the filter is cleared", func() {
+			cellRep.(*auctioncellrep.AuctionCellRep).SetLRPFilter(nil)
+
+			failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{acceptedLRP, rejectedLRP}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failedWork.LRPs).To(BeEmpty())
+		})
+	})
+
+	Describe("LRPs beyond the remaining capacity", func() {
+		var smallLRP, largeLRP rep.LRP
+
+		BeforeEach(func() {
+			config.RejectBeyondCapacity = true
+			client.RemainingResourcesReturns(executor.ExecutorResources{MemoryMB: 2048, DiskMB: 4096, Containers: 10}, nil)
+			client.AllocateContainersReturns([]executor.AllocationFailure{}, nil)
+
+			smallLRP = schedulertest.NewLRP("small-guid", 0).WithResource(1024, 1024).Build()
+			largeLRP = schedulertest.NewLRP("large-guid", 0).WithResource(3072, 1024).Build()
+		})
+
+		It("never allocates an LRP asking for more memory than the cell has left", func() {
+			failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{smallLRP, largeLRP}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failedWork.LRPs).To(ConsistOf(largeLRP))
+
+			Expect(client.AllocateContainersCallCount()).To(Equal(1))
+			_, requests := client.AllocateContainersArgsForCall(0)
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].Tags[rep.ProcessGuidTag]).To(Equal("small-guid"))
+			Expect(logger).To(gbytes.Say("rejecting-lrps-beyond-remaining-capacity"))
+		})
+
+		It("counts the resources of the LRPs it lets through", func() {
+			otherSmallLRP := smallLRP
+			otherSmallLRP.Index = 1
+			thirdSmallLRP := smallLRP
+			thirdSmallLRP.Index = 2
+
+			failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{smallLRP, otherSmallLRP, thirdSmallLRP}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failedWork.LRPs).To(ConsistOf(thirdSmallLRP))
+		})
+
+		Context("when fetching the remaining resources fails", func() {
+			BeforeEach(func() {
+				client.RemainingResourcesReturns(executor.ExecutorResources{}, commonErr)
+			})
+
+			It("does not reject any LRPs", func() {
+				failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{smallLRP, largeLRP}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(BeEmpty())
+			})
+		})
+
+		Context("when the check is disabled", func() {
+			BeforeEach(func() {
+				config.RejectBeyondCapacity = false
+			})
+
+			It("does not reject any LRPs", func() {
+				failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{smallLRP, largeLRP}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(BeEmpty())
+				Expect(client.RemainingResourcesCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("History", func() {
+		var lrps []rep.LRP
+
+		BeforeEach(func() {
+			config.History = auctioncellrep.ProvisioningHistoryConfig{MaxEvents: 3, MaxAge: time.Hour}
+
+			guidCount := 0
+			fakeGenerateContainerGuid = func(*rep.LRP, time.Time) (string, error) {
+				guidCount++
+				return fmt.Sprintf("container-guid-%d", guidCount), nil
+			}
+
+			lrps = []rep.LRP{}
+			for i := int32(0); i < 2; i++ {
+				lrps = append(lrps, schedulertest.NewLRP("process-guid", i).WithResource(2048, 1024).Build())
+			}
+
+			client.AllocateContainersStub = func(_ lager.Logger, requests []executor.AllocationRequest) ([]executor.AllocationFailure, error) {
+				failures := []executor.AllocationFailure{}
+				for i := range requests {
+					if requests[i].Tags[rep.ProcessIndexTag] == "1" {
+						failures = append(failures, executor.NewAllocationFailure(&requests[i], "out of memory"))
+					}
+				}
+				return failures, nil
+			}
+		})
+
+		It("records the outcome of every allocation", func() {
+			_, err := cellRep.Perform(logger, rep.Work{LRPs: lrps})
+			Expect(err).NotTo(HaveOccurred())
+
+			events := cellRep.History(time.Time{}, "")
+			Expect(events).To(HaveLen(2))
+			Expect(events[0].ContainerGuid).To(Equal("container-guid-1"))
+			Expect(events[0].ProcessGuid).To(Equal("process-guid"))
+			Expect(events[0].Lifecycle).To(Equal(rep.LRPLifecycle))
+			Expect(events[0].Succeeded).To(BeTrue())
+			Expect(events[1].ContainerGuid).To(Equal("container-guid-2"))
+			Expect(events[1].Succeeded).To(BeFalse())
+			Expect(events[1].FailureReason).To(ContainSubstring("out of memory"))
+		})
+
+		It("filters by time and guid", func() {
+			_, err := cellRep.Perform(logger, rep.Work{LRPs: lrps[:1]})
+			Expect(err).NotTo(HaveOccurred())
+			fakeClock.Increment(time.Minute)
+			_, err = cellRep.Perform(logger, rep.Work{LRPs: lrps[1:]})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cellRep.History(fakeClock.Now(), "")).To(HaveLen(1))
+			Expect(cellRep.History(time.Time{}, "process-guid")).To(HaveLen(2))
+			Expect(cellRep.History(time.Time{}, "container-guid-1")).To(HaveLen(1))
+			Expect(cellRep.History(time.Time{}, "unknown-guid")).To(BeEmpty())
+		})
+
+		It("keeps at most the configured number of events", func() {
+			for i := 0; i < 2; i++ {
+				_, err := cellRep.Perform(logger, rep.Work{LRPs: lrps})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			events := cellRep.History(time.Time{}, "")
+			Expect(events).To(HaveLen(3))
+			Expect(events[0].ContainerGuid).To(Equal("container-guid-2"))
+		})
+
+		It("drops events older than the configured age", func() {
+			_, err := cellRep.Perform(logger, rep.Work{LRPs: lrps})
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeClock.Increment(time.Hour + time.Second)
+			Expect(cellRep.History(time.Time{}, "")).To(BeEmpty())
+		})
+
+		Context("when the history is disabled", func() {
+			BeforeEach(func() {
+				config.History = auctioncellrep.ProvisioningHistoryConfig{}
+			})
+
+			It("records nothing", func() {
+				_, err := cellRep.Perform(logger, rep.Work{LRPs: lrps})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cellRep.History(time.Time{}, "")).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("crash backoff", func() {
+		var (
+			lrp      rep.LRP
+			lrpKey   models.ActualLRPKey
+			crashRep *auctioncellrep.AuctionCellRep
+		)
+
+		rejected := func() bool {
+			failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp}})
+			Expect(err).NotTo(HaveOccurred())
+			return len(failedWork.LRPs) == 1
+		}
+
+		BeforeEach(func() {
+			config.CrashBackoff = auctioncellrep.CrashBackoffConfig{
+				Initial:    10 * time.Second,
+				Max:        30 * time.Second,
+				ResetAfter: 5 * time.Minute,
+			}
+
+			lrpKey = models.NewActualLRPKey("process-guid", 1, "tests")
+			lrp = rep.NewLRP(lrpKey, rep.NewResource(2048, 1024, 100), rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}))
+			client.AllocateContainersReturns([]executor.AllocationFailure{}, nil)
+		})
+
+		JustBeforeEach(func() {
+			crashRep = cellRep.(*auctioncellrep.AuctionCellRep)
+		})
+
+		It("accepts an LRP that has not crashed", func() {
+			Expect(rejected()).To(BeFalse())
+		})
+
+		It("rejects an LRP until its backoff has elapsed", func() {
+			crashRep.RecordCrash(logger, &lrpKey)
+			Expect(rejected()).To(BeTrue())
+
+			fakeClock.Increment(9 * time.Second)
+			Expect(rejected()).To(BeTrue())
+
+			fakeClock.Increment(time.Second)
+			Expect(rejected()).To(BeFalse())
+		})
+
+		It("does not reject other instances of the same app", func() {
+			crashRep.RecordCrash(logger, &lrpKey)
+
+			other := lrp
+			other.Index = 2
+			failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp, other}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failedWork.LRPs).To(ConsistOf(lrp))
+		})
+
+		It("doubles the backoff after each crash, up to the maximum", func() {
+			expectedBackoffs := []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second, 30 * time.Second}
+			for _, expected := range expectedBackoffs {
+				crashRep.RecordCrash(logger, &lrpKey)
+
+				state, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.CrashBackoffs).To(Equal(map[string]time.Duration{lrp.Identifier(): expected}))
+
+				fakeClock.Increment(expected - time.Second)
+				Expect(rejected()).To(BeTrue())
+				fakeClock.Increment(time.Second)
+				Expect(rejected()).To(BeFalse())
+			}
+		})
+
+		It("resets the backoff after a sustained healthy period", func() {
+			crashRep.RecordCrash(logger, &lrpKey)
+			crashRep.RecordCrash(logger, &lrpKey)
+
+			fakeClock.Increment(5 * time.Minute)
+			state, _, err := cellRep.State(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.CrashBackoffs).To(BeEmpty())
+
+			crashRep.RecordCrash(logger, &lrpKey)
+			state, _, err = cellRep.State(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.CrashBackoffs).To(Equal(map[string]time.Duration{lrp.Identifier(): 10 * time.Second}))
+		})
+
+		Context("when the backoff is never reset", func() {
+			BeforeEach(func() {
+				config.CrashBackoff.ResetAfter = 0
+			})
+
+			It("still forgets an instance once the default reset period has passed", func() {
+				crashRep.RecordCrash(logger, &lrpKey)
+
+				fakeClock.Increment(auctioncellrep.DefaultCrashBackoffResetAfter - time.Second)
+				state, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.CrashBackoffs).To(HaveKey(lrp.Identifier()))
+
+				fakeClock.Increment(time.Second)
+				state, _, err = cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.CrashBackoffs).To(BeEmpty())
+			})
+		})
+
+		Context("when the backoff is disabled", func() {
+			BeforeEach(func() {
+				config.CrashBackoff = auctioncellrep.CrashBackoffConfig{}
+			})
+
+			It("never rejects a crashed LRP", func() {
+				crashRep.RecordCrash(logger, &lrpKey)
+				Expect(rejected()).To(BeFalse())
+
+				state, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.CrashBackoffs).To(BeNil())
+			})
+		})
+	})
 })
 
 func allocationRequestFromTask(task rep.Task, rootFSPath string) executor.AllocationRequest {
@@ -0,0 +1,80 @@
+// This file was generated by counterfeiter
+package auctioncellrepfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+)
+
+type FakeAllocationDecorator struct {
+	DecorateStub        func(logger lager.Logger, request *executor.AllocationRequest) error
+	decorateMutex       sync.RWMutex
+	decorateArgsForCall []struct {
+		logger  lager.Logger
+		request *executor.AllocationRequest
+	}
+	decorateReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeAllocationDecorator) Decorate(logger lager.Logger, request *executor.AllocationRequest) error {
+	fake.decorateMutex.Lock()
+	fake.decorateArgsForCall = append(fake.decorateArgsForCall, struct {
+		logger  lager.Logger
+		request *executor.AllocationRequest
+	}{logger, request})
+	fake.recordInvocation("Decorate", []interface{}{logger, request})
+	fake.decorateMutex.Unlock()
+	if fake.DecorateStub != nil {
+		return fake.DecorateStub(logger, request)
+	} else {
+		return fake.decorateReturns.result1
+	}
+}
+
+func (fake *FakeAllocationDecorator) DecorateCallCount() int {
+	fake.decorateMutex.RLock()
+	defer fake.decorateMutex.RUnlock()
+	return len(fake.decorateArgsForCall)
+}
+
+func (fake *FakeAllocationDecorator) DecorateArgsForCall(i int) (lager.Logger, *executor.AllocationRequest) {
+	fake.decorateMutex.RLock()
+	defer fake.decorateMutex.RUnlock()
+	return fake.decorateArgsForCall[i].logger, fake.decorateArgsForCall[i].request
+}
+
+func (fake *FakeAllocationDecorator) DecorateReturns(result1 error) {
+	fake.DecorateStub = nil
+	fake.decorateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAllocationDecorator) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.decorateMutex.RLock()
+	defer fake.decorateMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeAllocationDecorator) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ auctioncellrep.AllocationDecorator = new(FakeAllocationDecorator)
@@ -4,6 +4,8 @@ package auctioncellrepfakes
 import (
 	"sync"
 
+	"time"
+
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/auctioncellrep"
@@ -36,6 +38,21 @@ type FakeAuctionCellClient struct {
 	resetReturns     struct {
 		result1 error
 	}
+	HistoryStub        func(since time.Time, guid string) []rep.ProvisioningEvent
+	historyMutex       sync.RWMutex
+	historyArgsForCall []struct {
+		since time.Time
+		guid  string
+	}
+	historyReturns struct {
+		result1 []rep.ProvisioningEvent
+	}
+	InFlightStub        func() []string
+	inFlightMutex       sync.RWMutex
+	inFlightArgsForCall []struct{}
+	inFlightReturns     struct {
+		result1 []string
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -135,6 +152,65 @@ func (fake *FakeAuctionCellClient) ResetReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakeAuctionCellClient) History(since time.Time, guid string) []rep.ProvisioningEvent {
+	fake.historyMutex.Lock()
+	fake.historyArgsForCall = append(fake.historyArgsForCall, struct {
+		since time.Time
+		guid  string
+	}{since, guid})
+	fake.recordInvocation("History", []interface{}{since, guid})
+	fake.historyMutex.Unlock()
+	if fake.HistoryStub != nil {
+		return fake.HistoryStub(since, guid)
+	} else {
+		return fake.historyReturns.result1
+	}
+}
+
+func (fake *FakeAuctionCellClient) HistoryCallCount() int {
+	fake.historyMutex.RLock()
+	defer fake.historyMutex.RUnlock()
+	return len(fake.historyArgsForCall)
+}
+
+func (fake *FakeAuctionCellClient) HistoryArgsForCall(i int) (time.Time, string) {
+	fake.historyMutex.RLock()
+	defer fake.historyMutex.RUnlock()
+	return fake.historyArgsForCall[i].since, fake.historyArgsForCall[i].guid
+}
+
+func (fake *FakeAuctionCellClient) HistoryReturns(result1 []rep.ProvisioningEvent) {
+	fake.HistoryStub = nil
+	fake.historyReturns = struct {
+		result1 []rep.ProvisioningEvent
+	}{result1}
+}
+
+func (fake *FakeAuctionCellClient) InFlight() []string {
+	fake.inFlightMutex.Lock()
+	fake.inFlightArgsForCall = append(fake.inFlightArgsForCall, struct{}{})
+	fake.recordInvocation("InFlight", []interface{}{})
+	fake.inFlightMutex.Unlock()
+	if fake.InFlightStub != nil {
+		return fake.InFlightStub()
+	} else {
+		return fake.inFlightReturns.result1
+	}
+}
+
+func (fake *FakeAuctionCellClient) InFlightCallCount() int {
+	fake.inFlightMutex.RLock()
+	defer fake.inFlightMutex.RUnlock()
+	return len(fake.inFlightArgsForCall)
+}
+
+func (fake *FakeAuctionCellClient) InFlightReturns(result1 []string) {
+	fake.InFlightStub = nil
+	fake.inFlightReturns = struct {
+		result1 []string
+	}{result1}
+}
+
 func (fake *FakeAuctionCellClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -144,6 +220,10 @@ func (fake *FakeAuctionCellClient) Invocations() map[string][][]interface{} {
 	defer fake.performMutex.RUnlock()
 	fake.resetMutex.RLock()
 	defer fake.resetMutex.RUnlock()
+	fake.historyMutex.RLock()
+	defer fake.historyMutex.RUnlock()
+	fake.inFlightMutex.RLock()
+	defer fake.inFlightMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -0,0 +1,77 @@
+// This file was generated by counterfeiter
+package auctioncellrepfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+)
+
+type FakeDiskPressureChecker struct {
+	UnderPressureStub        func(logger lager.Logger) bool
+	underPressureMutex       sync.RWMutex
+	underPressureArgsForCall []struct {
+		logger lager.Logger
+	}
+	underPressureReturns struct {
+		result1 bool
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDiskPressureChecker) UnderPressure(logger lager.Logger) bool {
+	fake.underPressureMutex.Lock()
+	fake.underPressureArgsForCall = append(fake.underPressureArgsForCall, struct {
+		logger lager.Logger
+	}{logger})
+	fake.recordInvocation("UnderPressure", []interface{}{logger})
+	fake.underPressureMutex.Unlock()
+	if fake.UnderPressureStub != nil {
+		return fake.UnderPressureStub(logger)
+	} else {
+		return fake.underPressureReturns.result1
+	}
+}
+
+func (fake *FakeDiskPressureChecker) UnderPressureCallCount() int {
+	fake.underPressureMutex.RLock()
+	defer fake.underPressureMutex.RUnlock()
+	return len(fake.underPressureArgsForCall)
+}
+
+func (fake *FakeDiskPressureChecker) UnderPressureArgsForCall(i int) lager.Logger {
+	fake.underPressureMutex.RLock()
+	defer fake.underPressureMutex.RUnlock()
+	return fake.underPressureArgsForCall[i].logger
+}
+
+func (fake *FakeDiskPressureChecker) UnderPressureReturns(result1 bool) {
+	fake.UnderPressureStub = nil
+	fake.underPressureReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeDiskPressureChecker) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.underPressureMutex.RLock()
+	defer fake.underPressureMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeDiskPressureChecker) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ auctioncellrep.DiskPressureChecker = new(FakeDiskPressureChecker)
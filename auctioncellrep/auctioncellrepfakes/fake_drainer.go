@@ -0,0 +1,111 @@
+// This file was generated by counterfeiter
+package auctioncellrepfakes
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+)
+
+type FakeDrainer struct {
+	DrainStub        func(logger lager.Logger, timeout time.Duration) error
+	drainMutex       sync.RWMutex
+	drainArgsForCall []struct {
+		logger  lager.Logger
+		timeout time.Duration
+	}
+	drainReturns struct {
+		result1 error
+	}
+	UndrainStub        func(logger lager.Logger)
+	undrainMutex       sync.RWMutex
+	undrainArgsForCall []struct {
+		logger lager.Logger
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDrainer) Drain(logger lager.Logger, timeout time.Duration) error {
+	fake.drainMutex.Lock()
+	fake.drainArgsForCall = append(fake.drainArgsForCall, struct {
+		logger  lager.Logger
+		timeout time.Duration
+	}{logger, timeout})
+	fake.recordInvocation("Drain", []interface{}{logger, timeout})
+	fake.drainMutex.Unlock()
+	if fake.DrainStub != nil {
+		return fake.DrainStub(logger, timeout)
+	} else {
+		return fake.drainReturns.result1
+	}
+}
+
+func (fake *FakeDrainer) DrainCallCount() int {
+	fake.drainMutex.RLock()
+	defer fake.drainMutex.RUnlock()
+	return len(fake.drainArgsForCall)
+}
+
+func (fake *FakeDrainer) DrainArgsForCall(i int) (lager.Logger, time.Duration) {
+	fake.drainMutex.RLock()
+	defer fake.drainMutex.RUnlock()
+	return fake.drainArgsForCall[i].logger, fake.drainArgsForCall[i].timeout
+}
+
+func (fake *FakeDrainer) DrainReturns(result1 error) {
+	fake.DrainStub = nil
+	fake.drainReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDrainer) Undrain(logger lager.Logger) {
+	fake.undrainMutex.Lock()
+	fake.undrainArgsForCall = append(fake.undrainArgsForCall, struct {
+		logger lager.Logger
+	}{logger})
+	fake.recordInvocation("Undrain", []interface{}{logger})
+	fake.undrainMutex.Unlock()
+	if fake.UndrainStub != nil {
+		fake.UndrainStub(logger)
+	}
+}
+
+func (fake *FakeDrainer) UndrainCallCount() int {
+	fake.undrainMutex.RLock()
+	defer fake.undrainMutex.RUnlock()
+	return len(fake.undrainArgsForCall)
+}
+
+func (fake *FakeDrainer) UndrainArgsForCall(i int) lager.Logger {
+	fake.undrainMutex.RLock()
+	defer fake.undrainMutex.RUnlock()
+	return fake.undrainArgsForCall[i].logger
+}
+
+func (fake *FakeDrainer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.drainMutex.RLock()
+	defer fake.drainMutex.RUnlock()
+	fake.undrainMutex.RLock()
+	defer fake.undrainMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeDrainer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ auctioncellrep.Drainer = new(FakeDrainer)
@@ -0,0 +1,66 @@
+// This file was generated by counterfeiter
+package auctioncellrepfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/rep/auctioncellrep"
+)
+
+type FakeSummaryProvider struct {
+	SummaryStub        func() auctioncellrep.Summary
+	summaryMutex       sync.RWMutex
+	summaryArgsForCall []struct{}
+	summaryReturns     struct {
+		result1 auctioncellrep.Summary
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeSummaryProvider) Summary() auctioncellrep.Summary {
+	fake.summaryMutex.Lock()
+	fake.summaryArgsForCall = append(fake.summaryArgsForCall, struct{}{})
+	fake.recordInvocation("Summary", []interface{}{})
+	fake.summaryMutex.Unlock()
+	if fake.SummaryStub != nil {
+		return fake.SummaryStub()
+	} else {
+		return fake.summaryReturns.result1
+	}
+}
+
+func (fake *FakeSummaryProvider) SummaryCallCount() int {
+	fake.summaryMutex.RLock()
+	defer fake.summaryMutex.RUnlock()
+	return len(fake.summaryArgsForCall)
+}
+
+func (fake *FakeSummaryProvider) SummaryReturns(result1 auctioncellrep.Summary) {
+	fake.SummaryStub = nil
+	fake.summaryReturns = struct {
+		result1 auctioncellrep.Summary
+	}{result1}
+}
+
+func (fake *FakeSummaryProvider) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.summaryMutex.RLock()
+	defer fake.summaryMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeSummaryProvider) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ auctioncellrep.SummaryProvider = new(FakeSummaryProvider)
@@ -0,0 +1,77 @@
+package auctioncellrep
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// BBSOutagePolicy says what the cell does with new work while it is failing to
+// read from or write to the BBS.
+type BBSOutagePolicy string
+
+const (
+	// BBSOutagePolicyContinue accepts work as usual.
+	BBSOutagePolicyContinue BBSOutagePolicy = "continue"
+	// BBSOutagePolicyReject hands all work back to the auctioneer to be placed
+	// elsewhere until the BBS recovers.
+	BBSOutagePolicyReject BBSOutagePolicy = "reject"
+)
+
+// BBSOutageConfig applies a BBSOutagePolicy separately to failing reads and
+// failing writes as reported by Health.
+type BBSOutageConfig struct {
+	Health         rep.BBSHealth
+	OnReadFailure  BBSOutagePolicy
+	OnWriteFailure BBSOutagePolicy
+}
+
+func NewBBSOutageConfig(health rep.BBSHealth, onReadFailure, onWriteFailure string) (BBSOutageConfig, error) {
+	for _, policy := range []string{onReadFailure, onWriteFailure} {
+		switch BBSOutagePolicy(policy) {
+		case BBSOutagePolicyContinue, BBSOutagePolicyReject:
+		default:
+			return BBSOutageConfig{}, fmt.Errorf("invalid bbs outage policy: %q", policy)
+		}
+	}
+
+	return BBSOutageConfig{
+		Health:         health,
+		OnReadFailure:  BBSOutagePolicy(onReadFailure),
+		OnWriteFailure: BBSOutagePolicy(onWriteFailure),
+	}, nil
+}
+
+// rejectForBBSOutage reports whether new work should be turned away because of
+// the state of the BBS. Without reads the cell cannot find out what it is
+// meant to be running, and without writes it cannot record the starts, so in
+// either case allocating would leave the cell and the BBS out of step.
+func (a *AuctionCellRep) rejectForBBSOutage(logger lager.Logger) bool {
+	outage := a.bbsOutage()
+	if outage == "" {
+		return false
+	}
+
+	logger.Info("rejecting-work-while-" + outage)
+	return true
+}
+
+// bbsOutage names the failure that the cell is turning work away for, or
+// returns the empty string if it is accepting work. While it is turning work
+// away the cell reports itself as degraded in its state.
+func (a *AuctionCellRep) bbsOutage() string {
+	if a.bbsOutageConfig.Health == nil {
+		return ""
+	}
+
+	if a.bbsOutageConfig.OnWriteFailure == BBSOutagePolicyReject && a.bbsOutageConfig.Health.WritesFailing() {
+		return "bbs-writes-fail"
+	}
+
+	if a.bbsOutageConfig.OnReadFailure == BBSOutagePolicyReject && a.bbsOutageConfig.Health.ReadsFailing() {
+		return "bbs-reads-fail"
+	}
+
+	return ""
+}
@@ -0,0 +1,119 @@
+package auctioncellrep
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/go-loggregator/loggregator_v2"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+const repCapacityDrift = "RepCapacityDrift"
+
+var ErrCapacityDrift = errors.New("executor remaining memory disagrees with the memory of its containers")
+
+// CapacityDriftReporter periodically checks the executor's capacity
+// accounting. The memory the executor reports as remaining should equal its
+// total memory less the memory of the containers it lists; a difference means
+// it has leaked or lost track of a reservation, and that the cell is bidding
+// with the wrong capacity. The difference in megabytes is emitted as
+// RepCapacityDrift, positive when the executor reports less memory remaining
+// than it should, and logged as an error once it exceeds thresholdMB.
+//
+// The executor does not offer a consistent snapshot of its containers and
+// remaining resources, so the remaining memory is read on either side of the
+// container listing and the check is skipped when a container was allocated
+// or released in between; otherwise the change would be reported as drift.
+//
+// The rep bids with the executor's figures rather than keeping its own, so
+// there is no model on the rep's side to correct, and the rep cannot release
+// a reservation the executor has lost track of. The report exists to make the
+// drift visible.
+type CapacityDriftReporter struct {
+	logger         lager.Logger
+	interval       time.Duration
+	thresholdMB    int
+	clock          clock.Clock
+	executorClient executor.Client
+	metronClient   loggregator_v2.Client
+}
+
+func NewCapacityDriftReporter(
+	logger lager.Logger,
+	interval time.Duration,
+	thresholdMB int,
+	clock clock.Clock,
+	executorClient executor.Client,
+	metronClient loggregator_v2.Client,
+) *CapacityDriftReporter {
+	return &CapacityDriftReporter{
+		logger:         logger,
+		interval:       interval,
+		thresholdMB:    thresholdMB,
+		clock:          clock,
+		executorClient: executorClient,
+		metronClient:   metronClient,
+	}
+}
+
+func (r *CapacityDriftReporter) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	return rep.NewPeriodicRunner(r.logger, "capacity-drift-reporter", r.interval, r.clock, r.report).Run(signals, ready)
+}
+
+func (r *CapacityDriftReporter) report(logger lager.Logger) {
+	total, err := r.executorClient.TotalResources(logger)
+	if err != nil {
+		logger.Error("failed-to-get-total-resources", err)
+		return
+	}
+
+	before, err := r.executorClient.RemainingResources(logger)
+	if err != nil {
+		logger.Error("failed-to-get-remaining-resources", err)
+		return
+	}
+
+	containers, err := r.executorClient.ListContainers(logger)
+	if err != nil {
+		logger.Error("failed-to-list-containers", err)
+		return
+	}
+
+	remaining, err := r.executorClient.RemainingResources(logger)
+	if err != nil {
+		logger.Error("failed-to-get-remaining-resources", err)
+		return
+	}
+
+	if remaining.MemoryMB != before.MemoryMB {
+		logger.Debug("capacity-changed-during-check", lager.Data{
+			"before-mb": before.MemoryMB,
+			"after-mb":  remaining.MemoryMB,
+		})
+		return
+	}
+
+	allocatedMB := 0
+	for i := range containers {
+		allocatedMB += containers[i].MemoryMB
+	}
+
+	drift := total.MemoryMB - allocatedMB - remaining.MemoryMB
+	if drift > r.thresholdMB || -drift > r.thresholdMB {
+		logger.Error("capacity-drift", ErrCapacityDrift, lager.Data{
+			"drift-mb":     drift,
+			"total-mb":     total.MemoryMB,
+			"allocated-mb": allocatedMB,
+			"remaining-mb": remaining.MemoryMB,
+		})
+	}
+
+	err = r.metronClient.SendMetric(repCapacityDrift, drift)
+	if err != nil {
+		logger.Error("failed-to-send-capacity-drift-metric", err)
+	}
+}
@@ -0,0 +1,135 @@
+package auctioncellrep_test
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/executor/fakes"
+	mfakes "code.cloudfoundry.org/go-loggregator/loggregator_v2/fakes"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CapacityDriftReporter", func() {
+	const (
+		interval    = 10 * time.Second
+		thresholdMB = 64
+	)
+
+	var (
+		logger             *lagertest.TestLogger
+		fakeClock          *fakeclock.FakeClock
+		fakeExecutorClient *fakes.FakeClient
+		fakeMetronClient   *mfakes.FakeClient
+
+		process ifrit.Process
+	)
+
+	container := func(memoryMB int) executor.Container {
+		return executor.Container{Resource: executor.Resource{MemoryMB: memoryMB}}
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		fakeExecutorClient = new(fakes.FakeClient)
+		fakeMetronClient = new(mfakes.FakeClient)
+
+		fakeExecutorClient.TotalResourcesReturns(executor.ExecutorResources{MemoryMB: 1024}, nil)
+		fakeExecutorClient.ListContainersReturns([]executor.Container{container(256), container(256)}, nil)
+	})
+
+	JustBeforeEach(func() {
+		reporter := auctioncellrep.NewCapacityDriftReporter(logger, interval, thresholdMB, fakeClock, fakeExecutorClient, fakeMetronClient)
+		process = ifrit.Invoke(reporter)
+		Eventually(fakeClock.WatcherCount).Should(Equal(1))
+
+		fakeClock.WaitForWatcherAndIncrement(interval)
+		Eventually(fakeExecutorClient.RemainingResourcesCallCount).Should(Equal(2))
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+	})
+
+	Context("when the executor's accounting matches its containers", func() {
+		BeforeEach(func() {
+			fakeExecutorClient.RemainingResourcesReturns(executor.ExecutorResources{MemoryMB: 512}, nil)
+		})
+
+		It("emits no drift", func() {
+			Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(1))
+			name, value := fakeMetronClient.SendMetricArgsForCall(0)
+			Expect(name).To(Equal("RepCapacityDrift"))
+			Expect(value).To(Equal(0))
+			Expect(logger).NotTo(gbytes.Say(`reporter\.capacity-drift"`))
+		})
+	})
+
+	Context("when the drift is within the threshold", func() {
+		BeforeEach(func() {
+			fakeExecutorClient.RemainingResourcesReturns(executor.ExecutorResources{MemoryMB: 480}, nil)
+		})
+
+		It("emits the drift without logging it", func() {
+			Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(1))
+			_, value := fakeMetronClient.SendMetricArgsForCall(0)
+			Expect(value).To(Equal(32))
+			Expect(logger).NotTo(gbytes.Say(`reporter\.capacity-drift"`))
+		})
+	})
+
+	Context("when the drift exceeds the threshold", func() {
+		BeforeEach(func() {
+			fakeExecutorClient.RemainingResourcesReturns(executor.ExecutorResources{MemoryMB: 384}, nil)
+		})
+
+		It("emits and logs the drift", func() {
+			Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(1))
+			_, value := fakeMetronClient.SendMetricArgsForCall(0)
+			Expect(value).To(Equal(128))
+			Expect(logger).To(gbytes.Say(`reporter\.capacity-drift"`))
+		})
+	})
+
+	Context("when the executor reports more remaining than it should", func() {
+		BeforeEach(func() {
+			fakeExecutorClient.RemainingResourcesReturns(executor.ExecutorResources{MemoryMB: 640}, nil)
+		})
+
+		It("emits and logs a negative drift", func() {
+			Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(1))
+			_, value := fakeMetronClient.SendMetricArgsForCall(0)
+			Expect(value).To(Equal(-128))
+			Expect(logger).To(gbytes.Say(`reporter\.capacity-drift"`))
+		})
+	})
+
+	Context("when the executor's capacity changes while the containers are listed", func() {
+		BeforeEach(func() {
+			calls := 0
+			fakeExecutorClient.RemainingResourcesStub = func(lager.Logger) (executor.ExecutorResources, error) {
+				calls++
+				if calls == 1 {
+					return executor.ExecutorResources{MemoryMB: 768}, nil
+				}
+				return executor.ExecutorResources{MemoryMB: 384}, nil
+			}
+		})
+
+		It("skips the check", func() {
+			Eventually(logger).Should(gbytes.Say(`capacity-changed-during-check`))
+			Expect(fakeMetronClient.SendMetricCallCount()).To(Equal(0))
+			Expect(logger).NotTo(gbytes.Say(`reporter\.capacity-drift"`))
+		})
+	})
+})
@@ -0,0 +1,120 @@
+package auctioncellrep
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// CrashBackoffConfig controls how long the cell refuses to run an LRP instance
+// again after it crashes. The delay starts at Initial and doubles with every
+// further crash, up to Max. An instance that has not crashed for ResetAfter
+// starts over at Initial, and the cell forgets about it. A ResetAfter of zero
+// is taken to be DefaultCrashBackoffResetAfter, so that the cell does not
+// remember every instance that ever crashed on it. An Initial of zero disables
+// the backoff.
+type CrashBackoffConfig struct {
+	Initial    time.Duration
+	Max        time.Duration
+	ResetAfter time.Duration
+}
+
+const DefaultCrashBackoffResetAfter = 10 * time.Minute
+
+func (c CrashBackoffConfig) withDefaults() CrashBackoffConfig {
+	if c.ResetAfter <= 0 {
+		c.ResetAfter = DefaultCrashBackoffResetAfter
+	}
+	return c
+}
+
+type crashBackoff struct {
+	lastCrash time.Time
+	delay     time.Duration
+}
+
+var _ rep.CrashRecorder = new(AuctionCellRep)
+
+func (a *AuctionCellRep) RecordCrash(logger lager.Logger, lrpKey *models.ActualLRPKey) {
+	if a.crashBackoffConfig.Initial == 0 {
+		return
+	}
+
+	lrp := rep.LRP{ActualLRPKey: *lrpKey}
+	identifier := lrp.Identifier()
+	now := a.clock.Now()
+
+	a.crashBackoffLock.Lock()
+	defer a.crashBackoffLock.Unlock()
+
+	backoff, found := a.crashBackoffs[identifier]
+	if !found || a.crashBackoffExpired(backoff, now) {
+		backoff = &crashBackoff{delay: a.crashBackoffConfig.Initial}
+		a.crashBackoffs[identifier] = backoff
+	} else {
+		backoff.delay *= 2
+		if a.crashBackoffConfig.Max > 0 && backoff.delay > a.crashBackoffConfig.Max {
+			backoff.delay = a.crashBackoffConfig.Max
+		}
+	}
+	backoff.lastCrash = now
+
+	logger.Info("recorded-lrp-crash", lager.Data{
+		"lrp-key": lrpKey,
+		"backoff": backoff.delay.String(),
+	})
+}
+
+// withoutBackedOffLRPs separates out the LRPs that crashed too recently to be
+// run on this cell again.
+func (a *AuctionCellRep) withoutBackedOffLRPs(logger lager.Logger, lrps []rep.LRP) ([]rep.LRP, []rep.LRP) {
+	if a.crashBackoffConfig.Initial == 0 {
+		return lrps, nil
+	}
+
+	now := a.clock.Now()
+
+	a.crashBackoffLock.Lock()
+	defer a.crashBackoffLock.Unlock()
+
+	allowed, backedOff := partitionLRPs(lrps, func(lrp *rep.LRP) bool {
+		backoff, found := a.crashBackoffs[lrp.Identifier()]
+		return !found || !now.Before(backoff.lastCrash.Add(backoff.delay))
+	})
+
+	if len(backedOff) > 0 {
+		logger.Info("rejecting-lrps-in-crash-backoff", lager.Data{"num-rejected": len(backedOff)})
+	}
+
+	return allowed, backedOff
+}
+
+// currentCrashBackoffs returns the current backoff delay of every LRP instance
+// that has crashed on the cell recently, keyed by LRP identifier.
+func (a *AuctionCellRep) currentCrashBackoffs() map[string]time.Duration {
+	now := a.clock.Now()
+
+	a.crashBackoffLock.Lock()
+	defer a.crashBackoffLock.Unlock()
+
+	var backoffs map[string]time.Duration
+	for identifier, backoff := range a.crashBackoffs {
+		if a.crashBackoffExpired(backoff, now) {
+			delete(a.crashBackoffs, identifier)
+			continue
+		}
+
+		if backoffs == nil {
+			backoffs = make(map[string]time.Duration)
+		}
+		backoffs[identifier] = backoff.delay
+	}
+
+	return backoffs
+}
+
+func (a *AuctionCellRep) crashBackoffExpired(backoff *crashBackoff, now time.Time) bool {
+	return now.Sub(backoff.lastCrash) >= a.crashBackoffConfig.ResetAfter
+}
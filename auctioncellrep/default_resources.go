@@ -0,0 +1,49 @@
+package auctioncellrep
+
+import (
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// DefaultResourceConfig gives the memory and disk to reserve for an LRP that
+// asks for none. Only a limit of exactly zero is replaced, so an LRP that asks
+// for a small amount keeps it. A zero default passes the LRP's zero through to
+// the executor unchanged.
+type DefaultResourceConfig struct {
+	MemoryMB int
+	DiskMB   int
+}
+
+// withDefaultResources substitutes the defaults for the zero limits of the
+// LRPs. It runs before the LRPs are checked against the cell's capacity, so
+// that the checks see what will actually be allocated.
+func (a *AuctionCellRep) withDefaultResources(logger lager.Logger, lrps []rep.LRP) []rep.LRP {
+	if a.defaultResources.MemoryMB <= 0 && a.defaultResources.DiskMB <= 0 {
+		return lrps
+	}
+
+	defaulted := make([]rep.LRP, len(lrps))
+	for i := range lrps {
+		lrp := lrps[i]
+		substituted := false
+
+		if lrp.MemoryMB == 0 && a.defaultResources.MemoryMB > 0 {
+			lrp.MemoryMB = int32(a.defaultResources.MemoryMB)
+			substituted = true
+		}
+		if lrp.DiskMB == 0 && a.defaultResources.DiskMB > 0 {
+			lrp.DiskMB = int32(a.defaultResources.DiskMB)
+			substituted = true
+		}
+
+		if substituted {
+			logger.Info("substituting-default-lrp-resources", lager.Data{
+				"lrp-key":   lrp.ActualLRPKey,
+				"memory-mb": lrp.MemoryMB,
+				"disk-mb":   lrp.DiskMB,
+			})
+		}
+		defaulted[i] = lrp
+	}
+	return defaulted
+}
@@ -0,0 +1,63 @@
+package auctioncellrep
+
+import (
+	"code.cloudfoundry.org/go-loggregator/loggregator_v2"
+	"code.cloudfoundry.org/lager"
+)
+
+const repDiskPressure = "RepDiskPressure"
+
+//go:generate counterfeiter . DiskPressureChecker
+
+// DiskPressureChecker reports whether the filesystem backing the cell is too
+// full to safely accept new work, regardless of the capacity reported by the
+// executor.
+type DiskPressureChecker interface {
+	UnderPressure(logger lager.Logger) bool
+}
+
+type diskPressureChecker struct {
+	path         string
+	minFreeMB    int
+	metronClient loggregator_v2.Client
+}
+
+// NewDiskPressureChecker returns a DiskPressureChecker that considers the cell
+// under pressure when the filesystem containing path has less than minFreeMB
+// megabytes free. An empty path disables the check.
+func NewDiskPressureChecker(path string, minFreeMB int, metronClient loggregator_v2.Client) DiskPressureChecker {
+	return &diskPressureChecker{
+		path:         path,
+		minFreeMB:    minFreeMB,
+		metronClient: metronClient,
+	}
+}
+
+func (c *diskPressureChecker) UnderPressure(logger lager.Logger) bool {
+	if c.path == "" {
+		return false
+	}
+
+	freeMB, err := freeDiskSpaceMB(c.path)
+	if err != nil {
+		logger.Error("failed-to-check-free-disk-space", err, lager.Data{"path": c.path})
+		return false
+	}
+
+	pressure := 0
+	if freeMB < c.minFreeMB {
+		pressure = 1
+		logger.Info("disk-under-pressure", lager.Data{
+			"path":        c.path,
+			"free-mb":     freeMB,
+			"min-free-mb": c.minFreeMB,
+		})
+	}
+
+	err = c.metronClient.SendMetric(repDiskPressure, pressure)
+	if err != nil {
+		logger.Error("failed-to-send-disk-pressure-metric", err)
+	}
+
+	return pressure == 1
+}
@@ -0,0 +1,107 @@
+package auctioncellrep_test
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+
+	mfakes "code.cloudfoundry.org/go-loggregator/loggregator_v2/fakes"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DiskPressureChecker", func() {
+	var (
+		logger           *lagertest.TestLogger
+		fakeMetronClient *mfakes.FakeClient
+		tempDir          string
+		path             string
+		minFreeMB        int
+		checker          auctioncellrep.DiskPressureChecker
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeMetronClient = new(mfakes.FakeClient)
+
+		var err error
+		tempDir, err = ioutil.TempDir("", "disk-pressure")
+		Expect(err).NotTo(HaveOccurred())
+		path = tempDir
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	JustBeforeEach(func() {
+		checker = auctioncellrep.NewDiskPressureChecker(path, minFreeMB, fakeMetronClient)
+	})
+
+	Context("when the filesystem has enough free space", func() {
+		BeforeEach(func() {
+			minFreeMB = 0
+		})
+
+		It("is not under pressure", func() {
+			Expect(checker.UnderPressure(logger)).To(BeFalse())
+		})
+
+		It("emits a disk pressure metric of 0", func() {
+			checker.UnderPressure(logger)
+			Expect(fakeMetronClient.SendMetricCallCount()).To(Equal(1))
+			name, value := fakeMetronClient.SendMetricArgsForCall(0)
+			Expect(name).To(Equal("RepDiskPressure"))
+			Expect(value).To(Equal(0))
+		})
+	})
+
+	Context("when the filesystem is below the free space threshold", func() {
+		BeforeEach(func() {
+			minFreeMB = math.MaxInt32
+		})
+
+		It("is under pressure", func() {
+			Expect(checker.UnderPressure(logger)).To(BeTrue())
+		})
+
+		It("emits a disk pressure metric of 1", func() {
+			checker.UnderPressure(logger)
+			Expect(fakeMetronClient.SendMetricCallCount()).To(Equal(1))
+			name, value := fakeMetronClient.SendMetricArgsForCall(0)
+			Expect(name).To(Equal("RepDiskPressure"))
+			Expect(value).To(Equal(1))
+		})
+	})
+
+	Context("when the path cannot be checked", func() {
+		BeforeEach(func() {
+			path = "/path/does/not/exist"
+			minFreeMB = math.MaxInt32
+		})
+
+		It("is not under pressure", func() {
+			Expect(checker.UnderPressure(logger)).To(BeFalse())
+		})
+
+		It("logs the failure", func() {
+			checker.UnderPressure(logger)
+			Expect(logger.LogMessages()).To(ContainElement("test.failed-to-check-free-disk-space"))
+		})
+	})
+
+	Context("when no path is configured", func() {
+		BeforeEach(func() {
+			path = ""
+			minFreeMB = math.MaxInt32
+		})
+
+		It("is never under pressure", func() {
+			Expect(checker.UnderPressure(logger)).To(BeFalse())
+			Expect(fakeMetronClient.SendMetricCallCount()).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,16 @@
+//go:build !windows
+// +build !windows
+
+package auctioncellrep
+
+import "syscall"
+
+func freeDiskSpaceMB(path string) (int, error) {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(path, &stat)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(uint64(stat.Bavail) * uint64(stat.Bsize) / (1024 * 1024)), nil
+}
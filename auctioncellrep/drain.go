@@ -0,0 +1,123 @@
+package auctioncellrep
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+var ErrDrainTimedOut = errors.New("timed out waiting for in-flight work")
+
+//go:generate counterfeiter . Drainer
+
+type Drainer interface {
+	Drain(logger lager.Logger, timeout time.Duration) error
+	Undrain(logger lager.Logger)
+}
+
+// Drain stops the rep from accepting new work and waits for the work it is
+// already performing to finish, so that every container it asked the executor
+// for has either been allocated or failed by the time it returns. Work that
+// arrives while draining is handed back to the auctioneer. Allocations queued
+// for a slot are not waited for; Stop drops them.
+func (a *AuctionCellRep) Drain(logger lager.Logger, timeout time.Duration) error {
+	logger = logger.Session("drain")
+
+	a.drainLock.Lock()
+	a.draining = true
+	if a.performing == 0 {
+		a.drainLock.Unlock()
+		logger.Info("drained")
+		return nil
+	}
+	if a.drained == nil {
+		a.drained = make(chan struct{})
+	}
+	drained := a.drained
+	a.drainLock.Unlock()
+
+	logger.Info("waiting-for-in-flight-work", lager.Data{"in-flight": a.InFlight()})
+
+	timer := a.clock.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-drained:
+		logger.Info("drained")
+		return nil
+	case <-timer.C():
+		logger.Error("timed-out-waiting-for-in-flight-work", ErrDrainTimedOut, lager.Data{"in-flight": a.InFlight()})
+		return ErrDrainTimedOut
+	}
+}
+
+// Undrain lets the rep accept new work again after a drain.
+func (a *AuctionCellRep) Undrain(logger lager.Logger) {
+	a.drainLock.Lock()
+	defer a.drainLock.Unlock()
+
+	if a.draining {
+		logger.Session("undrain").Info("accepting-work")
+	}
+	a.draining = false
+}
+
+func (a *AuctionCellRep) isDraining() bool {
+	a.drainLock.Lock()
+	defer a.drainLock.Unlock()
+	return a.draining
+}
+
+// startPerforming registers a call to Perform, unless the rep is draining.
+func (a *AuctionCellRep) startPerforming() bool {
+	a.drainLock.Lock()
+	defer a.drainLock.Unlock()
+
+	if a.draining {
+		return false
+	}
+	a.performing++
+	return true
+}
+
+func (a *AuctionCellRep) finishPerforming() {
+	a.drainLock.Lock()
+	defer a.drainLock.Unlock()
+
+	a.performing--
+	if a.performing == 0 && a.drained != nil {
+		close(a.drained)
+		a.drained = nil
+	}
+}
+
+// DrainRunner drains the rep when it is signaled to shut down.
+type DrainRunner struct {
+	logger  lager.Logger
+	drainer Drainer
+	timeout time.Duration
+}
+
+func NewDrainRunner(logger lager.Logger, drainer Drainer, timeout time.Duration) *DrainRunner {
+	return &DrainRunner{
+		logger:  logger,
+		drainer: drainer,
+		timeout: timeout,
+	}
+}
+
+func (r *DrainRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := r.logger.Session("drain-runner")
+	close(ready)
+
+	signal := <-signals
+	logger.Info("received-signal", lager.Data{"signal": signal.String()})
+
+	// A timed out drain is logged by the drainer; it should not fail the
+	// shutdown of the rest of the rep.
+	r.drainer.Drain(logger, r.timeout)
+
+	return nil
+}
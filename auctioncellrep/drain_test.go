@@ -0,0 +1,61 @@
+package auctioncellrep_test
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	"code.cloudfoundry.org/rep/auctioncellrep/auctioncellrepfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+)
+
+var _ = Describe("DrainRunner", func() {
+	var (
+		logger      *lagertest.TestLogger
+		fakeDrainer *auctioncellrepfakes.FakeDrainer
+		process     ifrit.Process
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeDrainer = new(auctioncellrepfakes.FakeDrainer)
+
+		process = ifrit.Invoke(auctioncellrep.NewDrainRunner(logger, fakeDrainer, 30*time.Second))
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Kill)
+		Eventually(process.Wait()).Should(Receive())
+	})
+
+	It("does not drain before being signaled", func() {
+		Consistently(fakeDrainer.DrainCallCount).Should(Equal(0))
+	})
+
+	Context("when signaled", func() {
+		JustBeforeEach(func() {
+			process.Signal(os.Interrupt)
+		})
+
+		It("drains with the configured timeout and exits", func() {
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+			Expect(fakeDrainer.DrainCallCount()).To(Equal(1))
+			_, timeout := fakeDrainer.DrainArgsForCall(0)
+			Expect(timeout).To(Equal(30 * time.Second))
+		})
+
+		Context("when the drain times out", func() {
+			BeforeEach(func() {
+				fakeDrainer.DrainReturns(auctioncellrep.ErrDrainTimedOut)
+			})
+
+			It("still exits cleanly", func() {
+				Eventually(process.Wait()).Should(Receive(BeNil()))
+			})
+		})
+	})
+})
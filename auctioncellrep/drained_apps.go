@@ -0,0 +1,55 @@
+package auctioncellrep
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/rep"
+)
+
+// drainedAppRejectPeriod is how long the cell turns away the instances of an
+// app drained from it. It gives the auctioneer time to place them elsewhere.
+const drainedAppRejectPeriod = 5 * time.Minute
+
+// DrainedApps remembers the apps recently drained from the cell. Its Accepts
+// method is an LRPFilter that turns their instances away, so that the
+// auctioneer does not place them straight back on the cell they were just
+// moved off.
+type DrainedApps struct {
+	clock clock.Clock
+
+	lock    sync.Mutex
+	drained map[string]time.Time
+}
+
+func NewDrainedApps(clock clock.Clock) *DrainedApps {
+	return &DrainedApps{
+		clock:   clock,
+		drained: make(map[string]time.Time),
+	}
+}
+
+// Add records that the app with the given process guid has been drained.
+func (d *DrainedApps) Add(processGuid string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.drained[processGuid] = d.clock.Now()
+}
+
+// Accepts reports whether the cell may run the LRP, which it may unless its
+// app was drained less than drainedAppRejectPeriod ago.
+func (d *DrainedApps) Accepts(lrp *rep.LRP) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	drainedAt, found := d.drained[lrp.ProcessGuid]
+	if !found {
+		return true
+	}
+	if d.clock.Since(drainedAt) >= drainedAppRejectPeriod {
+		delete(d.drained, lrp.ProcessGuid)
+		return true
+	}
+	return false
+}
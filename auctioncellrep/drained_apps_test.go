@@ -0,0 +1,52 @@
+package auctioncellrep_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	"code.cloudfoundry.org/rep/schedulertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DrainedApps", func() {
+	var (
+		fakeClock   *fakeclock.FakeClock
+		drainedApps *auctioncellrep.DrainedApps
+	)
+
+	BeforeEach(func() {
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		drainedApps = auctioncellrep.NewDrainedApps(fakeClock)
+	})
+
+	It("accepts the instances of apps that were not drained", func() {
+		drainedApps.Add("drained-app")
+		lrp := schedulertest.NewLRP("other-app", 0).Build()
+		Expect(drainedApps.Accepts(&lrp)).To(BeTrue())
+	})
+
+	It("turns away the instances of a drained app for five minutes", func() {
+		drainedApps.Add("drained-app")
+		lrp := schedulertest.NewLRP("drained-app", 1).Build()
+		Expect(drainedApps.Accepts(&lrp)).To(BeFalse())
+
+		fakeClock.Increment(5*time.Minute - time.Second)
+		Expect(drainedApps.Accepts(&lrp)).To(BeFalse())
+
+		fakeClock.Increment(time.Second)
+		Expect(drainedApps.Accepts(&lrp)).To(BeTrue())
+	})
+
+	It("starts the period over when an app is drained again", func() {
+		drainedApps.Add("drained-app")
+		fakeClock.Increment(4 * time.Minute)
+		drainedApps.Add("drained-app")
+		fakeClock.Increment(4 * time.Minute)
+
+		lrp := schedulertest.NewLRP("drained-app", 0).Build()
+		Expect(drainedApps.Accepts(&lrp)).To(BeFalse())
+	})
+})
@@ -0,0 +1,46 @@
+package auctioncellrep
+
+import (
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// dryRunLRPs logs the containers the rep would have allocated for the LRPs
+// that made it through matching and validation, and hands the LRPs back
+// instead, so that the auctioneer places them on another cell.
+func (a *AuctionCellRep) dryRunLRPs(logger lager.Logger, requests []executor.AllocationRequest, lrpMap map[string]*rep.LRP) []rep.LRP {
+	lrps := make([]rep.LRP, 0, len(requests))
+	for i := range requests {
+		request := &requests[i]
+		lrp := lrpMap[request.Guid]
+		logger.Info("dry-run-would-allocate-container", lager.Data{
+			"container-guid": request.Guid,
+			"process-guid":   lrp.ProcessGuid,
+			"index":          lrp.Index,
+			"rootfs":         request.RootFSPath,
+			"memory-mb":      request.MemoryMB,
+			"disk-mb":        request.DiskMB,
+		})
+		lrps = append(lrps, *lrp)
+	}
+	return lrps
+}
+
+// dryRunTasks is dryRunLRPs for tasks.
+func (a *AuctionCellRep) dryRunTasks(logger lager.Logger, requests []executor.AllocationRequest, taskMap map[string]*rep.Task) []rep.Task {
+	tasks := make([]rep.Task, 0, len(requests))
+	for i := range requests {
+		request := &requests[i]
+		task := taskMap[request.Guid]
+		logger.Info("dry-run-would-allocate-container", lager.Data{
+			"container-guid": request.Guid,
+			"task-guid":      task.TaskGuid,
+			"rootfs":         request.RootFSPath,
+			"memory-mb":      request.MemoryMB,
+			"disk-mb":        request.DiskMB,
+		})
+		tasks = append(tasks, *task)
+	}
+	return tasks
+}
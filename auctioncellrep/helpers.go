@@ -1,6 +1,13 @@
 package auctioncellrep
 
-import uuid "github.com/nu7hatch/gouuid"
+import (
+	"crypto/sha1"
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/rep"
+	uuid "github.com/nu7hatch/gouuid"
+)
 
 func GenerateGuid() (string, error) {
 	guid, err := uuid.NewV4()
@@ -15,3 +22,54 @@ func GenerateGuid() (string, error) {
 
 	return guidString, nil
 }
+
+// InstanceGuidGenerator returns the instance guid to give the container of a
+// newly auctioned LRP instance. auctionedAt is when the cell received the
+// auction, and tells apart successive incarnations of the same instance.
+type InstanceGuidGenerator func(lrp *rep.LRP, auctionedAt time.Time) (string, error)
+
+const (
+	InstanceGuidStrategyUUID          = "uuid"
+	InstanceGuidStrategyDeterministic = "deterministic"
+)
+
+func NewInstanceGuidGenerator(strategy string) (InstanceGuidGenerator, error) {
+	switch strategy {
+	case InstanceGuidStrategyUUID:
+		return RandomInstanceGuid, nil
+	case InstanceGuidStrategyDeterministic:
+		return DeterministicInstanceGuid, nil
+	default:
+		return nil, fmt.Errorf("invalid instance guid strategy: %q", strategy)
+	}
+}
+
+// RandomInstanceGuid gives every LRP instance a new random guid.
+func RandomInstanceGuid(*rep.LRP, time.Time) (string, error) {
+	return GenerateGuid()
+}
+
+// DeterministicInstanceGuid derives the guid from the process guid and index
+// of the LRP instance and the time it was auctioned, so the guid can be
+// worked out again from the auction, while a later incarnation of the same
+// instance, such as its replacement after a crash or evacuation, gets a guid
+// of its own.
+func DeterministicInstanceGuid(lrp *rep.LRP, auctionedAt time.Time) (string, error) {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s.%d", lrp.Identifier(), auctionedAt.UnixNano())))
+	return fmt.Sprintf("%x", sum)[:28], nil
+}
+
+// partitionLRPs separates the LRPs that accept allows from those it rejects,
+// keeping the order they were given in.
+func partitionLRPs(lrps []rep.LRP, accept func(*rep.LRP) bool) ([]rep.LRP, []rep.LRP) {
+	var rejected []rep.LRP
+	allowed := make([]rep.LRP, 0, len(lrps))
+	for i := range lrps {
+		if !accept(&lrps[i]) {
+			rejected = append(rejected, lrps[i])
+			continue
+		}
+		allowed = append(allowed, lrps[i])
+	}
+	return allowed, rejected
+}
@@ -1,6 +1,10 @@
 package auctioncellrep_test
 
 import (
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/auctioncellrep"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -14,4 +18,54 @@ var _ = Describe("Helpers", func() {
 			Expect(guid).To(HaveLen(28))
 		})
 	})
+
+	Context("NewInstanceGuidGenerator", func() {
+		var (
+			lrp, otherLRP rep.LRP
+			auctionedAt   time.Time
+		)
+
+		BeforeEach(func() {
+			auctionedAt = time.Now()
+			lrp = rep.NewLRP(models.NewActualLRPKey("process-guid", 0, "domain"), rep.NewResource(1, 1, 1), rep.PlacementConstraint{})
+			otherLRP = rep.NewLRP(models.NewActualLRPKey("process-guid", 1, "domain"), rep.NewResource(1, 1, 1), rep.PlacementConstraint{})
+		})
+
+		It("generates a new 28 character guid every time with the uuid strategy", func() {
+			generate, err := auctioncellrep.NewInstanceGuidGenerator("uuid")
+			Expect(err).NotTo(HaveOccurred())
+
+			first, err := generate(&lrp, auctionedAt)
+			Expect(err).NotTo(HaveOccurred())
+			second, err := generate(&lrp, auctionedAt)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(first).To(HaveLen(28))
+			Expect(second).NotTo(Equal(first))
+		})
+
+		It("derives a 28 character guid from the LRP instance and auction with the deterministic strategy", func() {
+			generate, err := auctioncellrep.NewInstanceGuidGenerator("deterministic")
+			Expect(err).NotTo(HaveOccurred())
+
+			first, err := generate(&lrp, auctionedAt)
+			Expect(err).NotTo(HaveOccurred())
+			second, err := generate(&lrp, auctionedAt)
+			Expect(err).NotTo(HaveOccurred())
+			other, err := generate(&otherLRP, auctionedAt)
+			Expect(err).NotTo(HaveOccurred())
+			later, err := generate(&lrp, auctionedAt.Add(time.Second))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(first).To(HaveLen(28))
+			Expect(second).To(Equal(first))
+			Expect(other).NotTo(Equal(first))
+			Expect(later).NotTo(Equal(first))
+		})
+
+		It("rejects an unknown strategy", func() {
+			_, err := auctioncellrep.NewInstanceGuidGenerator("sequential")
+			Expect(err).To(MatchError(ContainSubstring("sequential")))
+		})
+	})
 })
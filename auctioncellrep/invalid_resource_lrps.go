@@ -0,0 +1,56 @@
+package auctioncellrep
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// withoutInvalidResourceLRPs separates out the LRPs whose resource limits can
+// never be satisfied on this cell, so that they are rejected with a reason in
+// the log rather than failing obscurely in the executor. A limit is invalid if
+// it is negative or, for memory and disk, larger than the cell's total
+// capacity. The check is skipped unless enabled.
+func (a *AuctionCellRep) withoutInvalidResourceLRPs(logger lager.Logger, lrps []rep.LRP) ([]rep.LRP, []rep.LRP) {
+	if !a.validateLRPResources || len(lrps) == 0 {
+		return lrps, nil
+	}
+
+	totalResources, err := a.client.TotalResources(logger)
+	if err != nil {
+		logger.Error("failed-to-fetch-total-resources", err)
+		return lrps, nil
+	}
+
+	return partitionLRPs(lrps, func(lrp *rep.LRP) bool {
+		reason := invalidResourceReason(lrp.Resource, totalResources)
+		if reason == "" {
+			return true
+		}
+		logger.Info("rejecting-lrp-with-invalid-resources", lager.Data{
+			"lrp-key": lrp.ActualLRPKey,
+			"reason":  reason,
+		})
+		return false
+	})
+}
+
+// invalidResourceReason describes the first resource limit the cell cannot
+// satisfy, or returns an empty string if it can satisfy them all.
+func invalidResourceReason(resource rep.Resource, total executor.ExecutorResources) string {
+	switch {
+	case resource.MemoryMB < 0:
+		return fmt.Sprintf("memory limit %d MB is negative", resource.MemoryMB)
+	case resource.DiskMB < 0:
+		return fmt.Sprintf("disk limit %d MB is negative", resource.DiskMB)
+	case resource.MaxPids < 0:
+		return fmt.Sprintf("pid limit %d is negative", resource.MaxPids)
+	case int(resource.MemoryMB) > total.MemoryMB:
+		return fmt.Sprintf("memory limit %d MB exceeds the cell's total of %d MB", resource.MemoryMB, total.MemoryMB)
+	case int(resource.DiskMB) > total.DiskMB:
+		return fmt.Sprintf("disk limit %d MB exceeds the cell's total of %d MB", resource.DiskMB, total.DiskMB)
+	}
+	return ""
+}
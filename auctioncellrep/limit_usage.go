@@ -0,0 +1,31 @@
+package auctioncellrep
+
+import (
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+type limitUsageCellClient struct {
+	AuctionCellClient
+	limitReporter rep.LimitReporter
+}
+
+// WithLimitUsage returns an AuctionCellClient that adds the current usage of
+// the cell's concurrency and rate limits to the state reported by client.
+func WithLimitUsage(client AuctionCellClient, limitReporter rep.LimitReporter) AuctionCellClient {
+	return &limitUsageCellClient{
+		AuctionCellClient: client,
+		limitReporter:     limitReporter,
+	}
+}
+
+func (c *limitUsageCellClient) State(logger lager.Logger) (rep.CellState, bool, error) {
+	state, healthy, err := c.AuctionCellClient.State(logger)
+	if err != nil {
+		return state, healthy, err
+	}
+
+	usage := c.limitReporter.LimitUsage()
+	state.LimitUsage = &usage
+	return state, healthy, nil
+}
@@ -0,0 +1,62 @@
+package auctioncellrep_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	"code.cloudfoundry.org/rep/auctioncellrep/auctioncellrepfakes"
+	"code.cloudfoundry.org/rep/repfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WithLimitUsage", func() {
+	var (
+		logger        *lagertest.TestLogger
+		fakeCellRep   *auctioncellrepfakes.FakeAuctionCellClient
+		limitReporter *repfakes.FakeLimitReporter
+		client        auctioncellrep.AuctionCellClient
+		usage         rep.LimitUsage
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeCellRep = new(auctioncellrepfakes.FakeAuctionCellClient)
+		limitReporter = new(repfakes.FakeLimitReporter)
+		usage = rep.LimitUsage{ConcurrencyUsed: 1, ConcurrencyLimit: 4, RateTokens: 10, RateLimit: 60}
+		limitReporter.LimitUsageReturns(usage)
+
+		client = auctioncellrep.WithLimitUsage(fakeCellRep, limitReporter)
+	})
+
+	It("adds the limit usage to the cell state", func() {
+		fakeCellRep.StateReturns(rep.CellState{Zone: "the-zone"}, true, nil)
+
+		state, healthy, err := client.State(logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(healthy).To(BeTrue())
+		Expect(state.Zone).To(Equal("the-zone"))
+		Expect(state.LimitUsage).To(Equal(&usage))
+	})
+
+	It("passes errors fetching the state through", func() {
+		fakeCellRep.StateReturns(rep.CellState{}, false, errors.New("boom"))
+
+		_, _, err := client.State(logger)
+		Expect(err).To(MatchError("boom"))
+		Expect(limitReporter.LimitUsageCallCount()).To(Equal(0))
+	})
+
+	It("delegates work to the wrapped client", func() {
+		work := rep.Work{Tasks: []rep.Task{{TaskGuid: "task-guid"}}}
+		fakeCellRep.PerformReturns(work, nil)
+
+		failed, err := client.Perform(logger, work)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(failed).To(Equal(work))
+		Expect(fakeCellRep.PerformCallCount()).To(Equal(1))
+	})
+})
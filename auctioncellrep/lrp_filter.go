@@ -0,0 +1,38 @@
+package auctioncellrep
+
+import (
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// LRPFilter decides whether the cell accepts an LRP it is auctioned.
+type LRPFilter func(lrp *rep.LRP) bool
+
+// SetLRPFilter replaces the filter that LRPs must pass to be allocated on the
+// cell. LRPs it rejects go back to the auctioneer; LRPs already on the cell
+// are left alone. A nil filter accepts every LRP. It is safe to call while
+// the rep is performing work.
+func (a *AuctionCellRep) SetLRPFilter(filter LRPFilter) {
+	a.lrpFilterLock.Lock()
+	defer a.lrpFilterLock.Unlock()
+	a.lrpFilter = filter
+}
+
+// withoutFilteredLRPs separates out the LRPs the current filter rejects.
+func (a *AuctionCellRep) withoutFilteredLRPs(logger lager.Logger, lrps []rep.LRP) ([]rep.LRP, []rep.LRP) {
+	a.lrpFilterLock.Lock()
+	filter := a.lrpFilter
+	a.lrpFilterLock.Unlock()
+
+	if filter == nil {
+		return lrps, nil
+	}
+
+	allowed, filtered := partitionLRPs(lrps, filter)
+
+	if len(filtered) > 0 {
+		logger.Info("rejecting-filtered-lrps", lager.Data{"num-rejected": len(filtered)})
+	}
+
+	return allowed, filtered
+}
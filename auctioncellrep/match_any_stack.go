@@ -0,0 +1,64 @@
+package auctioncellrep
+
+import (
+	"sort"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// anyStackPath returns the rootfs path that a cell matching any stack uses for
+// stacks it has no preloaded rootfs for: that of the preloaded stack that sorts
+// first by name, so that the choice does not change between restarts.
+func anyStackPath(stackPathMap rep.StackPathMap) string {
+	stacks := make([]string, 0, len(stackPathMap))
+	for stack := range stackPathMap {
+		stacks = append(stacks, stack)
+	}
+	if len(stacks) == 0 {
+		return ""
+	}
+
+	sort.Strings(stacks)
+	return stackPathMap[stacks[0]]
+}
+
+const repStackMismatches = "RepStackMismatches"
+
+// reportStackMismatch reports an LRP auctioned to the cell for a preloaded
+// stack the cell does not have, when the cell is configured to. Such work is
+// always handed back to the auctioneer, but a cell that keeps receiving it is
+// usually misconfigured. Each mismatch is counted, so that it shows up for a
+// rep without a lifecycle error handler, and passed to the handler as a
+// failure to place the LRP.
+func (a *AuctionCellRep) reportStackMismatch(logger lager.Logger, lrp *rep.LRP, containerGuid string) {
+	if !a.reportStackMismatches {
+		return
+	}
+
+	logger.Error("lrp-stack-not-supported", ErrPreloadedRootFSNotFound, lager.Data{
+		"process-guid": lrp.ProcessGuid,
+		"index":        lrp.Index,
+		"rootfs":       lrp.RootFs,
+	})
+	err := a.metronClient.IncrementCounter(repStackMismatches)
+	if err != nil {
+		logger.Error("failed-to-increment-stack-mismatches-counter", err)
+	}
+
+	a.lifecycleErrors.Report(rep.LifecycleError{
+		ProcessGuid:   lrp.ProcessGuid,
+		Index:         lrp.Index,
+		ContainerGuid: containerGuid,
+		Phase:         rep.LifecyclePhasePlace,
+		Err:           ErrPreloadedRootFSNotFound,
+	})
+}
+
+func (a *AuctionCellRep) pathForRootFS(rootFS string) (string, error) {
+	path, err := PathForRootFS(rootFS, a.stackPathMap)
+	if err == ErrPreloadedRootFSNotFound && a.matchAnyStack && a.anyStackPath != "" {
+		return a.anyStackPath, nil
+	}
+	return path, err
+}
@@ -0,0 +1,50 @@
+package auctioncellrep
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+)
+
+const repNegativeRemainingResources = "RepNegativeRemainingResources"
+
+var ErrNegativeRemainingResources = errors.New("executor reported negative remaining resources")
+
+// clampNegativeResources raises any negative remaining resource reported by
+// the executor to zero, so that an overcommitted or misbehaving executor
+// cannot make the cell look bigger or stranger than it is to the auctioneer.
+// It logs the executor's report and emits the number of negative resources
+// whenever it has to clamp.
+func (a *AuctionCellRep) clampNegativeResources(logger lager.Logger, remaining executor.ExecutorResources) executor.ExecutorResources {
+	reported := remaining
+	negative := 0
+
+	if remaining.MemoryMB < 0 {
+		remaining.MemoryMB = 0
+		negative++
+	}
+	if remaining.DiskMB < 0 {
+		remaining.DiskMB = 0
+		negative++
+	}
+	if remaining.Containers < 0 {
+		remaining.Containers = 0
+		negative++
+	}
+
+	if negative == 0 {
+		return remaining
+	}
+
+	logger.Error("clamping-negative-remaining-resources", ErrNegativeRemainingResources, lager.Data{
+		"remaining-resources": reported,
+	})
+
+	err := a.metronClient.SendMetric(repNegativeRemainingResources, negative)
+	if err != nil {
+		logger.Error("failed-to-send-negative-remaining-resources-metric", err)
+	}
+
+	return remaining
+}
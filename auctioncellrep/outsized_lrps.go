@@ -0,0 +1,45 @@
+package auctioncellrep
+
+import (
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+const repOutsizedLRPsRejected = "RepOutsizedLRPsRejected"
+
+// withoutOutsizedLRPs separates out the LRPs that ask for more than the
+// configured fraction of the cell's total memory, so that the auctioneer
+// places them on a larger cell instead of waiting for this one to empty.
+func (a *AuctionCellRep) withoutOutsizedLRPs(logger lager.Logger, lrps []rep.LRP) ([]rep.LRP, []rep.LRP) {
+	if a.maxLRPMemoryFraction <= 0 || len(lrps) == 0 {
+		return lrps, nil
+	}
+
+	totalResources, err := a.client.TotalResources(logger)
+	if err != nil {
+		logger.Error("failed-to-fetch-total-resources", err)
+		return lrps, nil
+	}
+
+	maxMemoryMB := int32(a.maxLRPMemoryFraction * float64(totalResources.MemoryMB))
+
+	allowed, outsized := partitionLRPs(lrps, func(lrp *rep.LRP) bool {
+		return lrp.MemoryMB <= maxMemoryMB
+	})
+
+	if len(outsized) > 0 {
+		logger.Info("rejecting-outsized-lrps", lager.Data{
+			"num-rejected":  len(outsized),
+			"max-memory-mb": maxMemoryMB,
+		})
+		for range outsized {
+			err := a.metronClient.IncrementCounter(repOutsizedLRPsRejected)
+			if err != nil {
+				logger.Error("failed-to-increment-outsized-lrps-counter", err)
+				break
+			}
+		}
+	}
+
+	return allowed, outsized
+}
@@ -0,0 +1,79 @@
+package auctioncellrep
+
+import (
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+)
+
+// PlacementScorer scores how readily the cell should take on new work given
+// its total and remaining resources, from 0 for a full cell to 1 for an empty
+// one.
+type PlacementScorer func(total, remaining executor.ExecutorResources) float64
+
+// LoadPlacementScore is the default PlacementScorer: the fraction of the
+// cell's scarcest resource, out of memory, disk and containers, that remains.
+func LoadPlacementScore(total, remaining executor.ExecutorResources) float64 {
+	score := 1.0
+	for _, resource := range []struct{ total, remaining int }{
+		{total.MemoryMB, remaining.MemoryMB},
+		{total.DiskMB, remaining.DiskMB},
+		{total.Containers, remaining.Containers},
+	} {
+		if resource.total <= 0 {
+			continue
+		}
+		fraction := float64(resource.remaining) / float64(resource.total)
+		if fraction < score {
+			score = fraction
+		}
+	}
+	if score < 0 {
+		return 0
+	}
+	return score
+}
+
+// LoadWeightConfig weights the score the auctioneer computes for the cell by
+// how loaded the cell is, so that among the cells an LRP fits on, a busy one
+// is picked after idle ones. State reports a LoadPenalty of
+// (1 - score) * Weight, which rep.CellState.ComputeScore adds to the cell's
+// score; the auctioneer picks the lowest. For a sense of scale, every starting
+// container adds the auctioneer's starting container weight, 0.25 by default.
+// Scorer defaults to LoadPlacementScore. A Weight of zero disables the
+// penalty.
+type LoadWeightConfig struct {
+	Weight float64
+	Scorer PlacementScorer
+}
+
+// PlacementScore scores the cell's current load with the configured scorer.
+func (a *AuctionCellRep) PlacementScore(logger lager.Logger) (float64, error) {
+	total, err := a.client.TotalResources(logger)
+	if err != nil {
+		return 0, err
+	}
+
+	remaining, err := a.client.RemainingResources(logger)
+	if err != nil {
+		return 0, err
+	}
+
+	return a.scorePlacement(total, remaining), nil
+}
+
+func (a *AuctionCellRep) scorePlacement(total, remaining executor.ExecutorResources) float64 {
+	scorer := a.loadWeightConfig.Scorer
+	if scorer == nil {
+		scorer = LoadPlacementScore
+	}
+	return scorer(total, remaining)
+}
+
+// loadPenalty is what State adds to the cell's auction score for the
+// resources it is about to advertise.
+func (a *AuctionCellRep) loadPenalty(total, remaining executor.ExecutorResources) float64 {
+	if a.loadWeightConfig.Weight <= 0 {
+		return 0
+	}
+	return (1 - a.scorePlacement(total, remaining)) * a.loadWeightConfig.Weight
+}
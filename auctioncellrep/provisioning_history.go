@@ -0,0 +1,113 @@
+package auctioncellrep
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/rep"
+)
+
+// ProvisioningHistoryConfig bounds the provisioning history kept by the cell.
+// At most MaxEvents events are kept, and events older than MaxAge are dropped.
+// A MaxEvents of zero disables the history; a MaxAge of zero keeps events
+// until they are pushed out by newer ones.
+type ProvisioningHistoryConfig struct {
+	MaxEvents int
+	MaxAge    time.Duration
+}
+
+type provisioningHistory struct {
+	config ProvisioningHistoryConfig
+	clock  clock.Clock
+
+	lock   sync.Mutex
+	events []rep.ProvisioningEvent
+}
+
+func newProvisioningHistory(config ProvisioningHistoryConfig, clock clock.Clock) *provisioningHistory {
+	return &provisioningHistory{
+		config: config,
+		clock:  clock,
+	}
+}
+
+// record adds the outcome of an allocation to the history. If err is non-nil
+// every request failed with it; otherwise only the given failures did.
+func (h *provisioningHistory) record(requests []executor.AllocationRequest, failures []executor.AllocationFailure, err error) {
+	if h.config.MaxEvents == 0 {
+		return
+	}
+
+	failed := make(map[string]string, len(failures))
+	for i := range failures {
+		failed[failures[i].Guid] = failures[i].Error()
+	}
+
+	now := h.clock.Now()
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for i := range requests {
+		event := rep.ProvisioningEvent{
+			Time:          now,
+			ContainerGuid: requests[i].Guid,
+			ProcessGuid:   requests[i].Tags[rep.ProcessGuidTag],
+			Lifecycle:     requests[i].Tags[rep.LifecycleTag],
+			Succeeded:     true,
+		}
+
+		if err != nil {
+			event.Succeeded = false
+			event.FailureReason = err.Error()
+		} else if reason, found := failed[requests[i].Guid]; found {
+			event.Succeeded = false
+			event.FailureReason = reason
+		}
+
+		h.events = append(h.events, event)
+	}
+
+	h.prune(now)
+}
+
+// query returns the events since the given time, oldest first. If guid is not
+// empty, only events for that container or process guid are returned.
+func (h *provisioningHistory) query(since time.Time, guid string) []rep.ProvisioningEvent {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.prune(h.clock.Now())
+
+	events := []rep.ProvisioningEvent{}
+	for _, event := range h.events {
+		if event.Time.Before(since) {
+			continue
+		}
+		if guid != "" && event.ContainerGuid != guid && event.ProcessGuid != guid {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func (h *provisioningHistory) prune(now time.Time) {
+	start := 0
+	if len(h.events) > h.config.MaxEvents {
+		start = len(h.events) - h.config.MaxEvents
+	}
+
+	if h.config.MaxAge > 0 {
+		for start < len(h.events) && now.Sub(h.events[start].Time) > h.config.MaxAge {
+			start++
+		}
+	}
+
+	if start > 0 {
+		h.events = append([]rep.ProvisioningEvent(nil), h.events[start:]...)
+	}
+}
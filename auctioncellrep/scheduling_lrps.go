@@ -0,0 +1,73 @@
+package auctioncellrep
+
+import (
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// withoutSchedulingLRPs drops the LRP instances that another auction on the
+// cell, or an earlier entry in the same auction, is already placing, so that
+// an instance delivered twice in quick succession is only allocated once. It
+// marks the remaining instances as being placed; the caller must release them
+// once their containers have been allocated, the allocation has failed or the
+// instances have been queued, which holds them again until they are
+// allocated.
+func (a *AuctionCellRep) withoutSchedulingLRPs(logger lager.Logger, lrps []rep.LRP) ([]rep.LRP, func()) {
+	a.schedulingLock.Lock()
+	defer a.schedulingLock.Unlock()
+
+	claimed := make([]string, 0, len(lrps))
+	remaining := make([]rep.LRP, 0, len(lrps))
+	for i := range lrps {
+		identifier := lrps[i].Identifier()
+		if a.scheduling[identifier] > 0 {
+			continue
+		}
+		a.scheduling[identifier]++
+		claimed = append(claimed, identifier)
+		remaining = append(remaining, lrps[i])
+	}
+
+	if skipped := len(lrps) - len(remaining); skipped > 0 {
+		logger.Info("skipping-lrps-already-being-scheduled", lager.Data{"num-skipped": skipped})
+		for i := 0; i < skipped; i++ {
+			err := a.metronClient.IncrementCounter(repEventsDeduplicated)
+			if err != nil {
+				logger.Error("failed-to-increment-events-deduplicated-counter", err)
+				break
+			}
+		}
+	}
+
+	release := func() {
+		a.releaseScheduling(claimed)
+	}
+
+	return remaining, release
+}
+
+// holdScheduling keeps an LRP instance marked as being placed until the
+// returned function is called.
+func (a *AuctionCellRep) holdScheduling(lrp *rep.LRP) func() {
+	identifier := lrp.Identifier()
+
+	a.schedulingLock.Lock()
+	defer a.schedulingLock.Unlock()
+	a.scheduling[identifier]++
+
+	return func() {
+		a.releaseScheduling([]string{identifier})
+	}
+}
+
+func (a *AuctionCellRep) releaseScheduling(identifiers []string) {
+	a.schedulingLock.Lock()
+	defer a.schedulingLock.Unlock()
+
+	for _, identifier := range identifiers {
+		a.scheduling[identifier]--
+		if a.scheduling[identifier] <= 0 {
+			delete(a.scheduling, identifier)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package auctioncellrep
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// SickCellConfig lets a cell take itself out of service when most of the LRP
+// instances it has recently tried to start have failed, so that a sick cell
+// stops attracting work it will only fail. Once at least MinAttempts starts
+// have ended in the last Window, the cell fails its health check and
+// withdraws its presence while the fraction of them that failed is above
+// MaxFailureRatio. The cell recovers as failures age out of the window or are
+// outweighed by successes. A Window of zero disables the check.
+type SickCellConfig struct {
+	Window          time.Duration
+	MinAttempts     int
+	MaxFailureRatio float64
+}
+
+type provisioningOutcome struct {
+	at     time.Time
+	failed bool
+}
+
+// sickCellDetector keeps the outcomes of the recent attempts to start LRP
+// instances on the cell. Allocation failures are recorded by the auction cell
+// rep, and run, initialization and start outcomes by the generator, through
+// RecordProvisioningOutcome. Allocations refused for lack of capacity say
+// nothing about the health of the cell and are not recorded.
+type sickCellDetector struct {
+	config SickCellConfig
+	clock  clock.Clock
+
+	lock     sync.Mutex
+	outcomes []provisioningOutcome
+	sick     bool
+}
+
+func newSickCellDetector(config SickCellConfig, clock clock.Clock) *sickCellDetector {
+	return &sickCellDetector{
+		config: config,
+		clock:  clock,
+	}
+}
+
+// record adds the outcome of an attempt to start an LRP instance.
+func (d *sickCellDetector) record(failed bool) {
+	if d.config.Window == 0 {
+		return
+	}
+
+	now := d.clock.Now()
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.outcomes = append(d.outcomes, provisioningOutcome{at: now, failed: failed})
+}
+
+// recordAllocationFailures records the allocations that failed for a reason
+// other than the cell being full.
+func (d *sickCellDetector) recordAllocationFailures(requests []executor.AllocationRequest, failures []executor.AllocationFailure, err error) {
+	if err != nil {
+		for range requests {
+			d.record(true)
+		}
+		return
+	}
+
+	for i := range failures {
+		if failures[i].ErrorMsg == executor.ErrInsufficientResourcesAvailable.Error() {
+			continue
+		}
+		d.record(true)
+	}
+}
+
+// check reports whether the cell is sick, logging whenever it becomes sick or
+// recovers.
+func (d *sickCellDetector) check(logger lager.Logger) bool {
+	if d.config.Window == 0 {
+		return false
+	}
+
+	cutoff := d.clock.Now().Add(-d.config.Window)
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	kept := d.outcomes[:0]
+	failures := 0
+	for _, outcome := range d.outcomes {
+		if outcome.at.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, outcome)
+		if outcome.failed {
+			failures++
+		}
+	}
+	d.outcomes = kept
+
+	attempts := len(d.outcomes)
+	sick := attempts > 0 && attempts >= d.config.MinAttempts &&
+		float64(failures)/float64(attempts) > d.config.MaxFailureRatio
+
+	data := lager.Data{
+		"attempts": attempts,
+		"failures": failures,
+		"window":   d.config.Window.String(),
+	}
+	if sick && !d.sick {
+		logger.Info("cell-became-sick", data)
+	} else if !sick && d.sick {
+		logger.Info("cell-recovered", data)
+	}
+	d.sick = sick
+
+	return sick
+}
+
+var _ rep.ProvisioningRecorder = new(AuctionCellRep)
+
+// RecordProvisioningOutcome records how an attempt to start an LRP instance
+// on the cell after it was allocated ended, a nil err meaning it started.
+func (a *AuctionCellRep) RecordProvisioningOutcome(logger lager.Logger, err error) {
+	a.sickCell.record(err != nil)
+}
+
+// Sick reports whether most of the recent attempts to start LRP instances on
+// the cell have failed, in which case the cell should withdraw its presence.
+func (a *AuctionCellRep) Sick(logger lager.Logger) bool {
+	return a.sickCell.check(logger)
+}
@@ -0,0 +1,56 @@
+package auctioncellrep
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// Summary captures the work performed by an AuctionCellRep over its lifetime.
+type Summary struct {
+	Provisioned  int
+	Failed       int
+	Evicted      int
+	PeakInFlight int
+	Uptime       time.Duration
+}
+
+//go:generate counterfeiter . SummaryProvider
+
+type SummaryProvider interface {
+	Summary() Summary
+}
+
+// SummaryReporter logs a single summary of the rep's work when it is
+// signaled to shut down.
+type SummaryReporter struct {
+	logger   lager.Logger
+	provider SummaryProvider
+}
+
+func NewSummaryReporter(logger lager.Logger, provider SummaryProvider) *SummaryReporter {
+	return &SummaryReporter{
+		logger:   logger,
+		provider: provider,
+	}
+}
+
+func (r *SummaryReporter) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := r.logger.Session("summary-reporter")
+	close(ready)
+
+	signal := <-signals
+	logger.Info("received-signal", lager.Data{"signal": signal.String()})
+
+	summary := r.provider.Summary()
+	logger.Info("shutdown-summary", lager.Data{
+		"provisioned":    summary.Provisioned,
+		"failed":         summary.Failed,
+		"evicted":        summary.Evicted,
+		"peak-in-flight": summary.PeakInFlight,
+		"uptime":         summary.Uptime.String(),
+	})
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package auctioncellrep_test
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	"code.cloudfoundry.org/rep/auctioncellrep/auctioncellrepfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+)
+
+var _ = Describe("SummaryReporter", func() {
+	var (
+		logger       *lagertest.TestLogger
+		fakeProvider *auctioncellrepfakes.FakeSummaryProvider
+		process      ifrit.Process
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeProvider = new(auctioncellrepfakes.FakeSummaryProvider)
+		fakeProvider.SummaryReturns(auctioncellrep.Summary{
+			Provisioned:  5,
+			Failed:       2,
+			Evicted:      1,
+			PeakInFlight: 3,
+			Uptime:       time.Minute,
+		})
+
+		process = ifrit.Invoke(auctioncellrep.NewSummaryReporter(logger, fakeProvider))
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Kill)
+		Eventually(process.Wait()).Should(Receive())
+	})
+
+	It("does not report a summary before being signaled", func() {
+		Consistently(fakeProvider.SummaryCallCount).Should(Equal(0))
+	})
+
+	Context("when signaled", func() {
+		BeforeEach(func() {
+			process.Signal(os.Interrupt)
+		})
+
+		It("logs a single summary and exits", func() {
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+			Expect(fakeProvider.SummaryCallCount()).To(Equal(1))
+			Expect(logger).To(gbytes.Say("shutdown-summary"))
+			Expect(logger.Logs()).To(HaveLen(2))
+			data := logger.Logs()[1].Data
+			Expect(data["provisioned"]).To(BeEquivalentTo(5))
+			Expect(data["failed"]).To(BeEquivalentTo(2))
+			Expect(data["evicted"]).To(BeEquivalentTo(1))
+			Expect(data["peak-in-flight"]).To(BeEquivalentTo(3))
+			Expect(data["uptime"]).To(Equal("1m0s"))
+		})
+	})
+})
@@ -0,0 +1,44 @@
+package auctioncellrep
+
+import (
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// withoutUnfittingLRPs separates out the LRPs that do not fit in what remains
+// of the cell's resources, in the order they were given, so that they go back
+// to the auctioneer without a round trip to the executor that is bound to
+// fail.
+func (a *AuctionCellRep) withoutUnfittingLRPs(logger lager.Logger, lrps []rep.LRP) ([]rep.LRP, []rep.LRP) {
+	if !a.rejectBeyondCapacity || len(lrps) == 0 {
+		return lrps, nil
+	}
+
+	remaining, err := a.client.RemainingResources(logger)
+	if err != nil {
+		logger.Error("failed-to-fetch-remaining-resources", err)
+		return lrps, nil
+	}
+
+	allowed, unfitting := partitionLRPs(lrps, func(lrp *rep.LRP) bool {
+		memoryMB, diskMB := int(lrp.MemoryMB), int(lrp.DiskMB)
+		if memoryMB > remaining.MemoryMB || diskMB > remaining.DiskMB || remaining.Containers < 1 {
+			return false
+		}
+
+		remaining.MemoryMB -= memoryMB
+		remaining.DiskMB -= diskMB
+		remaining.Containers--
+		return true
+	})
+
+	if len(unfitting) > 0 {
+		logger.Info("rejecting-lrps-beyond-remaining-capacity", lager.Data{
+			"num-rejected":        len(unfitting),
+			"remaining-memory-mb": remaining.MemoryMB,
+			"remaining-disk-mb":   remaining.DiskMB,
+		})
+	}
+
+	return allowed, unfitting
+}
@@ -0,0 +1,95 @@
+package auctioncellrep
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/go-loggregator/loggregator_v2"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+const (
+	repMemoryUtilization = "RepMemoryUtilization"
+	repDiskUtilization   = "RepDiskUtilization"
+)
+
+// UtilizationReporter periodically emits the percentage, from 0 to 100, of the
+// cell's memory and disk capacity that is allocated to containers. It
+// complements the raw remaining capacity emitted by the executor.
+type UtilizationReporter struct {
+	logger         lager.Logger
+	interval       time.Duration
+	clock          clock.Clock
+	executorClient executor.Client
+	metronClient   loggregator_v2.Client
+}
+
+func NewUtilizationReporter(
+	logger lager.Logger,
+	interval time.Duration,
+	clock clock.Clock,
+	executorClient executor.Client,
+	metronClient loggregator_v2.Client,
+) *UtilizationReporter {
+	return &UtilizationReporter{
+		logger:         logger,
+		interval:       interval,
+		clock:          clock,
+		executorClient: executorClient,
+		metronClient:   metronClient,
+	}
+}
+
+func (r *UtilizationReporter) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	return rep.NewPeriodicRunner(r.logger, "utilization-reporter", r.interval, r.clock, r.report).Run(signals, ready)
+}
+
+func (r *UtilizationReporter) report(logger lager.Logger) {
+	total, err := r.executorClient.TotalResources(logger)
+	if err != nil {
+		logger.Error("failed-to-get-total-resources", err)
+		return
+	}
+
+	remaining, err := r.executorClient.RemainingResources(logger)
+	if err != nil {
+		logger.Error("failed-to-get-remaining-resources", err)
+		return
+	}
+
+	metrics := []struct {
+		name  string
+		value int
+	}{
+		{repMemoryUtilization, utilizationPercent(total.MemoryMB, remaining.MemoryMB)},
+		{repDiskUtilization, utilizationPercent(total.DiskMB, remaining.DiskMB)},
+	}
+
+	for _, metric := range metrics {
+		err := r.metronClient.SendMetric(metric.name, metric.value)
+		if err != nil {
+			logger.Error("failed-to-send-utilization-metric", err, lager.Data{"metric": metric.name})
+		}
+	}
+}
+
+// utilizationPercent returns how much of total is in use, given how much
+// remains, clamped to between 0 and 100. A cell with no capacity is reported as
+// fully utilized.
+func utilizationPercent(total, remaining int) int {
+	if total <= 0 {
+		return 100
+	}
+
+	percent := 100 * (total - remaining) / total
+	if percent < 0 {
+		return 0
+	}
+	if percent > 100 {
+		return 100
+	}
+	return percent
+}
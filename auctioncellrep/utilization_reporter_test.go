@@ -0,0 +1,103 @@
+package auctioncellrep_test
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/executor/fakes"
+	mfakes "code.cloudfoundry.org/go-loggregator/loggregator_v2/fakes"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	"github.com/tedsuo/ifrit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UtilizationReporter", func() {
+	const interval = 10 * time.Second
+
+	var (
+		logger             *lagertest.TestLogger
+		fakeClock          *fakeclock.FakeClock
+		fakeExecutorClient *fakes.FakeClient
+		fakeMetronClient   *mfakes.FakeClient
+
+		process ifrit.Process
+	)
+
+	metrics := func() map[string]int {
+		sent := map[string]int{}
+		for i := 0; i < fakeMetronClient.SendMetricCallCount(); i++ {
+			name, value := fakeMetronClient.SendMetricArgsForCall(i)
+			sent[name] = value
+		}
+		return sent
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		fakeExecutorClient = new(fakes.FakeClient)
+		fakeMetronClient = new(mfakes.FakeClient)
+
+		fakeExecutorClient.TotalResourcesReturns(executor.ExecutorResources{MemoryMB: 1024, DiskMB: 2000, Containers: 10}, nil)
+		fakeExecutorClient.RemainingResourcesReturns(executor.ExecutorResources{MemoryMB: 256, DiskMB: 2000, Containers: 7}, nil)
+	})
+
+	JustBeforeEach(func() {
+		reporter := auctioncellrep.NewUtilizationReporter(logger, interval, fakeClock, fakeExecutorClient, fakeMetronClient)
+		process = ifrit.Invoke(reporter)
+		Eventually(fakeClock.WatcherCount).Should(Equal(1))
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+	})
+
+	It("emits the memory and disk utilization every interval", func() {
+		Consistently(fakeMetronClient.SendMetricCallCount).Should(Equal(0))
+
+		fakeClock.WaitForWatcherAndIncrement(interval)
+		Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(2))
+		Expect(metrics()).To(Equal(map[string]int{
+			"RepMemoryUtilization": 75,
+			"RepDiskUtilization":   0,
+		}))
+
+		fakeClock.WaitForWatcherAndIncrement(interval)
+		Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(4))
+	})
+
+	Context("when the cell has no capacity", func() {
+		BeforeEach(func() {
+			fakeExecutorClient.TotalResourcesReturns(executor.ExecutorResources{}, nil)
+			fakeExecutorClient.RemainingResourcesReturns(executor.ExecutorResources{}, nil)
+		})
+
+		It("reports the cell as fully utilized", func() {
+			fakeClock.WaitForWatcherAndIncrement(interval)
+			Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(2))
+			Expect(metrics()).To(Equal(map[string]int{
+				"RepMemoryUtilization": 100,
+				"RepDiskUtilization":   100,
+			}))
+		})
+	})
+
+	Context("when the resources cannot be fetched", func() {
+		BeforeEach(func() {
+			fakeExecutorClient.RemainingResourcesReturns(executor.ExecutorResources{}, errors.New("boom"))
+		})
+
+		It("emits nothing", func() {
+			fakeClock.WaitForWatcherAndIncrement(interval)
+			Eventually(fakeExecutorClient.RemainingResourcesCallCount).Should(Equal(1))
+			Consistently(fakeMetronClient.SendMetricCallCount).Should(Equal(0))
+		})
+	})
+})
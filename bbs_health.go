@@ -0,0 +1,81 @@
+package rep
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock"
+)
+
+//go:generate counterfeiter -o repfakes/fake_bbshealth.go . BBSHealth
+
+// BBSHealth tracks whether the cell has recently been able to read from and
+// write to the BBS. Only errors from a BBS that could not be reached or failed
+// to answer count as failures; errors about the request itself do not.
+type BBSHealth interface {
+	RecordRead(err error)
+	RecordWrite(err error)
+	ReadsFailing() bool
+	WritesFailing() bool
+}
+
+type bbsHealth struct {
+	clock  clock.Clock
+	window time.Duration
+
+	lock             sync.Mutex
+	lastReadFailure  time.Time
+	lastWriteFailure time.Time
+	readsSucceeding  bool
+	writesSucceeding bool
+}
+
+// NewBBSHealth returns a BBSHealth that considers reads or writes failing when
+// the most recent one failed less than window ago. Failures stop counting after
+// window even if no further requests are made, so that the cell tries again.
+func NewBBSHealth(clock clock.Clock, window time.Duration) BBSHealth {
+	return &bbsHealth{
+		clock:            clock,
+		window:           window,
+		readsSucceeding:  true,
+		writesSucceeding: true,
+	}
+}
+
+func (h *bbsHealth) RecordRead(err error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.readsSucceeding = !isBBSOutage(err)
+	if !h.readsSucceeding {
+		h.lastReadFailure = h.clock.Now()
+	}
+}
+
+func (h *bbsHealth) RecordWrite(err error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.writesSucceeding = !isBBSOutage(err)
+	if !h.writesSucceeding {
+		h.lastWriteFailure = h.clock.Now()
+	}
+}
+
+func (h *bbsHealth) ReadsFailing() bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return !h.readsSucceeding && h.clock.Since(h.lastReadFailure) < h.window
+}
+
+func (h *bbsHealth) WritesFailing() bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return !h.writesSucceeding && h.clock.Since(h.lastWriteFailure) < h.window
+}
+
+func isBBSOutage(err error) bool {
+	if err == nil {
+		return false
+	}
+	return models.ConvertError(err).Type == models.Error_UnknownError
+}
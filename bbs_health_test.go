@@ -0,0 +1,58 @@
+package rep_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BBSHealth", func() {
+	const window = 30 * time.Second
+
+	var (
+		fakeClock *fakeclock.FakeClock
+		health    rep.BBSHealth
+	)
+
+	BeforeEach(func() {
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		health = rep.NewBBSHealth(fakeClock, window)
+	})
+
+	It("starts out healthy", func() {
+		Expect(health.ReadsFailing()).To(BeFalse())
+		Expect(health.WritesFailing()).To(BeFalse())
+	})
+
+	It("tracks reads and writes separately", func() {
+		health.RecordWrite(errors.New("connection refused"))
+		Expect(health.WritesFailing()).To(BeTrue())
+		Expect(health.ReadsFailing()).To(BeFalse())
+
+		health.RecordRead(errors.New("connection refused"))
+		Expect(health.ReadsFailing()).To(BeTrue())
+	})
+
+	It("recovers on the next success", func() {
+		health.RecordWrite(errors.New("connection refused"))
+		health.RecordWrite(nil)
+		Expect(health.WritesFailing()).To(BeFalse())
+	})
+
+	It("stops counting a failure after the window", func() {
+		health.RecordWrite(errors.New("connection refused"))
+		fakeClock.Increment(window)
+		Expect(health.WritesFailing()).To(BeFalse())
+	})
+
+	It("does not count errors about the request itself", func() {
+		health.RecordWrite(models.ErrActualLRPCannotBeClaimed)
+		Expect(health.WritesFailing()).To(BeFalse())
+	})
+})
@@ -0,0 +1,35 @@
+package rep
+
+import "code.cloudfoundry.org/bbs/models"
+
+// CacheKeys returns the distinct cache keys the cached dependencies and
+// download actions of the desired LRP populate, in the order they appear.
+func CacheKeys(desired *models.DesiredLRP) []string {
+	var keys []string
+	seen := map[string]struct{}{}
+	add := func(key string) {
+		if key == "" {
+			return
+		}
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+
+	for _, dependency := range desired.CachedDependencies {
+		add(dependency.CacheKey)
+	}
+
+	for _, action := range []*models.Action{desired.Setup, desired.Action} {
+		walkActions(action, func(action models.ActionInterface) error {
+			if download, ok := action.(*models.DownloadAction); ok {
+				add(download.CacheKey)
+			}
+			return nil
+		})
+	}
+
+	return keys
+}
@@ -0,0 +1,34 @@
+package rep_test
+
+import (
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/rep"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CacheKeys", func() {
+	It("collects the cache keys of cached dependencies and nested downloads once each", func() {
+		desired := &models.DesiredLRP{
+			CachedDependencies: []*models.CachedDependency{
+				{From: "http://example.com/lifecycle.tgz", To: "/tmp/lifecycle", CacheKey: "lifecycle"},
+				{From: "http://example.com/uncached.tgz", To: "/tmp/uncached"},
+			},
+			Setup: models.WrapAction(models.Serial(
+				&models.DownloadAction{From: "http://example.com/droplet.tgz", To: "/home/vcap", CacheKey: "droplet", User: "vcap"},
+				models.Timeout(&models.DownloadAction{From: "http://example.com/lifecycle.tgz", To: "/tmp/lifecycle", CacheKey: "lifecycle", User: "vcap"}, 0),
+			)),
+			Action: models.WrapAction(&models.RunAction{Path: "/tmp/lifecycle/launcher", User: "vcap"}),
+		}
+
+		Expect(rep.CacheKeys(desired)).To(Equal([]string{"lifecycle", "droplet"}))
+	})
+
+	It("returns nothing for an LRP without cached downloads", func() {
+		desired := &models.DesiredLRP{
+			Action: models.WrapAction(&models.RunAction{Path: "/bin/sh", User: "vcap"}),
+		}
+
+		Expect(rep.CacheKeys(desired)).To(BeEmpty())
+	})
+})
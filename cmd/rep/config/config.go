@@ -60,38 +60,100 @@ func (m StackMap) MarshalJSON() (b []byte, err error) {
 
 type RepConfig struct {
 	loggregator_v2.MetronConfig
+	ActionDependencyPaths     []string              `json:"action_dependency_paths,omitempty"`
 	AdvertiseDomain           string                `json:"advertise_domain,omitempty"`
+	AllocationLabels          map[string]string     `json:"allocation_labels,omitempty"`
+	AllocationRetryAttempts   int                   `json:"allocation_retry_attempts,omitempty"`
+	AllocationRetryBackoff    durationjson.Duration `json:"allocation_retry_backoff,omitempty"`
 	BBSAddress                string                `json:"bbs_address"`
 	BBSCACertFile             string                `json:"bbs_ca_cert_file"`
 	BBSClientCertFile         string                `json:"bbs_client_cert_file"`
 	BBSClientKeyFile          string                `json:"bbs_client_key_file"`
 	BBSClientSessionCacheSize int                   `json:"bbs_client_session_cache_size,omitempty"`
 	BBSMaxIdleConnsPerHost    int                   `json:"bbs_max_idle_conns_per_host,omitempty"`
+	BBSOutageWindow           durationjson.Duration `json:"bbs_outage_window,omitempty"`
+	BBSReadFailurePolicy      string                `json:"bbs_read_failure_policy,omitempty"`
+	BBSWriteFailurePolicy     string                `json:"bbs_write_failure_policy,omitempty"`
 	CaCertFile                string                `json:"ca_cert_file"`
+	CapacityDriftInterval     durationjson.Duration `json:"capacity_drift_interval,omitempty"`
+	CapacityDriftThresholdMB  int                   `json:"capacity_drift_threshold_mb,omitempty"`
 	CellID                    string                `json:"cell_id"`
+	ClearStalePresence        bool                  `json:"clear_stale_presence"`
 	CommunicationTimeout      durationjson.Duration `json:"communication_timeout,omitempty"`
 	ConsulCACert              string                `json:"consul_ca_cert"`
 	ConsulClientCert          string                `json:"consul_client_cert"`
 	ConsulClientKey           string                `json:"consul_client_key"`
 	ConsulCluster             string                `json:"consul_cluster"`
+	ContainerSetupTimeout     durationjson.Duration `json:"container_setup_timeout,omitempty"`
+	CrashBackoffInitial       durationjson.Duration `json:"crash_backoff_initial,omitempty"`
+	CrashBackoffMax           durationjson.Duration `json:"crash_backoff_max,omitempty"`
+	CrashBackoffResetAfter    durationjson.Duration `json:"crash_backoff_reset_after,omitempty"`
+	DefaultLRPDiskMB          int                   `json:"default_lrp_disk_mb,omitempty"`
+	DefaultLRPMemoryMB        int                   `json:"default_lrp_memory_mb,omitempty"`
+	DefaultStartTimeout       durationjson.Duration `json:"default_start_timeout,omitempty"`
+	DenyPrivilegedLRPs        bool                  `json:"deny_privileged_lrps"`
+	DenyUnprivilegedRoot      bool                  `json:"deny_unprivileged_root"`
+	DiskPressureMinFreeMB     int                   `json:"disk_pressure_min_free_mb,omitempty"`
+	DiskPressurePath          string                `json:"disk_pressure_path,omitempty"`
 	DropsondePort             int                   `json:"dropsonde_port,omitempty"`
+	DryRun                    bool                  `json:"dry_run"`
 	EnableLegacyAPIServer     bool                  `json:"enable_legacy_api_endpoints"`
+	EnableShutdownSummary     bool                  `json:"enable_shutdown_summary"`
+	ErrorLogThrottleThreshold int                   `json:"error_log_throttle_threshold,omitempty"`
+	ErrorLogThrottleWindow    durationjson.Duration `json:"error_log_throttle_window,omitempty"`
 	EvacuationPollingInterval durationjson.Duration `json:"evacuation_polling_interval,omitempty"`
 	EvacuationTimeout         durationjson.Duration `json:"evacuation_timeout,omitempty"`
+	IdleSyncMaxDefer          durationjson.Duration `json:"idle_sync_max_defer,omitempty"`
+	IdleSyncMaxStarting       int                   `json:"idle_sync_max_starting,omitempty"`
+	InstanceGuidStrategy      string                `json:"instance_guid_strategy,omitempty"`
+	LimitUsageReportInterval  durationjson.Duration `json:"limit_usage_report_interval,omitempty"`
 	ListenAddr                string                `json:"listen_addr,omitempty"`
 	ListenAddrAdmin           string                `json:"listen_addr_admin"`
 	ListenAddrSecurable       string                `json:"listen_addr_securable,omitempty"`
+	LoadScoreWeight           float64               `json:"load_score_weight,omitempty"`
 	LockRetryInterval         durationjson.Duration `json:"lock_retry_interval,omitempty"`
 	LockTTL                   durationjson.Duration `json:"lock_ttl,omitempty"`
+	LogIndexCollisionPolicy   string                `json:"log_index_collision_policy,omitempty"`
+	MatchAnyStack             bool                  `json:"match_any_stack"`
+	MaxConcurrentAllocations  int                   `json:"max_concurrent_allocations,omitempty"`
+	MaxConcurrentStops        int                   `json:"max_concurrent_stops,omitempty"`
+	MaxInPlaceRestarts        int                   `json:"max_in_place_restarts,omitempty"`
+	MaxLRPMemoryFraction      float64               `json:"max_lrp_memory_fraction,omitempty"`
+	MaxMetadataBytes          int                   `json:"max_metadata_bytes,omitempty"`
+	MaxProvisionsPerMinute    int                   `json:"max_provisions_per_minute,omitempty"`
+	MaxRunRetries             int                   `json:"max_run_retries,omitempty"`
+	MaxStartTimeout           durationjson.Duration `json:"max_start_timeout,omitempty"`
+	MetadataLimitPolicy       string                `json:"metadata_limit_policy,omitempty"`
+	MissingContainerListings  int                   `json:"missing_container_listings,omitempty"`
 	OptionalPlacementTags     []string              `json:"optional_placement_tags"`
+	OrphanAction              string                `json:"orphan_action,omitempty"`
+	OrphanQuarantineTTL       durationjson.Duration `json:"orphan_quarantine_ttl,omitempty"`
+	OrderedStartupReadiness   string                `json:"ordered_startup_readiness,omitempty"`
 	PlacementTags             []string              `json:"placement_tags"`
 	PollingInterval           durationjson.Duration `json:"polling_interval,omitempty"`
+	PollingJitter             durationjson.Duration `json:"polling_jitter,omitempty"`
 	PreloadedRootFS           StackMap              `json:"preloaded_root_fs"`
+	ProvisioningHistorySize   int                   `json:"provisioning_history_size,omitempty"`
+	ProvisioningHistoryTTL    durationjson.Duration `json:"provisioning_history_ttl,omitempty"`
+	RejectLRPsBeyondCapacity  bool                  `json:"reject_lrps_beyond_capacity"`
+	ReportStackMismatches     bool                  `json:"report_stack_mismatches"`
 	RequireTLS                bool                  `json:"require_tls"`
+	RunActionEnv              map[string]string     `json:"run_action_env,omitempty"`
+	SerializeCacheWarming     bool                  `json:"serialize_cache_warming"`
+	SerializeContainerCalls   bool                  `json:"serialize_container_calls"`
 	ServerCertFile            string                `json:"server_cert_file"`
 	ServerKeyFile             string                `json:"server_key_file"`
 	SessionName               string                `json:"session_name,omitempty"`
+	ShutdownDrainTimeout      durationjson.Duration `json:"shutdown_drain_timeout,omitempty"`
+	SickCellFailureRatio      float64               `json:"sick_cell_failure_ratio,omitempty"`
+	SickCellMinAttempts       int                   `json:"sick_cell_min_attempts,omitempty"`
+	SickCellWindow            durationjson.Duration `json:"sick_cell_window,omitempty"`
+	StaleContainerMaxAge      durationjson.Duration `json:"stale_container_max_age,omitempty"`
+	StartupQuietPeriod        durationjson.Duration `json:"startup_quiet_period,omitempty"`
+	StateDivergenceThreshold  durationjson.Duration `json:"state_divergence_threshold,omitempty"`
 	SupportedProviders        []string              `json:"supported_providers"`
+	UtilizationInterval       durationjson.Duration `json:"utilization_interval,omitempty"`
+	ValidateLRPResources      bool                  `json:"validate_lrp_resources"`
 	Zone                      string                `json:"zone"`
 	debugserver.DebugServerConfig
 	executorinit.ExecutorConfig
@@ -104,18 +166,31 @@ func defaultConfig() RepConfig {
 		AdvertiseDomain:           "cell.service.cf.internal",
 		BBSClientSessionCacheSize: 0,
 		BBSMaxIdleConnsPerHost:    0,
+		BBSOutageWindow:           durationjson.Duration(30 * time.Second),
+		BBSReadFailurePolicy:      "continue",
+		BBSWriteFailurePolicy:     "continue",
 		CommunicationTimeout:      durationjson.Duration(10 * time.Second),
+		CrashBackoffMax:           durationjson.Duration(5 * time.Minute),
+		CrashBackoffResetAfter:    durationjson.Duration(10 * time.Minute),
 		DropsondePort:             3457,
 		EnableLegacyAPIServer:     true,
+		ErrorLogThrottleWindow:    durationjson.Duration(10 * time.Second),
 		EvacuationPollingInterval: durationjson.Duration(10 * time.Second),
 		EvacuationTimeout:         durationjson.Duration(10 * time.Minute),
 		ExecutorConfig:            executorinit.DefaultConfiguration,
+		InstanceGuidStrategy:      "uuid",
 		LagerConfig:               lagerflags.DefaultLagerConfig(),
 		ListenAddr:                "0.0.0.0:1800",
 		ListenAddrSecurable:       "0.0.0.0:1801",
 		LockRetryInterval:         durationjson.Duration(locket.RetryInterval),
 		LockTTL:                   durationjson.Duration(locket.DefaultSessionTTL),
+		MetadataLimitPolicy:       "truncate",
+		MissingContainerListings:  1,
+		OrphanAction:              "delete",
+		OrphanQuarantineTTL:       durationjson.Duration(24 * time.Hour),
 		PollingInterval:           durationjson.Duration(30 * time.Second),
+		ProvisioningHistorySize:   100,
+		ProvisioningHistoryTTL:    durationjson.Duration(time.Hour),
 		RequireTLS:                true,
 		SessionName:               "rep",
 	}
@@ -22,21 +22,35 @@ var _ = Describe("RepConfig", func() {
 
 	BeforeEach(func() {
 		configData = `{
+			"action_dependency_paths": ["/tmp/lifecycle"],
 			"advertise_domain": "test-domain",
+			"allocation_labels": {"datacenter": "dc1"},
+			"allocation_retry_attempts": 3,
+			"allocation_retry_backoff": "500ms",
 			"bbs_address": "1.1.1.1:9091",
 			"bbs_ca_cert_file": "/tmp/bbs_ca_cert",
 			"bbs_client_cert_file": "/tmp/bbs_client_cert",
 			"bbs_client_key_file": "/tmp/bbs_client_key",
 			"bbs_client_session_cache_size": 100,
 			"bbs_max_idle_conns_per_host": 10,
+			"bbs_outage_window": "1m",
+			"bbs_read_failure_policy": "reject",
+			"bbs_write_failure_policy": "reject",
 			"ca_cert_file": "/tmp/ca_cert",
 			"cache_path": "/tmp/cache",
+			"capacity_drift_interval": "5m",
+			"capacity_drift_threshold_mb": 128,
 			"cell_id" : "cell_z1/10",
+			"clear_stale_presence": true,
 			"communication_timeout": "11s",
 			"consul_ca_cert": "/tmp/consul_ca_cert",
 			"consul_client_cert": "/tmp/consul_client_cert",
 			"consul_client_key": "/tmp/consul_client_key",
 			"consul_cluster": "test cluster",
+			"container_setup_timeout": "15m",
+			"crash_backoff_initial": "2s",
+			"crash_backoff_max": "1m",
+			"crash_backoff_reset_after": "3m",
 			"container_inode_limit": 1000,
 			"container_max_cpu_shares": 4,
 			"container_metrics_report_interval": "16s",
@@ -44,10 +58,21 @@ var _ = Describe("RepConfig", func() {
 			"container_reap_interval": "11s",
 			"create_work_pool_size": 15,
 			"debug_address": "5.5.5.5:9090",
+			"default_lrp_disk_mb": 1024,
+			"default_lrp_memory_mb": 256,
+			"default_start_timeout": "2m",
+			"deny_privileged_lrps": true,
+			"deny_unprivileged_root": true,
 			"delete_work_pool_size": 10,
 			"disk_mb": "20000",
+			"disk_pressure_min_free_mb": 512,
+			"disk_pressure_path": "/var/vcap/data",
 			"dropsonde_port": 8082,
+			"dry_run": true,
 			"enable_legacy_api_endpoints": true,
+			"enable_shutdown_summary": true,
+			"error_log_throttle_threshold": 5,
+			"error_log_throttle_window": "30s",
 			"evacuation_polling_interval" : "13s",
 			"evacuation_timeout" : "12s",
 			"export_network_env_vars": false,
@@ -66,11 +91,18 @@ var _ = Describe("RepConfig", func() {
 			"healthcheck_work_pool_size": 10,
 			"healthy_monitoring_interval": "5s",
 			"healthy_monitoring_interval": "5s",
+			"idle_sync_max_defer": "2m",
+			"idle_sync_max_starting": 4,
+			"instance_guid_strategy": "deterministic",
+			"limit_usage_report_interval": "15s",
 			"listen_addr": "0.0.0.0:8080",
 			"listen_addr_admin": "0.0.0.1:8081",
 			"listen_addr_securable": "0.0.0.0:8081",
+			"load_score_weight": 0.5,
 			"lock_retry_interval": "5s",
 			"lock_ttl": "5s",
+			"log_index_collision_policy": "reject",
+			"match_any_stack": true,
 			"locket_address": "0.0.0.0:909090909",
 			"locket_ca_cert_file": "locket-ca-cert",
 			"locket_client_cert_file": "locket-client-cert",
@@ -78,26 +110,56 @@ var _ = Describe("RepConfig", func() {
 			"log_level": "debug",
 			"max_cache_size_in_bytes": 101,
 			"max_concurrent_downloads": 11,
+			"max_concurrent_allocations": 8,
+			"max_concurrent_stops": 4,
+			"max_in_place_restarts": 2,
+			"max_lrp_memory_fraction": 0.5,
+			"max_metadata_bytes": 4096,
+			"max_provisions_per_minute": 30,
+			"max_run_retries": 2,
+			"max_start_timeout": "10m",
 			"memory_mb": "1000",
+			"metadata_limit_policy": "reject",
 			"metrics_work_pool_size": 5,
+			"missing_container_listings": 3,
 			"optional_placement_tags": ["otag1", "otag2"],
+			"ordered_startup_readiness": "running",
+			"orphan_action": "quarantine",
+			"orphan_quarantine_ttl": "6h",
 			"path_to_ca_certs_for_downloads": "/tmp/ca-certs",
 			"placement_tags": ["tag1", "tag2"],
 			"polling_interval": "10s",
+			"polling_jitter": "3s",
 			"post_setup_hook": "post_setup_hook",
 			"post_setup_user": "post_setup_user",
 			"preloaded_root_fs": ["test:value", "test2:value2"],
+			"provisioning_history_size": 50,
+			"provisioning_history_ttl": "20m",
 			"read_work_pool_size": 15,
+			"reject_lrps_beyond_capacity": true,
+			"report_stack_mismatches": true,
 			"require_tls": true,
+			"run_action_env": {"CELL_ZONE": "z1"},
 			"reserved_expiration_time": "10s",
+			"serialize_cache_warming": true,
+			"serialize_container_calls": true,
 			"server_cert_file": "/tmp/server_cert",
 			"server_key_file": "/tmp/server_key",
 			"session_name": "test",
+			"shutdown_drain_timeout": "30s",
+			"sick_cell_failure_ratio": 0.9,
+			"sick_cell_min_attempts": 10,
+			"sick_cell_window": "5m",
 			"skip_cert_verify": true,
+			"stale_container_max_age": "20m",
+			"startup_quiet_period": "45s",
+			"state_divergence_threshold": "5m",
 			"supported_providers": ["provider1", "provider2"],
 			"temp_dir": "/tmp/test",
 			"trusted_system_certificates_path": "/tmp/trusted",
 			"unhealthy_monitoring_interval": "10s",
+			"utilization_interval": "1m",
+			"validate_lrp_resources": true,
 			"volman_driver_paths": "/tmp/volman1:/tmp/volman2",
 			"zone": "test-zone"
 		}`
@@ -127,31 +189,56 @@ var _ = Describe("RepConfig", func() {
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(repConfig).To(Equal(config.RepConfig{
+			ActionDependencyPaths:     []string{"/tmp/lifecycle"},
 			AdvertiseDomain:           "test-domain",
+			AllocationLabels:          map[string]string{"datacenter": "dc1"},
+			AllocationRetryAttempts:   3,
+			AllocationRetryBackoff:    durationjson.Duration(500 * time.Millisecond),
 			BBSAddress:                "1.1.1.1:9091",
 			BBSCACertFile:             "/tmp/bbs_ca_cert",
 			BBSClientCertFile:         "/tmp/bbs_client_cert",
 			BBSClientKeyFile:          "/tmp/bbs_client_key",
 			BBSClientSessionCacheSize: 100,
 			BBSMaxIdleConnsPerHost:    10,
+			BBSOutageWindow:           durationjson.Duration(time.Minute),
+			BBSReadFailurePolicy:      "reject",
+			BBSWriteFailurePolicy:     "reject",
 			CaCertFile:                "/tmp/ca_cert",
+			CapacityDriftInterval:     durationjson.Duration(5 * time.Minute),
+			CapacityDriftThresholdMB:  128,
 			CellID:                    "cell_z1/10",
+			ClearStalePresence:        true,
 			ClientLocketConfig: locket.ClientLocketConfig{
 				LocketAddress:        "0.0.0.0:909090909",
 				LocketCACertFile:     "locket-ca-cert",
 				LocketClientCertFile: "locket-client-cert",
 				LocketClientKeyFile:  "locket-client-key",
 			},
-			CommunicationTimeout: durationjson.Duration(11 * time.Second),
-			ConsulCACert:         "/tmp/consul_ca_cert",
-			ConsulClientCert:     "/tmp/consul_client_cert",
-			ConsulClientKey:      "/tmp/consul_client_key",
-			ConsulCluster:        "test cluster",
+			CommunicationTimeout:   durationjson.Duration(11 * time.Second),
+			ConsulCACert:           "/tmp/consul_ca_cert",
+			ConsulClientCert:       "/tmp/consul_client_cert",
+			ConsulClientKey:        "/tmp/consul_client_key",
+			ConsulCluster:          "test cluster",
+			ContainerSetupTimeout:  durationjson.Duration(15 * time.Minute),
+			CrashBackoffInitial:    durationjson.Duration(2 * time.Second),
+			CrashBackoffMax:        durationjson.Duration(time.Minute),
+			CrashBackoffResetAfter: durationjson.Duration(3 * time.Minute),
 			DebugServerConfig: debugserver.DebugServerConfig{
 				DebugAddress: "5.5.5.5:9090",
 			},
+			DefaultLRPDiskMB:          1024,
+			DefaultLRPMemoryMB:        256,
+			DefaultStartTimeout:       durationjson.Duration(2 * time.Minute),
+			DenyPrivilegedLRPs:        true,
+			DenyUnprivilegedRoot:      true,
+			DiskPressureMinFreeMB:     512,
+			DiskPressurePath:          "/var/vcap/data",
 			DropsondePort:             8082,
+			DryRun:                    true,
 			EnableLegacyAPIServer:     true,
+			EnableShutdownSummary:     true,
+			ErrorLogThrottleThreshold: 5,
+			ErrorLogThrottleWindow:    durationjson.Duration(30 * time.Second),
 			EvacuationPollingInterval: durationjson.Duration(13 * time.Second),
 			EvacuationTimeout:         durationjson.Duration(12 * time.Second),
 			ExecutorConfig: executorinit.ExecutorConfig{
@@ -194,24 +281,61 @@ var _ = Describe("RepConfig", func() {
 				UnhealthyMonitoringInterval:   10000000000,
 				VolmanDriverPaths:             "/tmp/volman1:/tmp/volman2",
 			},
+			IdleSyncMaxDefer:     durationjson.Duration(2 * time.Minute),
+			IdleSyncMaxStarting:  4,
+			InstanceGuidStrategy: "deterministic",
 			LagerConfig: lagerflags.LagerConfig{
 				LogLevel: lagerflags.DEBUG,
 			},
-			ListenAddr:            "0.0.0.0:8080",
-			ListenAddrAdmin:       "0.0.0.1:8081",
-			ListenAddrSecurable:   "0.0.0.0:8081",
-			LockRetryInterval:     durationjson.Duration(5 * time.Second),
-			LockTTL:               durationjson.Duration(5 * time.Second),
-			OptionalPlacementTags: []string{"otag1", "otag2"},
-			PlacementTags:         []string{"tag1", "tag2"},
-			PollingInterval:       durationjson.Duration(10 * time.Second),
-			PreloadedRootFS:       map[string]string{"test": "value", "test2": "value2"},
-			RequireTLS:            true,
-			ServerCertFile:        "/tmp/server_cert",
-			ServerKeyFile:         "/tmp/server_key",
-			SessionName:           "test",
-			SupportedProviders:    []string{"provider1", "provider2"},
-			Zone:                  "test-zone",
+			LimitUsageReportInterval: durationjson.Duration(15 * time.Second),
+			ListenAddr:               "0.0.0.0:8080",
+			ListenAddrAdmin:          "0.0.0.1:8081",
+			ListenAddrSecurable:      "0.0.0.0:8081",
+			LoadScoreWeight:          0.5,
+			LockRetryInterval:        durationjson.Duration(5 * time.Second),
+			LockTTL:                  durationjson.Duration(5 * time.Second),
+			LogIndexCollisionPolicy:  "reject",
+			MatchAnyStack:            true,
+			MaxConcurrentAllocations: 8,
+			MaxConcurrentStops:       4,
+			MaxInPlaceRestarts:       2,
+			MaxLRPMemoryFraction:     0.5,
+			MaxMetadataBytes:         4096,
+			MaxProvisionsPerMinute:   30,
+			MaxRunRetries:            2,
+			MaxStartTimeout:          durationjson.Duration(10 * time.Minute),
+			MetadataLimitPolicy:      "reject",
+			MissingContainerListings: 3,
+			OptionalPlacementTags:    []string{"otag1", "otag2"},
+			OrderedStartupReadiness:  "running",
+			OrphanAction:             "quarantine",
+			OrphanQuarantineTTL:      durationjson.Duration(6 * time.Hour),
+			PlacementTags:            []string{"tag1", "tag2"},
+			PollingInterval:          durationjson.Duration(10 * time.Second),
+			PollingJitter:            durationjson.Duration(3 * time.Second),
+			PreloadedRootFS:          map[string]string{"test": "value", "test2": "value2"},
+			ProvisioningHistorySize:  50,
+			ProvisioningHistoryTTL:   durationjson.Duration(20 * time.Minute),
+			RejectLRPsBeyondCapacity: true,
+			ReportStackMismatches:    true,
+			RequireTLS:               true,
+			RunActionEnv:             map[string]string{"CELL_ZONE": "z1"},
+			SerializeCacheWarming:    true,
+			SerializeContainerCalls:  true,
+			ServerCertFile:           "/tmp/server_cert",
+			ServerKeyFile:            "/tmp/server_key",
+			SessionName:              "test",
+			ShutdownDrainTimeout:     durationjson.Duration(30 * time.Second),
+			SickCellFailureRatio:     0.9,
+			SickCellMinAttempts:      10,
+			SickCellWindow:           durationjson.Duration(5 * time.Minute),
+			StaleContainerMaxAge:     durationjson.Duration(20 * time.Minute),
+			StartupQuietPeriod:       durationjson.Duration(45 * time.Second),
+			StateDivergenceThreshold: durationjson.Duration(5 * time.Minute),
+			SupportedProviders:       []string{"provider1", "provider2"},
+			UtilizationInterval:      durationjson.Duration(time.Minute),
+			ValidateLRPResources:     true,
+			Zone:                     "test-zone",
 		}))
 	})
 
@@ -261,12 +385,25 @@ var _ = Describe("RepConfig", func() {
 				ListenAddrSecurable:       "0.0.0.0:1801",
 				RequireTLS:                true,
 				PollingInterval:           durationjson.Duration(30 * time.Second),
+				ProvisioningHistorySize:   100,
+				ProvisioningHistoryTTL:    durationjson.Duration(time.Hour),
 				DropsondePort:             3457,
 				CommunicationTimeout:      durationjson.Duration(10 * time.Second),
+				CrashBackoffMax:           durationjson.Duration(5 * time.Minute),
+				CrashBackoffResetAfter:    durationjson.Duration(10 * time.Minute),
 				EvacuationPollingInterval: durationjson.Duration(10 * time.Second),
 				AdvertiseDomain:           "cell.service.cf.internal",
 				EnableLegacyAPIServer:     true,
+				ErrorLogThrottleWindow:    durationjson.Duration(10 * time.Second),
+				MetadataLimitPolicy:       "truncate",
+				InstanceGuidStrategy:      "uuid",
+				MissingContainerListings:  1,
+				OrphanAction:              "delete",
+				OrphanQuarantineTTL:       durationjson.Duration(24 * time.Hour),
 				BBSClientSessionCacheSize: 0,
+				BBSOutageWindow:           durationjson.Duration(30 * time.Second),
+				BBSReadFailurePolicy:      "continue",
+				BBSWriteFailurePolicy:     "continue",
 				EvacuationTimeout:         durationjson.Duration(10 * time.Minute),
 				LagerConfig:               lagerflags.DefaultLagerConfig(),
 				ExecutorConfig: executorinit.ExecutorConfig{
@@ -1,10 +1,10 @@
 package main
 
 import (
-	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"math/rand"
 	"net"
 	"net/url"
 	"os"
@@ -24,8 +24,6 @@ import (
 	"code.cloudfoundry.org/lager/lagerflags"
 	"code.cloudfoundry.org/localip"
 	"code.cloudfoundry.org/locket"
-	"code.cloudfoundry.org/locket/lock"
-	locketmodels "code.cloudfoundry.org/locket/models"
 	"code.cloudfoundry.org/operationq"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/auctioncellrep"
@@ -35,6 +33,7 @@ import (
 	"code.cloudfoundry.org/rep/generator"
 	"code.cloudfoundry.org/rep/handlers"
 	"code.cloudfoundry.org/rep/harmonizer"
+	"code.cloudfoundry.org/rep/logthrottle"
 	"code.cloudfoundry.org/rep/maintain"
 	"github.com/cloudfoundry/dropsonde"
 	"github.com/hashicorp/consul/api"
@@ -84,6 +83,9 @@ func main() {
 
 	clock := clock.NewClock()
 	logger, reconfigurableSink := lagerflags.NewFromConfig(repConfig.SessionName, repConfig.LagerConfig)
+	if repConfig.ErrorLogThrottleThreshold > 0 {
+		logger = logthrottle.NewLogger(logger, clock, time.Duration(repConfig.ErrorLogThrottleWindow), repConfig.ErrorLogThrottleThreshold)
+	}
 
 	var gardenHealthcheckRootFS string
 
@@ -116,6 +118,10 @@ func main() {
 	}
 	defer executorClient.Cleanup(logger)
 
+	if repConfig.SerializeContainerCalls {
+		executorClient = rep.NewSerializedExecutorClient(executorClient)
+	}
+
 	consulClient := initializeConsulClient(logger, repConfig)
 
 	serviceClient := maintain.NewCellPresenceClient(consulClient, clock)
@@ -136,15 +142,130 @@ func main() {
 	)
 
 	bbsClient := initializeBBSClient(logger, repConfig)
-	httpServer, address := initializeServer(bbsClient, executorClient, evacuatable, evacuationReporter, logger, repConfig, false)
-	httpsServer, _ := initializeServer(bbsClient, executorClient, evacuatable, evacuationReporter, logger, repConfig, true)
+	bbsHealth := rep.NewBBSHealth(clock, time.Duration(repConfig.BBSOutageWindow))
+	bbsOutageConfig, err := auctioncellrep.NewBBSOutageConfig(bbsHealth, repConfig.BBSReadFailurePolicy, repConfig.BBSWriteFailurePolicy)
+	if err != nil {
+		logger.Fatal("invalid-bbs-outage-policy", err)
+	}
+	if repConfig.MatchAnyStack {
+		if len(repConfig.PreloadedRootFS) == 0 {
+			logger.Info("match-any-stack-ignored-without-preloaded-stacks")
+		} else {
+			logger.Info("match-any-stack-enabled")
+		}
+	}
+	generateInstanceGuid, err := auctioncellrep.NewInstanceGuidGenerator(repConfig.InstanceGuidStrategy)
+	if err != nil {
+		logger.Fatal("invalid-instance-guid-strategy", err)
+	}
+	auctionCellRep := auctioncellrep.New(
+		auctioncellrep.Config{
+			CellID:                repConfig.CellID,
+			PreloadedStackPathMap: rep.StackPathMap(repConfig.PreloadedRootFS),
+			ArbitraryRootFSes:     repConfig.SupportedProviders,
+			Zone:                  repConfig.Zone,
+			PlacementTags:         repConfig.PlacementTags,
+			OptionalPlacementTags: repConfig.OptionalPlacementTags,
+			StartupQuietPeriod:    time.Duration(repConfig.StartupQuietPeriod),
+			CrashBackoff: auctioncellrep.CrashBackoffConfig{
+				Initial:    time.Duration(repConfig.CrashBackoffInitial),
+				Max:        time.Duration(repConfig.CrashBackoffMax),
+				ResetAfter: time.Duration(repConfig.CrashBackoffResetAfter),
+			},
+			BBSOutage:     bbsOutageConfig,
+			MatchAnyStack: repConfig.MatchAnyStack,
+			History: auctioncellrep.ProvisioningHistoryConfig{
+				MaxEvents: repConfig.ProvisioningHistorySize,
+				MaxAge:    time.Duration(repConfig.ProvisioningHistoryTTL),
+			},
+			MaxLRPMemoryFraction: repConfig.MaxLRPMemoryFraction,
+			ValidateLRPResources: repConfig.ValidateLRPResources,
+			AllocationRetry: auctioncellrep.AllocationRetryConfig{
+				MaxAttempts: repConfig.AllocationRetryAttempts,
+				Backoff:     time.Duration(repConfig.AllocationRetryBackoff),
+			},
+			DefaultResources: auctioncellrep.DefaultResourceConfig{
+				MemoryMB: repConfig.DefaultLRPMemoryMB,
+				DiskMB:   repConfig.DefaultLRPDiskMB,
+			},
+			MaxConcurrentAllocations: repConfig.MaxConcurrentAllocations,
+			SickCell: auctioncellrep.SickCellConfig{
+				Window:          time.Duration(repConfig.SickCellWindow),
+				MinAttempts:     repConfig.SickCellMinAttempts,
+				MaxFailureRatio: repConfig.SickCellFailureRatio,
+			},
+			RejectBeyondCapacity:  repConfig.RejectLRPsBeyondCapacity,
+			ReportStackMismatches: repConfig.ReportStackMismatches,
+			DryRun:                repConfig.DryRun,
+			LoadWeight: auctioncellrep.LoadWeightConfig{
+				Weight: repConfig.LoadScoreWeight,
+			},
+		},
+		generateInstanceGuid,
+		executorClient,
+		evacuationReporter,
+		clock,
+		auctioncellrep.NewDiskPressureChecker(repConfig.DiskPressurePath, repConfig.DiskPressureMinFreeMB, metronClient),
+		initializeAllocationDecorator(repConfig),
+		metronClient,
+	)
+	metadataLimit, err := rep.NewMetadataLimit(repConfig.MaxMetadataBytes, repConfig.MetadataLimitPolicy)
+	if err != nil {
+		logger.Fatal("invalid-metadata-limit", err)
+	}
+	logIndexPolicy, err := rep.NewLogIndexCollisionPolicy(repConfig.LogIndexCollisionPolicy)
+	if err != nil {
+		logger.Fatal("invalid-log-index-collision-policy", err)
+	}
+	startupReadiness, err := generator.ParseStartupReadiness(repConfig.OrderedStartupReadiness)
+	if err != nil {
+		logger.Fatal("invalid-ordered-startup-readiness", err)
+	}
+	orphanAction, err := rep.NewOrphanAction(repConfig.OrphanAction)
+	if err != nil {
+		logger.Fatal("invalid-orphan-action", err)
+	}
+
 	opGenerator := generator.New(
-		repConfig.CellID,
+		generator.Config{
+			CellID:                 repConfig.CellID,
+			EvacuationTTLInSeconds: uint64(time.Duration(repConfig.EvacuationTimeout).Seconds()),
+			MetadataLimit:          metadataLimit,
+			DependencyCheck:        rep.DependencyCheck{Paths: repConfig.ActionDependencyPaths},
+			PrivilegeCheck: rep.PrivilegeCheck{
+				DenyPrivileged:       repConfig.DenyPrivilegedLRPs,
+				DenyUnprivilegedRoot: repConfig.DenyUnprivilegedRoot,
+			},
+			StartTimeout: rep.StartTimeoutLimit{
+				Default: time.Duration(repConfig.DefaultStartTimeout),
+				Max:     time.Duration(repConfig.MaxStartTimeout),
+				Setup:   time.Duration(repConfig.ContainerSetupTimeout),
+			},
+			EnvOverlay:               rep.NewEnvOverlay(repConfig.RunActionEnv),
+			OrphanHandler:            rep.NewOrphanHandler(orphanAction),
+			OrphanQuarantineTTL:      time.Duration(repConfig.OrphanQuarantineTTL),
+			LogIndexPolicy:           logIndexPolicy,
+			MaxConcurrentStops:       repConfig.MaxConcurrentStops,
+			MaxProvisionsPerMinute:   repConfig.MaxProvisionsPerMinute,
+			MaxInPlaceRestarts:       repConfig.MaxInPlaceRestarts,
+			MissingContainerListings: repConfig.MissingContainerListings,
+			StartupReadiness:         startupReadiness,
+			SerializeCacheWarming:    repConfig.SerializeCacheWarming,
+			MaxRunRetries:            repConfig.MaxRunRetries,
+			DryRun:                   repConfig.DryRun,
+		},
 		bbsClient,
 		executorClient,
 		evacuationReporter,
-		uint64(time.Duration(repConfig.EvacuationTimeout).Seconds()),
+		clock,
+		generator.NewDivergenceTracker(clock, time.Duration(repConfig.StateDivergenceThreshold), metronClient),
+		auctionCellRep,
+		auctionCellRep,
+		metronClient,
+		bbsHealth,
 	)
+	limitReporter := rep.MergeLimitReporters(auctionCellRep, opGenerator)
+	cellClient := auctioncellrep.WithLimitUsage(auctionCellRep, limitReporter)
 	cleanup := evacuation.NewEvacuationCleanup(logger, repConfig.CellID, bbsClient, executorClient, clock, metronClient)
 
 	_, portString, err := net.SplitHostPort(repConfig.ListenAddr)
@@ -158,6 +279,15 @@ func main() {
 
 	registrationRunner := initializeRegistrationRunner(logger, consulClient, repConfig, portNum, clock)
 
+	idleConfig := harmonizer.IdleConfig{}
+	if repConfig.IdleSyncMaxDefer > 0 {
+		idleConfig = harmonizer.IdleConfig{
+			Activity:    auctionCellRep,
+			MaxStarting: repConfig.IdleSyncMaxStarting,
+			MaxDefer:    time.Duration(repConfig.IdleSyncMaxDefer),
+		}
+	}
+
 	bulker := harmonizer.NewBulker(
 		logger,
 		time.Duration(repConfig.PollingInterval),
@@ -167,19 +297,101 @@ func main() {
 		opGenerator,
 		queue,
 		metronClient,
+		idleConfig,
+		harmonizer.NewJitter(time.Duration(repConfig.PollingJitter), rand.NewSource(time.Now().UnixNano())),
 	)
+	eventConsumer := harmonizer.NewEventConsumer(logger, opGenerator, queue, clock)
+	liveness := harmonizer.NewLiveness(eventConsumer, bulker)
+
+	drainedApps := auctioncellrep.NewDrainedApps(clock)
+	auctionCellRep.SetLRPFilter(drainedApps.Accepts)
+	evacuationAppDrainer := evacuation.NewAppDrainer(repConfig.CellID, bbsClient, executorClient)
+	appDrainer := handlers.AppDrainFunc(func(logger lager.Logger, processGuid string) ([]models.ActualLRPKey, error) {
+		drainedApps.Add(processGuid)
+		return evacuationAppDrainer.DrainApp(logger, processGuid)
+	})
+	httpServer, address := initializeServer(cellClient, executorClient, evacuatable, auctionCellRep, appDrainer, liveness, logger, repConfig, false)
+	httpsServer, _ := initializeServer(cellClient, executorClient, evacuatable, auctionCellRep, appDrainer, liveness, logger, repConfig, true)
 
 	members := grouper.Members{
-		{"presence", initializeCellPresence(address, serviceClient, executorClient, logger, repConfig, preloadedRootFSes, true)},
+		{"presence", initializeCellPresence(address, serviceClient, executorClient, auctionCellRep, logger, repConfig, preloadedRootFSes, true)},
 		{"http_server", httpServer},
 		{"https_server", httpsServer},
 		{"evacuation-cleanup", cleanup},
 		{"bulker", bulker},
-		{"event-consumer", harmonizer.NewEventConsumer(logger, opGenerator, queue)},
+		{"event-consumer", eventConsumer},
 		{"evacuator", evacuator},
 		{"registration-runner", registrationRunner},
 	}
 
+	if repConfig.LimitUsageReportInterval > 0 {
+		members = append(members, grouper.Member{Name: "limit-usage-reporter", Runner: generator.NewLimitUsageReporter(
+			logger,
+			time.Duration(repConfig.LimitUsageReportInterval),
+			clock,
+			limitReporter,
+			metronClient,
+		)})
+	}
+
+	if repConfig.UtilizationInterval > 0 {
+		members = append(members, grouper.Member{Name: "utilization-reporter", Runner: auctioncellrep.NewUtilizationReporter(
+			logger,
+			time.Duration(repConfig.UtilizationInterval),
+			clock,
+			executorClient,
+			metronClient,
+		)})
+	}
+
+	if repConfig.CapacityDriftInterval > 0 {
+		members = append(members, grouper.Member{Name: "capacity-drift-reporter", Runner: auctioncellrep.NewCapacityDriftReporter(
+			logger,
+			time.Duration(repConfig.CapacityDriftInterval),
+			repConfig.CapacityDriftThresholdMB,
+			clock,
+			executorClient,
+			metronClient,
+		)})
+	}
+
+	if repConfig.StaleContainerMaxAge > 0 {
+		members = append(members, grouper.Member{Name: "stale-container-sweeper", Runner: generator.NewStaleContainerSweeper(
+			logger,
+			time.Duration(repConfig.PollingInterval),
+			time.Duration(repConfig.StaleContainerMaxAge),
+			clock,
+			repConfig.CellID,
+			bbsClient,
+			executorClient,
+		)})
+	}
+
+	if repConfig.ShutdownDrainTimeout > 0 {
+		members = append(grouper.Members{
+			{"drain-runner", auctioncellrep.NewDrainRunner(logger, auctionCellRep, time.Duration(repConfig.ShutdownDrainTimeout))},
+		}, members...)
+	}
+
+	if repConfig.MaxConcurrentAllocations > 0 {
+		// Listed ahead of the drain runner so that the queue is only stopped
+		// once the work being performed has been drained.
+		members = append(grouper.Members{
+			{"allocation-queue", ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+				close(ready)
+				<-signals
+				auctionCellRep.Stop(logger)
+				return nil
+			})},
+		}, members...)
+	}
+
+	if repConfig.EnableShutdownSummary {
+		members = append(grouper.Members{
+			{"summary-reporter", auctioncellrep.NewSummaryReporter(logger, auctionCellRep)},
+		}, members...)
+	}
+
 	members = append(executorMembers, members...)
 
 	if repConfig.DebugAddress != "" {
@@ -211,10 +423,18 @@ func initializeDropsonde(logger lager.Logger, dropsondePort int) {
 	}
 }
 
+func initializeAllocationDecorator(repConfig config.RepConfig) auctioncellrep.AllocationDecorator {
+	if len(repConfig.AllocationLabels) == 0 {
+		return auctioncellrep.NewNoopAllocationDecorator()
+	}
+	return auctioncellrep.NewLabelAllocationDecorator(repConfig.AllocationLabels)
+}
+
 func initializeCellPresence(
 	address string,
 	serviceClient maintain.CellPresenceClient,
 	executorClient executor.Client,
+	auctionCellRep *auctioncellrep.AuctionCellRep,
 	logger lager.Logger,
 	repConfig config.RepConfig,
 	preloadedRootFSes []string,
@@ -228,6 +448,7 @@ func initializeCellPresence(
 		repUrl = fmt.Sprintf("http://%s:%s", repURL(repConfig.CellID, repConfig.AdvertiseDomain), port)
 	}
 
+	var presenceClient maintain.PresenceClient = serviceClient
 	if repConfig.LocketAddress != "" {
 		locketClient, err := locket.NewClient(logger, repConfig.ClientLocketConfig)
 		if err != nil {
@@ -239,82 +460,60 @@ func initializeCellPresence(
 			logger.Fatal("failed-to-generate-guid", err)
 		}
 
-		resources, err := executorClient.TotalResources(logger)
-		if err != nil {
-			logger.Fatal("failed-to-get-total-resources", err)
-		}
-		cellCapacity := models.NewCellCapacity(int32(resources.MemoryMB), int32(resources.DiskMB), int32(resources.Containers))
-		cellPresence := models.NewCellPresence(repConfig.CellID, address, repUrl,
-			repConfig.Zone, cellCapacity, repConfig.SupportedProviders,
-			preloadedRootFSes, repConfig.PlacementTags, repConfig.OptionalPlacementTags)
-
-		payload, err := json.Marshal(cellPresence)
-		if err != nil {
-			logger.Fatal("failed-to-encode-cell-presence", err)
-		}
-
-		lockPayload := &locketmodels.Resource{
-			Key:   repConfig.CellID,
-			Owner: guid.String(),
-			Value: string(payload),
-			Type:  locketmodels.PresenceType,
-		}
-
-		logger.Debug("presence-payload", lager.Data{"payload": lockPayload})
-		return lock.NewPresenceRunner(
-			logger,
-			locketClient,
-			lockPayload,
-			int64(time.Duration(repConfig.LockTTL)/time.Second),
-			clock.NewClock(),
-			locket.RetryInterval,
-		)
-	} else {
-		config := maintain.Config{
-			CellID:                repConfig.CellID,
-			RepAddress:            address,
-			RepUrl:                repUrl,
-			Zone:                  repConfig.Zone,
-			RetryInterval:         time.Duration(repConfig.LockRetryInterval),
-			RootFSProviders:       repConfig.SupportedProviders,
-			PreloadedRootFSes:     preloadedRootFSes,
-			PlacementTags:         repConfig.PlacementTags,
-			OptionalPlacementTags: repConfig.OptionalPlacementTags,
+		presenceClient = maintain.NewLocketPresenceClient(locketClient, guid.String(), clock.NewClock())
+
+		// Only clearing a stale presence needs the Maintainer; otherwise the
+		// locket presence is held as soon as the rep starts, as before.
+		if !repConfig.ClearStalePresence {
+			resources, err := executorClient.TotalResources(logger)
+			if err != nil {
+				logger.Fatal("failed-to-get-total-resources", err)
+			}
+			cellCapacity := models.NewCellCapacity(int32(resources.MemoryMB), int32(resources.DiskMB), int32(resources.Containers))
+			cellPresence := models.NewCellPresence(repConfig.CellID, address, repUrl,
+				repConfig.Zone, cellCapacity, repConfig.SupportedProviders,
+				preloadedRootFSes, repConfig.PlacementTags, repConfig.OptionalPlacementTags)
+
+			return presenceClient.NewCellPresenceRunner(logger, &cellPresence, locket.RetryInterval, time.Duration(repConfig.LockTTL))
 		}
+	}
 
-		return maintain.New(
-			logger,
-			config,
-			executorClient,
-			serviceClient,
-			time.Duration(repConfig.LockTTL),
-			clock.NewClock(),
-		)
+	config := maintain.Config{
+		CellID:                repConfig.CellID,
+		RepAddress:            address,
+		RepUrl:                repUrl,
+		Zone:                  repConfig.Zone,
+		RetryInterval:         time.Duration(repConfig.LockRetryInterval),
+		RootFSProviders:       repConfig.SupportedProviders,
+		PreloadedRootFSes:     preloadedRootFSes,
+		PlacementTags:         repConfig.PlacementTags,
+		OptionalPlacementTags: repConfig.OptionalPlacementTags,
+		ClearStalePresence:    repConfig.ClearStalePresence,
+		Withdrawn:             auctionCellRep.Sick,
 	}
+
+	return maintain.New(
+		logger,
+		config,
+		executorClient,
+		presenceClient,
+		time.Duration(repConfig.LockTTL),
+		clock.NewClock(),
+	)
 }
 
 func initializeServer(
-	bbsClient bbs.InternalClient,
+	auctionCellRep auctioncellrep.AuctionCellClient,
 	executorClient executor.Client,
 	evacuatable evacuation_context.Evacuatable,
-	evacuationReporter evacuation_context.EvacuationReporter,
+	drainer auctioncellrep.Drainer,
+	appDrainer handlers.AppDrainer,
+	health handlers.HealthChecker,
 	logger lager.Logger,
 	repConfig config.RepConfig,
 	secure bool,
 ) (ifrit.Runner, string) {
-	auctionCellRep := auctioncellrep.New(
-		repConfig.CellID,
-		rep.StackPathMap(repConfig.PreloadedRootFS),
-		repConfig.SupportedProviders,
-		repConfig.Zone,
-		auctioncellrep.GenerateGuid,
-		executorClient,
-		evacuationReporter,
-		repConfig.PlacementTags,
-		repConfig.OptionalPlacementTags,
-	)
-
-	handlers := getHandlers(logger, auctionCellRep, executorClient, evacuatable, repConfig.EnableLegacyAPIServer, secure)
+	handlers := getHandlers(logger, auctionCellRep, executorClient, evacuatable, drainer, time.Duration(repConfig.ShutdownDrainTimeout), appDrainer, health, repConfig.EnableLegacyAPIServer, secure, rep.NewCellIdentity(repConfig.CellID))
 	routes := getRoutes(repConfig.EnableLegacyAPIServer, secure)
 	router, err := rata.NewRouter(routes, handlers)
 
@@ -351,14 +550,19 @@ func getHandlers(
 	auctionCellRep auctioncellrep.AuctionCellClient,
 	executorClient executor.Client,
 	evacuatable evacuation_context.Evacuatable,
+	drainer auctioncellrep.Drainer,
+	drainTimeout time.Duration,
+	appDrainer handlers.AppDrainer,
+	health handlers.HealthChecker,
 	enableLegacyAPIServer bool,
 	isSecureServer bool,
+	identity rep.CellIdentity,
 ) rata.Handlers {
 
 	if enableLegacyAPIServer && !isSecureServer {
-		return handlers.NewLegacy(auctionCellRep, executorClient, evacuatable, logger)
+		return handlers.NewLegacy(auctionCellRep, executorClient, evacuatable, drainer, drainTimeout, appDrainer, health, logger, identity)
 	}
-	return handlers.New(auctionCellRep, executorClient, evacuatable, logger, isSecureServer)
+	return handlers.New(auctionCellRep, executorClient, evacuatable, drainer, drainTimeout, appDrainer, health, logger, isSecureServer, identity)
 }
 
 func getRoutes(enableLegacyAPIServer, isSecureServer bool) rata.Routes {
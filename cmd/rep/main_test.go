@@ -365,6 +365,40 @@ dYbCU/DMZjsv+Pt9flhj7ELLo+WKHyI767hJSq9A7IT3GzFt8iGiEAt1qj2yS0DX
 					Expect(value.CellId).To(Equal(repConfig.CellID))
 				})
 
+				Context("when a previous run left its presence behind", func() {
+					var locketClient locketmodels.LocketClient
+
+					BeforeEach(func() {
+						var err error
+						locketClient, err = locket.NewClient(logger, repConfig.ClientLocketConfig)
+						Expect(err).NotTo(HaveOccurred())
+
+						_, err = locketClient.Lock(context.Background(), &locketmodels.LockRequest{
+							Resource: &locketmodels.Resource{
+								Key:   repConfig.CellID,
+								Owner: "previous-run",
+								Value: "{}",
+								Type:  locketmodels.PresenceType,
+							},
+							TtlInSeconds: 60,
+						})
+						Expect(err).NotTo(HaveOccurred())
+
+						repConfig.ClearStalePresence = true
+						runner = testrunner.New(representativePath, repConfig)
+					})
+
+					It("clears it and maintains its own presence straight away", func() {
+						Eventually(func() (string, error) {
+							response, err := locketClient.Fetch(context.Background(), &locketmodels.FetchRequest{Key: repConfig.CellID})
+							if err != nil {
+								return "", err
+							}
+							return response.Resource.Owner, nil
+						}, 10*time.Second).ShouldNot(Equal("previous-run"))
+					})
+				})
+
 				Context("when it loses its presence", func() {
 					var locketClient locketmodels.LocketClient
 					var response *locketmodels.FetchResponse
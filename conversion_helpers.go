@@ -21,6 +21,8 @@ const (
 	ProcessGuidTag  = "process-guid"
 	InstanceGuidTag = "instance-guid"
 	ProcessIndexTag = "process-index"
+
+	AnnotationTag = "annotation"
 )
 
 var (
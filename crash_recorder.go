@@ -0,0 +1,13 @@
+package rep
+
+import (
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager"
+)
+
+//go:generate counterfeiter -o repfakes/fake_crash_recorder.go . CrashRecorder
+
+// CrashRecorder is notified whenever an LRP instance on the cell crashes.
+type CrashRecorder interface {
+	RecordCrash(logger lager.Logger, lrpKey *models.ActualLRPKey)
+}
@@ -0,0 +1,68 @@
+package rep
+
+import (
+	"sort"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/executor"
+)
+
+// EnvOverlay holds environment variables the cell adds to every run action of
+// the LRPs it runs, such as its zone or cell id. The LRP takes precedence: a
+// variable the run action or the LRP itself already sets keeps the LRP's
+// value.
+type EnvOverlay []models.EnvironmentVariable
+
+// NewEnvOverlay returns an EnvOverlay of the given variables, ordered by name.
+func NewEnvOverlay(env map[string]string) EnvOverlay {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	overlay := make(EnvOverlay, 0, len(names))
+	for _, name := range names {
+		overlay = append(overlay, models.EnvironmentVariable{Name: name, Value: env[name]})
+	}
+	return overlay
+}
+
+// Apply adds the overlay to the run actions of the run request.
+func (o EnvOverlay) Apply(runReq *executor.RunRequest) {
+	if len(o) == 0 {
+		return
+	}
+
+	lrpEnv := map[string]struct{}{}
+	for _, env := range runReq.Env {
+		lrpEnv[env.Name] = struct{}{}
+	}
+
+	for _, action := range []*models.Action{runReq.Setup, runReq.Action, runReq.Monitor} {
+		walkActions(action, func(action models.ActionInterface) error {
+			if run, ok := action.(*models.RunAction); ok {
+				o.applyToRun(run, lrpEnv)
+			}
+			return nil
+		})
+	}
+}
+
+func (o EnvOverlay) applyToRun(run *models.RunAction, lrpEnv map[string]struct{}) {
+	set := map[string]struct{}{}
+	for _, env := range run.Env {
+		set[env.Name] = struct{}{}
+	}
+
+	for i := range o {
+		if _, ok := lrpEnv[o[i].Name]; ok {
+			continue
+		}
+		if _, ok := set[o[i].Name]; ok {
+			continue
+		}
+		env := o[i]
+		run.Env = append(run.Env, &env)
+	}
+}
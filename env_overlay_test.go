@@ -0,0 +1,51 @@
+package rep_test
+
+import (
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/rep"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EnvOverlay", func() {
+	var (
+		overlay rep.EnvOverlay
+		runReq  executor.RunRequest
+		run     *models.RunAction
+	)
+
+	BeforeEach(func() {
+		overlay = rep.NewEnvOverlay(map[string]string{
+			"CELL_ID":  "the-cell",
+			"THE_KEY":  "the-cell-value",
+			"LRP_WIDE": "the-cell-value",
+		})
+
+		run = &models.RunAction{
+			Path: "/bin/sh",
+			User: "vcap",
+			Env:  []*models.EnvironmentVariable{{Name: "THE_KEY", Value: "THE_VALUE"}},
+		}
+		runReq = executor.NewRunRequest("guid", &executor.RunInfo{
+			Action: models.WrapAction(models.Timeout(run, 0)),
+			Env:    []executor.EnvironmentVariable{{Name: "LRP_WIDE", Value: "the-lrp-value"}},
+		}, executor.Tags{})
+	})
+
+	It("orders the variables by name", func() {
+		Expect(overlay).To(Equal(rep.EnvOverlay{
+			{Name: "CELL_ID", Value: "the-cell"},
+			{Name: "LRP_WIDE", Value: "the-cell-value"},
+			{Name: "THE_KEY", Value: "the-cell-value"},
+		}))
+	})
+
+	It("adds the variables the LRP does not set to its run actions", func() {
+		overlay.Apply(&runReq)
+		Expect(run.Env).To(ConsistOf(
+			&models.EnvironmentVariable{Name: "THE_KEY", Value: "THE_VALUE"},
+			&models.EnvironmentVariable{Name: "CELL_ID", Value: "the-cell"},
+		))
+	})
+})
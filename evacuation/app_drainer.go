@@ -0,0 +1,81 @@
+package evacuation
+
+import (
+	"code.cloudfoundry.org/bbs"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// AppDrainer moves the instances of a single app off the cell while leaving
+// the rest of the cell's work running. It serves the rep's drain app route.
+type AppDrainer struct {
+	cellID         string
+	bbsClient      bbs.InternalClient
+	executorClient executor.Client
+}
+
+func NewAppDrainer(
+	cellID string,
+	bbsClient bbs.InternalClient,
+	executorClient executor.Client,
+) *AppDrainer {
+	return &AppDrainer{
+		cellID:         cellID,
+		bbsClient:      bbsClient,
+		executorClient: executorClient,
+	}
+}
+
+// DrainApp unclaims the cell's instances of the app with the given process
+// guid, so that they are rescheduled elsewhere, and deletes their containers.
+// It returns the keys of the instances that were moved.
+func (d *AppDrainer) DrainApp(logger lager.Logger, processGuid string) ([]models.ActualLRPKey, error) {
+	logger = logger.Session("drain-app", lager.Data{"process-guid": processGuid})
+	logger.Info("starting")
+	defer logger.Info("finished")
+
+	containers, err := d.executorClient.ListContainers(logger)
+	if err != nil {
+		logger.Error("failed-listing-containers", err)
+		return nil, err
+	}
+
+	moved := []models.ActualLRPKey{}
+	for _, container := range containers {
+		if container.Tags[rep.LifecycleTag] != rep.LRPLifecycle ||
+			container.Tags[rep.ProcessGuidTag] != processGuid ||
+			container.State == executor.StateCompleted {
+			continue
+		}
+
+		lrpKey, err := rep.ActualLRPKeyFromTags(container.Tags)
+		if err != nil {
+			logger.Error("failed-to-generate-lrp-key", err, lager.Data{"container-guid": container.Guid})
+			continue
+		}
+
+		instanceKey, err := rep.ActualLRPInstanceKeyFromContainer(container, d.cellID)
+		if err != nil {
+			logger.Error("failed-to-generate-instance-key", err, lager.Data{"container-guid": container.Guid})
+			continue
+		}
+
+		_, err = d.bbsClient.EvacuateClaimedActualLRP(logger, lrpKey, instanceKey)
+		if err != nil {
+			logger.Error("failed-to-unclaim-actual-lrp", err, lager.Data{"lrp-key": lrpKey})
+			continue
+		}
+
+		err = d.executorClient.DeleteContainer(logger, container.Guid)
+		if err != nil {
+			logger.Error("failed-to-delete-container", err, lager.Data{"container-guid": container.Guid})
+		}
+
+		moved = append(moved, *lrpKey)
+	}
+
+	logger.Info("drained-instances", lager.Data{"num-instances": len(moved)})
+	return moved, nil
+}
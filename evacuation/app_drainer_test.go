@@ -0,0 +1,134 @@
+package evacuation_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/bbs/fake_bbs"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/evacuation"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppDrainer", func() {
+	var (
+		logger             *lagertest.TestLogger
+		cellID             string
+		fakeBBSClient      *fake_bbs.FakeInternalClient
+		fakeExecutorClient *fakes.FakeClient
+
+		drainer *evacuation.AppDrainer
+	)
+
+	lrpContainer := func(guid, processGuid, index string, state executor.State) executor.Container {
+		return executor.Container{
+			Guid:  guid,
+			State: state,
+			Tags: executor.Tags{
+				rep.LifecycleTag:    rep.LRPLifecycle,
+				rep.DomainTag:       "domain",
+				rep.ProcessGuidTag:  processGuid,
+				rep.ProcessIndexTag: index,
+				rep.InstanceGuidTag: guid,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		cellID = "the-cell-id"
+		logger = lagertest.NewTestLogger("drainer")
+		fakeBBSClient = &fake_bbs.FakeInternalClient{}
+		fakeExecutorClient = &fakes.FakeClient{}
+
+		fakeExecutorClient.ListContainersReturns([]executor.Container{
+			lrpContainer("instance-0", "the-app", "0", executor.StateRunning),
+			lrpContainer("instance-1", "the-app", "1", executor.StateCreated),
+			lrpContainer("instance-2", "the-app", "2", executor.StateCompleted),
+			lrpContainer("other-instance", "other-app", "0", executor.StateRunning),
+			{
+				Guid:  "the-app",
+				State: executor.StateRunning,
+				Tags:  executor.Tags{rep.LifecycleTag: rep.TaskLifecycle, rep.ProcessGuidTag: "the-app"},
+			},
+		}, nil)
+
+		drainer = evacuation.NewAppDrainer(cellID, fakeBBSClient, fakeExecutorClient)
+	})
+
+	It("unclaims and deletes only the app's active instances", func() {
+		moved, err := drainer.DrainApp(logger, "the-app")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(moved).To(ConsistOf(
+			models.NewActualLRPKey("the-app", 0, "domain"),
+			models.NewActualLRPKey("the-app", 1, "domain"),
+		))
+
+		Expect(fakeBBSClient.EvacuateClaimedActualLRPCallCount()).To(Equal(2))
+		_, lrpKey, instanceKey := fakeBBSClient.EvacuateClaimedActualLRPArgsForCall(0)
+		Expect(*lrpKey).To(Equal(models.NewActualLRPKey("the-app", 0, "domain")))
+		Expect(*instanceKey).To(Equal(models.NewActualLRPInstanceKey("instance-0", cellID)))
+
+		Expect(fakeExecutorClient.DeleteContainerCallCount()).To(Equal(2))
+		_, guid := fakeExecutorClient.DeleteContainerArgsForCall(0)
+		Expect(guid).To(Equal("instance-0"))
+		_, guid = fakeExecutorClient.DeleteContainerArgsForCall(1)
+		Expect(guid).To(Equal("instance-1"))
+	})
+
+	It("does not touch the containers of other apps", func() {
+		_, err := drainer.DrainApp(logger, "the-app")
+		Expect(err).NotTo(HaveOccurred())
+
+		for i := 0; i < fakeExecutorClient.DeleteContainerCallCount(); i++ {
+			_, guid := fakeExecutorClient.DeleteContainerArgsForCall(i)
+			Expect(guid).NotTo(Equal("other-instance"))
+		}
+	})
+
+	Context("when the app has no instances on the cell", func() {
+		It("returns no instances", func() {
+			moved, err := drainer.DrainApp(logger, "missing-app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(moved).To(BeEmpty())
+			Expect(fakeBBSClient.EvacuateClaimedActualLRPCallCount()).To(Equal(0))
+			Expect(fakeExecutorClient.DeleteContainerCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when unclaiming an instance fails", func() {
+		BeforeEach(func() {
+			fakeBBSClient.EvacuateClaimedActualLRPStub = func(_ lager.Logger, lrpKey *models.ActualLRPKey, _ *models.ActualLRPInstanceKey) (bool, error) {
+				if lrpKey.Index == 0 {
+					return false, errors.New("boom")
+				}
+				return false, nil
+			}
+		})
+
+		It("leaves that instance running and does not report it as moved", func() {
+			moved, err := drainer.DrainApp(logger, "the-app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(moved).To(ConsistOf(models.NewActualLRPKey("the-app", 1, "domain")))
+
+			Expect(fakeExecutorClient.DeleteContainerCallCount()).To(Equal(1))
+			_, guid := fakeExecutorClient.DeleteContainerArgsForCall(0)
+			Expect(guid).To(Equal("instance-1"))
+		})
+	})
+
+	Context("when listing containers fails", func() {
+		BeforeEach(func() {
+			fakeExecutorClient.ListContainersReturns(nil, errors.New("boom"))
+		})
+
+		It("returns the error", func() {
+			_, err := drainer.DrainApp(logger, "the-app")
+			Expect(err).To(MatchError("boom"))
+		})
+	})
+})
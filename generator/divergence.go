@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/go-loggregator/loggregator_v2"
+	"code.cloudfoundry.org/lager"
+)
+
+const repStateDivergence = "RepStateDivergence"
+
+var ErrPersistentStateDivergence = errors.New("container and bbs state have diverged for longer than the threshold")
+
+//go:generate counterfeiter -o fake_generator/fake_divergence_tracker.go . DivergenceTracker
+
+// DivergenceTracker records how long the LRP containers on the cell and the
+// ActualLRPs recorded in the BBS have disagreed about particular instances.
+type DivergenceTracker interface {
+	// Observe is given the container guids that currently disagree with the
+	// BBS. Guids not passed in are considered reconciled.
+	Observe(logger lager.Logger, divergentGuids []string)
+}
+
+type divergenceTracker struct {
+	clock        clock.Clock
+	threshold    time.Duration
+	metronClient loggregator_v2.Client
+
+	lock      sync.Mutex
+	firstSeen map[string]time.Time
+	alerted   map[string]struct{}
+}
+
+// NewDivergenceTracker returns a DivergenceTracker that logs an error and
+// counts an instance in the RepStateDivergence metric once it has diverged for
+// at least threshold. A threshold of zero disables tracking.
+func NewDivergenceTracker(clock clock.Clock, threshold time.Duration, metronClient loggregator_v2.Client) DivergenceTracker {
+	return &divergenceTracker{
+		clock:        clock,
+		threshold:    threshold,
+		metronClient: metronClient,
+		firstSeen:    make(map[string]time.Time),
+		alerted:      make(map[string]struct{}),
+	}
+}
+
+func (t *divergenceTracker) Observe(logger lager.Logger, divergentGuids []string) {
+	if t.threshold == 0 {
+		return
+	}
+
+	logger = logger.Session("divergence-tracker")
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.clock.Now()
+	current := make(map[string]struct{}, len(divergentGuids))
+	for _, guid := range divergentGuids {
+		current[guid] = struct{}{}
+		if _, found := t.firstSeen[guid]; !found {
+			t.firstSeen[guid] = now
+		}
+	}
+
+	for guid := range t.firstSeen {
+		if _, found := current[guid]; found {
+			continue
+		}
+
+		if _, found := t.alerted[guid]; found {
+			logger.Info("state-divergence-resolved", lager.Data{"container-guid": guid})
+			delete(t.alerted, guid)
+		}
+		delete(t.firstSeen, guid)
+	}
+
+	persistent := 0
+	for guid, firstSeen := range t.firstSeen {
+		duration := now.Sub(firstSeen)
+		if duration < t.threshold {
+			continue
+		}
+
+		persistent++
+		if _, found := t.alerted[guid]; !found {
+			logger.Error("persistent-state-divergence", ErrPersistentStateDivergence, lager.Data{
+				"container-guid": guid,
+				"duration":       duration.String(),
+			})
+			t.alerted[guid] = struct{}{}
+		}
+	}
+
+	err := t.metronClient.SendMetric(repStateDivergence, persistent)
+	if err != nil {
+		logger.Error("failed-to-send-state-divergence-metric", err)
+	}
+}
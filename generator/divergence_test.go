@@ -0,0 +1,99 @@
+package generator_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/go-loggregator/loggregator_v2/fakes"
+	"code.cloudfoundry.org/rep/generator"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("DivergenceTracker", func() {
+	const threshold = time.Minute
+
+	var (
+		fakeClock        *fakeclock.FakeClock
+		fakeMetronClient *mfakes.FakeClient
+		tracker          generator.DivergenceTracker
+	)
+
+	lastMetric := func() int {
+		count := fakeMetronClient.SendMetricCallCount()
+		Expect(count).NotTo(BeZero())
+		name, value := fakeMetronClient.SendMetricArgsForCall(count - 1)
+		Expect(name).To(Equal("RepStateDivergence"))
+		return value
+	}
+
+	BeforeEach(func() {
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		fakeMetronClient = new(mfakes.FakeClient)
+		tracker = generator.NewDivergenceTracker(fakeClock, threshold, fakeMetronClient)
+	})
+
+	It("does not alert on a transient divergence", func() {
+		tracker.Observe(logger, []string{"guid-1"})
+		Expect(lastMetric()).To(Equal(0))
+
+		fakeClock.Increment(threshold / 2)
+		tracker.Observe(logger, []string{})
+		Expect(lastMetric()).To(Equal(0))
+
+		fakeClock.Increment(threshold)
+		tracker.Observe(logger, []string{"guid-1"})
+		Expect(lastMetric()).To(Equal(0))
+		Expect(logger).NotTo(gbytes.Say("persistent-state-divergence"))
+	})
+
+	It("alerts once a divergence outlives the threshold", func() {
+		tracker.Observe(logger, []string{"guid-1", "guid-2"})
+		fakeClock.Increment(threshold / 2)
+		tracker.Observe(logger, []string{"guid-1", "guid-2", "guid-3"})
+		fakeClock.Increment(threshold / 2)
+		tracker.Observe(logger, []string{"guid-1", "guid-2", "guid-3"})
+
+		Expect(lastMetric()).To(Equal(2))
+		Expect(logger).To(gbytes.Say("persistent-state-divergence"))
+		Expect(logger).To(gbytes.Say("persistent-state-divergence"))
+	})
+
+	It("logs each persistent divergence only once", func() {
+		tracker.Observe(logger, []string{"guid-1"})
+		fakeClock.Increment(threshold)
+		tracker.Observe(logger, []string{"guid-1"})
+		fakeClock.Increment(threshold)
+		tracker.Observe(logger, []string{"guid-1"})
+
+		Expect(lastMetric()).To(Equal(1))
+		Expect(logger).To(gbytes.Say("persistent-state-divergence"))
+		Expect(logger).NotTo(gbytes.Say("persistent-state-divergence"))
+	})
+
+	It("clears the signal once the divergence is reconciled", func() {
+		tracker.Observe(logger, []string{"guid-1"})
+		fakeClock.Increment(threshold)
+		tracker.Observe(logger, []string{"guid-1"})
+		Expect(lastMetric()).To(Equal(1))
+
+		tracker.Observe(logger, []string{})
+		Expect(lastMetric()).To(Equal(0))
+		Expect(logger).To(gbytes.Say("state-divergence-resolved"))
+	})
+
+	Context("when the threshold is zero", func() {
+		BeforeEach(func() {
+			tracker = generator.NewDivergenceTracker(fakeClock, 0, fakeMetronClient)
+		})
+
+		It("does not track divergence", func() {
+			tracker.Observe(logger, []string{"guid-1"})
+			fakeClock.Increment(time.Hour)
+			tracker.Observe(logger, []string{"guid-1"})
+
+			Expect(fakeMetronClient.SendMetricCallCount()).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,67 @@
+// This file was generated by counterfeiter
+package fake_generator
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep/generator"
+)
+
+type FakeDivergenceTracker struct {
+	ObserveStub        func(logger lager.Logger, divergentGuids []string)
+	observeMutex       sync.RWMutex
+	observeArgsForCall []struct {
+		logger         lager.Logger
+		divergentGuids []string
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDivergenceTracker) Observe(logger lager.Logger, divergentGuids []string) {
+	fake.observeMutex.Lock()
+	fake.observeArgsForCall = append(fake.observeArgsForCall, struct {
+		logger         lager.Logger
+		divergentGuids []string
+	}{logger, divergentGuids})
+	fake.recordInvocation("Observe", []interface{}{logger, divergentGuids})
+	fake.observeMutex.Unlock()
+	if fake.ObserveStub != nil {
+		fake.ObserveStub(logger, divergentGuids)
+	}
+}
+
+func (fake *FakeDivergenceTracker) ObserveCallCount() int {
+	fake.observeMutex.RLock()
+	defer fake.observeMutex.RUnlock()
+	return len(fake.observeArgsForCall)
+}
+
+func (fake *FakeDivergenceTracker) ObserveArgsForCall(i int) (lager.Logger, []string) {
+	fake.observeMutex.RLock()
+	defer fake.observeMutex.RUnlock()
+	return fake.observeArgsForCall[i].logger, fake.observeArgsForCall[i].divergentGuids
+}
+
+func (fake *FakeDivergenceTracker) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.observeMutex.RLock()
+	defer fake.observeMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeDivergenceTracker) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ generator.DivergenceTracker = new(FakeDivergenceTracker)
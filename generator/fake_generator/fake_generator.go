@@ -6,6 +6,7 @@ import (
 
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/operationq"
+	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/generator"
 )
 
@@ -28,6 +29,12 @@ type FakeGenerator struct {
 		result1 <-chan operationq.Operation
 		result2 error
 	}
+	LimitUsageStub        func() rep.LimitUsage
+	limitUsageMutex       sync.RWMutex
+	limitUsageArgsForCall []struct{}
+	limitUsageReturns     struct {
+		result1 rep.LimitUsage
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -100,6 +107,31 @@ func (fake *FakeGenerator) OperationStreamReturns(result1 <-chan operationq.Oper
 	}{result1, result2}
 }
 
+func (fake *FakeGenerator) LimitUsage() rep.LimitUsage {
+	fake.limitUsageMutex.Lock()
+	fake.limitUsageArgsForCall = append(fake.limitUsageArgsForCall, struct{}{})
+	fake.recordInvocation("LimitUsage", []interface{}{})
+	fake.limitUsageMutex.Unlock()
+	if fake.LimitUsageStub != nil {
+		return fake.LimitUsageStub()
+	} else {
+		return fake.limitUsageReturns.result1
+	}
+}
+
+func (fake *FakeGenerator) LimitUsageCallCount() int {
+	fake.limitUsageMutex.RLock()
+	defer fake.limitUsageMutex.RUnlock()
+	return len(fake.limitUsageArgsForCall)
+}
+
+func (fake *FakeGenerator) LimitUsageReturns(result1 rep.LimitUsage) {
+	fake.LimitUsageStub = nil
+	fake.limitUsageReturns = struct {
+		result1 rep.LimitUsage
+	}{result1}
+}
+
 func (fake *FakeGenerator) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -107,6 +139,8 @@ func (fake *FakeGenerator) Invocations() map[string][][]interface{} {
 	defer fake.batchOperationsMutex.RUnlock()
 	fake.operationStreamMutex.RLock()
 	defer fake.operationStreamMutex.RUnlock()
+	fake.limitUsageMutex.RLock()
+	defer fake.limitUsageMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -3,10 +3,13 @@ package generator
 
 import (
 	"fmt"
+	"time"
 
 	"code.cloudfoundry.org/bbs"
 	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/go-loggregator/loggregator_v2"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/operationq"
 	"code.cloudfoundry.org/rep"
@@ -21,37 +24,108 @@ type Generator interface {
 	// BatchOperations creates a set of operations across all containers the Rep is managing.
 	BatchOperations(lager.Logger) (map[string]operationq.Operation, error)
 
-	// OperationStream creates an operation every time a container lifecycle event is observed.
+	// OperationStream creates an operation every time a container lifecycle event is observed,
+	// and every time a container held back from starting is requeued.
 	OperationStream(lager.Logger) (<-chan operationq.Operation, error)
+
+	// LimitUsage reports how much of the provisioning rate limit is in use.
+	LimitUsage() rep.LimitUsage
 }
 
 type generator struct {
 	cellID            string
 	bbs               bbs.InternalClient
+	associationStore  internal.AssociationStore
 	executorClient    executor.Client
 	lrpProcessor      internal.LRPProcessor
 	taskProcessor     internal.TaskProcessor
 	containerDelegate internal.ContainerDelegate
+	divergenceTracker DivergenceTracker
+	metronClient      loggregator_v2.Client
+	missingContainers *missingContainers
+	requeue           chan string
+}
+
+// requeueBufferSize is how many requeued containers the generator buffers
+// before further requeues are left to the next poll.
+const requeueBufferSize = 1024
+
+// Config holds the settings of a Generator that come from the rep's
+// configuration.
+type Config struct {
+	CellID                   string
+	EvacuationTTLInSeconds   uint64
+	MetadataLimit            rep.MetadataLimit
+	DependencyCheck          rep.DependencyCheck
+	PrivilegeCheck           rep.PrivilegeCheck
+	StartTimeout             rep.StartTimeoutLimit
+	EnvOverlay               rep.EnvOverlay
+	OrphanHandler            rep.OrphanHandler
+	OrphanQuarantineTTL      time.Duration
+	LifecycleErrors          rep.LifecycleErrorHandler
+	LifecycleEvents          *rep.LifecycleEvents
+	LogIndexPolicy           rep.LogIndexCollisionPolicy
+	MaxConcurrentStops       int
+	MaxProvisionsPerMinute   int
+	MaxInPlaceRestarts       int
+	MissingContainerListings int
+	StartupReadiness         executor.State
+	SerializeCacheWarming    bool
+	MaxRunRetries            int
+	DryRun                   bool
 }
 
 func New(
-	cellID string,
+	config Config,
 	bbs bbs.InternalClient,
 	executorClient executor.Client,
 	evacuationReporter evacuation_context.EvacuationReporter,
-	evacuationTTLInSeconds uint64,
+	clock clock.Clock,
+	divergenceTracker DivergenceTracker,
+	crashRecorder rep.CrashRecorder,
+	provisioningRecorder rep.ProvisioningRecorder,
+	metronClient loggregator_v2.Client,
+	bbsHealth rep.BBSHealth,
 ) Generator {
-	containerDelegate := internal.NewContainerDelegate(executorClient)
-	lrpProcessor := internal.NewLRPProcessor(bbs, containerDelegate, cellID, evacuationReporter, evacuationTTLInSeconds)
+	cellID := config.CellID
+	bbs = internal.NewHealthRecordingClient(bbs, bbsHealth)
+	if config.DryRun {
+		bbs = internal.NewDryRunClient(bbs)
+	}
+	containerDelegate := internal.NewContainerDelegate(executorClient, clock, config.MaxConcurrentStops, config.MaxProvisionsPerMinute, config.MaxRunRetries)
+	associationStore := internal.NewBBSAssociationStore(bbs)
+	requeue := make(chan string, requeueBufferSize)
+	lrpProcessor := internal.NewLRPProcessor(bbs, associationStore, containerDelegate, evacuationReporter, crashRecorder, provisioningRecorder, clock, metronClient, requeue, internal.LRPProcessorConfig{
+		CellID:                 cellID,
+		EvacuationTTLInSeconds: config.EvacuationTTLInSeconds,
+		MetadataLimit:          config.MetadataLimit,
+		DependencyCheck:        config.DependencyCheck,
+		PrivilegeCheck:         config.PrivilegeCheck,
+		StartTimeout:           config.StartTimeout,
+		EnvOverlay:             config.EnvOverlay,
+		OrphanHandler:          config.OrphanHandler,
+		OrphanQuarantineTTL:    config.OrphanQuarantineTTL,
+		LifecycleErrors:        config.LifecycleErrors,
+		LifecycleEvents:        config.LifecycleEvents,
+		LogIndexPolicy:         config.LogIndexPolicy,
+		MaxInPlaceRestarts:     config.MaxInPlaceRestarts,
+		StartupReadiness:       config.StartupReadiness,
+		SerializeCacheWarming:  config.SerializeCacheWarming,
+	})
 	taskProcessor := internal.NewTaskProcessor(bbs, containerDelegate, cellID)
 
 	return &generator{
 		cellID:            cellID,
 		bbs:               bbs,
+		associationStore:  associationStore,
 		executorClient:    executorClient,
 		lrpProcessor:      lrpProcessor,
 		taskProcessor:     taskProcessor,
 		containerDelegate: containerDelegate,
+		divergenceTracker: divergenceTracker,
+		metronClient:      metronClient,
+		missingContainers: newMissingContainers(config.MissingContainerListings),
+		requeue:           requeue,
 	}
 }
 
@@ -60,6 +134,8 @@ func (g *generator) BatchOperations(logger lager.Logger) (map[string]operationq.
 	logger.Info("started")
 
 	containers := make(map[string]executor.Container)
+	var listedContainers []executor.Container
+	var mappingStoreEntries int
 	instanceLRPs := make(map[string]models.ActualLRP)
 	evacuatingLRPs := make(map[string]models.ActualLRP)
 	tasks := make(map[string]*models.Task)
@@ -74,6 +150,7 @@ func (g *generator) BatchOperations(logger lager.Logger) (map[string]operationq.
 			err = fmt.Errorf("failed to list containers: %s", err.Error())
 		}
 
+		listedContainers = foundContainers
 		for _, c := range foundContainers {
 			containers[c.Guid] = c
 		}
@@ -82,13 +159,13 @@ func (g *generator) BatchOperations(logger lager.Logger) (map[string]operationq.
 	}()
 
 	go func() {
-		filter := models.ActualLRPFilter{CellID: g.cellID}
-		groups, err := g.bbs.ActualLRPGroups(logger, filter)
+		groups, err := g.associationStore.ActualLRPGroupsForCell(logger, g.cellID)
 		if err != nil {
 			logger.Error("failed-to-retrieve-lrp-groups", err)
 			err = fmt.Errorf("failed to retrieve lrps: %s", err.Error())
 		}
 
+		mappingStoreEntries = len(groups)
 		for _, group := range groups {
 			if group.Instance != nil {
 				instanceLRPs[rep.LRPContainerGuid(group.Instance.GetProcessGuid(), group.Instance.GetInstanceGuid())] = *group.Instance
@@ -127,6 +204,11 @@ func (g *generator) BatchOperations(logger lager.Logger) (map[string]operationq.
 	}
 	logger.Info("succeeded-getting-containers-lrps-and-tasks")
 
+	g.lrpProcessor.ObserveContainers(logger, listedContainers)
+	g.reportMappingStoreEntries(logger, mappingStoreEntries, listedContainers)
+
+	g.divergenceTracker.Observe(logger, divergentLRPGuids(containers, instanceLRPs, evacuatingLRPs))
+
 	batch := make(map[string]operationq.Operation)
 
 	// create operations for processes with containers
@@ -134,11 +216,16 @@ func (g *generator) BatchOperations(logger lager.Logger) (map[string]operationq.
 		batch[guid] = g.operationFromContainer(logger, guid)
 	}
 
+	confirmed := g.confirmMissingContainers(logger, containers, instanceLRPs, evacuatingLRPs, tasks)
+
 	// create operations for instance lrps with no containers
 	for guid, lrp := range instanceLRPs {
 		if _, foundContainer := batch[guid]; foundContainer {
 			continue
 		}
+		if _, ok := confirmed[guid]; !ok {
+			continue
+		}
 		if _, foundEvacuatingLRP := evacuatingLRPs[guid]; foundEvacuatingLRP {
 			batch[guid] = NewResidualJointLRPOperation(logger, g.bbs, g.containerDelegate, lrp.ActualLRPKey, lrp.ActualLRPInstanceKey)
 		} else {
@@ -149,7 +236,8 @@ func (g *generator) BatchOperations(logger lager.Logger) (map[string]operationq.
 	// create operations for evacuating lrps with no containers
 	for guid, lrp := range evacuatingLRPs {
 		_, found := batch[guid]
-		if !found {
+		_, ok := confirmed[guid]
+		if !found && ok {
 			batch[guid] = NewResidualEvacuatingLRPOperation(logger, g.bbs, g.containerDelegate, lrp.ActualLRPKey, lrp.ActualLRPInstanceKey)
 		}
 	}
@@ -157,7 +245,8 @@ func (g *generator) BatchOperations(logger lager.Logger) (map[string]operationq.
 	// create operations for tasks with no containers
 	for guid, _ := range tasks {
 		_, found := batch[guid]
-		if !found {
+		_, ok := confirmed[guid]
+		if !found && ok {
 			batch[guid] = NewResidualTaskOperation(logger, guid, g.bbs, g.containerDelegate)
 		}
 	}
@@ -166,6 +255,39 @@ func (g *generator) BatchOperations(logger lager.Logger) (map[string]operationq.
 	return batch, nil
 }
 
+// confirmMissingContainers returns the guids of the ActualLRPs and Tasks whose
+// containers have been missing for long enough to clean up their records.
+func (g *generator) confirmMissingContainers(
+	logger lager.Logger,
+	containers map[string]executor.Container,
+	instanceLRPs, evacuatingLRPs map[string]models.ActualLRP,
+	tasks map[string]*models.Task,
+) map[string]struct{} {
+	missing := make(map[string]struct{})
+	for guid := range instanceLRPs {
+		if _, found := containers[guid]; !found {
+			missing[guid] = struct{}{}
+		}
+	}
+	for guid := range evacuatingLRPs {
+		if _, found := containers[guid]; !found {
+			missing[guid] = struct{}{}
+		}
+	}
+	for guid := range tasks {
+		if _, found := containers[guid]; !found {
+			missing[guid] = struct{}{}
+		}
+	}
+
+	confirmed := g.missingContainers.confirm(missing)
+	if deferred := len(missing) - len(confirmed); deferred > 0 {
+		logger.Info("deferring-missing-container-cleanup", lager.Data{"num-deferred": deferred})
+	}
+
+	return confirmed
+}
+
 func (g *generator) OperationStream(logger lager.Logger) (<-chan operationq.Operation, error) {
 	streamLogger := logger.Session("operation-stream")
 
@@ -179,14 +301,37 @@ func (g *generator) OperationStream(logger lager.Logger) (<-chan operationq.Oper
 	streamLogger.Info("succeeded-subscribing")
 
 	opChan := make(chan operationq.Operation)
+	closed := make(chan struct{})
+	requeuing := make(chan struct{})
+
+	go func() {
+		defer close(requeuing)
+		for {
+			select {
+			case guid := <-g.requeue:
+				streamLogger.Debug("requeuing-held-container", lager.Data{"container-guid": guid})
+				select {
+				case opChan <- g.operationFromContainer(logger, guid):
+				case <-closed:
+					return
+				}
+			case <-closed:
+				return
+			}
+		}
+	}()
 
 	go func() {
 		defer events.Close()
 
+		versions := make(map[string]int)
+
 		for {
 			e, err := events.Next()
 			if err != nil {
 				streamLogger.Debug("event-stream-closed")
+				close(closed)
+				<-requeuing
 				close(opChan)
 				return
 			}
@@ -198,6 +343,20 @@ func (g *generator) OperationStream(logger lager.Logger) (<-chan operationq.Oper
 			}
 
 			container := lifecycle.Container()
+			if e.EventType() == executor.EventTypeContainerRemoved {
+				delete(versions, container.Guid)
+			} else if version := containerStateVersion(container.State); version > 0 {
+				if version < versions[container.Guid] {
+					streamLogger.Info("skipped-stale-lifecycle-event", lager.Data{
+						"container-guid":  container.Guid,
+						"container-state": container.State,
+					})
+					g.recordDeduplicatedEvent(streamLogger)
+					continue
+				}
+				versions[container.Guid] = version
+			}
+
 			opChan <- g.operationFromContainer(logger, container.Guid)
 		}
 	}()
@@ -205,6 +364,65 @@ func (g *generator) OperationStream(logger lager.Logger) (<-chan operationq.Oper
 	return opChan, nil
 }
 
+const repEventsDeduplicated = "RepEventsDeduplicated"
+
+func (g *generator) recordDeduplicatedEvent(logger lager.Logger) {
+	err := g.metronClient.IncrementCounter(repEventsDeduplicated)
+	if err != nil {
+		logger.Error("failed-to-increment-events-deduplicated-counter", err)
+	}
+}
+
+// divergentLRPGuids returns the guids of claimed LRP containers with no
+// matching ActualLRP and of instance ActualLRPs with no matching container.
+func divergentLRPGuids(containers map[string]executor.Container, instanceLRPs, evacuatingLRPs map[string]models.ActualLRP) []string {
+	guids := []string{}
+
+	for guid, container := range containers {
+		if container.Tags[rep.LifecycleTag] != rep.LRPLifecycle || container.State == executor.StateReserved {
+			continue
+		}
+		_, foundInstance := instanceLRPs[guid]
+		_, foundEvacuating := evacuatingLRPs[guid]
+		if !foundInstance && !foundEvacuating {
+			guids = append(guids, guid)
+		}
+	}
+
+	for guid := range instanceLRPs {
+		if _, found := containers[guid]; !found {
+			guids = append(guids, guid)
+		}
+	}
+
+	return guids
+}
+
+// containerStateVersion orders container states by how far along the container
+// lifecycle they are, so that a lifecycle event delivered after a newer one for
+// the same container can be recognized as stale. States without a place in the
+// lifecycle return 0 and are never considered stale.
+func containerStateVersion(state executor.State) int {
+	switch state {
+	case executor.StateReserved:
+		return 1
+	case executor.StateInitializing:
+		return 2
+	case executor.StateCreated:
+		return 3
+	case executor.StateRunning:
+		return 4
+	case executor.StateCompleted:
+		return 5
+	default:
+		return 0
+	}
+}
+
+func (g *generator) LimitUsage() rep.LimitUsage {
+	return g.containerDelegate.LimitUsage()
+}
+
 func (g *generator) operationFromContainer(logger lager.Logger, guid string) operationq.Operation {
-	return NewContainerOperation(logger, g.lrpProcessor, g.taskProcessor, g.containerDelegate, guid)
+	return NewContainerOperation(logger, g.lrpProcessor, g.taskProcessor, g.containerDelegate, g.metronClient, guid)
 }
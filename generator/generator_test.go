@@ -4,12 +4,16 @@ import (
 	"errors"
 
 	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/executor"
 	efakes "code.cloudfoundry.org/executor/fakes"
+	mfakes "code.cloudfoundry.org/go-loggregator/loggregator_v2/fakes"
 	"code.cloudfoundry.org/operationq"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
 	"code.cloudfoundry.org/rep/generator"
+	"code.cloudfoundry.org/rep/generator/fake_generator"
+	"code.cloudfoundry.org/rep/repfakes"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -24,8 +28,10 @@ func (BogusEvent) EventType() executor.EventType {
 
 var _ = Describe("Generator", func() {
 	var (
-		cellID             string
-		fakeExecutorClient *efakes.FakeClient
+		cellID                string
+		fakeExecutorClient    *efakes.FakeClient
+		fakeDivergenceTracker *fake_generator.FakeDivergenceTracker
+		fakeMetronClient      *mfakes.FakeClient
 
 		opGenerator generator.Generator
 	)
@@ -33,8 +39,10 @@ var _ = Describe("Generator", func() {
 	BeforeEach(func() {
 		cellID = "some-cell-id"
 		fakeExecutorClient = new(efakes.FakeClient)
+		fakeDivergenceTracker = new(fake_generator.FakeDivergenceTracker)
+		fakeMetronClient = new(mfakes.FakeClient)
 		fakeEvacuationReporter := &fake_evacuation_context.FakeEvacuationReporter{}
-		opGenerator = generator.New(cellID, fakeBBS, fakeExecutorClient, fakeEvacuationReporter, 0)
+		opGenerator = generator.New(generator.Config{CellID: cellID}, fakeBBS, fakeExecutorClient, fakeEvacuationReporter, clock.NewClock(), fakeDivergenceTracker, new(repfakes.FakeCrashRecorder), new(repfakes.FakeProvisioningRecorder), fakeMetronClient, new(repfakes.FakeBBSHealth))
 	})
 
 	Describe("BatchOperations", func() {
@@ -128,6 +136,34 @@ var _ = Describe("Generator", func() {
 				Expect(logger).To(Say(sessionName + ".succeeded"))
 			})
 
+			It("reports the instance LRPs with no containers as divergent", func() {
+				Expect(fakeDivergenceTracker.ObserveCallCount()).To(Equal(1))
+				_, guids := fakeDivergenceTracker.ObserveArgsForCall(0)
+				Expect(guids).To(ConsistOf(instanceGuidInstanceLRPOnly, instanceGuidInstanceAndEvacuatingLRPsOnly))
+			})
+
+			Context("when there are claimed LRP containers with no ActualLRP", func() {
+				BeforeEach(func() {
+					lrpTags := executor.Tags{rep.LifecycleTag: rep.LRPLifecycle}
+					fakeExecutorClient.ListContainersReturns([]executor.Container{
+						{Guid: instanceGuidContainerOnly, State: executor.StateRunning, Tags: lrpTags},
+						{Guid: "guid-reserved-container", State: executor.StateReserved, Tags: lrpTags},
+						{Guid: instanceGuidContainerForInstanceLRP, State: executor.StateRunning, Tags: lrpTags},
+						{Guid: instanceGuidContainerForEvacuatingLRP, State: executor.StateRunning, Tags: lrpTags},
+						{Guid: guidContainerForTask, State: executor.StateRunning, Tags: executor.Tags{rep.LifecycleTag: rep.TaskLifecycle}},
+					}, nil)
+				})
+
+				It("reports them as divergent, ignoring reserved containers", func() {
+					_, guids := fakeDivergenceTracker.ObserveArgsForCall(0)
+					Expect(guids).To(ConsistOf(
+						instanceGuidContainerOnly,
+						instanceGuidInstanceLRPOnly,
+						instanceGuidInstanceAndEvacuatingLRPsOnly,
+					))
+				})
+			})
+
 			It("returns a batch of the correct size", func() {
 				Expect(batch).To(HaveLen(8))
 			})
@@ -177,6 +213,37 @@ var _ = Describe("Generator", func() {
 				Expect(batch[guid]).To(BeAssignableToTypeOf(new(generator.ResidualTaskOperation)))
 			})
 
+			Context("when a container must be missing from several listings", func() {
+				BeforeEach(func() {
+					fakeEvacuationReporter := &fake_evacuation_context.FakeEvacuationReporter{}
+					opGenerator = generator.New(generator.Config{CellID: cellID, MissingContainerListings: 2}, fakeBBS, fakeExecutorClient, fakeEvacuationReporter, clock.NewClock(), fakeDivergenceTracker, new(repfakes.FakeCrashRecorder), new(repfakes.FakeProvisioningRecorder), fakeMetronClient, new(repfakes.FakeBBSHealth))
+				})
+
+				It("does not return residual operations after the first listing", func() {
+					Expect(batch).To(HaveLen(4))
+					Expect(logger).To(Say(sessionName + ".deferring-missing-container-cleanup"))
+				})
+
+				It("returns residual operations once the containers are missing again", func() {
+					batch, batchErr = opGenerator.BatchOperations(logger)
+					Expect(batchErr).NotTo(HaveOccurred())
+					Expect(batch).To(HaveLen(8))
+					Expect(batch[guidTaskOnly]).To(BeAssignableToTypeOf(new(generator.ResidualTaskOperation)))
+				})
+
+				It("starts counting again when a container reappears", func() {
+					fakeBBS.TasksByCellIDReturns([]*models.Task{{TaskGuid: guidContainerForTask}}, nil)
+					fakeExecutorClient.ListContainersReturns([]executor.Container{{Guid: guidTaskOnly}}, nil)
+					_, err := opGenerator.BatchOperations(logger)
+					Expect(err).NotTo(HaveOccurred())
+
+					fakeBBS.TasksByCellIDReturns([]*models.Task{{TaskGuid: guidTaskOnly}}, nil)
+					fakeExecutorClient.ListContainersReturns(nil, nil)
+					batch, batchErr = opGenerator.BatchOperations(logger)
+					Expect(batchErr).NotTo(HaveOccurred())
+					Expect(batch).NotTo(HaveKey(guidTaskOnly))
+				})
+			})
 		})
 
 		Context("when retrieving data fails", func() {
@@ -223,6 +290,10 @@ var _ = Describe("Generator", func() {
 				It("logs the failure", func() {
 					Expect(logger).To(Say(sessionName + ".failed-to-retrieve-lrp-groups"))
 				})
+
+				It("does not report any divergence", func() {
+					Expect(fakeDivergenceTracker.ObserveCallCount()).To(Equal(0))
+				})
 			})
 		})
 	})
@@ -351,6 +422,78 @@ var _ = Describe("Generator", func() {
 						Eventually(logger).Should(Say(sessionPrefix + "received-non-lifecycle-event"))
 					})
 				})
+
+				Context("when lifecycle events for a container arrive out of order", func() {
+					var running, completed executor.Container
+
+					BeforeEach(func() {
+						running = executor.Container{
+							Guid:  "some-instance-guid",
+							State: executor.StateRunning,
+							Tags: executor.Tags{
+								rep.LifecycleTag:    rep.LRPLifecycle,
+								rep.ProcessGuidTag:  "some-process-guid",
+								rep.DomainTag:       "some-domain",
+								rep.ProcessIndexTag: "1",
+							},
+						}
+						completed = running
+						completed.State = executor.StateCompleted
+					})
+
+					It("ignores events older than the newest one seen for that container", func() {
+						receivedEvents <- executor.NewContainerRunningEvent(running)
+						Eventually(stream).Should(Receive())
+
+						receivedEvents <- executor.NewContainerCompleteEvent(completed)
+						Eventually(stream).Should(Receive())
+
+						receivedEvents <- executor.NewContainerRunningEvent(running)
+						Eventually(logger).Should(Say(sessionPrefix + "skipped-stale-lifecycle-event"))
+						Consistently(stream).ShouldNot(Receive())
+						Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+						Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("RepEventsDeduplicated"))
+
+						receivedEvents <- executor.NewContainerCompleteEvent(completed)
+						Eventually(stream).Should(Receive())
+					})
+
+					It("does not consider events for other containers stale", func() {
+						receivedEvents <- executor.NewContainerCompleteEvent(completed)
+						Eventually(stream).Should(Receive())
+
+						other := running
+						other.Guid = "other-instance-guid"
+						receivedEvents <- executor.NewContainerRunningEvent(other)
+
+						var operation operationq.Operation
+						Eventually(stream).Should(Receive(&operation))
+						Expect(operation.Key()).To(Equal("other-instance-guid"))
+					})
+
+					It("forgets the container once it is removed", func() {
+						receivedEvents <- executor.NewContainerCompleteEvent(completed)
+						Eventually(stream).Should(Receive())
+
+						receivedEvents <- executor.NewContainerRemovedEvent(completed)
+						Eventually(stream).Should(Receive())
+
+						reserved := running
+						reserved.State = executor.StateReserved
+						receivedEvents <- executor.NewContainerReservedEvent(reserved)
+						Eventually(stream).Should(Receive())
+					})
+
+					It("handles events without a known state conservatively", func() {
+						receivedEvents <- executor.NewContainerCompleteEvent(completed)
+						Eventually(stream).Should(Receive())
+
+						invalid := running
+						invalid.State = executor.StateInvalid
+						receivedEvents <- executor.NewContainerRunningEvent(invalid)
+						Eventually(stream).Should(Receive())
+					})
+				})
 			})
 		})
 
@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"code.cloudfoundry.org/bbs"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager"
+)
+
+//go:generate counterfeiter -o fake_internal/fake_association_store.go association_store.go AssociationStore
+
+// AssociationStore records and looks up the association between LRP
+// instance containers and the cell running them.
+type AssociationStore interface {
+	Claim(logger lager.Logger, lrpKey *models.ActualLRPKey, instanceKey *models.ActualLRPInstanceKey) error
+	Remove(logger lager.Logger, lrpKey *models.ActualLRPKey, instanceKey *models.ActualLRPInstanceKey) error
+	ActualLRPGroupsForCell(logger lager.Logger, cellID string) ([]*models.ActualLRPGroup, error)
+}
+
+type bbsAssociationStore struct {
+	bbsClient bbs.InternalClient
+}
+
+// NewBBSAssociationStore returns an AssociationStore backed by the ActualLRP
+// records in the BBS.
+func NewBBSAssociationStore(bbsClient bbs.InternalClient) AssociationStore {
+	return &bbsAssociationStore{
+		bbsClient: bbsClient,
+	}
+}
+
+func (s *bbsAssociationStore) Claim(logger lager.Logger, lrpKey *models.ActualLRPKey, instanceKey *models.ActualLRPInstanceKey) error {
+	return s.bbsClient.ClaimActualLRP(logger, lrpKey.ProcessGuid, int(lrpKey.Index), instanceKey)
+}
+
+func (s *bbsAssociationStore) Remove(logger lager.Logger, lrpKey *models.ActualLRPKey, instanceKey *models.ActualLRPInstanceKey) error {
+	return s.bbsClient.RemoveActualLRP(logger, lrpKey.ProcessGuid, int(lrpKey.Index), instanceKey)
+}
+
+func (s *bbsAssociationStore) ActualLRPGroupsForCell(logger lager.Logger, cellID string) ([]*models.ActualLRPGroup, error) {
+	return s.bbsClient.ActualLRPGroups(logger, models.ActualLRPFilter{CellID: cellID})
+}
@@ -0,0 +1,82 @@
+package internal_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/bbs/fake_bbs"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep/generator/internal"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BBSAssociationStore", func() {
+	var (
+		logger      *lagertest.TestLogger
+		fakeBBS     *fake_bbs.FakeInternalClient
+		store       internal.AssociationStore
+		lrpKey      models.ActualLRPKey
+		instanceKey models.ActualLRPInstanceKey
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeBBS = new(fake_bbs.FakeInternalClient)
+		store = internal.NewBBSAssociationStore(fakeBBS)
+		lrpKey = models.NewActualLRPKey("process-guid", 2, "domain")
+		instanceKey = models.NewActualLRPInstanceKey("instance-guid", "cell-id")
+	})
+
+	Describe("Claim", func() {
+		It("claims the actual lrp in the bbs", func() {
+			Expect(store.Claim(logger, &lrpKey, &instanceKey)).To(Succeed())
+
+			Expect(fakeBBS.ClaimActualLRPCallCount()).To(Equal(1))
+			_, processGuid, index, actualInstanceKey := fakeBBS.ClaimActualLRPArgsForCall(0)
+			Expect(processGuid).To(Equal("process-guid"))
+			Expect(index).To(Equal(2))
+			Expect(*actualInstanceKey).To(Equal(instanceKey))
+		})
+
+		Context("when the bbs fails", func() {
+			BeforeEach(func() {
+				fakeBBS.ClaimActualLRPReturns(errors.New("boom"))
+			})
+
+			It("returns the error", func() {
+				Expect(store.Claim(logger, &lrpKey, &instanceKey)).To(MatchError("boom"))
+			})
+		})
+	})
+
+	Describe("Remove", func() {
+		It("removes the actual lrp from the bbs", func() {
+			Expect(store.Remove(logger, &lrpKey, &instanceKey)).To(Succeed())
+
+			Expect(fakeBBS.RemoveActualLRPCallCount()).To(Equal(1))
+			_, processGuid, index, actualInstanceKey := fakeBBS.RemoveActualLRPArgsForCall(0)
+			Expect(processGuid).To(Equal("process-guid"))
+			Expect(index).To(Equal(2))
+			Expect(*actualInstanceKey).To(Equal(instanceKey))
+		})
+	})
+
+	Describe("ActualLRPGroupsForCell", func() {
+		var groups []*models.ActualLRPGroup
+
+		BeforeEach(func() {
+			groups = []*models.ActualLRPGroup{{Instance: &models.ActualLRP{}}}
+			fakeBBS.ActualLRPGroupsReturns(groups, nil)
+		})
+
+		It("fetches the actual lrp groups for the cell", func() {
+			Expect(store.ActualLRPGroupsForCell(logger, "cell-id")).To(Equal(groups))
+
+			Expect(fakeBBS.ActualLRPGroupsCallCount()).To(Equal(1))
+			_, filter := fakeBBS.ActualLRPGroupsArgsForCall(0)
+			Expect(filter).To(Equal(models.ActualLRPFilter{CellID: "cell-id"}))
+		})
+	})
+})
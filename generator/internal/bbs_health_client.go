@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"code.cloudfoundry.org/bbs"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+type healthRecordingClient struct {
+	bbs.InternalClient
+	health rep.BBSHealth
+}
+
+// NewHealthRecordingClient wraps a BBS client so that the outcome of the reads
+// the rep uses to find its work, and of the writes that record LRP starts, is
+// reported to health.
+func NewHealthRecordingClient(client bbs.InternalClient, health rep.BBSHealth) bbs.InternalClient {
+	return &healthRecordingClient{
+		InternalClient: client,
+		health:         health,
+	}
+}
+
+func (c *healthRecordingClient) ActualLRPGroups(logger lager.Logger, filter models.ActualLRPFilter) ([]*models.ActualLRPGroup, error) {
+	groups, err := c.InternalClient.ActualLRPGroups(logger, filter)
+	c.health.RecordRead(err)
+	return groups, err
+}
+
+func (c *healthRecordingClient) TasksByCellID(logger lager.Logger, cellID string) ([]*models.Task, error) {
+	tasks, err := c.InternalClient.TasksByCellID(logger, cellID)
+	c.health.RecordRead(err)
+	return tasks, err
+}
+
+func (c *healthRecordingClient) ClaimActualLRP(logger lager.Logger, processGuid string, index int, instanceKey *models.ActualLRPInstanceKey) error {
+	err := c.InternalClient.ClaimActualLRP(logger, processGuid, index, instanceKey)
+	c.health.RecordWrite(err)
+	return err
+}
+
+func (c *healthRecordingClient) StartActualLRP(logger lager.Logger, key *models.ActualLRPKey, instanceKey *models.ActualLRPInstanceKey, netInfo *models.ActualLRPNetInfo) error {
+	err := c.InternalClient.StartActualLRP(logger, key, instanceKey, netInfo)
+	c.health.RecordWrite(err)
+	return err
+}
@@ -0,0 +1,77 @@
+package internal_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/bbs"
+	"code.cloudfoundry.org/bbs/fake_bbs"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep/generator/internal"
+	"code.cloudfoundry.org/rep/repfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HealthRecordingClient", func() {
+	var (
+		logger      *lagertest.TestLogger
+		fakeBBS     *fake_bbs.FakeInternalClient
+		health      *repfakes.FakeBBSHealth
+		client      bbs.InternalClient
+		lrpKey      models.ActualLRPKey
+		instanceKey models.ActualLRPInstanceKey
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeBBS = new(fake_bbs.FakeInternalClient)
+		health = new(repfakes.FakeBBSHealth)
+		client = internal.NewHealthRecordingClient(fakeBBS, health)
+		lrpKey = models.NewActualLRPKey("process-guid", 2, "domain")
+		instanceKey = models.NewActualLRPInstanceKey("instance-guid", "cell-id")
+	})
+
+	It("records the outcome of claiming an actual lrp as a write", func() {
+		fakeBBS.ClaimActualLRPReturns(errors.New("boom"))
+
+		err := client.ClaimActualLRP(logger, "process-guid", 2, &instanceKey)
+		Expect(err).To(MatchError("boom"))
+
+		Expect(fakeBBS.ClaimActualLRPCallCount()).To(Equal(1))
+		Expect(health.RecordWriteCallCount()).To(Equal(1))
+		Expect(health.RecordWriteArgsForCall(0)).To(MatchError("boom"))
+		Expect(health.RecordReadCallCount()).To(Equal(0))
+	})
+
+	It("records the outcome of starting an actual lrp as a write", func() {
+		netInfo := models.NewActualLRPNetInfo("1.2.3.4", models.NewPortMapping(61999, 8080))
+
+		Expect(client.StartActualLRP(logger, &lrpKey, &instanceKey, &netInfo)).To(Succeed())
+
+		Expect(fakeBBS.StartActualLRPCallCount()).To(Equal(1))
+		Expect(health.RecordWriteCallCount()).To(Equal(1))
+		Expect(health.RecordWriteArgsForCall(0)).To(BeNil())
+	})
+
+	It("records the outcome of fetching actual lrps and tasks as reads", func() {
+		fakeBBS.ActualLRPGroupsReturns(nil, errors.New("boom"))
+
+		_, err := client.ActualLRPGroups(logger, models.ActualLRPFilter{CellID: "cell-id"})
+		Expect(err).To(MatchError("boom"))
+		_, err = client.TasksByCellID(logger, "cell-id")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(health.RecordReadCallCount()).To(Equal(2))
+		Expect(health.RecordReadArgsForCall(0)).To(MatchError("boom"))
+		Expect(health.RecordReadArgsForCall(1)).To(BeNil())
+		Expect(health.RecordWriteCallCount()).To(Equal(0))
+	})
+
+	It("passes other calls through to the wrapped client", func() {
+		Expect(client.RemoveActualLRP(logger, "process-guid", 2, &instanceKey)).To(Succeed())
+		Expect(fakeBBS.RemoveActualLRPCallCount()).To(Equal(1))
+		Expect(health.RecordWriteCallCount()).To(Equal(0))
+	})
+})
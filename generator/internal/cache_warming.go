@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// claimCacheWarming reports whether the container may be run now that its
+// desired LRP is known. When cache warming is serialized, the first container
+// of a process to run with a given cache key warms it, and the other
+// containers of the process sharing the key stay reserved until the first has
+// started running or completed, so that they find the download in the cache
+// rather than fetching it again. The gate is scoped to the process, as
+// different apps that share a key, such as a lifecycle, warm it independently.
+// A warming container that no longer exists releases its keys, and a container
+// held back for maxStartupHold is let through without warming.
+func (p *ordinaryLRPProcessor) claimCacheWarming(logger lager.Logger, lrpContainer *lrpContainer, desired *models.DesiredLRP) bool {
+	if !p.serializeWarming {
+		return true
+	}
+
+	keys := rep.CacheKeys(desired)
+	if len(keys) == 0 {
+		return true
+	}
+
+	p.cacheWarmingLock.Lock()
+	defer p.cacheWarmingLock.Unlock()
+
+	warmers := p.cacheWarming[lrpContainer.ProcessGuid]
+	for _, key := range keys {
+		guid, warming := warmers[key]
+		if !warming || guid == lrpContainer.Guid {
+			continue
+		}
+
+		container, ok := p.containerDelegate.GetContainer(logger, guid)
+		if !ok || !stillWarming(container.State) {
+			p.releaseCacheKeysLocked(guid)
+			continue
+		}
+
+		if !p.held.hold(lrpContainer.ProcessGuid, lrpContainer.Guid) {
+			logger.Info("giving-up-waiting-for-cache-warming", lager.Data{
+				"cache-key":              key,
+				"warming-container-guid": guid,
+			})
+			p.held.release(lrpContainer.Guid)
+			return true
+		}
+
+		logger.Info("waiting-for-cache-warming", lager.Data{
+			"cache-key":              key,
+			"warming-container-guid": guid,
+		})
+		return false
+	}
+	p.held.release(lrpContainer.Guid)
+
+	if p.cacheWarming[lrpContainer.ProcessGuid] == nil {
+		p.cacheWarming[lrpContainer.ProcessGuid] = make(map[string]string)
+	}
+	for _, key := range keys {
+		p.cacheWarming[lrpContainer.ProcessGuid][key] = lrpContainer.Guid
+	}
+	return true
+}
+
+// releaseCacheWarming lets containers waiting on the cache keys warmed by the
+// given container run, and requeues them so they do not wait for the next
+// poll.
+func (p *ordinaryLRPProcessor) releaseCacheWarming(logger lager.Logger, guid string) {
+	p.cacheWarmingLock.Lock()
+	processGuid, released := p.releaseCacheKeysLocked(guid)
+	p.cacheWarmingLock.Unlock()
+
+	if released {
+		p.held.wake(logger, processGuid)
+	}
+}
+
+func (p *ordinaryLRPProcessor) releaseCacheKeysLocked(guid string) (string, bool) {
+	for processGuid, warmers := range p.cacheWarming {
+		released := false
+		for key, warmingGuid := range warmers {
+			if warmingGuid == guid {
+				delete(warmers, key)
+				released = true
+			}
+		}
+		if len(warmers) == 0 {
+			delete(p.cacheWarming, processGuid)
+		}
+		if released {
+			return processGuid, true
+		}
+	}
+	return "", false
+}
+
+func stillWarming(state executor.State) bool {
+	switch state {
+	case executor.StateReserved, executor.StateInitializing, executor.StateCreated:
+		return true
+	default:
+		return false
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"archive/tar"
 	"errors"
 	"fmt"
+	"time"
 
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
 )
 
 const MAX_RESULT_SIZE = 1024 * 10
@@ -19,19 +22,47 @@ var ErrResultFileTooLarge = errors.New(
 
 type ContainerDelegate interface {
 	GetContainer(logger lager.Logger, guid string) (executor.Container, bool)
+	ListContainers(logger lager.Logger) ([]executor.Container, bool)
+	AllocateContainer(logger lager.Logger, req *executor.AllocationRequest) bool
 	RunContainer(logger lager.Logger, req *executor.RunRequest) bool
 	StopContainer(logger lager.Logger, guid string) bool
 	DeleteContainer(logger lager.Logger, guid string) bool
 	FetchContainerResultFile(logger lager.Logger, guid string, filename string) (string, error)
+	LimitUsage() rep.LimitUsage
 }
 
 type containerDelegate struct {
-	client executor.Client
+	client           executor.Client
+	clock            clock.Clock
+	stopSlots        chan struct{}
+	provisionLimiter *provisionLimiter
+	maxRunRetries    int
 }
 
-func NewContainerDelegate(client executor.Client) ContainerDelegate {
+// NewContainerDelegate returns a ContainerDelegate that allows at most
+// maxConcurrentStops containers to be stopped or deleted at once, and starts
+// at most maxProvisionsPerMinute containers a minute. Runs over the rate wait
+// for their turn. A limit of zero places no limit on the respective operation.
+// A run that fails with a retryable error is retried up to maxRunRetries times,
+// waiting runRetryBackoff before the first retry and twice as long before
+// every further one, before the container is deleted.
+func NewContainerDelegate(client executor.Client, clock clock.Clock, maxConcurrentStops, maxProvisionsPerMinute, maxRunRetries int) ContainerDelegate {
+	var stopSlots chan struct{}
+	if maxConcurrentStops > 0 {
+		stopSlots = make(chan struct{}, maxConcurrentStops)
+	}
+
+	var limiter *provisionLimiter
+	if maxProvisionsPerMinute > 0 {
+		limiter = newProvisionLimiter(clock, maxProvisionsPerMinute)
+	}
+
 	return &containerDelegate{
-		client: client,
+		client:           client,
+		clock:            clock,
+		stopSlots:        stopSlots,
+		provisionLimiter: limiter,
+		maxRunRetries:    maxRunRetries,
 	}
 }
 
@@ -46,10 +77,46 @@ func (d *containerDelegate) GetContainer(logger lager.Logger, guid string) (exec
 	return container, true
 }
 
+func (d *containerDelegate) ListContainers(logger lager.Logger) ([]executor.Container, bool) {
+	logger.Debug("list-containers")
+	containers, err := d.client.ListContainers(logger)
+	if err != nil {
+		logger.Error("failed-list-containers", err)
+		return nil, false
+	}
+	logger.Debug("succeeded-list-containers")
+	return containers, true
+}
+
+func (d *containerDelegate) AllocateContainer(logger lager.Logger, req *executor.AllocationRequest) bool {
+	logger.Info("allocating-container")
+	failures, err := d.client.AllocateContainers(logger, []executor.AllocationRequest{*req})
+	if err != nil {
+		logInfoOrError(logger, "failed-allocating-container", err)
+		return false
+	}
+	if len(failures) > 0 {
+		logger.Error("failed-allocating-container", &failures[0])
+		return false
+	}
+	logger.Info("succeeded-allocating-container")
+	return true
+}
+
 func (d *containerDelegate) RunContainer(logger lager.Logger, req *executor.RunRequest) bool {
+	if d.provisionLimiter != nil {
+		delay := d.provisionLimiter.wait()
+		if delay > 0 {
+			logger.Info("delayed-by-provision-rate-limit", lager.Data{"delay": delay.String()})
+		}
+	}
+
 	logger.Info("running-container")
-	err := d.client.RunContainer(logger, req)
+	err := d.runWithRetries(logger, req)
 	if err != nil {
+		if d.provisionLimiter != nil {
+			d.provisionLimiter.refund()
+		}
 		logInfoOrError(logger, "failed-running-container", err)
 		d.DeleteContainer(logger, req.Guid)
 		return false
@@ -58,7 +125,49 @@ func (d *containerDelegate) RunContainer(logger lager.Logger, req *executor.RunR
 	return true
 }
 
+// runRetryBackoff is how long the first retry of a failed run waits.
+const runRetryBackoff = time.Second
+
+func (d *containerDelegate) runWithRetries(logger lager.Logger, req *executor.RunRequest) error {
+	err := d.client.RunContainer(logger, req)
+
+	backoff := runRetryBackoff
+	for attempt := 1; err != nil && attempt <= d.maxRunRetries && retryableExecutorError(err); attempt++ {
+		logger.Info("retrying-running-container", lager.Data{
+			"attempt": attempt,
+			"backoff": backoff.String(),
+			"error":   err.Error(),
+		})
+		d.clock.Sleep(backoff)
+		backoff *= 2
+
+		err = d.client.RunContainer(logger, req)
+		if err == executor.ErrInvalidTransition && d.alreadyRun(logger, req.Guid) {
+			logger.Info("earlier-run-took-effect")
+			return nil
+		}
+	}
+
+	return err
+}
+
+// alreadyRun reports whether an earlier attempt to run the container went
+// through even though its response was lost. The executor only runs reserved
+// containers, so a retry of a run that took effect fails with an invalid
+// transition and finds the container past the reserved state.
+func (d *containerDelegate) alreadyRun(logger lager.Logger, guid string) bool {
+	container, err := d.client.GetContainer(logger, guid)
+	if err != nil {
+		logInfoOrError(logger, "failed-fetch-container", err)
+		return false
+	}
+	return container.State != executor.StateReserved
+}
+
 func (d *containerDelegate) StopContainer(logger lager.Logger, guid string) bool {
+	release := d.acquireStopSlot()
+	defer release()
+
 	logger.Info("stopping-container")
 	err := d.client.StopContainer(logger, guid)
 	if err != nil {
@@ -70,6 +179,9 @@ func (d *containerDelegate) StopContainer(logger lager.Logger, guid string) bool
 }
 
 func (d *containerDelegate) DeleteContainer(logger lager.Logger, guid string) bool {
+	release := d.acquireStopSlot()
+	defer release()
+
 	logger.Info("deleting-container")
 	err := d.client.DeleteContainer(logger, guid)
 	if err != nil {
@@ -108,6 +220,26 @@ func (d *containerDelegate) FetchContainerResultFile(logger lager.Logger, guid s
 	return string(buf[:n]), nil
 }
 
+func (d *containerDelegate) acquireStopSlot() func() {
+	if d.stopSlots == nil {
+		return func() {}
+	}
+
+	d.stopSlots <- struct{}{}
+	return func() { <-d.stopSlots }
+}
+
+// LimitUsage reports the provisioning rate limit. The concurrency limit is
+// the auction cell rep's, which decides how many containers start at once.
+func (d *containerDelegate) LimitUsage() rep.LimitUsage {
+	usage := rep.LimitUsage{}
+	if d.provisionLimiter != nil {
+		usage.RateTokens = d.provisionLimiter.tokens()
+		usage.RateLimit = d.provisionLimiter.perMinute
+	}
+	return usage
+}
+
 func logInfoOrError(logger lager.Logger, msg string, err error) {
 	if err == executor.ErrContainerNotFound {
 		logger.Info(msg, lager.Data{"error": err.Error()})
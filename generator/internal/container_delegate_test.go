@@ -3,11 +3,16 @@ package internal_test
 import (
 	"errors"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"code.cloudfoundry.org/archiver/extractor/test_helper"
+	"code.cloudfoundry.org/clock/fakeclock"
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/generator/internal"
 
 	. "github.com/onsi/ginkgo"
@@ -19,15 +24,84 @@ var _ = Describe("ContainerDelegate", func() {
 	var containerDelegate internal.ContainerDelegate
 	var executorClient *fakes.FakeClient
 	var logger *lagertest.TestLogger
+	var fakeClock *fakeclock.FakeClock
 	var expectedGuid = "some-instance-guid"
 	const sessionPrefix = "test"
 
 	BeforeEach(func() {
 		executorClient = new(fakes.FakeClient)
-		containerDelegate = internal.NewContainerDelegate(executorClient)
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		containerDelegate = internal.NewContainerDelegate(executorClient, fakeClock, 0, 0, 0)
 		logger = lagertest.NewTestLogger(sessionPrefix)
 	})
 
+	Describe("ListContainers", func() {
+		It("lists the containers", func() {
+			containers := []executor.Container{{Guid: expectedGuid}}
+			executorClient.ListContainersReturns(containers, nil)
+
+			result, ok := containerDelegate.ListContainers(logger)
+			Expect(ok).To(BeTrue())
+			Expect(result).To(Equal(containers))
+		})
+
+		Context("when listing fails", func() {
+			BeforeEach(func() {
+				executorClient.ListContainersReturns(nil, errors.New("ka-boom"))
+			})
+
+			It("returns false", func() {
+				_, ok := containerDelegate.ListContainers(logger)
+				Expect(ok).To(BeFalse())
+				Expect(logger).To(gbytes.Say(sessionPrefix + ".failed-list-containers"))
+			})
+		})
+	})
+
+	Describe("AllocateContainer", func() {
+		var result bool
+		var allocationRequest executor.AllocationRequest
+
+		BeforeEach(func() {
+			allocationRequest = executor.NewAllocationRequest(expectedGuid, &executor.Resource{MemoryMB: 128}, executor.Tags{})
+		})
+
+		JustBeforeEach(func() {
+			result = containerDelegate.AllocateContainer(logger, &allocationRequest)
+		})
+
+		It("allocates the container", func() {
+			Expect(executorClient.AllocateContainersCallCount()).To(Equal(1))
+			_, requests := executorClient.AllocateContainersArgsForCall(0)
+			Expect(requests).To(Equal([]executor.AllocationRequest{allocationRequest}))
+			Expect(result).To(BeTrue())
+		})
+
+		Context("when the executor cannot allocate the container", func() {
+			BeforeEach(func() {
+				executorClient.AllocateContainersReturns([]executor.AllocationFailure{
+					executor.NewAllocationFailure(&allocationRequest, "insufficient resources"),
+				}, nil)
+			})
+
+			It("returns false", func() {
+				Expect(result).To(BeFalse())
+				Expect(logger).To(gbytes.Say(sessionPrefix + ".failed-allocating-container"))
+			})
+		})
+
+		Context("when allocating fails", func() {
+			BeforeEach(func() {
+				executorClient.AllocateContainersReturns(nil, errors.New("ka-boom"))
+			})
+
+			It("returns false", func() {
+				Expect(result).To(BeFalse())
+				Expect(logger).To(gbytes.Say(sessionPrefix + ".failed-allocating-container"))
+			})
+		})
+	})
+
 	Describe("RunContainer", func() {
 		var result bool
 		var runRequest executor.RunRequest
@@ -171,6 +245,231 @@ var _ = Describe("ContainerDelegate", func() {
 		})
 	})
 
+	Context("when the number of concurrent stops is limited", func() {
+		var (
+			inFlight, maxInFlight int32
+			release               chan struct{}
+		)
+
+		BeforeEach(func() {
+			containerDelegate = internal.NewContainerDelegate(executorClient, fakeClock, 2, 0, 0)
+			inFlight, maxInFlight = 0, 0
+			release = make(chan struct{})
+
+			block := func(lager.Logger, string) error {
+				current := atomic.AddInt32(&inFlight, 1)
+				for {
+					max := atomic.LoadInt32(&maxInFlight)
+					if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+						break
+					}
+				}
+				<-release
+				atomic.AddInt32(&inFlight, -1)
+				return nil
+			}
+			executorClient.StopContainerStub = block
+			executorClient.DeleteContainerStub = block
+		})
+
+		It("stops and deletes no more than the limit of containers at once", func() {
+			done := make(chan bool, 5)
+			for i := 0; i < 3; i++ {
+				go func() { done <- containerDelegate.DeleteContainer(logger, expectedGuid) }()
+			}
+			for i := 0; i < 2; i++ {
+				go func() { done <- containerDelegate.StopContainer(logger, expectedGuid) }()
+			}
+
+			calls := func() int {
+				return executorClient.StopContainerCallCount() + executorClient.DeleteContainerCallCount()
+			}
+			Eventually(calls).Should(Equal(2))
+			Consistently(calls).Should(Equal(2))
+
+			close(release)
+			for i := 0; i < 5; i++ {
+				Eventually(done).Should(Receive(BeTrue()))
+			}
+
+			Expect(calls()).To(Equal(5))
+			Expect(atomic.LoadInt32(&maxInFlight)).To(BeEquivalentTo(2))
+		})
+	})
+
+	Describe("retrying runs", func() {
+		var (
+			runRequest executor.RunRequest
+			done       chan bool
+		)
+
+		BeforeEach(func() {
+			containerDelegate = internal.NewContainerDelegate(executorClient, fakeClock, 0, 0, 2)
+			runRequest = executor.NewRunRequest(expectedGuid, &executor.RunInfo{}, executor.Tags{})
+		})
+
+		JustBeforeEach(func() {
+			done = make(chan bool, 1)
+			go func() { done <- containerDelegate.RunContainer(logger, &runRequest) }()
+		})
+
+		Context("when running fails with a retryable error", func() {
+			BeforeEach(func() {
+				attempts := 0
+				executorClient.RunContainerStub = func(lager.Logger, *executor.RunRequest) error {
+					attempts++
+					if attempts == 1 {
+						return errors.New("connection reset by peer")
+					}
+					return nil
+				}
+			})
+
+			It("waits before retrying the run", func() {
+				fakeClock.WaitForWatcherAndIncrement(time.Second - time.Millisecond)
+				Consistently(executorClient.RunContainerCallCount).Should(Equal(1))
+
+				fakeClock.Increment(time.Millisecond)
+				Eventually(done).Should(Receive(BeTrue()))
+				Expect(executorClient.RunContainerCallCount()).To(Equal(2))
+				Expect(executorClient.DeleteContainerCallCount()).To(Equal(0))
+				Expect(logger).To(gbytes.Say(sessionPrefix + ".retrying-running-container"))
+			})
+		})
+
+		Context("when running keeps failing with a retryable error", func() {
+			BeforeEach(func() {
+				executorClient.RunContainerReturns(errors.New("connection reset by peer"))
+			})
+
+			It("waits twice as long before every further retry, then deletes the container", func() {
+				fakeClock.WaitForWatcherAndIncrement(time.Second)
+				fakeClock.WaitForWatcherAndIncrement(2*time.Second - time.Millisecond)
+				Consistently(executorClient.RunContainerCallCount).Should(Equal(2))
+
+				fakeClock.Increment(time.Millisecond)
+				Eventually(done).Should(Receive(BeFalse()))
+				Expect(executorClient.RunContainerCallCount()).To(Equal(3))
+				Expect(executorClient.DeleteContainerCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when running fails with a permanent error", func() {
+			BeforeEach(func() {
+				executorClient.RunContainerReturns(executor.ErrStepsInvalid)
+			})
+
+			It("deletes the container without retrying", func() {
+				Eventually(done).Should(Receive(BeFalse()))
+				Expect(executorClient.RunContainerCallCount()).To(Equal(1))
+				Expect(executorClient.DeleteContainerCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when the response to a run that took effect was lost", func() {
+			BeforeEach(func() {
+				attempts := 0
+				executorClient.RunContainerStub = func(lager.Logger, *executor.RunRequest) error {
+					attempts++
+					if attempts == 1 {
+						return errors.New("connection reset by peer")
+					}
+					return executor.ErrInvalidTransition
+				}
+			})
+
+			Context("and the container has moved past reserved", func() {
+				BeforeEach(func() {
+					executorClient.GetContainerReturns(executor.Container{Guid: expectedGuid, State: executor.StateInitializing}, nil)
+				})
+
+				It("treats the run as successful", func() {
+					fakeClock.WaitForWatcherAndIncrement(time.Second)
+					Eventually(done).Should(Receive(BeTrue()))
+					Expect(executorClient.DeleteContainerCallCount()).To(Equal(0))
+					Expect(logger).To(gbytes.Say(sessionPrefix + ".earlier-run-took-effect"))
+				})
+			})
+
+			Context("and the container is still reserved", func() {
+				BeforeEach(func() {
+					executorClient.GetContainerReturns(executor.Container{Guid: expectedGuid, State: executor.StateReserved}, nil)
+				})
+
+				It("deletes the container", func() {
+					fakeClock.WaitForWatcherAndIncrement(time.Second)
+					Eventually(done).Should(Receive(BeFalse()))
+					Expect(executorClient.DeleteContainerCallCount()).To(Equal(1))
+				})
+			})
+		})
+	})
+
+	Context("when the provisioning rate is limited", func() {
+		var runRequest executor.RunRequest
+
+		BeforeEach(func() {
+			containerDelegate = internal.NewContainerDelegate(executorClient, fakeClock, 0, 3, 0)
+			runRequest = executor.NewRunRequest(expectedGuid, &executor.RunInfo{}, executor.Tags{})
+		})
+
+		runContainers := func(count int) chan bool {
+			done := make(chan bool, count)
+			for i := 0; i < count; i++ {
+				go func() { done <- containerDelegate.RunContainer(logger, &runRequest) }()
+			}
+			return done
+		}
+
+		It("runs a burst up to the limit and queues the rest", func() {
+			done := runContainers(5)
+
+			Eventually(executorClient.RunContainerCallCount).Should(Equal(3))
+			Consistently(executorClient.RunContainerCallCount).Should(Equal(3))
+
+			Eventually(fakeClock.WatcherCount).Should(Equal(2))
+			fakeClock.Increment(20 * time.Second)
+			Eventually(executorClient.RunContainerCallCount).Should(Equal(4))
+			Consistently(executorClient.RunContainerCallCount).Should(Equal(4))
+
+			fakeClock.Increment(20 * time.Second)
+			Eventually(executorClient.RunContainerCallCount).Should(Equal(5))
+
+			for i := 0; i < 5; i++ {
+				Eventually(done).Should(Receive(BeTrue()))
+			}
+			Expect(logger).To(gbytes.Say(sessionPrefix + ".delayed-by-provision-rate-limit"))
+		})
+
+		It("reports the tokens left in the limit", func() {
+			Expect(containerDelegate.LimitUsage()).To(Equal(rep.LimitUsage{RateTokens: 3, RateLimit: 3}))
+
+			done := runContainers(2)
+			for i := 0; i < 2; i++ {
+				Eventually(done).Should(Receive(BeTrue()))
+			}
+			Expect(containerDelegate.LimitUsage().RateTokens).To(Equal(1))
+
+			fakeClock.Increment(20 * time.Second)
+			Expect(containerDelegate.LimitUsage().RateTokens).To(Equal(2))
+		})
+
+		It("does not count provisions that fail against the limit", func() {
+			executorClient.RunContainerReturns(errors.New("boom"))
+			done := runContainers(3)
+			for i := 0; i < 3; i++ {
+				Eventually(done).Should(Receive(BeFalse()))
+			}
+
+			executorClient.RunContainerReturns(nil)
+			done = runContainers(3)
+			for i := 0; i < 3; i++ {
+				Eventually(done).Should(Receive(BeTrue()))
+			}
+			Expect(executorClient.RunContainerCallCount()).To(Equal(6))
+		})
+	})
+
 	Describe("FetchContainerResultFile", func() {
 		var (
 			filename string
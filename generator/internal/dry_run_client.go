@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"code.cloudfoundry.org/bbs"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager"
+)
+
+type dryRunClient struct {
+	bbs.InternalClient
+}
+
+// NewDryRunClient wraps a BBS client so that the reads go through but the
+// writes the rep makes about its LRPs and tasks are only logged, for a cell
+// running in dry-run mode. Each write reports success, and the evacuation of a
+// running LRP reports that the container should be kept.
+func NewDryRunClient(client bbs.InternalClient) bbs.InternalClient {
+	return &dryRunClient{InternalClient: client}
+}
+
+func logDryRunWrite(logger lager.Logger, write string, data lager.Data) {
+	data["write"] = write
+	logger.Info("dry-run-skipping-bbs-write", data)
+}
+
+func (c *dryRunClient) ClaimActualLRP(logger lager.Logger, processGuid string, index int, instanceKey *models.ActualLRPInstanceKey) error {
+	logDryRunWrite(logger, "claim-actual-lrp", lager.Data{"process-guid": processGuid, "index": index})
+	return nil
+}
+
+func (c *dryRunClient) StartActualLRP(logger lager.Logger, key *models.ActualLRPKey, instanceKey *models.ActualLRPInstanceKey, netInfo *models.ActualLRPNetInfo) error {
+	logDryRunWrite(logger, "start-actual-lrp", lager.Data{"process-guid": key.ProcessGuid, "index": key.Index})
+	return nil
+}
+
+func (c *dryRunClient) CrashActualLRP(logger lager.Logger, key *models.ActualLRPKey, instanceKey *models.ActualLRPInstanceKey, errorMessage string) error {
+	logDryRunWrite(logger, "crash-actual-lrp", lager.Data{"process-guid": key.ProcessGuid, "index": key.Index})
+	return nil
+}
+
+func (c *dryRunClient) RemoveActualLRP(logger lager.Logger, processGuid string, index int, instanceKey *models.ActualLRPInstanceKey) error {
+	logDryRunWrite(logger, "remove-actual-lrp", lager.Data{"process-guid": processGuid, "index": index})
+	return nil
+}
+
+func (c *dryRunClient) EvacuateClaimedActualLRP(logger lager.Logger, key *models.ActualLRPKey, instanceKey *models.ActualLRPInstanceKey) (bool, error) {
+	logDryRunWrite(logger, "evacuate-claimed-actual-lrp", lager.Data{"process-guid": key.ProcessGuid, "index": key.Index})
+	return false, nil
+}
+
+func (c *dryRunClient) EvacuateRunningActualLRP(logger lager.Logger, key *models.ActualLRPKey, instanceKey *models.ActualLRPInstanceKey, netInfo *models.ActualLRPNetInfo, ttl uint64) (bool, error) {
+	logDryRunWrite(logger, "evacuate-running-actual-lrp", lager.Data{"process-guid": key.ProcessGuid, "index": key.Index})
+	return true, nil
+}
+
+func (c *dryRunClient) EvacuateStoppedActualLRP(logger lager.Logger, key *models.ActualLRPKey, instanceKey *models.ActualLRPInstanceKey) (bool, error) {
+	logDryRunWrite(logger, "evacuate-stopped-actual-lrp", lager.Data{"process-guid": key.ProcessGuid, "index": key.Index})
+	return false, nil
+}
+
+func (c *dryRunClient) EvacuateCrashedActualLRP(logger lager.Logger, key *models.ActualLRPKey, instanceKey *models.ActualLRPInstanceKey, errorMessage string) (bool, error) {
+	logDryRunWrite(logger, "evacuate-crashed-actual-lrp", lager.Data{"process-guid": key.ProcessGuid, "index": key.Index})
+	return false, nil
+}
+
+func (c *dryRunClient) RemoveEvacuatingActualLRP(logger lager.Logger, key *models.ActualLRPKey, instanceKey *models.ActualLRPInstanceKey) error {
+	logDryRunWrite(logger, "remove-evacuating-actual-lrp", lager.Data{"process-guid": key.ProcessGuid, "index": key.Index})
+	return nil
+}
+
+func (c *dryRunClient) StartTask(logger lager.Logger, taskGuid string, cellID string) (bool, error) {
+	logDryRunWrite(logger, "start-task", lager.Data{"task-guid": taskGuid})
+	return false, nil
+}
+
+func (c *dryRunClient) FailTask(logger lager.Logger, taskGuid string, failureReason string) error {
+	logDryRunWrite(logger, "fail-task", lager.Data{"task-guid": taskGuid})
+	return nil
+}
+
+func (c *dryRunClient) CompleteTask(logger lager.Logger, taskGuid string, cellID string, failed bool, failureReason string, result string) error {
+	logDryRunWrite(logger, "complete-task", lager.Data{"task-guid": taskGuid})
+	return nil
+}
@@ -0,0 +1,66 @@
+package internal_test
+
+import (
+	"code.cloudfoundry.org/bbs"
+	"code.cloudfoundry.org/bbs/fake_bbs"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep/generator/internal"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("DryRunClient", func() {
+	var (
+		logger      *lagertest.TestLogger
+		fakeBBS     *fake_bbs.FakeInternalClient
+		client      bbs.InternalClient
+		lrpKey      models.ActualLRPKey
+		instanceKey models.ActualLRPInstanceKey
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeBBS = new(fake_bbs.FakeInternalClient)
+		client = internal.NewDryRunClient(fakeBBS)
+		lrpKey = models.NewActualLRPKey("process-guid", 2, "domain")
+		instanceKey = models.NewActualLRPInstanceKey("instance-guid", "cell-id")
+	})
+
+	It("logs the writes instead of making them", func() {
+		netInfo := models.NewActualLRPNetInfo("1.2.3.4", models.NewPortMapping(61999, 8080))
+
+		Expect(client.ClaimActualLRP(logger, "process-guid", 2, &instanceKey)).To(Succeed())
+		Expect(client.StartActualLRP(logger, &lrpKey, &instanceKey, &netInfo)).To(Succeed())
+		Expect(client.CrashActualLRP(logger, &lrpKey, &instanceKey, "crashed")).To(Succeed())
+		Expect(client.RemoveActualLRP(logger, "process-guid", 2, &instanceKey)).To(Succeed())
+		Expect(client.FailTask(logger, "task-guid", "failed")).To(Succeed())
+
+		Expect(fakeBBS.ClaimActualLRPCallCount()).To(Equal(0))
+		Expect(fakeBBS.StartActualLRPCallCount()).To(Equal(0))
+		Expect(fakeBBS.CrashActualLRPCallCount()).To(Equal(0))
+		Expect(fakeBBS.RemoveActualLRPCallCount()).To(Equal(0))
+		Expect(fakeBBS.FailTaskCallCount()).To(Equal(0))
+		Expect(logger).To(gbytes.Say("dry-run-skipping-bbs-write"))
+	})
+
+	It("keeps the container of a running LRP being evacuated", func() {
+		netInfo := models.NewActualLRPNetInfo("1.2.3.4", models.NewPortMapping(61999, 8080))
+
+		keep, err := client.EvacuateRunningActualLRP(logger, &lrpKey, &instanceKey, &netInfo, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keep).To(BeTrue())
+		Expect(fakeBBS.EvacuateRunningActualLRPCallCount()).To(Equal(0))
+	})
+
+	It("passes the reads through", func() {
+		fakeBBS.DesiredLRPByProcessGuidReturns(&models.DesiredLRP{ProcessGuid: "process-guid"}, nil)
+
+		desired, err := client.DesiredLRPByProcessGuid(logger, "process-guid")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(desired.ProcessGuid).To(Equal("process-guid"))
+		Expect(fakeBBS.DesiredLRPByProcessGuidCallCount()).To(Equal(1))
+	})
+})
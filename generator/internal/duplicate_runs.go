@@ -0,0 +1,27 @@
+package internal
+
+import "code.cloudfoundry.org/lager"
+
+// markRunIssued records that a run request is being issued for the container
+// and reports whether it is the first. A container can still be seen as
+// reserved after it has been run, for instance by a bulk pass working from a
+// listing taken before the run, and running it a second time is a no-op at
+// best. The record is dropped once the container completes, so a container
+// reserved again for an in-place restart can be run anew.
+func (p *ordinaryLRPProcessor) markRunIssued(logger lager.Logger, guid string) bool {
+	p.runsLock.Lock()
+	defer p.runsLock.Unlock()
+
+	if _, issued := p.runs[guid]; issued {
+		logger.Info("skipping-duplicate-run")
+		return false
+	}
+	p.runs[guid] = struct{}{}
+	return true
+}
+
+func (p *ordinaryLRPProcessor) forgetRun(guid string) {
+	p.runsLock.Lock()
+	defer p.runsLock.Unlock()
+	delete(p.runs, guid)
+}
@@ -23,6 +23,16 @@ func newEvacuationLRPProcessor(bbsClient bbs.InternalClient, containerDelegate C
 	}
 }
 
+// ObserveContainers does nothing, as the evacuation processor does not depend
+// on the other containers on the cell.
+func (p *evacuationLRPProcessor) ObserveContainers(lager.Logger, []executor.Container) {}
+
+// TrackedContainers returns zero, as the evacuation processor keeps no records
+// about containers.
+func (p *evacuationLRPProcessor) TrackedContainers() int {
+	return 0
+}
+
 func (p *evacuationLRPProcessor) Process(logger lager.Logger, container executor.Container) {
 	logger = logger.Session("evacuation-lrp-processor", lager.Data{
 		"container-guid":  container.Guid,
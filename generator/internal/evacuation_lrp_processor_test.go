@@ -7,12 +7,15 @@ import (
 
 	"code.cloudfoundry.org/bbs/fake_bbs"
 	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/executor"
+	mfakes "code.cloudfoundry.org/go-loggregator/loggregator_v2/fakes"
 	"code.cloudfoundry.org/lager/lagertest"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
 	"code.cloudfoundry.org/rep/generator/internal"
 	"code.cloudfoundry.org/rep/generator/internal/fake_internal"
+	"code.cloudfoundry.org/rep/repfakes"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -53,7 +56,10 @@ var _ = Describe("EvacuationLrpProcessor", func() {
 			fakeEvacuationReporter = &fake_evacuation_context.FakeEvacuationReporter{}
 			fakeEvacuationReporter.EvacuatingReturns(true)
 
-			lrpProcessor = internal.NewLRPProcessor(fakeBBS, fakeContainerDelegate, localCellID, fakeEvacuationReporter, evacuationTTL)
+			lrpProcessor = internal.NewLRPProcessor(fakeBBS, internal.NewBBSAssociationStore(fakeBBS), fakeContainerDelegate, fakeEvacuationReporter, new(repfakes.FakeCrashRecorder), new(repfakes.FakeProvisioningRecorder), clock.NewClock(), new(mfakes.FakeClient), nil, internal.LRPProcessorConfig{
+				CellID:                 localCellID,
+				EvacuationTTLInSeconds: evacuationTTL,
+			})
 
 			processGuid = "process-guid"
 			desiredLRP = models.DesiredLRP{
@@ -0,0 +1,25 @@
+package internal
+
+import "code.cloudfoundry.org/executor"
+
+// permanentExecutorErrors are the errors the executor returns for requests
+// that will fail the same way however often they are made.
+var permanentExecutorErrors = []error{
+	executor.ErrContainerNotFound,
+	executor.ErrGuidNotSpecified,
+	executor.ErrInvalidTransition,
+	executor.ErrLimitsInvalid,
+	executor.ErrStepsInvalid,
+}
+
+// retryableExecutorError reports whether a request that failed with the given
+// error may succeed if it is made again. Errors the executor does not name as
+// permanent, such as those from a failed connection, are retryable.
+func retryableExecutorError(err error) bool {
+	for _, permanent := range permanentExecutorErrors {
+		if err == permanent {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+// This file was generated by counterfeiter
+package fake_internal
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep/generator/internal"
+)
+
+type FakeAssociationStore struct {
+	ClaimStub        func(logger lager.Logger, lrpKey *models.ActualLRPKey, instanceKey *models.ActualLRPInstanceKey) error
+	claimMutex       sync.RWMutex
+	claimArgsForCall []struct {
+		logger      lager.Logger
+		lrpKey      *models.ActualLRPKey
+		instanceKey *models.ActualLRPInstanceKey
+	}
+	claimReturns struct {
+		result1 error
+	}
+	RemoveStub        func(logger lager.Logger, lrpKey *models.ActualLRPKey, instanceKey *models.ActualLRPInstanceKey) error
+	removeMutex       sync.RWMutex
+	removeArgsForCall []struct {
+		logger      lager.Logger
+		lrpKey      *models.ActualLRPKey
+		instanceKey *models.ActualLRPInstanceKey
+	}
+	removeReturns struct {
+		result1 error
+	}
+	ActualLRPGroupsForCellStub        func(logger lager.Logger, cellID string) ([]*models.ActualLRPGroup, error)
+	actualLRPGroupsForCellMutex       sync.RWMutex
+	actualLRPGroupsForCellArgsForCall []struct {
+		logger lager.Logger
+		cellID string
+	}
+	actualLRPGroupsForCellReturns struct {
+		result1 []*models.ActualLRPGroup
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeAssociationStore) Claim(logger lager.Logger, lrpKey *models.ActualLRPKey, instanceKey *models.ActualLRPInstanceKey) error {
+	fake.claimMutex.Lock()
+	fake.claimArgsForCall = append(fake.claimArgsForCall, struct {
+		logger      lager.Logger
+		lrpKey      *models.ActualLRPKey
+		instanceKey *models.ActualLRPInstanceKey
+	}{logger, lrpKey, instanceKey})
+	fake.recordInvocation("Claim", []interface{}{logger, lrpKey, instanceKey})
+	fake.claimMutex.Unlock()
+	if fake.ClaimStub != nil {
+		return fake.ClaimStub(logger, lrpKey, instanceKey)
+	} else {
+		return fake.claimReturns.result1
+	}
+}
+
+func (fake *FakeAssociationStore) ClaimCallCount() int {
+	fake.claimMutex.RLock()
+	defer fake.claimMutex.RUnlock()
+	return len(fake.claimArgsForCall)
+}
+
+func (fake *FakeAssociationStore) ClaimArgsForCall(i int) (lager.Logger, *models.ActualLRPKey, *models.ActualLRPInstanceKey) {
+	fake.claimMutex.RLock()
+	defer fake.claimMutex.RUnlock()
+	return fake.claimArgsForCall[i].logger, fake.claimArgsForCall[i].lrpKey, fake.claimArgsForCall[i].instanceKey
+}
+
+func (fake *FakeAssociationStore) ClaimReturns(result1 error) {
+	fake.ClaimStub = nil
+	fake.claimReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAssociationStore) Remove(logger lager.Logger, lrpKey *models.ActualLRPKey, instanceKey *models.ActualLRPInstanceKey) error {
+	fake.removeMutex.Lock()
+	fake.removeArgsForCall = append(fake.removeArgsForCall, struct {
+		logger      lager.Logger
+		lrpKey      *models.ActualLRPKey
+		instanceKey *models.ActualLRPInstanceKey
+	}{logger, lrpKey, instanceKey})
+	fake.recordInvocation("Remove", []interface{}{logger, lrpKey, instanceKey})
+	fake.removeMutex.Unlock()
+	if fake.RemoveStub != nil {
+		return fake.RemoveStub(logger, lrpKey, instanceKey)
+	} else {
+		return fake.removeReturns.result1
+	}
+}
+
+func (fake *FakeAssociationStore) RemoveCallCount() int {
+	fake.removeMutex.RLock()
+	defer fake.removeMutex.RUnlock()
+	return len(fake.removeArgsForCall)
+}
+
+func (fake *FakeAssociationStore) RemoveArgsForCall(i int) (lager.Logger, *models.ActualLRPKey, *models.ActualLRPInstanceKey) {
+	fake.removeMutex.RLock()
+	defer fake.removeMutex.RUnlock()
+	return fake.removeArgsForCall[i].logger, fake.removeArgsForCall[i].lrpKey, fake.removeArgsForCall[i].instanceKey
+}
+
+func (fake *FakeAssociationStore) RemoveReturns(result1 error) {
+	fake.RemoveStub = nil
+	fake.removeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAssociationStore) ActualLRPGroupsForCell(logger lager.Logger, cellID string) ([]*models.ActualLRPGroup, error) {
+	fake.actualLRPGroupsForCellMutex.Lock()
+	fake.actualLRPGroupsForCellArgsForCall = append(fake.actualLRPGroupsForCellArgsForCall, struct {
+		logger lager.Logger
+		cellID string
+	}{logger, cellID})
+	fake.recordInvocation("ActualLRPGroupsForCell", []interface{}{logger, cellID})
+	fake.actualLRPGroupsForCellMutex.Unlock()
+	if fake.ActualLRPGroupsForCellStub != nil {
+		return fake.ActualLRPGroupsForCellStub(logger, cellID)
+	} else {
+		return fake.actualLRPGroupsForCellReturns.result1, fake.actualLRPGroupsForCellReturns.result2
+	}
+}
+
+func (fake *FakeAssociationStore) ActualLRPGroupsForCellCallCount() int {
+	fake.actualLRPGroupsForCellMutex.RLock()
+	defer fake.actualLRPGroupsForCellMutex.RUnlock()
+	return len(fake.actualLRPGroupsForCellArgsForCall)
+}
+
+func (fake *FakeAssociationStore) ActualLRPGroupsForCellArgsForCall(i int) (lager.Logger, string) {
+	fake.actualLRPGroupsForCellMutex.RLock()
+	defer fake.actualLRPGroupsForCellMutex.RUnlock()
+	return fake.actualLRPGroupsForCellArgsForCall[i].logger, fake.actualLRPGroupsForCellArgsForCall[i].cellID
+}
+
+func (fake *FakeAssociationStore) ActualLRPGroupsForCellReturns(result1 []*models.ActualLRPGroup, result2 error) {
+	fake.ActualLRPGroupsForCellStub = nil
+	fake.actualLRPGroupsForCellReturns = struct {
+		result1 []*models.ActualLRPGroup
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAssociationStore) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.claimMutex.RLock()
+	defer fake.claimMutex.RUnlock()
+	fake.removeMutex.RLock()
+	defer fake.removeMutex.RUnlock()
+	fake.actualLRPGroupsForCellMutex.RLock()
+	defer fake.actualLRPGroupsForCellMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeAssociationStore) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ internal.AssociationStore = new(FakeAssociationStore)
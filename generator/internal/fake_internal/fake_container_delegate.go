@@ -6,6 +6,7 @@ import (
 
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/generator/internal"
 )
 
@@ -20,6 +21,24 @@ type FakeContainerDelegate struct {
 		result1 executor.Container
 		result2 bool
 	}
+	ListContainersStub        func(logger lager.Logger) ([]executor.Container, bool)
+	listContainersMutex       sync.RWMutex
+	listContainersArgsForCall []struct {
+		logger lager.Logger
+	}
+	listContainersReturns struct {
+		result1 []executor.Container
+		result2 bool
+	}
+	AllocateContainerStub        func(logger lager.Logger, req *executor.AllocationRequest) bool
+	allocateContainerMutex       sync.RWMutex
+	allocateContainerArgsForCall []struct {
+		logger lager.Logger
+		req    *executor.AllocationRequest
+	}
+	allocateContainerReturns struct {
+		result1 bool
+	}
 	RunContainerStub        func(logger lager.Logger, req *executor.RunRequest) bool
 	runContainerMutex       sync.RWMutex
 	runContainerArgsForCall []struct {
@@ -58,6 +77,12 @@ type FakeContainerDelegate struct {
 		result1 string
 		result2 error
 	}
+	LimitUsageStub        func() rep.LimitUsage
+	limitUsageMutex       sync.RWMutex
+	limitUsageArgsForCall []struct{}
+	limitUsageReturns     struct {
+		result1 rep.LimitUsage
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -97,6 +122,74 @@ func (fake *FakeContainerDelegate) GetContainerReturns(result1 executor.Containe
 	}{result1, result2}
 }
 
+func (fake *FakeContainerDelegate) ListContainers(logger lager.Logger) ([]executor.Container, bool) {
+	fake.listContainersMutex.Lock()
+	fake.listContainersArgsForCall = append(fake.listContainersArgsForCall, struct {
+		logger lager.Logger
+	}{logger})
+	fake.recordInvocation("ListContainers", []interface{}{logger})
+	fake.listContainersMutex.Unlock()
+	if fake.ListContainersStub != nil {
+		return fake.ListContainersStub(logger)
+	} else {
+		return fake.listContainersReturns.result1, fake.listContainersReturns.result2
+	}
+}
+
+func (fake *FakeContainerDelegate) ListContainersCallCount() int {
+	fake.listContainersMutex.RLock()
+	defer fake.listContainersMutex.RUnlock()
+	return len(fake.listContainersArgsForCall)
+}
+
+func (fake *FakeContainerDelegate) ListContainersArgsForCall(i int) lager.Logger {
+	fake.listContainersMutex.RLock()
+	defer fake.listContainersMutex.RUnlock()
+	return fake.listContainersArgsForCall[i].logger
+}
+
+func (fake *FakeContainerDelegate) ListContainersReturns(result1 []executor.Container, result2 bool) {
+	fake.ListContainersStub = nil
+	fake.listContainersReturns = struct {
+		result1 []executor.Container
+		result2 bool
+	}{result1, result2}
+}
+
+func (fake *FakeContainerDelegate) AllocateContainer(logger lager.Logger, req *executor.AllocationRequest) bool {
+	fake.allocateContainerMutex.Lock()
+	fake.allocateContainerArgsForCall = append(fake.allocateContainerArgsForCall, struct {
+		logger lager.Logger
+		req    *executor.AllocationRequest
+	}{logger, req})
+	fake.recordInvocation("AllocateContainer", []interface{}{logger, req})
+	fake.allocateContainerMutex.Unlock()
+	if fake.AllocateContainerStub != nil {
+		return fake.AllocateContainerStub(logger, req)
+	} else {
+		return fake.allocateContainerReturns.result1
+	}
+}
+
+func (fake *FakeContainerDelegate) AllocateContainerCallCount() int {
+	fake.allocateContainerMutex.RLock()
+	defer fake.allocateContainerMutex.RUnlock()
+	return len(fake.allocateContainerArgsForCall)
+}
+
+func (fake *FakeContainerDelegate) AllocateContainerArgsForCall(i int) (lager.Logger, *executor.AllocationRequest) {
+	fake.allocateContainerMutex.RLock()
+	defer fake.allocateContainerMutex.RUnlock()
+	return fake.allocateContainerArgsForCall[i].logger, fake.allocateContainerArgsForCall[i].req
+}
+
+func (fake *FakeContainerDelegate) AllocateContainerReturns(result1 bool) {
+	fake.AllocateContainerStub = nil
+	fake.allocateContainerReturns = struct {
+		result1 bool
+	}{result1}
+}
+
 func (fake *FakeContainerDelegate) RunContainer(logger lager.Logger, req *executor.RunRequest) bool {
 	fake.runContainerMutex.Lock()
 	fake.runContainerArgsForCall = append(fake.runContainerArgsForCall, struct {
@@ -235,11 +328,40 @@ func (fake *FakeContainerDelegate) FetchContainerResultFileReturns(result1 strin
 	}{result1, result2}
 }
 
+func (fake *FakeContainerDelegate) LimitUsage() rep.LimitUsage {
+	fake.limitUsageMutex.Lock()
+	fake.limitUsageArgsForCall = append(fake.limitUsageArgsForCall, struct{}{})
+	fake.recordInvocation("LimitUsage", []interface{}{})
+	fake.limitUsageMutex.Unlock()
+	if fake.LimitUsageStub != nil {
+		return fake.LimitUsageStub()
+	} else {
+		return fake.limitUsageReturns.result1
+	}
+}
+
+func (fake *FakeContainerDelegate) LimitUsageCallCount() int {
+	fake.limitUsageMutex.RLock()
+	defer fake.limitUsageMutex.RUnlock()
+	return len(fake.limitUsageArgsForCall)
+}
+
+func (fake *FakeContainerDelegate) LimitUsageReturns(result1 rep.LimitUsage) {
+	fake.LimitUsageStub = nil
+	fake.limitUsageReturns = struct {
+		result1 rep.LimitUsage
+	}{result1}
+}
+
 func (fake *FakeContainerDelegate) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.getContainerMutex.RLock()
 	defer fake.getContainerMutex.RUnlock()
+	fake.listContainersMutex.RLock()
+	defer fake.listContainersMutex.RUnlock()
+	fake.allocateContainerMutex.RLock()
+	defer fake.allocateContainerMutex.RUnlock()
 	fake.runContainerMutex.RLock()
 	defer fake.runContainerMutex.RUnlock()
 	fake.stopContainerMutex.RLock()
@@ -248,6 +370,8 @@ func (fake *FakeContainerDelegate) Invocations() map[string][][]interface{} {
 	defer fake.deleteContainerMutex.RUnlock()
 	fake.fetchContainerResultFileMutex.RLock()
 	defer fake.fetchContainerResultFileMutex.RUnlock()
+	fake.limitUsageMutex.RLock()
+	defer fake.limitUsageMutex.RUnlock()
 	return fake.invocations
 }
 
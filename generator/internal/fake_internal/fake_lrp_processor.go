@@ -10,12 +10,24 @@ import (
 )
 
 type FakeLRPProcessor struct {
-	ProcessStub        func(lager.Logger, executor.Container)
+	ProcessStub        func(arg1 lager.Logger, arg2 executor.Container)
 	processMutex       sync.RWMutex
 	processArgsForCall []struct {
 		arg1 lager.Logger
 		arg2 executor.Container
 	}
+	ObserveContainersStub        func(arg1 lager.Logger, arg2 []executor.Container)
+	observeContainersMutex       sync.RWMutex
+	observeContainersArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 []executor.Container
+	}
+	TrackedContainersStub        func() int
+	trackedContainersMutex       sync.RWMutex
+	trackedContainersArgsForCall []struct{}
+	trackedContainersReturns     struct {
+		result1 int
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -45,11 +57,70 @@ func (fake *FakeLRPProcessor) ProcessArgsForCall(i int) (lager.Logger, executor.
 	return fake.processArgsForCall[i].arg1, fake.processArgsForCall[i].arg2
 }
 
+func (fake *FakeLRPProcessor) ObserveContainers(arg1 lager.Logger, arg2 []executor.Container) {
+	var arg2Copy []executor.Container
+	if arg2 != nil {
+		arg2Copy = make([]executor.Container, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.observeContainersMutex.Lock()
+	fake.observeContainersArgsForCall = append(fake.observeContainersArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 []executor.Container
+	}{arg1, arg2Copy})
+	fake.recordInvocation("ObserveContainers", []interface{}{arg1, arg2Copy})
+	fake.observeContainersMutex.Unlock()
+	if fake.ObserveContainersStub != nil {
+		fake.ObserveContainersStub(arg1, arg2)
+	}
+}
+
+func (fake *FakeLRPProcessor) ObserveContainersCallCount() int {
+	fake.observeContainersMutex.RLock()
+	defer fake.observeContainersMutex.RUnlock()
+	return len(fake.observeContainersArgsForCall)
+}
+
+func (fake *FakeLRPProcessor) ObserveContainersArgsForCall(i int) (lager.Logger, []executor.Container) {
+	fake.observeContainersMutex.RLock()
+	defer fake.observeContainersMutex.RUnlock()
+	return fake.observeContainersArgsForCall[i].arg1, fake.observeContainersArgsForCall[i].arg2
+}
+
+func (fake *FakeLRPProcessor) TrackedContainers() int {
+	fake.trackedContainersMutex.Lock()
+	fake.trackedContainersArgsForCall = append(fake.trackedContainersArgsForCall, struct{}{})
+	fake.recordInvocation("TrackedContainers", []interface{}{})
+	fake.trackedContainersMutex.Unlock()
+	if fake.TrackedContainersStub != nil {
+		return fake.TrackedContainersStub()
+	} else {
+		return fake.trackedContainersReturns.result1
+	}
+}
+
+func (fake *FakeLRPProcessor) TrackedContainersCallCount() int {
+	fake.trackedContainersMutex.RLock()
+	defer fake.trackedContainersMutex.RUnlock()
+	return len(fake.trackedContainersArgsForCall)
+}
+
+func (fake *FakeLRPProcessor) TrackedContainersReturns(result1 int) {
+	fake.TrackedContainersStub = nil
+	fake.trackedContainersReturns = struct {
+		result1 int
+	}{result1}
+}
+
 func (fake *FakeLRPProcessor) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.processMutex.RLock()
 	defer fake.processMutex.RUnlock()
+	fake.observeContainersMutex.RLock()
+	defer fake.observeContainersMutex.RUnlock()
+	fake.trackedContainersMutex.RLock()
+	defer fake.trackedContainersMutex.RUnlock()
 	return fake.invocations
 }
 
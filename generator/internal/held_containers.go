@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+// maxStartupHold bounds how long a startup gate holds a reserved container
+// back. It is kept well below the executor's default reserved container
+// expiration of a minute, so that the executor does not reap a reservation
+// while the cell is still waiting to run it.
+const maxStartupHold = 30 * time.Second
+
+// heldContainers tracks the reserved containers that startup gates are holding
+// back, grouped by process guid. When something happens that may open a gate
+// for a process, such as one of its instances starting to run, the held
+// containers of that process are sent to requeue so that they are processed
+// again straight away rather than on the next poll.
+type heldContainers struct {
+	clock   clock.Clock
+	requeue chan<- string
+
+	lock sync.Mutex
+	held map[string]map[string]time.Time
+}
+
+func newHeldContainers(clock clock.Clock, requeue chan<- string) *heldContainers {
+	return &heldContainers{
+		clock:   clock,
+		requeue: requeue,
+		held:    make(map[string]map[string]time.Time),
+	}
+}
+
+// hold records that a gate is holding the container back. It returns false
+// once the container has been held for maxStartupHold, when the gate should
+// let it through instead.
+func (h *heldContainers) hold(processGuid, guid string) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	containers, ok := h.held[processGuid]
+	if !ok {
+		containers = make(map[string]time.Time)
+		h.held[processGuid] = containers
+	}
+
+	since, ok := containers[guid]
+	if !ok {
+		containers[guid] = h.clock.Now()
+		return true
+	}
+	return h.clock.Now().Sub(since) < maxStartupHold
+}
+
+// release forgets a container that is no longer held back.
+func (h *heldContainers) release(guid string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for processGuid, containers := range h.held {
+		delete(containers, guid)
+		if len(containers) == 0 {
+			delete(h.held, processGuid)
+		}
+	}
+}
+
+// isHeld reports whether a gate is holding the container back.
+func (h *heldContainers) isHeld(guid string) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for _, containers := range h.held {
+		if _, ok := containers[guid]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// wake requeues the containers held back for the process. A container that
+// does not fit in the requeue buffer is left to the next poll.
+func (h *heldContainers) wake(logger lager.Logger, processGuid string) {
+	if h.requeue == nil {
+		return
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for guid := range h.held[processGuid] {
+		select {
+		case h.requeue <- guid:
+		default:
+			logger.Info("dropped-held-container-requeue", lager.Data{"held-container-guid": guid})
+		}
+	}
+}
+
+// addGuids adds the guids of the held containers to guids.
+func (h *heldContainers) addGuids(guids map[string]struct{}) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for _, containers := range h.held {
+		for guid := range containers {
+			guids[guid] = struct{}{}
+		}
+	}
+}
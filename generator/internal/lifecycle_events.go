@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+const (
+	repLRPInitializationDuration = "RepLRPInitializationDuration"
+	repLRPRunDuration            = "RepLRPRunDuration"
+)
+
+// phaseDurationMetrics are the metrics that report how long the phase ending
+// in each transition took. The allocation phase is reported as
+// RepLRPTimeToRunning instead.
+var phaseDurationMetrics = map[rep.LifecycleTransition]string{
+	rep.LifecycleTransitionInitialized: repLRPInitializationDuration,
+	rep.LifecycleTransitionRunning:     repLRPRunDuration,
+}
+
+func (p *ordinaryLRPProcessor) emitLifecycleEvent(logger lager.Logger, lrpContainer *lrpContainer, transition rep.LifecycleTransition) {
+	now := p.clock.Now()
+	duration := p.phaseDuration(lrpContainer, transition, now)
+
+	p.lifecycleEvents.Emit(rep.LifecycleEvent{
+		ProcessGuid:   lrpContainer.ProcessGuid,
+		Index:         lrpContainer.Index,
+		ContainerGuid: lrpContainer.Guid,
+		Transition:    transition,
+		Time:          now,
+		Duration:      duration,
+	})
+
+	name, ok := phaseDurationMetrics[transition]
+	if !ok || duration == 0 {
+		return
+	}
+	err := p.metronClient.SendDuration(name, duration)
+	if err != nil {
+		logger.Error("failed-to-send-phase-duration", err, lager.Data{"metric": name})
+	}
+}
+
+// phaseDuration returns how long the container spent in the phase that the
+// transition ends, and records when the next phase began. The allocation phase
+// begins when the container was allocated; each later phase begins with the
+// previous transition.
+func (p *ordinaryLRPProcessor) phaseDuration(lrpContainer *lrpContainer, transition rep.LifecycleTransition, now time.Time) time.Duration {
+	p.transitionsLock.Lock()
+	defer p.transitionsLock.Unlock()
+
+	var began time.Time
+	if transition == rep.LifecycleTransitionAllocated {
+		if lrpContainer.AllocatedAt != 0 {
+			began = time.Unix(0, lrpContainer.AllocatedAt)
+		}
+	} else {
+		began = p.transitions[lrpContainer.Guid]
+	}
+
+	if transition == rep.LifecycleTransitionRunning {
+		delete(p.transitions, lrpContainer.Guid)
+	} else {
+		p.transitions[lrpContainer.Guid] = now
+	}
+
+	if began.IsZero() {
+		return 0
+	}
+	return now.Sub(began)
+}
+
+// recordInitialized emits the initialized event the first time the container
+// is seen created. The executor can take a container from initializing
+// straight to running between two polls, so it is also called, with seen
+// false, when the container becomes ready, keeping the events in order. The
+// rep then cannot tell where initialization ended and the run began, so
+// neither phase is given a duration.
+func (p *ordinaryLRPProcessor) recordInitialized(logger lager.Logger, lrpContainer *lrpContainer, seen bool) {
+	p.initializedLock.Lock()
+	_, reported := p.initialized[lrpContainer.Guid]
+	p.initialized[lrpContainer.Guid] = struct{}{}
+	p.initializedLock.Unlock()
+
+	if reported {
+		return
+	}
+	if !seen {
+		p.forgetTransition(lrpContainer.Guid)
+	}
+	p.emitLifecycleEvent(logger, lrpContainer, rep.LifecycleTransitionInitialized)
+	if !seen {
+		p.forgetTransition(lrpContainer.Guid)
+	}
+}
+
+// forgetTransition forgets when the container's current phase began, so that
+// the phase is not given a duration.
+func (p *ordinaryLRPProcessor) forgetTransition(guid string) {
+	p.transitionsLock.Lock()
+	defer p.transitionsLock.Unlock()
+	delete(p.transitions, guid)
+}
+
+func (p *ordinaryLRPProcessor) forgetInitialized(guid string) {
+	p.initializedLock.Lock()
+	delete(p.initialized, guid)
+	p.initializedLock.Unlock()
+
+	p.forgetTransition(guid)
+}
@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+const (
+	repLRPRunSucceeded   = "RepLRPRunSucceeded"
+	repLRPRunFailed      = "RepLRPRunFailed"
+	repLRPStartSucceeded = "RepLRPStartSucceeded"
+	repLRPCrashed        = "RepLRPCrashed"
+)
+
+var errRunFailed = errors.New("executor failed to run the container")
+
+func (p *ordinaryLRPProcessor) reportLifecycleError(lrpContainer *lrpContainer, phase rep.LifecyclePhase, err error) {
+	p.lifecycleErrors.Report(rep.LifecycleError{
+		ProcessGuid:   lrpContainer.ProcessGuid,
+		Index:         lrpContainer.Index,
+		ContainerGuid: lrpContainer.Guid,
+		Phase:         phase,
+		Err:           err,
+	})
+}
+
+func (p *ordinaryLRPProcessor) incrementCounter(logger lager.Logger, name string) {
+	err := p.metronClient.IncrementCounter(name)
+	if err != nil {
+		logger.Error("failed-to-increment-counter", err, lager.Data{"metric": name})
+	}
+}
@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// resolveLogIndexCollision checks the log index of the run request against
+// those of the other started instances of the same LRP on the cell, as last
+// observed, and reports whether the container may be run. With the reject
+// policy a container colliding with another instance is not run.
+func (p *ordinaryLRPProcessor) resolveLogIndexCollision(logger lager.Logger, lrpContainer *lrpContainer, runReq *executor.RunRequest) bool {
+	if p.logIndexPolicy == rep.LogIndexCollisionPolicyNone {
+		return true
+	}
+
+	p.instancesLock.Lock()
+	defer p.instancesLock.Unlock()
+
+	for guid, instance := range p.instances[lrpContainer.ProcessGuid] {
+		if guid == lrpContainer.Guid || instance.logIndex != runReq.LogConfig.Index {
+			continue
+		}
+
+		switch instance.state {
+		case executor.StateInitializing, executor.StateCreated, executor.StateRunning:
+			logger.Info("log-index-collision", lager.Data{
+				"log-index": runReq.LogConfig.Index,
+				"policy":    p.logIndexPolicy,
+			})
+			return false
+		}
+	}
+
+	return true
+}
@@ -1,10 +1,15 @@
 package internal
 
 import (
+	"time"
+
 	"code.cloudfoundry.org/bbs"
 	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/go-loggregator/loggregator_v2"
 	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
 )
 
@@ -26,6 +31,8 @@ func newLRPContainer(lrpKey *models.ActualLRPKey, instanceKey *models.ActualLRPI
 
 type LRPProcessor interface {
 	Process(lager.Logger, executor.Container)
+	ObserveContainers(lager.Logger, []executor.Container)
+	TrackedContainers() int
 }
 
 type lrpProcessor struct {
@@ -34,15 +41,40 @@ type lrpProcessor struct {
 	evacuationProcessor LRPProcessor
 }
 
+// LRPProcessorConfig holds the settings shared by the ordinary and
+// evacuation LRP processors.
+type LRPProcessorConfig struct {
+	CellID                 string
+	EvacuationTTLInSeconds uint64
+	MetadataLimit          rep.MetadataLimit
+	DependencyCheck        rep.DependencyCheck
+	PrivilegeCheck         rep.PrivilegeCheck
+	StartTimeout           rep.StartTimeoutLimit
+	EnvOverlay             rep.EnvOverlay
+	OrphanHandler          rep.OrphanHandler
+	OrphanQuarantineTTL    time.Duration
+	LifecycleErrors        rep.LifecycleErrorHandler
+	LifecycleEvents        *rep.LifecycleEvents
+	LogIndexPolicy         rep.LogIndexCollisionPolicy
+	MaxInPlaceRestarts     int
+	StartupReadiness       executor.State
+	SerializeCacheWarming  bool
+}
+
 func NewLRPProcessor(
 	bbsClient bbs.InternalClient,
+	associationStore AssociationStore,
 	containerDelegate ContainerDelegate,
-	cellID string,
 	evacuationReporter evacuation_context.EvacuationReporter,
-	evacuationTTLInSeconds uint64,
+	crashRecorder rep.CrashRecorder,
+	provisioningRecorder rep.ProvisioningRecorder,
+	clock clock.Clock,
+	metronClient loggregator_v2.Client,
+	requeue chan<- string,
+	config LRPProcessorConfig,
 ) LRPProcessor {
-	ordinaryProcessor := newOrdinaryLRPProcessor(bbsClient, containerDelegate, cellID)
-	evacuationProcessor := newEvacuationLRPProcessor(bbsClient, containerDelegate, cellID, evacuationTTLInSeconds)
+	ordinaryProcessor := newOrdinaryLRPProcessor(bbsClient, associationStore, containerDelegate, crashRecorder, provisioningRecorder, clock, metronClient, requeue, config)
+	evacuationProcessor := newEvacuationLRPProcessor(bbsClient, containerDelegate, config.CellID, config.EvacuationTTLInSeconds)
 	return &lrpProcessor{
 		evacuationReporter:  evacuationReporter,
 		ordinaryProcessor:   ordinaryProcessor,
@@ -50,6 +82,15 @@ func NewLRPProcessor(
 	}
 }
 
+func (p *lrpProcessor) ObserveContainers(logger lager.Logger, containers []executor.Container) {
+	p.ordinaryProcessor.ObserveContainers(logger, containers)
+	p.evacuationProcessor.ObserveContainers(logger, containers)
+}
+
+func (p *lrpProcessor) TrackedContainers() int {
+	return p.ordinaryProcessor.TrackedContainers() + p.evacuationProcessor.TrackedContainers()
+}
+
 func (p *lrpProcessor) Process(logger lager.Logger, container executor.Container) {
 	if p.evacuationReporter.Evacuating() {
 		p.evacuationProcessor.Process(logger, container)
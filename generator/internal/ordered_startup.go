@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"strconv"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// startupRank orders the states a container passes through on its way to
+// running.
+func startupRank(state executor.State) int {
+	switch state {
+	case executor.StateReserved:
+		return 1
+	case executor.StateInitializing:
+		return 2
+	case executor.StateCreated:
+		return 3
+	case executor.StateRunning:
+		return 4
+	default:
+		return 0
+	}
+}
+
+type observedInstance struct {
+	index    int32
+	logIndex int
+	state    executor.State
+}
+
+// observesInstances reports whether the processor needs to know about the
+// other instances on the cell, for ordered startup or to detect log index
+// collisions.
+func (p *ordinaryLRPProcessor) observesInstances() bool {
+	return p.startupReadiness != "" || p.logIndexPolicy != rep.LogIndexCollisionPolicyNone
+}
+
+// ObserveContainers replaces what the processor knows about the LRP instances
+// on the cell with the containers listed at the start of a pass, so that
+// ordered startup and log index collision checks do not list the containers
+// once per reserved container. Between passes it is kept up to date by the
+// containers being processed. The records of containers that are no longer
+// listed are dropped.
+func (p *ordinaryLRPProcessor) ObserveContainers(logger lager.Logger, containers []executor.Container) {
+	p.pruneRecords(logger, containers)
+
+	if !p.observesInstances() {
+		return
+	}
+
+	instances := make(map[string]map[string]observedInstance)
+	for i := range containers {
+		container := &containers[i]
+		if container.Tags[rep.LifecycleTag] != rep.LRPLifecycle {
+			continue
+		}
+
+		index, err := strconv.Atoi(container.Tags[rep.ProcessIndexTag])
+		if err != nil {
+			continue
+		}
+
+		processGuid := container.Tags[rep.ProcessGuidTag]
+		if instances[processGuid] == nil {
+			instances[processGuid] = make(map[string]observedInstance)
+		}
+		instances[processGuid][container.Guid] = observedInstance{
+			index:    int32(index),
+			logIndex: container.LogConfig.Index,
+			state:    container.State,
+		}
+	}
+
+	p.instancesLock.Lock()
+	p.instances = instances
+	p.instancesLock.Unlock()
+}
+
+// observeInstance records the state the container is being processed in. When
+// the state has changed, the containers of the same LRP held back for it are
+// requeued, as the change may be the one they are waiting for.
+func (p *ordinaryLRPProcessor) observeInstance(logger lager.Logger, lrpContainer *lrpContainer) {
+	if !p.observesInstances() {
+		return
+	}
+
+	p.instancesLock.Lock()
+	if p.instances[lrpContainer.ProcessGuid] == nil {
+		p.instances[lrpContainer.ProcessGuid] = make(map[string]observedInstance)
+	}
+	previous, seen := p.instances[lrpContainer.ProcessGuid][lrpContainer.Guid]
+	p.instances[lrpContainer.ProcessGuid][lrpContainer.Guid] = observedInstance{
+		index:    lrpContainer.Index,
+		logIndex: lrpContainer.LogConfig.Index,
+		state:    lrpContainer.State,
+	}
+	p.instancesLock.Unlock()
+
+	if !seen || previous.state != lrpContainer.State {
+		p.held.wake(logger, lrpContainer.ProcessGuid)
+	}
+}
+
+func (p *ordinaryLRPProcessor) forgetInstance(guid string) {
+	p.instancesLock.Lock()
+	defer p.instancesLock.Unlock()
+
+	for processGuid, instances := range p.instances {
+		delete(instances, guid)
+		if len(instances) == 0 {
+			delete(p.instances, processGuid)
+		}
+	}
+}
+
+// earlierInstancesReady reports whether every instance of the same LRP on the
+// cell with a lower index has reached the startup readiness state. Completed
+// instances are not waited for, as they will never become ready. An instance
+// held back stays reserved until an earlier instance changes state, and is
+// let through once it has been held for maxStartupHold.
+func (p *ordinaryLRPProcessor) earlierInstancesReady(logger lager.Logger, lrpContainer *lrpContainer) bool {
+	if p.startupReadiness == "" {
+		return true
+	}
+
+	p.instancesLock.Lock()
+	var waitingFor *observedInstance
+	for guid, instance := range p.instances[lrpContainer.ProcessGuid] {
+		if guid == lrpContainer.Guid ||
+			instance.index >= lrpContainer.Index ||
+			instance.state == executor.StateCompleted {
+			continue
+		}
+
+		if startupRank(instance.state) < startupRank(p.startupReadiness) {
+			waitingFor = &instance
+			break
+		}
+	}
+	p.instancesLock.Unlock()
+
+	if waitingFor == nil {
+		p.held.release(lrpContainer.Guid)
+		return true
+	}
+
+	data := lager.Data{
+		"index": waitingFor.index,
+		"state": waitingFor.state,
+	}
+	if !p.held.hold(lrpContainer.ProcessGuid, lrpContainer.Guid) {
+		logger.Info("giving-up-waiting-for-earlier-instance", data)
+		p.held.release(lrpContainer.Guid)
+		return true
+	}
+
+	logger.Info("waiting-for-earlier-instance", data)
+	return false
+}
@@ -1,28 +1,116 @@
 package internal
 
 import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
 	"code.cloudfoundry.org/bbs"
 	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/go-loggregator/loggregator_v2"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/rep"
 )
 
 type ordinaryLRPProcessor struct {
 	bbsClient         bbs.InternalClient
+	associationStore  AssociationStore
 	containerDelegate ContainerDelegate
 	cellID            string
+	metadataLimit     rep.MetadataLimit
+	dependencyCheck   rep.DependencyCheck
+	privilegeCheck    rep.PrivilegeCheck
+	startTimeout      rep.StartTimeoutLimit
+	envOverlay        rep.EnvOverlay
+	orphanHandler     rep.OrphanHandler
+	quarantineTTL     time.Duration
+	lifecycleErrors   rep.LifecycleErrorHandler
+	lifecycleEvents   *rep.LifecycleEvents
+	logIndexPolicy    rep.LogIndexCollisionPolicy
+	crashRecorder     rep.CrashRecorder
+	provisioning      rep.ProvisioningRecorder
+	maxRestarts       int
+	startupReadiness  executor.State
+	serializeWarming  bool
+	clock             clock.Clock
+	metronClient      loggregator_v2.Client
+
+	restartsLock sync.Mutex
+	restarts     map[string]inPlaceRestarts
+
+	readyLock     sync.Mutex
+	starting      map[string]struct{}
+	startFailures map[string]struct{}
+	ready         map[string]struct{}
+
+	initializedLock sync.Mutex
+	initialized     map[string]struct{}
+
+	transitionsLock sync.Mutex
+	transitions     map[string]time.Time
+
+	runsLock sync.Mutex
+	runs     map[string]struct{}
+
+	quarantineLock sync.Mutex
+	quarantined    map[string]time.Time
+
+	cacheWarmingLock sync.Mutex
+	cacheWarming     map[string]map[string]string
+
+	held *heldContainers
+
+	instancesLock sync.Mutex
+	instances     map[string]map[string]observedInstance
 }
 
 func newOrdinaryLRPProcessor(
 	bbsClient bbs.InternalClient,
+	associationStore AssociationStore,
 	containerDelegate ContainerDelegate,
-	cellID string,
+	crashRecorder rep.CrashRecorder,
+	provisioningRecorder rep.ProvisioningRecorder,
+	clock clock.Clock,
+	metronClient loggregator_v2.Client,
+	requeue chan<- string,
+	config LRPProcessorConfig,
 ) LRPProcessor {
 	return &ordinaryLRPProcessor{
 		bbsClient:         bbsClient,
+		associationStore:  associationStore,
 		containerDelegate: containerDelegate,
-		cellID:            cellID,
+		cellID:            config.CellID,
+		metadataLimit:     config.MetadataLimit,
+		dependencyCheck:   config.DependencyCheck,
+		privilegeCheck:    config.PrivilegeCheck,
+		startTimeout:      config.StartTimeout,
+		envOverlay:        config.EnvOverlay,
+		orphanHandler:     config.OrphanHandler,
+		quarantineTTL:     config.OrphanQuarantineTTL,
+		lifecycleErrors:   config.LifecycleErrors,
+		lifecycleEvents:   config.LifecycleEvents,
+		logIndexPolicy:    config.LogIndexPolicy,
+		crashRecorder:     crashRecorder,
+		provisioning:      provisioningRecorder,
+		maxRestarts:       config.MaxInPlaceRestarts,
+		startupReadiness:  config.StartupReadiness,
+		serializeWarming:  config.SerializeCacheWarming,
+		clock:             clock,
+		metronClient:      metronClient,
+		restarts:          make(map[string]inPlaceRestarts),
+		starting:          make(map[string]struct{}),
+		startFailures:     make(map[string]struct{}),
+		ready:             make(map[string]struct{}),
+		initialized:       make(map[string]struct{}),
+		transitions:       make(map[string]time.Time),
+		runs:              make(map[string]struct{}),
+		quarantined:       make(map[string]time.Time),
+		cacheWarming:      make(map[string]map[string]string),
+		held:              newHeldContainers(clock, requeue),
+		instances:         make(map[string]map[string]observedInstance),
 	}
 }
 
@@ -49,6 +137,8 @@ func (p *ordinaryLRPProcessor) Process(logger lager.Logger, container executor.C
 	logger = logger.WithData(lager.Data{"lrp-instance-key": instanceKey})
 
 	lrpContainer := newLRPContainer(lrpKey, instanceKey, container)
+	p.observeInstance(logger, lrpContainer)
+
 	switch lrpContainer.Container.State {
 	case executor.StateReserved:
 		p.processReservedContainer(logger, lrpContainer)
@@ -67,6 +157,10 @@ func (p *ordinaryLRPProcessor) Process(logger lager.Logger, container executor.C
 
 func (p *ordinaryLRPProcessor) processReservedContainer(logger lager.Logger, lrpContainer *lrpContainer) {
 	logger = logger.Session("process-reserved-container")
+	if !p.earlierInstancesReady(logger, lrpContainer) {
+		return
+	}
+
 	ok := p.claimLRPContainer(logger, lrpContainer)
 	if !ok {
 		return
@@ -75,6 +169,34 @@ func (p *ordinaryLRPProcessor) processReservedContainer(logger lager.Logger, lrp
 	desired, err := p.bbsClient.DesiredLRPByProcessGuid(logger, lrpContainer.ProcessGuid)
 	if err != nil {
 		logger.Error("failed-to-fetch-desired", err)
+		if isNotFound(err) {
+			p.abandonUndesired(logger, lrpContainer)
+			err = p.associationStore.Remove(logger, lrpContainer.ActualLRPKey, lrpContainer.ActualLRPInstanceKey)
+			if err != nil {
+				logger.Info("failed-to-remove-actual-lrp", lager.Data{"error": err})
+			}
+		}
+		return
+	}
+
+	err = rep.ValidateActions(desired)
+	if err != nil {
+		logger.Error("failed-action-validation", err)
+		p.rejectContainer(logger, lrpContainer, err.Error())
+		return
+	}
+
+	err = p.dependencyCheck.Check(desired)
+	if err != nil {
+		logger.Error("failed-dependency-check", err)
+		p.rejectContainer(logger, lrpContainer, err.Error())
+		return
+	}
+
+	err = p.privilegeCheck.Check(desired)
+	if err != nil {
+		logger.Error("failed-privilege-check", err)
+		p.rejectContainer(logger, lrpContainer, err.Error())
 		return
 	}
 
@@ -83,25 +205,167 @@ func (p *ordinaryLRPProcessor) processReservedContainer(logger lager.Logger, lrp
 		logger.Error("failed-to-construct-run-request", err)
 		return
 	}
+
+	err = p.enforceMetadataLimit(logger, desired, &runReq)
+	if err != nil {
+		logger.Error("failed-to-enforce-metadata-limit", err)
+		p.rejectContainer(logger, lrpContainer, err.Error())
+		return
+	}
+
+	p.enforceStartTimeout(logger, &runReq)
+	p.envOverlay.Apply(&runReq)
+
+	if !p.resolveLogIndexCollision(logger, lrpContainer, &runReq) {
+		p.rejectContainer(logger, lrpContainer, fmt.Sprintf("log index %d is in use by another instance on the cell", runReq.LogConfig.Index))
+		return
+	}
+
+	if !p.claimCacheWarming(logger, lrpContainer, desired) {
+		return
+	}
+
+	if !p.markRunIssued(logger, lrpContainer.Guid) {
+		return
+	}
+
 	ok = p.containerDelegate.RunContainer(logger, &runReq)
 	if !ok {
-		p.bbsClient.RemoveActualLRP(logger, lrpContainer.ProcessGuid, int(lrpContainer.Index), lrpContainer.ActualLRPInstanceKey)
+		p.incrementCounter(logger, repLRPRunFailed)
+		p.reportLifecycleError(lrpContainer, rep.LifecyclePhaseRun, errRunFailed)
+		p.provisioning.RecordProvisioningOutcome(logger, errRunFailed)
+		p.forgetRun(lrpContainer.Guid)
+		p.releaseCacheWarming(logger, lrpContainer.Guid)
+		err = p.associationStore.Remove(logger, lrpContainer.ActualLRPKey, lrpContainer.ActualLRPInstanceKey)
+		if err != nil {
+			logger.Info("failed-to-remove-actual-lrp", lager.Data{"error": err})
+		}
 		return
 	}
+
+	p.markStarting(lrpContainer.Guid)
+	p.incrementCounter(logger, repLRPRunSucceeded)
+	p.recordTimeToRunning(logger, lrpContainer)
+	p.emitLifecycleEvent(logger, lrpContainer, rep.LifecycleTransitionAllocated)
+}
+
+// rejectContainer fails the placement of the ActualLRP on the cell for the
+// given reason and deletes the container without running it. The ActualLRP
+// is removed rather than crashed, as the reason comes from the cell's own
+// checks: it is auctioned again, and a cell that does not make them may run
+// it, without the rejection using up any of the instance's crashes.
+func (p *ordinaryLRPProcessor) rejectContainer(logger lager.Logger, lrpContainer *lrpContainer, reason string) {
+	logger.Info("rejecting-container", lager.Data{"reason": reason})
+	err := p.associationStore.Remove(logger, lrpContainer.ActualLRPKey, lrpContainer.ActualLRPInstanceKey)
+	if err != nil {
+		logger.Info("failed-to-remove-actual-lrp", lager.Data{"error": err})
+	}
+	p.containerDelegate.DeleteContainer(logger, lrpContainer.Guid)
+	p.forgetContainer(logger, lrpContainer.Guid)
+}
+
+// enforceMetadataLimit passes the desired LRP's annotation through to the
+// container with the rest of the run request's tags, and applies the
+// configured metadata limit to them.
+func (p *ordinaryLRPProcessor) enforceMetadataLimit(logger lager.Logger, desired *models.DesiredLRP, runReq *executor.RunRequest) error {
+	tags := executor.Tags{}
+	for key, value := range runReq.Tags {
+		tags[key] = value
+	}
+	if desired.Annotation != "" {
+		tags[rep.AnnotationTag] = desired.Annotation
+	}
+
+	size := rep.MetadataSize(tags)
+	if p.metadataLimit.MaxBytes != 0 && size > p.metadataLimit.MaxBytes {
+		logger.Info("metadata-exceeds-limit", lager.Data{
+			"metadata-bytes": size,
+			"max-bytes":      p.metadataLimit.MaxBytes,
+			"policy":         p.metadataLimit.Policy,
+		})
+	}
+
+	tags, err := p.metadataLimit.Enforce(tags)
+	if err != nil {
+		return err
+	}
+	runReq.Tags = tags
+
+	return nil
+}
+
+func (p *ordinaryLRPProcessor) enforceStartTimeout(logger lager.Logger, runReq *executor.RunRequest) {
+	requested := time.Duration(runReq.StartTimeoutMs) * time.Millisecond
+	timeout, clamped := p.startTimeout.Apply(requested)
+
+	data := lager.Data{
+		"requested-start-timeout": requested.String(),
+		"default-start-timeout":   p.startTimeout.Default.String(),
+		"start-timeout":           timeout.String(),
+	}
+	switch {
+	case clamped:
+		logger.Info("clamping-start-timeout", data)
+	case requested != 0 && p.startTimeout.Default != 0:
+		logger.Info("overriding-default-start-timeout", data)
+	}
+
+	runReq.StartTimeoutMs = uint(timeout / time.Millisecond)
 }
 
 func (p *ordinaryLRPProcessor) processInitializingContainer(logger lager.Logger, lrpContainer *lrpContainer) {
 	logger = logger.Session("process-initializing-container")
-	p.claimLRPContainer(logger, lrpContainer)
+	if p.abandonStalledSetup(logger, lrpContainer) {
+		return
+	}
+	if p.claimLRPContainer(logger, lrpContainer) {
+		p.markStarting(lrpContainer.Guid)
+	}
 }
 
 func (p *ordinaryLRPProcessor) processCreatedContainer(logger lager.Logger, lrpContainer *lrpContainer) {
 	logger = logger.Session("process-created-container")
-	p.claimLRPContainer(logger, lrpContainer)
+	if p.abandonStalledSetup(logger, lrpContainer) {
+		return
+	}
+	if !p.claimLRPContainer(logger, lrpContainer) {
+		return
+	}
+	p.markStarting(lrpContainer.Guid)
+	p.recordInitialized(logger, lrpContainer, true)
+}
+
+// abandonStalledSetup deletes a container that has been set up for longer
+// than the cell allows and removes its actual LRP, so that the instance can be
+// placed again instead of waiting on the executor forever.
+func (p *ordinaryLRPProcessor) abandonStalledSetup(logger lager.Logger, lrpContainer *lrpContainer) bool {
+	if lrpContainer.AllocatedAt == 0 {
+		return false
+	}
+
+	allocatedAt := time.Unix(0, lrpContainer.AllocatedAt)
+	if !p.startTimeout.SetupExpired(allocatedAt, p.clock.Now()) {
+		return false
+	}
+
+	logger.Info("abandoning-stalled-container-setup", lager.Data{
+		"setup-duration": p.clock.Now().Sub(allocatedAt).String(),
+		"setup-timeout":  p.startTimeout.Setup.String(),
+	})
+
+	p.forgetContainer(logger, lrpContainer.Guid)
+	p.containerDelegate.DeleteContainer(logger, lrpContainer.Guid)
+	err := p.associationStore.Remove(logger, lrpContainer.ActualLRPKey, lrpContainer.ActualLRPInstanceKey)
+	if err != nil {
+		logger.Info("failed-to-remove-actual-lrp", lager.Data{"error": err})
+	}
+
+	return true
 }
 
 func (p *ordinaryLRPProcessor) processRunningContainer(logger lager.Logger, lrpContainer *lrpContainer) {
 	logger = logger.Session("process-running-container")
+	p.releaseCacheWarming(logger, lrpContainer.Guid)
 
 	logger.Debug("extracting-net-info-from-container")
 	netInfo, err := rep.ActualLRPNetInfoFromContainer(lrpContainer.Container)
@@ -115,15 +379,50 @@ func (p *ordinaryLRPProcessor) processRunningContainer(logger lager.Logger, lrpC
 	err = p.bbsClient.StartActualLRP(logger, lrpContainer.ActualLRPKey, lrpContainer.ActualLRPInstanceKey, netInfo)
 	bbsErr := models.ConvertError(err)
 	if bbsErr != nil && bbsErr.Type == models.Error_ActualLRPCannotBeStarted {
-		p.containerDelegate.StopContainer(logger, lrpContainer.Guid)
+		p.handleOrphan(logger, lrpContainer, p.containerDelegate.StopContainer)
+		return
 	}
+	if err != nil {
+		// The container is kept, and the start is made again when it is next
+		// processed.
+		logger.Error("failed-bbs-start-actual-lrp", err)
+		if p.markStartFailed(lrpContainer.Guid) {
+			p.reportLifecycleError(lrpContainer, rep.LifecyclePhaseStart, err)
+		}
+		return
+	}
+
+	p.recordReady(logger, lrpContainer)
 }
 
 func (p *ordinaryLRPProcessor) processCompletedContainer(logger lager.Logger, lrpContainer *lrpContainer) {
 	logger = logger.Session("process-completed-container")
+	if quarantined, expired := p.isQuarantined(lrpContainer.Guid); quarantined {
+		if !expired {
+			logger.Info("skipping-quarantined-container")
+			return
+		}
+		logger.Info("deleting-expired-quarantined-container")
+		p.containerDelegate.DeleteContainer(logger, lrpContainer.Guid)
+		p.forgetContainer(logger, lrpContainer.Guid)
+		return
+	}
+
+	wasStarting := p.isStarting(lrpContainer.Guid)
+	p.forgetReady(lrpContainer.Guid)
+	p.forgetInitialized(lrpContainer.Guid)
+	p.forgetRun(lrpContainer.Guid)
+	p.releaseCacheWarming(logger, lrpContainer.Guid)
+
+	if !lrpContainer.RunResult.Stopped && wasStarting {
+		err := errors.New(lrpContainer.RunResult.FailureReason)
+		p.reportLifecycleError(lrpContainer, rep.LifecyclePhaseInitialize, err)
+		p.provisioning.RecordProvisioningOutcome(logger, err)
+	}
 
 	if lrpContainer.RunResult.Stopped {
-		err := p.bbsClient.RemoveActualLRP(logger, lrpContainer.ProcessGuid, int(lrpContainer.Index), lrpContainer.ActualLRPInstanceKey)
+		p.forgetRestarts(lrpContainer.Guid)
+		err := p.associationStore.Remove(logger, lrpContainer.ActualLRPKey, lrpContainer.ActualLRPInstanceKey)
 		if err != nil {
 			logger.Info("failed-to-remove-actual-lrp", lager.Data{"error": err})
 		}
@@ -132,9 +431,68 @@ func (p *ordinaryLRPProcessor) processCompletedContainer(logger lager.Logger, lr
 		if err != nil {
 			logger.Info("failed-to-crash-actual-lrp", lager.Data{"error": err})
 		}
+		p.crashRecorder.RecordCrash(logger, lrpContainer.ActualLRPKey)
+		p.incrementCounter(logger, repLRPCrashed)
+
+		if p.restartInPlace(logger, lrpContainer) {
+			return
+		}
+		p.forgetRestarts(lrpContainer.Guid)
 	}
 
 	p.containerDelegate.DeleteContainer(logger, lrpContainer.Guid)
+	p.forgetContainer(logger, lrpContainer.Guid)
+}
+
+// inPlaceRestartResetPeriod is how long an instance has to go without
+// crashing before its in-place restarts are counted from zero again.
+const inPlaceRestartResetPeriod = 5 * time.Minute
+
+type inPlaceRestarts struct {
+	count         int
+	lastRestarted time.Time
+}
+
+// restartInPlace replaces a crashed container with a fresh reservation for the
+// same instance, which is then claimed and run like any other reserved
+// container. The crash has already been reported, so the replacement only
+// runs if the BBS lets the instance be claimed again rather than holding it
+// back or placing it elsewhere; otherwise it is deleted as an orphan. It gives
+// up once the instance has been restarted maxRestarts times since it last
+// stayed up for inPlaceRestartResetPeriod, or if the replacement cannot be
+// reserved.
+func (p *ordinaryLRPProcessor) restartInPlace(logger lager.Logger, lrpContainer *lrpContainer) bool {
+	now := p.clock.Now()
+
+	p.restartsLock.Lock()
+	restarts := p.restarts[lrpContainer.Guid]
+	if now.Sub(restarts.lastRestarted) >= inPlaceRestartResetPeriod {
+		restarts.count = 0
+	}
+	if restarts.count >= p.maxRestarts {
+		p.restartsLock.Unlock()
+		return false
+	}
+	restarts.count++
+	restarts.lastRestarted = now
+	p.restarts[lrpContainer.Guid] = restarts
+	p.restartsLock.Unlock()
+
+	logger = logger.Session("restart-in-place", lager.Data{"restarts": restarts.count})
+
+	if !p.containerDelegate.DeleteContainer(logger, lrpContainer.Guid) {
+		return false
+	}
+
+	resource := lrpContainer.Container.Resource
+	req := executor.NewAllocationRequest(lrpContainer.Guid, &resource, lrpContainer.Tags)
+	return p.containerDelegate.AllocateContainer(logger, &req)
+}
+
+func (p *ordinaryLRPProcessor) forgetRestarts(guid string) {
+	p.restartsLock.Lock()
+	defer p.restartsLock.Unlock()
+	delete(p.restarts, guid)
 }
 
 func (p *ordinaryLRPProcessor) processInvalidContainer(logger lager.Logger, lrpContainer *lrpContainer) {
@@ -143,11 +501,14 @@ func (p *ordinaryLRPProcessor) processInvalidContainer(logger lager.Logger, lrpC
 }
 
 func (p *ordinaryLRPProcessor) claimLRPContainer(logger lager.Logger, lrpContainer *lrpContainer) bool {
-	err := p.bbsClient.ClaimActualLRP(logger, lrpContainer.ProcessGuid, int(lrpContainer.Index), lrpContainer.ActualLRPInstanceKey)
+	err := p.associationStore.Claim(logger, lrpContainer.ActualLRPKey, lrpContainer.ActualLRPInstanceKey)
 	bbsErr := models.ConvertError(err)
 	if err != nil {
-		if bbsErr.Type == models.Error_ActualLRPCannotBeClaimed {
-			p.containerDelegate.DeleteContainer(logger, lrpContainer.Guid)
+		switch bbsErr.Type {
+		case models.Error_ActualLRPCannotBeClaimed:
+			p.handleOrphan(logger, lrpContainer, p.containerDelegate.DeleteContainer)
+		case models.Error_ResourceNotFound:
+			p.abandonUndesired(logger, lrpContainer)
 		}
 		return false
 	}
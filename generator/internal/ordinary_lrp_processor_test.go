@@ -4,16 +4,22 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"code.cloudfoundry.org/bbs/fake_bbs"
 	"code.cloudfoundry.org/bbs/models"
 	"code.cloudfoundry.org/bbs/models/test/model_helpers"
+	"code.cloudfoundry.org/clock/fakeclock"
 	"code.cloudfoundry.org/executor"
+	mfakes "code.cloudfoundry.org/go-loggregator/loggregator_v2/fakes"
+	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
 	"code.cloudfoundry.org/rep/generator/internal"
 	"code.cloudfoundry.org/rep/generator/internal/fake_internal"
+	"code.cloudfoundry.org/rep/repfakes"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -24,19 +30,35 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 	const expectedCellID = "cell-id"
 
 	var (
-		processor          internal.LRPProcessor
-		logger             *lagertest.TestLogger
-		bbsClient          *fake_bbs.FakeInternalClient
-		containerDelegate  *fake_internal.FakeContainerDelegate
-		evacuationReporter *fake_evacuation_context.FakeEvacuationReporter
+		processor            internal.LRPProcessor
+		logger               *lagertest.TestLogger
+		bbsClient            *fake_bbs.FakeInternalClient
+		containerDelegate    *fake_internal.FakeContainerDelegate
+		evacuationReporter   *fake_evacuation_context.FakeEvacuationReporter
+		crashRecorder        *repfakes.FakeCrashRecorder
+		provisioningRecorder *repfakes.FakeProvisioningRecorder
+		fakeClock            *fakeclock.FakeClock
+		fakeMetronClient     *mfakes.FakeClient
+		config               internal.LRPProcessorConfig
+		requeue              chan string
 	)
 
+	buildProcessor := func() internal.LRPProcessor {
+		return internal.NewLRPProcessor(bbsClient, internal.NewBBSAssociationStore(bbsClient), containerDelegate, evacuationReporter, crashRecorder, provisioningRecorder, fakeClock, fakeMetronClient, requeue, config)
+	}
+
 	BeforeEach(func() {
 		bbsClient = new(fake_bbs.FakeInternalClient)
 		containerDelegate = new(fake_internal.FakeContainerDelegate)
 		evacuationReporter = &fake_evacuation_context.FakeEvacuationReporter{}
 		evacuationReporter.EvacuatingReturns(false)
-		processor = internal.NewLRPProcessor(bbsClient, containerDelegate, expectedCellID, evacuationReporter, 124)
+		crashRecorder = new(repfakes.FakeCrashRecorder)
+		provisioningRecorder = new(repfakes.FakeProvisioningRecorder)
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		fakeMetronClient = new(mfakes.FakeClient)
+		requeue = make(chan string, 10)
+		config = internal.LRPProcessorConfig{CellID: expectedCellID, EvacuationTTLInSeconds: 124}
+		processor = buildProcessor()
 		logger = lagertest.NewTestLogger("test")
 	})
 
@@ -51,6 +73,24 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 			expectedSessionName string
 		)
 
+		rejectionReason := func() string {
+			for _, log := range logger.Logs() {
+				if strings.HasSuffix(log.Message, ".rejecting-container") {
+					return log.Data["reason"].(string)
+				}
+			}
+			return ""
+		}
+
+		expectActualLRPRemoved := func() {
+			Expect(bbsClient.CrashActualLRPCallCount()).To(Equal(0))
+			Expect(bbsClient.RemoveActualLRPCallCount()).To(Equal(1))
+			_, processGuid, index, instanceKey := bbsClient.RemoveActualLRPArgsForCall(0)
+			Expect(processGuid).To(Equal(expectedLrpKey.ProcessGuid))
+			Expect(int32(index)).To(Equal(expectedLrpKey.Index))
+			Expect(*instanceKey).To(Equal(expectedInstanceKey))
+		}
+
 		BeforeEach(func() {
 			desiredLRP = model_helpers.NewValidDesiredLRP("process-guid")
 			expectedLrpKey = models.NewActualLRPKey("process-guid", 2, "domain")
@@ -95,6 +135,111 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 					Expect(*instanceKey).To(Equal(expectedInstanceKey))
 				})
 
+				Context("when the LRP is no longer desired once the actual LRP is claimed", func() {
+					BeforeEach(func() {
+						bbsClient.DesiredLRPByProcessGuidReturns(nil, models.ErrResourceNotFound)
+					})
+
+					It("deletes the container and removes the actual LRP without running it", func() {
+						Expect(containerDelegate.RunContainerCallCount()).To(Equal(0))
+
+						Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+						_, containerGuid := containerDelegate.DeleteContainerArgsForCall(0)
+						Expect(containerGuid).To(Equal(container.Guid))
+
+						Expect(bbsClient.RemoveActualLRPCallCount()).To(Equal(1))
+						_, processGuid, index, instanceKey := bbsClient.RemoveActualLRPArgsForCall(0)
+						Expect(processGuid).To(Equal(expectedLrpKey.ProcessGuid))
+						Expect(int32(index)).To(Equal(expectedLrpKey.Index))
+						Expect(*instanceKey).To(Equal(expectedInstanceKey))
+					})
+				})
+
+				Context("when instances are started in index order", func() {
+					var siblings []executor.Container
+
+					sibling := func(index int32, state executor.State) executor.Container {
+						key := models.NewActualLRPKey(expectedLrpKey.ProcessGuid, index, expectedLrpKey.Domain)
+						instanceKey := models.NewActualLRPInstanceKey(fmt.Sprintf("instance-guid-%d", index), expectedCellID)
+						c := newLRPContainer(key, instanceKey, expectedNetInfo)
+						c.Tags[rep.LifecycleTag] = rep.LRPLifecycle
+						c.State = state
+						return c
+					}
+
+					BeforeEach(func() {
+						config.StartupReadiness = executor.StateRunning
+						processor = buildProcessor()
+						siblings = []executor.Container{
+							sibling(0, executor.StateRunning),
+							sibling(1, executor.StateCreated),
+							sibling(3, executor.StateReserved),
+						}
+					})
+
+					Context("when an earlier instance has not reached the readiness state", func() {
+						BeforeEach(func() {
+							processor.ObserveContainers(logger, append(siblings, container))
+						})
+
+						It("neither claims nor runs the container", func() {
+							Expect(bbsClient.ClaimActualLRPCallCount()).To(Equal(0))
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(0))
+							Expect(logger).To(Say(expectedSessionName + ".waiting-for-earlier-instance"))
+						})
+
+						It("does not list the containers itself", func() {
+							Expect(containerDelegate.ListContainersCallCount()).To(Equal(0))
+						})
+
+						It("requeues the container once the earlier instance changes state", func() {
+							Expect(requeue).To(BeEmpty())
+
+							processor.Process(logger, siblings[0])
+							Expect(requeue).To(BeEmpty())
+
+							siblings[1].State = executor.StateRunning
+							processor.Process(logger, siblings[1])
+							Expect(requeue).To(Receive(Equal(container.Guid)))
+
+							processor.Process(logger, container)
+							Expect(bbsClient.ClaimActualLRPCallCount()).To(Equal(1))
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+						})
+
+						It("lets the container run once it has been held back for too long", func() {
+							fakeClock.Increment(30 * time.Second)
+							processor.Process(logger, container)
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+							Expect(logger).To(Say("giving-up-waiting-for-earlier-instance"))
+						})
+					})
+
+					Context("when every earlier instance is ready or completed", func() {
+						BeforeEach(func() {
+							siblings[1].State = executor.StateCompleted
+							processor.ObserveContainers(logger, append(siblings, container))
+						})
+
+						It("claims and runs the container", func() {
+							Expect(bbsClient.ClaimActualLRPCallCount()).To(Equal(1))
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+						})
+					})
+
+					Context("when an earlier instance has gone away", func() {
+						BeforeEach(func() {
+							processor.ObserveContainers(logger, append(siblings, container))
+							processor.ObserveContainers(logger, []executor.Container{siblings[0], siblings[2], container})
+						})
+
+						It("claims and runs the container", func() {
+							Expect(bbsClient.ClaimActualLRPCallCount()).To(Equal(1))
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+						})
+					})
+				})
+
 				Context("when claiming fails because ErrActualLRPCannotBeClaimed", func() {
 					BeforeEach(func() {
 						bbsClient.ClaimActualLRPReturns(models.NewError(
@@ -113,6 +258,65 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 					It("does not try to run the container", func() {
 						Expect(containerDelegate.RunContainerCallCount()).To(Equal(0))
 					})
+
+					Context("when an orphan handler is given", func() {
+						var (
+							action       rep.OrphanAction
+							orphanGuid   string
+							orphanTags   executor.Tags
+							handlerCalls int
+						)
+
+						BeforeEach(func() {
+							handlerCalls = 0
+							handler := func(_ lager.Logger, guid string, tags executor.Tags) rep.OrphanAction {
+								handlerCalls++
+								orphanGuid, orphanTags = guid, tags
+								return action
+							}
+							config.OrphanHandler = handler
+							processor = buildProcessor()
+						})
+
+						Context("when it returns delete", func() {
+							BeforeEach(func() {
+								action = rep.OrphanActionDelete
+							})
+
+							It("passes it the container guid and tags", func() {
+								Expect(handlerCalls).To(Equal(1))
+								Expect(orphanGuid).To(Equal(container.Guid))
+								Expect(orphanTags).To(Equal(container.Tags))
+							})
+
+							It("deletes the container", func() {
+								Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+								Expect(containerDelegate.StopContainerCallCount()).To(Equal(0))
+							})
+						})
+
+						Context("when it returns keep", func() {
+							BeforeEach(func() {
+								action = rep.OrphanActionKeep
+							})
+
+							It("leaves the container alone", func() {
+								Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(0))
+								Expect(containerDelegate.StopContainerCallCount()).To(Equal(0))
+							})
+						})
+
+						Context("when it returns quarantine", func() {
+							BeforeEach(func() {
+								action = rep.OrphanActionQuarantine
+							})
+
+							It("deletes the reserved container, as nothing has run in it", func() {
+								Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+								Expect(containerDelegate.StopContainerCallCount()).To(Equal(0))
+							})
+						})
+					})
 				})
 
 				Context("when claiming fails for an unknown reason", func() {
@@ -135,12 +339,49 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 
 						expectedRunRequest, err := rep.NewRunRequestFromDesiredLRP(container.Guid, desiredLRP, &expectedLrpKey, &expectedInstanceKey)
 						Expect(err).NotTo(HaveOccurred())
+						if desiredLRP.Annotation != "" {
+							expectedRunRequest.Tags[rep.AnnotationTag] = desiredLRP.Annotation
+						}
 
 						delegateLogger, runRequest := containerDelegate.RunContainerArgsForCall(0)
 						Expect(*runRequest).To(Equal(expectedRunRequest))
 						Expect(delegateLogger.SessionName()).To(Equal(expectedSessionName))
 					})
 
+					Context("when running succeeds", func() {
+						BeforeEach(func() {
+							container.AllocatedAt = fakeClock.Now().Add(-5 * time.Second).UnixNano()
+							containerDelegate.RunContainerReturns(true)
+						})
+
+						It("reports the time from allocation to running", func() {
+							Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(1))
+							name, duration := fakeMetronClient.SendDurationArgsForCall(0)
+							Expect(name).To(Equal("RepLRPTimeToRunning"))
+							Expect(duration).To(Equal(5 * time.Second))
+						})
+
+						It("counts the run", func() {
+							Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+							Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("RepLRPRunSucceeded"))
+						})
+
+						It("does not run the container again if it is still seen as reserved", func() {
+							processor.Process(logger, container)
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+							Expect(logger).To(Say("skipping-duplicate-run"))
+						})
+
+						It("runs the container again once it has completed and been reserved anew", func() {
+							completed := container
+							completed.State = executor.StateCompleted
+							processor.Process(logger, completed)
+
+							processor.Process(logger, container)
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(2))
+						})
+					})
+
 					Context("when running fails", func() {
 						BeforeEach(func() {
 							containerDelegate.RunContainerReturns(false)
@@ -154,6 +395,580 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 							Expect(int32(index)).To(Equal(expectedLrpKey.Index))
 							Expect(*instanceKey).To(Equal(expectedInstanceKey))
 						})
+
+						It("counts the failed run", func() {
+							Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+							Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("RepLRPRunFailed"))
+						})
+
+						It("records the failure to provision the instance", func() {
+							Expect(provisioningRecorder.RecordProvisioningOutcomeCallCount()).To(Equal(1))
+							_, err := provisioningRecorder.RecordProvisioningOutcomeArgsForCall(0)
+							Expect(err).To(HaveOccurred())
+						})
+
+						It("has the container delegate log the failure with the container guid and LRP key", func() {
+							delegateLogger, _ := containerDelegate.RunContainerArgsForCall(0)
+							delegateLogger.Error("failed-running-container", errors.New("boom"))
+
+							logs := logger.Logs()
+							failure := logs[len(logs)-1]
+							Expect(failure.Message).To(HaveSuffix("failed-running-container"))
+							Expect(failure.Data["container-guid"]).To(Equal(container.Guid))
+							Expect(failure.Data["lrp-key"]).To(HaveKeyWithValue("process_guid", expectedLrpKey.ProcessGuid))
+						})
+
+						It("forgets the run, so a container reserved again is run", func() {
+							processor.Process(logger, container)
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(2))
+						})
+
+						Context("when the removal fails", func() {
+							BeforeEach(func() {
+								bbsClient.RemoveActualLRPReturns(errors.New("whoops"))
+							})
+
+							It("logs the failure", func() {
+								Expect(logger).To(Say("failed-to-remove-actual-lrp"))
+							})
+						})
+					})
+
+					Context("when the LRP has no action", func() {
+						BeforeEach(func() {
+							desiredLRP.Action = nil
+						})
+
+						It("removes the actual LRP and deletes the container without running it", func() {
+							Expect(logger).To(Say("failed-action-validation"))
+							expectActualLRPRemoved()
+							Expect(rejectionReason()).To(Equal("desired LRP has no action"))
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(0))
+							Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+						})
+					})
+
+					Context("when a run action has no path", func() {
+						BeforeEach(func() {
+							desiredLRP.Action = models.WrapAction(&models.RunAction{User: "vcap"})
+						})
+
+						It("removes the actual LRP and deletes the container without running it", func() {
+							expectActualLRPRemoved()
+							reason := rejectionReason()
+							Expect(reason).To(HavePrefix("invalid action: "))
+							Expect(reason).To(ContainSubstring(models.ErrInvalidField{Field: "path"}.Error()))
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(0))
+							Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+						})
+					})
+
+					Context("when a run action uses a dependency path that no download populates", func() {
+						BeforeEach(func() {
+							desiredLRP.CachedDependencies = nil
+							desiredLRP.Setup = nil
+							desiredLRP.Action = models.WrapAction(&models.RunAction{Path: "/tmp/lifecycle/launcher", User: "vcap"})
+							config.DependencyCheck = rep.DependencyCheck{Paths: []string{"/tmp/lifecycle"}}
+							processor = buildProcessor()
+						})
+
+						It("removes the actual LRP so that it is placed again", func() {
+							expectActualLRPRemoved()
+							Expect(rejectionReason()).To(ContainSubstring("/tmp/lifecycle/launcher"))
+						})
+
+						It("deletes the container without running it", func() {
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(0))
+							Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+							_, guid := containerDelegate.DeleteContainerArgsForCall(0)
+							Expect(guid).To(Equal(container.Guid))
+						})
+					})
+
+					Context("when the LRP breaks the cell's privilege policy", func() {
+						BeforeEach(func() {
+							desiredLRP.Privileged = true
+							config.PrivilegeCheck = rep.PrivilegeCheck{DenyPrivileged: true}
+							processor = buildProcessor()
+						})
+
+						It("removes the actual LRP and deletes the container without running it", func() {
+							expectActualLRPRemoved()
+							Expect(rejectionReason()).To(ContainSubstring("privileged containers are not allowed"))
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(0))
+							Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+						})
+					})
+
+					Context("when the desired LRP's metadata exceeds the limit", func() {
+						BeforeEach(func() {
+							desiredLRP.Annotation = "a-very-long-annotation"
+							config.MetadataLimit = rep.MetadataLimit{MaxBytes: len(rep.AnnotationTag) + len(desiredLRP.Annotation) - 1}
+						})
+
+						Context("when the policy is to truncate", func() {
+							BeforeEach(func() {
+								config.MetadataLimit.Policy = rep.MetadataLimitPolicyTruncate
+								processor = buildProcessor()
+							})
+
+							It("runs the container without the annotation", func() {
+								Expect(logger).To(Say("metadata-exceeds-limit"))
+								Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+								_, runRequest := containerDelegate.RunContainerArgsForCall(0)
+								Expect(runRequest.Tags).NotTo(HaveKey(rep.AnnotationTag))
+							})
+						})
+
+						Context("when the policy is to reject", func() {
+							BeforeEach(func() {
+								config.MetadataLimit.Policy = rep.MetadataLimitPolicyReject
+								processor = buildProcessor()
+							})
+
+							It("removes the actual LRP and deletes the container without running it", func() {
+								Expect(logger).To(Say("metadata-exceeds-limit"))
+								expectActualLRPRemoved()
+								Expect(rejectionReason()).To(Equal(rep.ErrMetadataTooLarge.Error()))
+								Expect(containerDelegate.RunContainerCallCount()).To(Equal(0))
+								Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+							})
+						})
+
+						Context("when the metadata fits exactly", func() {
+							BeforeEach(func() {
+								config.MetadataLimit = rep.MetadataLimit{
+									MaxBytes: len(rep.AnnotationTag) + len(desiredLRP.Annotation),
+									Policy:   rep.MetadataLimitPolicyReject,
+								}
+								processor = buildProcessor()
+							})
+
+							It("runs the container with the annotation", func() {
+								Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+								_, runRequest := containerDelegate.RunContainerArgsForCall(0)
+								Expect(runRequest.Tags).To(HaveKeyWithValue(rep.AnnotationTag, desiredLRP.Annotation))
+							})
+						})
+					})
+
+					Context("when the container goes through its life", func() {
+						BeforeEach(func() {
+							containerDelegate.RunContainerReturns(true)
+						})
+
+						It("tracks the container until it is stopped and deleted", func() {
+							Expect(processor.TrackedContainers()).To(Equal(1))
+
+							created := container
+							created.State = executor.StateCreated
+							processor.Process(logger, created)
+
+							running := container
+							running.State = executor.StateRunning
+							processor.Process(logger, running)
+							Expect(processor.TrackedContainers()).To(Equal(1))
+
+							completed := container
+							completed.State = executor.StateCompleted
+							completed.RunResult.Stopped = true
+							processor.Process(logger, completed)
+							Expect(processor.TrackedContainers()).To(BeZero())
+						})
+
+						It("stops tracking a container that crashed", func() {
+							running := container
+							running.State = executor.StateRunning
+							processor.Process(logger, running)
+
+							crashed := container
+							crashed.State = executor.StateCompleted
+							crashed.RunResult.FailureReason = "crashed"
+							processor.Process(logger, crashed)
+							Expect(processor.TrackedContainers()).To(BeZero())
+						})
+
+						It("stops tracking a container that leaves the cell without being deleted", func() {
+							running := container
+							running.State = executor.StateRunning
+							processor.Process(logger, running)
+
+							processor.ObserveContainers(logger, []executor.Container{running})
+							Expect(processor.TrackedContainers()).To(Equal(1))
+
+							processor.ObserveContainers(logger, nil)
+							Expect(processor.TrackedContainers()).To(BeZero())
+							Expect(logger).To(Say("forgetting-unlisted-container"))
+						})
+					})
+
+					Context("when lifecycle events are streamed", func() {
+						var events *rep.LifecycleEvents
+
+						BeforeEach(func() {
+							events = rep.NewLifecycleEvents(10)
+							containerDelegate.RunContainerReturns(true)
+							config.LifecycleEvents = events
+							processor = buildProcessor()
+						})
+
+						It("emits the allocated, initialized and running transitions in order, once each", func() {
+							created := container
+							created.State = executor.StateCreated
+							processor.Process(logger, created)
+							processor.Process(logger, created)
+
+							running := container
+							running.State = executor.StateRunning
+							processor.Process(logger, running)
+							processor.Process(logger, running)
+
+							var transitions []rep.LifecycleTransition
+							for len(events.Events()) > 0 {
+								event := <-events.Events()
+								Expect(event.ProcessGuid).To(Equal(expectedLrpKey.ProcessGuid))
+								Expect(event.Index).To(Equal(expectedLrpKey.Index))
+								Expect(event.ContainerGuid).To(Equal(container.Guid))
+								Expect(event.Time).To(Equal(fakeClock.Now()))
+								transitions = append(transitions, event.Transition)
+							}
+							Expect(transitions).To(Equal([]rep.LifecycleTransition{
+								rep.LifecycleTransitionAllocated,
+								rep.LifecycleTransitionInitialized,
+								rep.LifecycleTransitionRunning,
+							}))
+						})
+
+						It("still emits the initialized transition before running when the container is not seen created", func() {
+							running := container
+							running.State = executor.StateRunning
+							processor.Process(logger, running)
+
+							var transitions []rep.LifecycleTransition
+							for len(events.Events()) > 0 {
+								transitions = append(transitions, (<-events.Events()).Transition)
+							}
+							Expect(transitions).To(Equal([]rep.LifecycleTransition{
+								rep.LifecycleTransitionAllocated,
+								rep.LifecycleTransitionInitialized,
+								rep.LifecycleTransitionRunning,
+							}))
+						})
+
+						Context("when each phase takes time", func() {
+							BeforeEach(func() {
+								container.AllocatedAt = fakeClock.Now().Add(-time.Second).UnixNano()
+							})
+
+							It("reports how long each phase took on the events and as metrics", func() {
+								fakeClock.Increment(2 * time.Second)
+								created := container
+								created.State = executor.StateCreated
+								processor.Process(logger, created)
+
+								fakeClock.Increment(3 * time.Second)
+								running := container
+								running.State = executor.StateRunning
+								processor.Process(logger, running)
+
+								durations := map[rep.LifecycleTransition]time.Duration{}
+								for len(events.Events()) > 0 {
+									event := <-events.Events()
+									durations[event.Transition] = event.Duration
+								}
+								Expect(durations).To(Equal(map[rep.LifecycleTransition]time.Duration{
+									rep.LifecycleTransitionAllocated:   time.Second,
+									rep.LifecycleTransitionInitialized: 2 * time.Second,
+									rep.LifecycleTransitionRunning:     3 * time.Second,
+								}))
+
+								metrics := map[string]time.Duration{}
+								for i := 0; i < fakeMetronClient.SendDurationCallCount(); i++ {
+									name, duration := fakeMetronClient.SendDurationArgsForCall(i)
+									metrics[name] = duration
+								}
+								Expect(metrics).To(HaveKeyWithValue("RepLRPTimeToRunning", time.Second))
+								Expect(metrics).To(HaveKeyWithValue("RepLRPInitializationDuration", 2*time.Second))
+								Expect(metrics).To(HaveKeyWithValue("RepLRPRunDuration", 3*time.Second))
+							})
+
+							It("reports neither the initialization nor the run when the container is not seen created", func() {
+								fakeClock.Increment(5 * time.Second)
+								running := container
+								running.State = executor.StateRunning
+								processor.Process(logger, running)
+
+								durations := map[rep.LifecycleTransition]time.Duration{}
+								for len(events.Events()) > 0 {
+									event := <-events.Events()
+									durations[event.Transition] = event.Duration
+								}
+								Expect(durations).To(Equal(map[rep.LifecycleTransition]time.Duration{
+									rep.LifecycleTransitionAllocated:   time.Second,
+									rep.LifecycleTransitionInitialized: 0,
+									rep.LifecycleTransitionRunning:     0,
+								}))
+
+								for i := 0; i < fakeMetronClient.SendDurationCallCount(); i++ {
+									name, _ := fakeMetronClient.SendDurationArgsForCall(i)
+									Expect(name).NotTo(Equal("RepLRPInitializationDuration"))
+									Expect(name).NotTo(Equal("RepLRPRunDuration"))
+								}
+							})
+						})
+
+						Context("when nothing reads the events", func() {
+							BeforeEach(func() {
+								events = rep.NewLifecycleEvents(0)
+								config.LifecycleEvents = events
+								processor = buildProcessor()
+							})
+
+							It("drops them without holding up the container", func() {
+								running := container
+								running.State = executor.StateRunning
+
+								done := make(chan struct{})
+								go func() {
+									defer close(done)
+									processor.Process(logger, running)
+								}()
+								Eventually(done).Should(BeClosed())
+
+								Expect(bbsClient.StartActualLRPCallCount()).To(Equal(1))
+								Expect(events.Dropped()).To(BeEquivalentTo(3))
+							})
+						})
+					})
+
+					Context("when cache warming is serialized", func() {
+						var siblings []executor.Container
+
+						BeforeEach(func() {
+							desiredLRP.CachedDependencies = []*models.CachedDependency{
+								{From: "http://example.com/lifecycle.tgz", To: "/tmp/lifecycle", CacheKey: "lifecycle"},
+							}
+							containerDelegate.RunContainerReturns(true)
+							config.SerializeCacheWarming = true
+							processor = buildProcessor()
+
+							siblings = nil
+							for i := int32(3); i < 6; i++ {
+								key := models.NewActualLRPKey(expectedLrpKey.ProcessGuid, i, expectedLrpKey.Domain)
+								instanceKey := models.NewActualLRPInstanceKey(fmt.Sprintf("instance-guid-%d", i), expectedCellID)
+								sibling := newLRPContainer(key, instanceKey, expectedNetInfo)
+								sibling.State = executor.StateReserved
+								siblings = append(siblings, sibling)
+							}
+						})
+
+						It("holds back the instances sharing the cache key until the first has finished initializing", func() {
+							initializing := container
+							initializing.State = executor.StateInitializing
+							containerDelegate.GetContainerReturns(initializing, true)
+
+							for _, sibling := range siblings {
+								processor.Process(logger, sibling)
+							}
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+							Expect(logger).To(Say("waiting-for-cache-warming"))
+
+							running := container
+							running.State = executor.StateRunning
+							processor.Process(logger, running)
+
+							for _, sibling := range siblings {
+								processor.Process(logger, sibling)
+							}
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(1 + len(siblings)))
+
+							_, firstRun := containerDelegate.RunContainerArgsForCall(0)
+							Expect(firstRun.Guid).To(Equal(container.Guid))
+						})
+
+						It("releases the cache key when the first instance has gone away", func() {
+							containerDelegate.GetContainerReturns(executor.Container{}, false)
+
+							processor.Process(logger, siblings[0])
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(2))
+						})
+
+						It("does not hold back instances with a different cache key", func() {
+							desiredLRP.CachedDependencies[0].CacheKey = "other-lifecycle"
+							initializing := container
+							initializing.State = executor.StateInitializing
+							containerDelegate.GetContainerReturns(initializing, true)
+
+							processor.Process(logger, siblings[0])
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(2))
+						})
+
+						It("requeues the held instances once the first starts running", func() {
+							initializing := container
+							initializing.State = executor.StateInitializing
+							containerDelegate.GetContainerReturns(initializing, true)
+
+							for _, sibling := range siblings {
+								processor.Process(logger, sibling)
+							}
+							Expect(requeue).To(BeEmpty())
+
+							running := container
+							running.State = executor.StateRunning
+							processor.Process(logger, running)
+
+							requeued := []string{}
+							for len(requeue) > 0 {
+								requeued = append(requeued, <-requeue)
+							}
+							Expect(requeued).To(ConsistOf(siblings[0].Guid, siblings[1].Guid, siblings[2].Guid))
+						})
+
+						It("does not hold back instances of another process sharing the cache key", func() {
+							initializing := container
+							initializing.State = executor.StateInitializing
+							containerDelegate.GetContainerReturns(initializing, true)
+
+							otherKey := models.NewActualLRPKey("other-process-guid", 0, expectedLrpKey.Domain)
+							other := newLRPContainer(otherKey, models.NewActualLRPInstanceKey("other-instance-guid", expectedCellID), expectedNetInfo)
+							other.State = executor.StateReserved
+
+							processor.Process(logger, other)
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(2))
+						})
+
+						It("lets an instance run once it has been held back for too long", func() {
+							initializing := container
+							initializing.State = executor.StateInitializing
+							containerDelegate.GetContainerReturns(initializing, true)
+
+							processor.Process(logger, siblings[0])
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+
+							fakeClock.Increment(30 * time.Second)
+							processor.Process(logger, siblings[0])
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(2))
+							Expect(logger).To(Say("giving-up-waiting-for-cache-warming"))
+						})
+					})
+
+					Context("when the cell adds environment variables", func() {
+						BeforeEach(func() {
+							desiredLRP.Setup = nil
+							desiredLRP.Action = models.WrapAction(&models.RunAction{
+								Path: "/bin/sh",
+								User: "vcap",
+								Env:  []*models.EnvironmentVariable{{Name: "THE_KEY", Value: "THE_VALUE"}},
+							})
+							overlay := rep.NewEnvOverlay(map[string]string{"CELL_ID": expectedCellID, "THE_KEY": "the-cell-value"})
+							config.EnvOverlay = overlay
+							processor = buildProcessor()
+						})
+
+						It("runs the container with the cell's variables merged into the run action, keeping the LRP's values", func() {
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+							_, runRequest := containerDelegate.RunContainerArgsForCall(0)
+							run := runRequest.Action.GetRunAction()
+							Expect(run.Env).To(ConsistOf(
+								&models.EnvironmentVariable{Name: "THE_KEY", Value: "THE_VALUE"},
+								&models.EnvironmentVariable{Name: "CELL_ID", Value: expectedCellID},
+							))
+						})
+					})
+
+					Context("when the cell bounds the start timeout", func() {
+						BeforeEach(func() {
+							desiredLRP.StartTimeoutMs = 10 * 60 * 1000
+							config.StartTimeout = rep.StartTimeoutLimit{Default: time.Minute, Max: 5 * time.Minute}
+							processor = buildProcessor()
+						})
+
+						It("runs the container with the start timeout clamped to the maximum", func() {
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+							_, runRequest := containerDelegate.RunContainerArgsForCall(0)
+							Expect(runRequest.StartTimeoutMs).To(BeEquivalentTo(5 * 60 * 1000))
+							Expect(logger).To(Say("clamping-start-timeout"))
+						})
+
+						Context("when the LRP does not ask for a start timeout", func() {
+							BeforeEach(func() {
+								desiredLRP.StartTimeoutMs = 0
+							})
+
+							It("runs the container with the default start timeout", func() {
+								Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+								_, runRequest := containerDelegate.RunContainerArgsForCall(0)
+								Expect(runRequest.StartTimeoutMs).To(BeEquivalentTo(60 * 1000))
+							})
+						})
+					})
+
+					Context("when another instance on the cell uses the same log index", func() {
+						var others []executor.Container
+
+						newProcessor := func(policy rep.LogIndexCollisionPolicy) internal.LRPProcessor {
+							config.LogIndexPolicy = policy
+							processor := buildProcessor()
+							processor.ObserveContainers(logger, others)
+							return processor
+						}
+
+						BeforeEach(func() {
+							other := func(index int, state executor.State) executor.Container {
+								c := newLRPContainer(expectedLrpKey, models.NewActualLRPInstanceKey(fmt.Sprintf("other-guid-%d", index), expectedCellID), expectedNetInfo)
+								c.Tags[rep.LifecycleTag] = rep.LRPLifecycle
+								c.State = state
+								c.LogConfig.Index = index
+								return c
+							}
+							others = []executor.Container{
+								other(0, executor.StateRunning),
+								other(int(expectedLrpKey.Index), executor.StateRunning),
+								other(1, executor.StateCompleted),
+								container,
+							}
+						})
+
+						Context("with the reject policy", func() {
+							BeforeEach(func() {
+								processor = newProcessor(rep.LogIndexCollisionPolicyReject)
+							})
+
+							It("removes the actual LRP and deletes the container without running it", func() {
+								Expect(containerDelegate.RunContainerCallCount()).To(Equal(0))
+								expectActualLRPRemoved()
+								Expect(rejectionReason()).To(ContainSubstring("log index 2"))
+								Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+								Expect(logger).To(Say("log-index-collision"))
+							})
+
+							It("does not list the containers itself", func() {
+								Expect(containerDelegate.ListContainersCallCount()).To(Equal(0))
+							})
+
+							Context("when the other instance has completed", func() {
+								BeforeEach(func() {
+									others[1].State = executor.StateCompleted
+									processor = newProcessor(rep.LogIndexCollisionPolicyReject)
+								})
+
+								It("runs the container", func() {
+									Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+								})
+							})
+						})
+
+						Context("with no policy", func() {
+							BeforeEach(func() {
+								processor = newProcessor(rep.LogIndexCollisionPolicyNone)
+							})
+
+							It("runs the container with its own log index", func() {
+								Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+								_, runRequest := containerDelegate.RunContainerArgsForCall(0)
+								Expect(runRequest.LogConfig.Index).To(Equal(int(expectedLrpKey.Index)))
+							})
+						})
 					})
 				})
 
@@ -179,6 +994,28 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 						})
 					})
 
+					Context("when the claim fails because the LRP is no longer desired", func() {
+						var events *rep.LifecycleEvents
+
+						BeforeEach(func() {
+							events = rep.NewLifecycleEvents(10)
+							config.LifecycleEvents = events
+							processor = buildProcessor()
+							bbsClient.ClaimActualLRPReturns(models.ErrResourceNotFound)
+						})
+
+						It("deletes the container without starting or initializing it", func() {
+							Expect(logger).To(Say("abandoning-container-for-undesired-lrp"))
+							Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+							_, containerGuid := containerDelegate.DeleteContainerArgsForCall(0)
+							Expect(containerGuid).To(Equal(container.Guid))
+
+							Expect(bbsClient.StartActualLRPCallCount()).To(Equal(0))
+							Expect(events.Events()).NotTo(Receive())
+							Expect(processor.TrackedContainers()).To(Equal(0))
+						})
+					})
+
 					Context("when the claim fails for an unknown reason", func() {
 						BeforeEach(func() {
 							bbsClient.ClaimActualLRPReturns(errors.New("boom"))
@@ -197,6 +1034,45 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 					})
 
 					itClaimsTheLRPOrDeletesTheContainer(sessionPrefix + "process-initializing-container")
+
+					Context("when the cell bounds the container setup time", func() {
+						BeforeEach(func() {
+							config.StartTimeout = rep.StartTimeoutLimit{Setup: 5 * time.Minute}
+							processor = buildProcessor()
+						})
+
+						Context("and the container has been set up for longer", func() {
+							BeforeEach(func() {
+								container.AllocatedAt = fakeClock.Now().Add(-6 * time.Minute).UnixNano()
+							})
+
+							It("deletes the container and removes the actual LRP without claiming it", func() {
+								Expect(logger).To(Say("abandoning-stalled-container-setup"))
+								Expect(bbsClient.ClaimActualLRPCallCount()).To(Equal(0))
+
+								Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+								_, guid := containerDelegate.DeleteContainerArgsForCall(0)
+								Expect(guid).To(Equal(container.Guid))
+
+								Expect(bbsClient.RemoveActualLRPCallCount()).To(Equal(1))
+								_, processGuid, index, instanceKey := bbsClient.RemoveActualLRPArgsForCall(0)
+								Expect(processGuid).To(Equal(expectedLrpKey.ProcessGuid))
+								Expect(int32(index)).To(Equal(expectedLrpKey.Index))
+								Expect(*instanceKey).To(Equal(expectedInstanceKey))
+							})
+						})
+
+						Context("and the container is still within the bound", func() {
+							BeforeEach(func() {
+								container.AllocatedAt = fakeClock.Now().Add(-time.Minute).UnixNano()
+							})
+
+							It("claims the actual LRP as usual", func() {
+								Expect(bbsClient.ClaimActualLRPCallCount()).To(Equal(1))
+								Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(0))
+							})
+						})
+					})
 				})
 
 				Context("and the container is CREATED", func() {
@@ -232,6 +1108,64 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 						))
 					})
 
+					Context("when the container records when it was allocated", func() {
+						BeforeEach(func() {
+							container.AllocatedAt = fakeClock.Now().Add(-time.Minute).UnixNano()
+						})
+
+						It("does not report a start it did not see", func() {
+							Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(0))
+							Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(0))
+						})
+
+						Context("when the processor saw the container starting", func() {
+							BeforeEach(func() {
+								created := container
+								created.State = executor.StateCreated
+								processor.Process(logger, created)
+							})
+
+							It("reports the time from allocation to ready once", func() {
+								Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(1))
+								name, duration := fakeMetronClient.SendDurationArgsForCall(0)
+								Expect(name).To(Equal("RepLRPTimeToReady"))
+								Expect(duration).To(Equal(time.Minute))
+
+								processor.Process(logger, container)
+								Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(1))
+							})
+
+							It("counts the start once", func() {
+								Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+								Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("RepLRPStartSucceeded"))
+
+								processor.Process(logger, container)
+								Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+							})
+
+							It("records that the instance was provisioned", func() {
+								Expect(provisioningRecorder.RecordProvisioningOutcomeCallCount()).To(Equal(1))
+								_, err := provisioningRecorder.RecordProvisioningOutcomeArgsForCall(0)
+								Expect(err).NotTo(HaveOccurred())
+							})
+
+							Context("when the BBS does not accept the start", func() {
+								BeforeEach(func() {
+									bbsClient.StartActualLRPReturns(errors.New("boom"))
+								})
+
+								It("counts the start once the BBS accepts it", func() {
+									Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(0))
+
+									bbsClient.StartActualLRPReturns(nil)
+									processor.Process(logger, container)
+									Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+									Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("RepLRPStartSucceeded"))
+								})
+							})
+						})
+					})
+
 					Context("when starting fails because ErrActualLRPCannotBeStarted", func() {
 						BeforeEach(func() {
 							bbsClient.StartActualLRPReturns(models.NewError(models.Error_ActualLRPCannotBeStarted, "foobar").ToError())
@@ -243,6 +1177,54 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 							Expect(containerGuid).To(Equal(container.Guid))
 							Expect(delegateLogger.SessionName()).To(Equal(expectedSessionName))
 						})
+
+						Context("when the orphan handler quarantines the container", func() {
+							var stopped executor.Container
+
+							BeforeEach(func() {
+								config.OrphanHandler = rep.NewOrphanHandler(rep.OrphanActionQuarantine)
+								config.OrphanQuarantineTTL = time.Hour
+								processor = buildProcessor()
+
+								stopped = container
+								stopped.State = executor.StateCompleted
+								stopped.RunResult.Stopped = true
+							})
+
+							It("stops the container without deleting it", func() {
+								Expect(containerDelegate.StopContainerCallCount()).To(Equal(1))
+								Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(0))
+							})
+
+							It("keeps the container once it has stopped", func() {
+								processor.Process(logger, stopped)
+
+								Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(0))
+								Expect(bbsClient.RemoveActualLRPCallCount()).To(Equal(0))
+								Expect(logger).To(Say("skipping-quarantined-container"))
+							})
+
+							It("deletes the container once the quarantine has expired", func() {
+								fakeClock.Increment(time.Hour)
+								processor.Process(logger, stopped)
+
+								Expect(logger).To(Say("deleting-expired-quarantined-container"))
+								Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+								_, guid := containerDelegate.DeleteContainerArgsForCall(0)
+								Expect(guid).To(Equal(container.Guid))
+								Expect(bbsClient.RemoveActualLRPCallCount()).To(Equal(0))
+								Expect(processor.TrackedContainers()).To(BeZero())
+							})
+
+							It("forgets the container once it is no longer on the cell", func() {
+								processor.ObserveContainers(logger, []executor.Container{container})
+								processor.Process(logger, stopped)
+								Expect(logger).To(Say("skipping-quarantined-container"))
+
+								processor.ObserveContainers(logger, nil)
+								Expect(processor.TrackedContainers()).To(BeZero())
+							})
+						})
 					})
 
 					Context("when starting fails for an unknown reason", func() {
@@ -254,6 +1236,70 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 							Expect(containerDelegate.StopContainerCallCount()).To(Equal(0))
 							Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(0))
 						})
+
+						Context("when a lifecycle error handler is configured", func() {
+							var lifecycleErrors []rep.LifecycleError
+
+							BeforeEach(func() {
+								lifecycleErrors = nil
+								config.LifecycleErrors = func(err rep.LifecycleError) {
+									lifecycleErrors = append(lifecycleErrors, err)
+								}
+								processor = buildProcessor()
+							})
+
+							It("does not report an error for a container it did not see starting", func() {
+								Expect(lifecycleErrors).To(BeEmpty())
+							})
+
+							Context("when the container was seen starting", func() {
+								BeforeEach(func() {
+									created := container
+									created.State = executor.StateCreated
+									processor.Process(logger, created)
+								})
+
+								It("reports a start error once, however often the start is retried", func() {
+									Expect(lifecycleErrors).To(HaveLen(1))
+									Expect(lifecycleErrors[0].Phase).To(Equal(rep.LifecyclePhaseStart))
+									Expect(lifecycleErrors[0].Err).To(MatchError("boom"))
+
+									processor.Process(logger, container)
+									Expect(bbsClient.StartActualLRPCallCount()).To(Equal(2))
+									Expect(lifecycleErrors).To(HaveLen(1))
+								})
+							})
+						})
+					})
+
+					Context("when the store recovers before a later poll", func() {
+						BeforeEach(func() {
+							attempts := 0
+							bbsClient.StartActualLRPStub = func(lager.Logger, *models.ActualLRPKey, *models.ActualLRPInstanceKey, *models.ActualLRPNetInfo) error {
+								attempts++
+								if attempts <= 2 {
+									return models.ErrUnknownError
+								}
+								return nil
+							}
+						})
+
+						It("makes the start once per poll and keeps the container until it succeeds", func() {
+							Expect(bbsClient.StartActualLRPCallCount()).To(Equal(1))
+							Expect(logger).To(Say("failed-bbs-start-actual-lrp"))
+
+							processor.Process(logger, container)
+							Expect(bbsClient.StartActualLRPCallCount()).To(Equal(2))
+
+							processor.Process(logger, container)
+							Expect(bbsClient.StartActualLRPCallCount()).To(Equal(3))
+							_, lrpKey, instanceKey, _ := bbsClient.StartActualLRPArgsForCall(2)
+							Expect(*lrpKey).To(Equal(expectedLrpKey))
+							Expect(*instanceKey).To(Equal(expectedInstanceKey))
+
+							Expect(containerDelegate.StopContainerCallCount()).To(Equal(0))
+							Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(0))
+						})
 					})
 				})
 
@@ -277,6 +1323,10 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 							Expect(*instanceKey).To(Equal(expectedInstanceKey))
 						})
 
+						It("does not record a crash", func() {
+							Expect(crashRecorder.RecordCrashCallCount()).To(Equal(0))
+						})
+
 						Context("when the removal succeeds", func() {
 							It("deletes the container", func() {
 								Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
@@ -314,12 +1364,130 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 							Expect(reason).To(Equal("crashed"))
 						})
 
+						It("records the crash", func() {
+							Expect(crashRecorder.RecordCrashCallCount()).To(Equal(1))
+							_, lrpKey := crashRecorder.RecordCrashArgsForCall(0)
+							Expect(*lrpKey).To(Equal(expectedLrpKey))
+						})
+
+						It("counts the crash", func() {
+							Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+							Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("RepLRPCrashed"))
+						})
+
+						Context("when a lifecycle error handler is configured", func() {
+							var lifecycleErrors []rep.LifecycleError
+
+							BeforeEach(func() {
+								lifecycleErrors = nil
+								handler := func(err rep.LifecycleError) {
+									lifecycleErrors = append(lifecycleErrors, err)
+								}
+								config.LifecycleErrors = handler
+								processor = buildProcessor()
+							})
+
+							It("does not report an error for a container it did not see starting", func() {
+								Expect(lifecycleErrors).To(BeEmpty())
+							})
+
+							Context("when the container was seen starting", func() {
+								BeforeEach(func() {
+									initializing := container
+									initializing.State = executor.StateInitializing
+									processor.Process(logger, initializing)
+								})
+
+								It("reports an initialize error, as the container never became ready", func() {
+									Expect(lifecycleErrors).To(HaveLen(1))
+									Expect(lifecycleErrors[0].Phase).To(Equal(rep.LifecyclePhaseInitialize))
+									Expect(lifecycleErrors[0].ProcessGuid).To(Equal(expectedLrpKey.ProcessGuid))
+									Expect(lifecycleErrors[0].Index).To(Equal(expectedLrpKey.Index))
+									Expect(lifecycleErrors[0].ContainerGuid).To(Equal(container.Guid))
+									Expect(lifecycleErrors[0].Err).To(MatchError("crashed"))
+								})
+							})
+						})
+
 						It("deletes the container", func() {
 							Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
 							delegateLogger, containerGuid := containerDelegate.DeleteContainerArgsForCall(0)
 							Expect(containerGuid).To(Equal(container.Guid))
 							Expect(delegateLogger.SessionName()).To(Equal(expectedSessionName))
 						})
+
+						Context("when in-place restarts are enabled", func() {
+							BeforeEach(func() {
+								config.MaxInPlaceRestarts = 2
+								processor = buildProcessor()
+								containerDelegate.DeleteContainerReturns(true)
+								containerDelegate.AllocateContainerReturns(true)
+							})
+
+							It("replaces the container with a new reservation for the same instance", func() {
+								Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+								_, containerGuid := containerDelegate.DeleteContainerArgsForCall(0)
+								Expect(containerGuid).To(Equal(container.Guid))
+
+								Expect(containerDelegate.AllocateContainerCallCount()).To(Equal(1))
+								_, req := containerDelegate.AllocateContainerArgsForCall(0)
+								Expect(req.Guid).To(Equal(container.Guid))
+								Expect(req.Resource).To(Equal(container.Resource))
+								Expect(req.Tags).To(Equal(container.Tags))
+							})
+
+							It("reports the crash", func() {
+								Expect(bbsClient.CrashActualLRPCallCount()).To(Equal(1))
+								Expect(crashRecorder.RecordCrashCallCount()).To(Equal(1))
+								Expect(fakeMetronClient.IncrementCounterArgsForCall(fakeMetronClient.IncrementCounterCallCount() - 1)).To(Equal("RepLRPCrashed"))
+							})
+
+							It("reports every crash and stops restarting once the instance has used up its restarts", func() {
+								processor.Process(logger, container)
+								Expect(containerDelegate.AllocateContainerCallCount()).To(Equal(2))
+								Expect(bbsClient.CrashActualLRPCallCount()).To(Equal(2))
+
+								processor.Process(logger, container)
+								Expect(containerDelegate.AllocateContainerCallCount()).To(Equal(2))
+								Expect(bbsClient.CrashActualLRPCallCount()).To(Equal(3))
+								Expect(crashRecorder.RecordCrashCallCount()).To(Equal(3))
+							})
+
+							It("starts counting again after the instance is rescheduled", func() {
+								processor.Process(logger, container)
+								processor.Process(logger, container)
+								Expect(containerDelegate.AllocateContainerCallCount()).To(Equal(2))
+
+								processor.Process(logger, container)
+								Expect(containerDelegate.AllocateContainerCallCount()).To(Equal(3))
+							})
+
+							It("starts counting again once the instance has stayed up for a while", func() {
+								processor.Process(logger, container)
+								Expect(containerDelegate.AllocateContainerCallCount()).To(Equal(2))
+
+								fakeClock.Increment(5 * time.Minute)
+								processor.Process(logger, container)
+								Expect(containerDelegate.AllocateContainerCallCount()).To(Equal(3))
+
+								processor.Process(logger, container)
+								Expect(containerDelegate.AllocateContainerCallCount()).To(Equal(4))
+
+								processor.Process(logger, container)
+								Expect(containerDelegate.AllocateContainerCallCount()).To(Equal(4))
+							})
+
+							Context("when the new reservation fails", func() {
+								BeforeEach(func() {
+									containerDelegate.AllocateContainerReturns(false)
+								})
+
+								It("reports the crash so the instance is rescheduled", func() {
+									Expect(bbsClient.CrashActualLRPCallCount()).To(Equal(1))
+									Expect(crashRecorder.RecordCrashCallCount()).To(Equal(1))
+								})
+							})
+						})
 					})
 				})
 
@@ -335,6 +1503,80 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 			})
 		})
 	})
+
+	Describe("with a custom association store", func() {
+		var (
+			associationStore    *fake_internal.FakeAssociationStore
+			container           executor.Container
+			expectedLrpKey      models.ActualLRPKey
+			expectedInstanceKey models.ActualLRPInstanceKey
+		)
+
+		BeforeEach(func() {
+			associationStore = new(fake_internal.FakeAssociationStore)
+			processor = internal.NewLRPProcessor(bbsClient, associationStore, containerDelegate, evacuationReporter, crashRecorder, provisioningRecorder, fakeClock, fakeMetronClient, requeue, config)
+
+			expectedLrpKey = models.NewActualLRPKey("process-guid", 2, "domain")
+			expectedInstanceKey = models.NewActualLRPInstanceKey("instance-guid", "cell-id")
+			container = newLRPContainer(expectedLrpKey, expectedInstanceKey, models.NewActualLRPNetInfo("1.2.3.4"))
+		})
+
+		JustBeforeEach(func() {
+			processor.Process(logger, container)
+		})
+
+		Context("when the container is RESERVED", func() {
+			BeforeEach(func() {
+				container.State = executor.StateReserved
+				bbsClient.DesiredLRPByProcessGuidReturns(model_helpers.NewValidDesiredLRP("process-guid"), nil)
+			})
+
+			It("records the claim in the association store", func() {
+				Expect(associationStore.ClaimCallCount()).To(Equal(1))
+				_, lrpKey, instanceKey := associationStore.ClaimArgsForCall(0)
+				Expect(*lrpKey).To(Equal(expectedLrpKey))
+				Expect(*instanceKey).To(Equal(expectedInstanceKey))
+				Expect(bbsClient.ClaimActualLRPCallCount()).To(Equal(0))
+			})
+
+			Context("when the association store cannot claim the lrp", func() {
+				BeforeEach(func() {
+					associationStore.ClaimReturns(models.ErrActualLRPCannotBeClaimed)
+				})
+
+				It("deletes the container without running it", func() {
+					Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+					Expect(containerDelegate.RunContainerCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when running the container fails", func() {
+				BeforeEach(func() {
+					containerDelegate.RunContainerReturns(false)
+				})
+
+				It("removes the association", func() {
+					Expect(associationStore.RemoveCallCount()).To(Equal(1))
+					Expect(bbsClient.RemoveActualLRPCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when the container is COMPLETED after being stopped", func() {
+			BeforeEach(func() {
+				container.State = executor.StateCompleted
+				container.RunResult.Stopped = true
+			})
+
+			It("removes the association", func() {
+				Expect(associationStore.RemoveCallCount()).To(Equal(1))
+				_, lrpKey, instanceKey := associationStore.RemoveArgsForCall(0)
+				Expect(*lrpKey).To(Equal(expectedLrpKey))
+				Expect(*instanceKey).To(Equal(expectedInstanceKey))
+				Expect(bbsClient.RemoveActualLRPCallCount()).To(Equal(0))
+			})
+		})
+	})
 })
 
 func newLRPContainer(lrpKey models.ActualLRPKey, instanceKey models.ActualLRPInstanceKey, netInfo models.ActualLRPNetInfo) executor.Container {
@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// handleOrphan consults the orphan handler about a container whose ActualLRP
+// it can no longer claim or start. remove is how the container is got rid of
+// when the handler picks the default of deleting it. A reserved container has
+// never run, so there is nothing in it to inspect and it is removed rather
+// than quarantined.
+func (p *ordinaryLRPProcessor) handleOrphan(logger lager.Logger, lrpContainer *lrpContainer, remove func(logger lager.Logger, guid string) bool) {
+	action := p.orphanHandler.Handle(logger, lrpContainer.Guid, lrpContainer.Tags)
+	if action == rep.OrphanActionQuarantine && lrpContainer.State == executor.StateReserved {
+		action = rep.OrphanActionDelete
+	}
+	logger.Info("handling-orphaned-container", lager.Data{"action": action})
+
+	switch action {
+	case rep.OrphanActionKeep:
+	case rep.OrphanActionQuarantine:
+		p.quarantineLock.Lock()
+		p.quarantined[lrpContainer.Guid] = p.clock.Now()
+		p.quarantineLock.Unlock()
+		p.containerDelegate.StopContainer(logger, lrpContainer.Guid)
+	default:
+		remove(logger, lrpContainer.Guid)
+		p.forgetContainer(logger, lrpContainer.Guid)
+	}
+}
+
+// isQuarantined reports whether the container was quarantined, in which case
+// it is left on the cell once it has stopped, and whether it has been kept for
+// longer than quarantineTTL, in which case it is due to be deleted. A zero
+// quarantineTTL keeps quarantined containers until they are deleted by hand.
+func (p *ordinaryLRPProcessor) isQuarantined(guid string) (quarantined, expired bool) {
+	p.quarantineLock.Lock()
+	defer p.quarantineLock.Unlock()
+
+	since, found := p.quarantined[guid]
+	if !found {
+		return false, false
+	}
+	return true, p.quarantineTTL > 0 && p.clock.Now().Sub(since) >= p.quarantineTTL
+}
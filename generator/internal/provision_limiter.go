@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+)
+
+// provisionLimiter is a token bucket that allows perMinute containers to be
+// provisioned in a burst and then refills at perMinute tokens a minute.
+type provisionLimiter struct {
+	clock     clock.Clock
+	perMinute int
+	interval  time.Duration
+	burst     time.Duration
+
+	lock sync.Mutex
+	// next is the time at which the bucket would be full again if no further
+	// tokens were taken.
+	next time.Time
+}
+
+func newProvisionLimiter(clock clock.Clock, perMinute int) *provisionLimiter {
+	interval := time.Minute / time.Duration(perMinute)
+	return &provisionLimiter{
+		clock:     clock,
+		perMinute: perMinute,
+		interval:  interval,
+		burst:     interval * time.Duration(perMinute-1),
+	}
+}
+
+// wait blocks until a token is available and takes it. The returned duration
+// is how long the caller was held back.
+func (l *provisionLimiter) wait() time.Duration {
+	l.lock.Lock()
+	now := l.clock.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	allowAt := l.next.Add(-l.burst)
+	l.next = l.next.Add(l.interval)
+	l.lock.Unlock()
+
+	delay := allowAt.Sub(now)
+	if delay <= 0 {
+		return 0
+	}
+	l.clock.Sleep(delay)
+	return delay
+}
+
+// refund returns a token taken by a provision that did not succeed.
+func (l *provisionLimiter) refund() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.next = l.next.Add(-l.interval)
+}
+
+// tokens returns how many containers could be provisioned right now without
+// waiting.
+func (l *provisionLimiter) tokens() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.clock.Now()
+	next := l.next
+	if next.Before(now) {
+		next = now
+	}
+
+	slack := now.Sub(next) + l.burst
+	if slack < 0 {
+		return 0
+	}
+	return int(slack/l.interval) + 1
+}
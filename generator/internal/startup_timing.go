@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+const (
+	repLRPTimeToRunning = "RepLRPTimeToRunning"
+	repLRPTimeToReady   = "RepLRPTimeToReady"
+)
+
+// recordTimeToRunning reports how long it took from the container being
+// allocated until the executor accepted the run request.
+func (p *ordinaryLRPProcessor) recordTimeToRunning(logger lager.Logger, lrpContainer *lrpContainer) {
+	p.sendStartupDuration(logger, repLRPTimeToRunning, lrpContainer)
+}
+
+// markStarting records that the processor has seen the container claimed and
+// on its way to running.
+func (p *ordinaryLRPProcessor) markStarting(guid string) {
+	p.readyLock.Lock()
+	defer p.readyLock.Unlock()
+	if _, ready := p.ready[guid]; !ready {
+		p.starting[guid] = struct{}{}
+	}
+}
+
+// recordReady counts the start of the LRP and reports how long it took from
+// the container being allocated until it first passed its health check, once
+// the BBS has accepted the start. Both are only reported when the processor
+// saw the container starting, so that a container that was already running
+// when the rep started is not counted again, and only once per container,
+// however many times the running container is processed.
+func (p *ordinaryLRPProcessor) recordReady(logger lager.Logger, lrpContainer *lrpContainer) {
+	p.readyLock.Lock()
+	_, observed := p.starting[lrpContainer.Guid]
+	delete(p.starting, lrpContainer.Guid)
+	delete(p.startFailures, lrpContainer.Guid)
+	p.ready[lrpContainer.Guid] = struct{}{}
+	p.readyLock.Unlock()
+
+	if observed {
+		p.provisioning.RecordProvisioningOutcome(logger, nil)
+		p.incrementCounter(logger, repLRPStartSucceeded)
+		p.sendStartupDuration(logger, repLRPTimeToReady, lrpContainer)
+		p.recordInitialized(logger, lrpContainer, false)
+		p.emitLifecycleEvent(logger, lrpContainer, rep.LifecycleTransitionRunning)
+	}
+}
+
+// isStarting reports whether the container was seen starting and has not yet
+// become ready.
+func (p *ordinaryLRPProcessor) isStarting(guid string) bool {
+	p.readyLock.Lock()
+	defer p.readyLock.Unlock()
+	_, starting := p.starting[guid]
+	return starting
+}
+
+// markStartFailed records that the BBS did not accept the start of the
+// container, and returns whether the failure is to be reported. It is only
+// reported for a container seen starting, and only once, as the start is
+// attempted again every time the running container is processed.
+func (p *ordinaryLRPProcessor) markStartFailed(guid string) bool {
+	p.readyLock.Lock()
+	defer p.readyLock.Unlock()
+	if _, starting := p.starting[guid]; !starting {
+		return false
+	}
+	if _, reported := p.startFailures[guid]; reported {
+		return false
+	}
+	p.startFailures[guid] = struct{}{}
+	return true
+}
+
+// forgetReady forgets whether the container was starting or ready.
+func (p *ordinaryLRPProcessor) forgetReady(guid string) {
+	p.readyLock.Lock()
+	defer p.readyLock.Unlock()
+	delete(p.ready, guid)
+	delete(p.starting, guid)
+	delete(p.startFailures, guid)
+}
+
+func (p *ordinaryLRPProcessor) sendStartupDuration(logger lager.Logger, name string, lrpContainer *lrpContainer) {
+	if lrpContainer.AllocatedAt == 0 {
+		return
+	}
+
+	duration := p.clock.Now().Sub(time.Unix(0, lrpContainer.AllocatedAt))
+	err := p.metronClient.SendDuration(name, duration)
+	if err != nil {
+		logger.Error("failed-to-send-startup-duration", err, lager.Data{"metric": name})
+	}
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+)
+
+// forgetContainer drops every record the processor keeps about a container it
+// is deleting or that has left the cell, so that the records do not outlive
+// the container.
+func (p *ordinaryLRPProcessor) forgetContainer(logger lager.Logger, guid string) {
+	p.forgetReady(guid)
+	p.forgetInitialized(guid)
+	p.forgetRun(guid)
+	p.forgetRestarts(guid)
+	p.releaseCacheWarming(logger, guid)
+	p.held.release(guid)
+	p.forgetInstance(guid)
+
+	p.quarantineLock.Lock()
+	delete(p.quarantined, guid)
+	p.quarantineLock.Unlock()
+}
+
+// pruneRecords forgets the containers the processor holds records about that
+// are no longer on the cell, such as those deleted by an operator or reaped by
+// the executor, which the processor never sees being deleted.
+func (p *ordinaryLRPProcessor) pruneRecords(logger lager.Logger, containers []executor.Container) {
+	listed := make(map[string]struct{}, len(containers))
+	for i := range containers {
+		listed[containers[i].Guid] = struct{}{}
+	}
+
+	for guid := range p.trackedGuids() {
+		if _, ok := listed[guid]; !ok {
+			logger.Debug("forgetting-unlisted-container", lager.Data{"container-guid": guid})
+			p.forgetContainer(logger, guid)
+		}
+	}
+}
+
+// TrackedContainers returns the number of containers the processor holds
+// records about.
+func (p *ordinaryLRPProcessor) TrackedContainers() int {
+	return len(p.trackedGuids())
+}
+
+func (p *ordinaryLRPProcessor) trackedGuids() map[string]struct{} {
+	guids := map[string]struct{}{}
+
+	p.restartsLock.Lock()
+	for guid := range p.restarts {
+		guids[guid] = struct{}{}
+	}
+	p.restartsLock.Unlock()
+
+	addGuids(guids, &p.readyLock, p.starting)
+	addGuids(guids, &p.readyLock, p.startFailures)
+	addGuids(guids, &p.readyLock, p.ready)
+	addGuids(guids, &p.initializedLock, p.initialized)
+	addGuids(guids, &p.runsLock, p.runs)
+	p.quarantineLock.Lock()
+	for guid := range p.quarantined {
+		guids[guid] = struct{}{}
+	}
+	p.quarantineLock.Unlock()
+
+	p.transitionsLock.Lock()
+	for guid := range p.transitions {
+		guids[guid] = struct{}{}
+	}
+	p.transitionsLock.Unlock()
+
+	p.cacheWarmingLock.Lock()
+	for _, warmers := range p.cacheWarming {
+		for _, guid := range warmers {
+			guids[guid] = struct{}{}
+		}
+	}
+	p.cacheWarmingLock.Unlock()
+
+	p.held.addGuids(guids)
+
+	return guids
+}
+
+func addGuids(guids map[string]struct{}, lock *sync.Mutex, tracked map[string]struct{}) {
+	lock.Lock()
+	defer lock.Unlock()
+	for guid := range tracked {
+		guids[guid] = struct{}{}
+	}
+}
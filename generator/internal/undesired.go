@@ -0,0 +1,19 @@
+package internal
+
+import (
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager"
+)
+
+// abandonUndesired deletes a container that is still being allocated or set
+// up for an LRP that has since been deleted, so that its resources are not
+// held for an instance nobody wants.
+func (p *ordinaryLRPProcessor) abandonUndesired(logger lager.Logger, lrpContainer *lrpContainer) {
+	logger.Info("abandoning-container-for-undesired-lrp")
+	p.containerDelegate.DeleteContainer(logger, lrpContainer.Guid)
+	p.forgetContainer(logger, lrpContainer.Guid)
+}
+
+func isNotFound(err error) bool {
+	return models.ConvertError(err).Type == models.Error_ResourceNotFound
+}
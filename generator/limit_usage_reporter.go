@@ -0,0 +1,67 @@
+package generator
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/go-loggregator/loggregator_v2"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+const (
+	repConcurrencyUsed  = "RepConcurrencyUsed"
+	repConcurrencyLimit = "RepConcurrencyLimit"
+	repRateTokens       = "RepRateTokens"
+)
+
+// LimitUsageReporter periodically emits how much of the cell's concurrency and
+// provisioning rate limits is in use.
+type LimitUsageReporter struct {
+	logger        lager.Logger
+	interval      time.Duration
+	clock         clock.Clock
+	limitReporter rep.LimitReporter
+	metronClient  loggregator_v2.Client
+}
+
+func NewLimitUsageReporter(
+	logger lager.Logger,
+	interval time.Duration,
+	clock clock.Clock,
+	limitReporter rep.LimitReporter,
+	metronClient loggregator_v2.Client,
+) *LimitUsageReporter {
+	return &LimitUsageReporter{
+		logger:        logger,
+		interval:      interval,
+		clock:         clock,
+		limitReporter: limitReporter,
+		metronClient:  metronClient,
+	}
+}
+
+func (r *LimitUsageReporter) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	return rep.NewPeriodicRunner(r.logger, "limit-usage-reporter", r.interval, r.clock, r.report).Run(signals, ready)
+}
+
+func (r *LimitUsageReporter) report(logger lager.Logger) {
+	usage := r.limitReporter.LimitUsage()
+
+	metrics := []struct {
+		name  string
+		value int
+	}{
+		{repConcurrencyUsed, usage.ConcurrencyUsed},
+		{repConcurrencyLimit, usage.ConcurrencyLimit},
+		{repRateTokens, usage.RateTokens},
+	}
+
+	for _, metric := range metrics {
+		err := r.metronClient.SendMetric(metric.name, metric.value)
+		if err != nil {
+			logger.Error("failed-to-send-limit-usage-metric", err, lager.Data{"metric": metric.name})
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package generator_test
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/go-loggregator/loggregator_v2/fakes"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/generator"
+	"code.cloudfoundry.org/rep/repfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+)
+
+var _ = Describe("LimitUsageReporter", func() {
+	const interval = 10 * time.Second
+
+	var (
+		fakeClock        *fakeclock.FakeClock
+		limitReporter    *repfakes.FakeLimitReporter
+		fakeMetronClient *mfakes.FakeClient
+
+		process ifrit.Process
+	)
+
+	metrics := func() map[string]int {
+		sent := map[string]int{}
+		for i := 0; i < fakeMetronClient.SendMetricCallCount(); i++ {
+			name, value := fakeMetronClient.SendMetricArgsForCall(i)
+			sent[name] = value
+		}
+		return sent
+	}
+
+	BeforeEach(func() {
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		limitReporter = new(repfakes.FakeLimitReporter)
+		fakeMetronClient = new(mfakes.FakeClient)
+
+		limitReporter.LimitUsageReturns(rep.LimitUsage{
+			ConcurrencyUsed:  2,
+			ConcurrencyLimit: 5,
+			RateTokens:       7,
+			RateLimit:        30,
+		})
+
+		reporter := generator.NewLimitUsageReporter(logger, interval, fakeClock, limitReporter, fakeMetronClient)
+		process = ifrit.Invoke(reporter)
+		Eventually(fakeClock.WatcherCount).Should(Equal(1))
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+	})
+
+	It("emits the limit usage every interval", func() {
+		Consistently(fakeMetronClient.SendMetricCallCount).Should(Equal(0))
+
+		fakeClock.WaitForWatcherAndIncrement(interval)
+		Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(3))
+		Expect(metrics()).To(Equal(map[string]int{
+			"RepConcurrencyUsed":  2,
+			"RepConcurrencyLimit": 5,
+			"RepRateTokens":       7,
+		}))
+
+		fakeClock.WaitForWatcherAndIncrement(interval)
+		Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(6))
+	})
+})
@@ -0,0 +1,46 @@
+package generator
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+const (
+	repMappingStoreEntries = "RepMappingStoreEntries"
+
+	// orphanedEntriesThreshold is how many more mapping store entries than LRP
+	// containers the cell may have before the entries are considered orphaned.
+	orphanedEntriesThreshold = 10
+)
+
+var ErrOrphanedMappingEntries = errors.New("mapping store has grown well beyond the cell's lrp containers")
+
+// reportMappingStoreEntries emits the number of container-to-LRP mappings held
+// for the cell, and logs an error when that number has grown well beyond the
+// number of LRP containers the cell is running. It is given what
+// BatchOperations has already fetched, so reporting costs no further calls to
+// the BBS or the executor.
+func (g *generator) reportMappingStoreEntries(logger lager.Logger, entries int, containers []executor.Container) {
+	err := g.metronClient.SendMetric(repMappingStoreEntries, entries)
+	if err != nil {
+		logger.Error("failed-to-send-mapping-store-entries-metric", err)
+	}
+
+	lrpContainers := 0
+	for i := range containers {
+		if containers[i].State == executor.StateCompleted || containers[i].Tags[rep.LifecycleTag] != rep.LRPLifecycle {
+			continue
+		}
+		lrpContainers++
+	}
+
+	if entries > lrpContainers+orphanedEntriesThreshold {
+		logger.Error("mapping-store-has-orphaned-entries", ErrOrphanedMappingEntries, lager.Data{
+			"entries":        entries,
+			"lrp-containers": lrpContainers,
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package generator_test
+
+import (
+	"errors"
+	"fmt"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/executor"
+	efakes "code.cloudfoundry.org/executor/fakes"
+	mfakes "code.cloudfoundry.org/go-loggregator/loggregator_v2/fakes"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
+	"code.cloudfoundry.org/rep/generator"
+	"code.cloudfoundry.org/rep/generator/fake_generator"
+	"code.cloudfoundry.org/rep/repfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Mapping store entries", func() {
+	var (
+		fakeExecutorClient *efakes.FakeClient
+		fakeMetronClient   *mfakes.FakeClient
+
+		opGenerator generator.Generator
+	)
+
+	lrpGroups := func(count int) []*models.ActualLRPGroup {
+		groups := make([]*models.ActualLRPGroup, count)
+		for i := range groups {
+			lrp := models.NewUnclaimedActualLRP(models.NewActualLRPKey(fmt.Sprintf("process-guid-%d", i), 0, "domain"), 0)
+			groups[i] = &models.ActualLRPGroup{Instance: lrp}
+		}
+		return groups
+	}
+
+	lrpContainers := func(count int) []executor.Container {
+		containers := make([]executor.Container, count)
+		for i := range containers {
+			containers[i] = executor.Container{
+				Guid:  fmt.Sprintf("container-%d", i),
+				State: executor.StateRunning,
+				Tags:  executor.Tags{rep.LifecycleTag: rep.LRPLifecycle},
+			}
+		}
+		return containers
+	}
+
+	mappingStoreEntries := func() []int {
+		values := []int{}
+		for i := 0; i < fakeMetronClient.SendMetricCallCount(); i++ {
+			name, value := fakeMetronClient.SendMetricArgsForCall(i)
+			if name == "RepMappingStoreEntries" {
+				values = append(values, value)
+			}
+		}
+		return values
+	}
+
+	BeforeEach(func() {
+		fakeExecutorClient = new(efakes.FakeClient)
+		fakeMetronClient = new(mfakes.FakeClient)
+
+		fakeBBS.ActualLRPGroupsReturns(lrpGroups(3), nil)
+		fakeExecutorClient.ListContainersReturns(lrpContainers(3), nil)
+
+		opGenerator = generator.New(generator.Config{CellID: "cell-id"}, fakeBBS, fakeExecutorClient, new(fake_evacuation_context.FakeEvacuationReporter), clock.NewClock(), new(fake_generator.FakeDivergenceTracker), new(repfakes.FakeCrashRecorder), new(repfakes.FakeProvisioningRecorder), fakeMetronClient, new(repfakes.FakeBBSHealth))
+	})
+
+	It("emits the number of mapping store entries for the cell with every batch", func() {
+		_, err := opGenerator.BatchOperations(logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mappingStoreEntries()).To(Equal([]int{3}))
+
+		fakeBBS.ActualLRPGroupsReturns(lrpGroups(5), nil)
+		_, err = opGenerator.BatchOperations(logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mappingStoreEntries()).To(Equal([]int{3, 5}))
+	})
+
+	It("reuses the batch's calls to the BBS and the executor", func() {
+		_, err := opGenerator.BatchOperations(logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeBBS.ActualLRPGroupsCallCount()).To(Equal(1))
+		Expect(fakeExecutorClient.ListContainersCallCount()).To(Equal(1))
+	})
+
+	It("does not log an error when the entries track the LRP containers", func() {
+		_, err := opGenerator.BatchOperations(logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(logger).NotTo(gbytes.Say("mapping-store-has-orphaned-entries"))
+	})
+
+	Context("when the entries grow far beyond the LRP containers", func() {
+		BeforeEach(func() {
+			containers := append(lrpContainers(1), executor.Container{
+				Guid:  "completed",
+				State: executor.StateCompleted,
+				Tags:  executor.Tags{rep.LifecycleTag: rep.LRPLifecycle},
+			}, executor.Container{
+				Guid:  "task",
+				State: executor.StateRunning,
+				Tags:  executor.Tags{rep.LifecycleTag: rep.TaskLifecycle},
+			})
+			fakeExecutorClient.ListContainersReturns(containers, nil)
+			fakeBBS.ActualLRPGroupsReturns(lrpGroups(12), nil)
+		})
+
+		It("logs an error", func() {
+			_, err := opGenerator.BatchOperations(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(logger).To(gbytes.Say("mapping-store-has-orphaned-entries"))
+			Expect(logger).To(gbytes.Say(`"entries":12,"error":"mapping store has grown well beyond the cell's lrp containers","lrp-containers":1`))
+		})
+	})
+
+	Context("when fetching the entries fails", func() {
+		BeforeEach(func() {
+			fakeBBS.ActualLRPGroupsReturns(nil, errors.New("boom"))
+		})
+
+		It("does not emit the metric", func() {
+			_, err := opGenerator.BatchOperations(logger)
+			Expect(err).To(HaveOccurred())
+			Expect(mappingStoreEntries()).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,48 @@
+package generator
+
+import "sync"
+
+// missingContainers counts how many consecutive container listings each
+// ActualLRP or Task on the cell has had no container in. Requiring more than
+// one listing keeps the rep from removing records from the BBS because of a
+// single stale listing from the executor.
+type missingContainers struct {
+	requiredListings int
+
+	lock   sync.Mutex
+	counts map[string]int
+}
+
+func newMissingContainers(requiredListings int) *missingContainers {
+	return &missingContainers{
+		requiredListings: requiredListings,
+		counts:           make(map[string]int),
+	}
+}
+
+// confirm records the guids missing from the latest listing and returns the
+// ones that have now been missing from enough consecutive listings to act on.
+func (m *missingContainers) confirm(missing map[string]struct{}) map[string]struct{} {
+	if m.requiredListings <= 1 {
+		return missing
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for guid := range m.counts {
+		if _, found := missing[guid]; !found {
+			delete(m.counts, guid)
+		}
+	}
+
+	confirmed := make(map[string]struct{}, len(missing))
+	for guid := range missing {
+		m.counts[guid]++
+		if m.counts[guid] >= m.requiredListings {
+			confirmed[guid] = struct{}{}
+		}
+	}
+
+	return confirmed
+}
@@ -5,6 +5,7 @@ import (
 
 	"code.cloudfoundry.org/bbs"
 	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/go-loggregator/loggregator_v2"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/generator/internal"
@@ -196,6 +197,14 @@ func (o *ResidualTaskOperation) Execute() {
 	}
 }
 
+const repExecutorUnknownState = "RepExecutorUnknownState"
+
+// isKnownContainerState reports whether the processors know how to handle the
+// given state. StateInvalid is known; the processors clean up after it.
+func isKnownContainerState(state executor.State) bool {
+	return state == executor.StateInvalid || containerStateVersion(state) > 0
+}
+
 // ContainerOperation acquires the current state of a container and performs any
 // bbs or container operations necessary to harmonize the state of the world.
 type ContainerOperation struct {
@@ -203,6 +212,7 @@ type ContainerOperation struct {
 	lrpProcessor      internal.LRPProcessor
 	taskProcessor     internal.TaskProcessor
 	containerDelegate internal.ContainerDelegate
+	metronClient      loggregator_v2.Client
 	Guid              string
 }
 
@@ -211,6 +221,7 @@ func NewContainerOperation(
 	lrpProcessor internal.LRPProcessor,
 	taskProcessor internal.TaskProcessor,
 	containerDelegate internal.ContainerDelegate,
+	metronClient loggregator_v2.Client,
 	guid string,
 ) *ContainerOperation {
 	return &ContainerOperation{
@@ -218,6 +229,7 @@ func NewContainerOperation(
 		lrpProcessor:      lrpProcessor,
 		taskProcessor:     taskProcessor,
 		containerDelegate: containerDelegate,
+		metronClient:      metronClient,
 		Guid:              guid,
 	}
 }
@@ -243,6 +255,17 @@ func (o *ContainerOperation) Execute() {
 		"container-state": container.State,
 	})
 
+	// a state from a newer executor may mean anything, so leave the container
+	// and its BBS record alone rather than risk acting on a guess
+	if !isKnownContainerState(container.State) {
+		logger.Error("skipped-container-in-unknown-state", nil)
+		err := o.metronClient.IncrementCounter(repExecutorUnknownState)
+		if err != nil {
+			logger.Error("failed-to-increment-unknown-state-counter", err)
+		}
+		return
+	}
+
 	lifecycle := container.Tags[rep.LifecycleTag]
 
 	switch lifecycle {
@@ -5,6 +5,7 @@ import (
 
 	"code.cloudfoundry.org/bbs/models"
 	"code.cloudfoundry.org/executor"
+	mfakes "code.cloudfoundry.org/go-loggregator/loggregator_v2/fakes"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/generator"
 	"code.cloudfoundry.org/rep/generator/internal"
@@ -332,6 +333,7 @@ var _ = Describe("Operation", func() {
 			containerDelegate  *fake_internal.FakeContainerDelegate
 			lrpProcessor       *fake_internal.FakeLRPProcessor
 			taskProcessor      *fake_internal.FakeTaskProcessor
+			fakeMetronClient   *mfakes.FakeClient
 			containerOperation *generator.ContainerOperation
 			guid               string
 		)
@@ -340,8 +342,9 @@ var _ = Describe("Operation", func() {
 			containerDelegate = new(fake_internal.FakeContainerDelegate)
 			lrpProcessor = new(fake_internal.FakeLRPProcessor)
 			taskProcessor = new(fake_internal.FakeTaskProcessor)
+			fakeMetronClient = new(mfakes.FakeClient)
 			guid = "the-guid"
-			containerOperation = generator.NewContainerOperation(logger, lrpProcessor, taskProcessor, containerDelegate, guid)
+			containerOperation = generator.NewContainerOperation(logger, lrpProcessor, taskProcessor, containerDelegate, fakeMetronClient, guid)
 		})
 
 		Describe("Key", func() {
@@ -393,6 +396,34 @@ var _ = Describe("Operation", func() {
 					containerDelegate.GetContainerReturns(executor.Container{}, true)
 				})
 
+				Context("when the container is in a state the rep does not know", func() {
+					BeforeEach(func() {
+						container = executor.Container{
+							State: executor.State("some-new-state"),
+							Tags: executor.Tags{
+								rep.LifecycleTag: rep.LRPLifecycle,
+							},
+						}
+						containerDelegate.GetContainerReturns(container, true)
+					})
+
+					It("does not farm the container out to any processor", func() {
+						Expect(lrpProcessor.ProcessCallCount()).To(Equal(0))
+						Expect(taskProcessor.ProcessCallCount()).To(Equal(0))
+					})
+
+					It("does not touch the container", func() {
+						Expect(containerDelegate.StopContainerCallCount()).To(Equal(0))
+						Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(0))
+					})
+
+					It("logs and counts the unknown state", func() {
+						Expect(logger).To(Say(sessionName + ".skipped-container-in-unknown-state"))
+						Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+						Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("RepExecutorUnknownState"))
+					})
+				})
+
 				Context("when the container has an LRP lifecycle tag", func() {
 					BeforeEach(func() {
 						container = executor.Container{
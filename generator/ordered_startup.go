@@ -0,0 +1,19 @@
+package generator
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/executor"
+)
+
+// ParseStartupReadiness validates the state that the earlier instances of an
+// LRP on the cell must reach before a later instance is started. An empty
+// readiness starts instances in whatever order they arrive.
+func ParseStartupReadiness(readiness string) (executor.State, error) {
+	switch state := executor.State(readiness); state {
+	case "", executor.StateCreated, executor.StateRunning:
+		return state, nil
+	default:
+		return "", fmt.Errorf("invalid startup readiness %q: must be %q or %q", readiness, executor.StateCreated, executor.StateRunning)
+	}
+}
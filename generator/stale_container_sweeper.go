@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/bbs"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/generator/internal"
+)
+
+// StaleContainerSweeper periodically deletes claimed LRP containers that were
+// allocated more than maxAge ago, have not started running, and have no
+// actual LRP on the cell in the BBS. Such containers are left behind when the
+// actual LRP is removed or moves to another cell while the container is being
+// set up, and would otherwise hold the cell's resources until the executor
+// gives up on them. Reserved containers are left alone: they may be held back
+// by the startup gates, and the executor reaps them once their reservation
+// expires.
+type StaleContainerSweeper struct {
+	logger           lager.Logger
+	interval         time.Duration
+	maxAge           time.Duration
+	clock            clock.Clock
+	cellID           string
+	associationStore internal.AssociationStore
+	executorClient   executor.Client
+}
+
+func NewStaleContainerSweeper(
+	logger lager.Logger,
+	interval time.Duration,
+	maxAge time.Duration,
+	clock clock.Clock,
+	cellID string,
+	bbsClient bbs.InternalClient,
+	executorClient executor.Client,
+) *StaleContainerSweeper {
+	return &StaleContainerSweeper{
+		logger:           logger,
+		interval:         interval,
+		maxAge:           maxAge,
+		clock:            clock,
+		cellID:           cellID,
+		associationStore: internal.NewBBSAssociationStore(bbsClient),
+		executorClient:   executorClient,
+	}
+}
+
+func (s *StaleContainerSweeper) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := s.logger.WithData(lager.Data{"max-age": s.maxAge.String()})
+	return rep.NewPeriodicRunner(logger, "stale-container-sweeper", s.interval, s.clock, s.sweep).Run(signals, ready)
+}
+
+func (s *StaleContainerSweeper) sweep(logger lager.Logger) {
+	logger = logger.Session("sweep")
+
+	containers, err := s.executorClient.ListContainers(logger)
+	if err != nil {
+		logger.Error("failed-to-list-containers", err)
+		return
+	}
+
+	groups, err := s.associationStore.ActualLRPGroupsForCell(logger, s.cellID)
+	if err != nil {
+		logger.Error("failed-to-fetch-actual-lrps", err)
+		return
+	}
+
+	tracked := make(map[string]struct{})
+	for _, group := range groups {
+		if group.Instance != nil {
+			tracked[group.Instance.InstanceGuid] = struct{}{}
+		}
+		if group.Evacuating != nil {
+			tracked[group.Evacuating.InstanceGuid] = struct{}{}
+		}
+	}
+
+	now := s.clock.Now()
+	for i := range containers {
+		container := &containers[i]
+		if !s.stale(container, now) {
+			continue
+		}
+		if _, ok := tracked[container.Tags[rep.InstanceGuidTag]]; ok {
+			continue
+		}
+
+		logger.Info("deleting-stale-container", lager.Data{
+			"container-guid":  container.Guid,
+			"container-state": container.State,
+			"age":             now.Sub(time.Unix(0, container.AllocatedAt)).String(),
+		})
+		err := s.executorClient.DeleteContainer(logger, container.Guid)
+		if err != nil {
+			logger.Error("failed-to-delete-stale-container", err, lager.Data{"container-guid": container.Guid})
+		}
+	}
+}
+
+// stale reports whether the container is a claimed LRP container that has
+// been allocated for longer than the maximum age without starting to run.
+func (s *StaleContainerSweeper) stale(container *executor.Container, now time.Time) bool {
+	if container.Tags[rep.LifecycleTag] != rep.LRPLifecycle || container.AllocatedAt == 0 {
+		return false
+	}
+
+	switch container.State {
+	case executor.StateInitializing, executor.StateCreated:
+	default:
+		return false
+	}
+
+	return now.Sub(time.Unix(0, container.AllocatedAt)) > s.maxAge
+}
@@ -0,0 +1,113 @@
+package generator_test
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/executor"
+	efakes "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/generator"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+)
+
+var _ = Describe("StaleContainerSweeper", func() {
+	const (
+		interval = 30 * time.Second
+		maxAge   = 10 * time.Minute
+	)
+
+	var (
+		fakeClock          *fakeclock.FakeClock
+		fakeExecutorClient *efakes.FakeClient
+
+		sweeper *generator.StaleContainerSweeper
+		process ifrit.Process
+	)
+
+	lrpContainer := func(guid string, state executor.State, age time.Duration) executor.Container {
+		return executor.Container{
+			Guid:        guid,
+			State:       state,
+			AllocatedAt: fakeClock.Now().Add(-age).UnixNano(),
+			Tags: executor.Tags{
+				rep.LifecycleTag:    rep.LRPLifecycle,
+				rep.InstanceGuidTag: guid,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		fakeExecutorClient = new(efakes.FakeClient)
+
+		tracked := models.NewClaimedActualLRP(
+			models.NewActualLRPKey("process-guid", 0, "domain"),
+			models.NewActualLRPInstanceKey("tracked", "cell-id"),
+			0,
+		)
+		fakeBBS.ActualLRPGroupsReturns([]*models.ActualLRPGroup{{Instance: tracked}}, nil)
+
+		task := lrpContainer("task", executor.StateReserved, time.Hour)
+		task.Tags[rep.LifecycleTag] = rep.TaskLifecycle
+
+		fakeExecutorClient.ListContainersReturns([]executor.Container{
+			lrpContainer("untracked", executor.StateInitializing, time.Hour),
+			lrpContainer("tracked", executor.StateInitializing, time.Hour),
+			lrpContainer("reserved", executor.StateReserved, time.Hour),
+			lrpContainer("young", executor.StateCreated, time.Minute),
+			lrpContainer("running", executor.StateRunning, time.Hour),
+			task,
+		}, nil)
+
+		sweeper = generator.NewStaleContainerSweeper(logger, interval, maxAge, fakeClock, "cell-id", fakeBBS, fakeExecutorClient)
+	})
+
+	JustBeforeEach(func() {
+		process = ifrit.Invoke(sweeper)
+		Eventually(fakeClock.WatcherCount).Should(Equal(1))
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+	})
+
+	It("does not sweep before the interval elapses", func() {
+		fakeClock.WaitForWatcherAndIncrement(interval - time.Second)
+		Consistently(fakeExecutorClient.ListContainersCallCount).Should(Equal(0))
+	})
+
+	It("deletes only the old untracked claimed containers that have not started, on every interval", func() {
+		fakeClock.WaitForWatcherAndIncrement(interval)
+		Eventually(fakeExecutorClient.DeleteContainerCallCount).Should(Equal(1))
+		_, guid := fakeExecutorClient.DeleteContainerArgsForCall(0)
+		Expect(guid).To(Equal("untracked"))
+		Expect(logger).To(gbytes.Say("deleting-stale-container"))
+
+		_, filter := fakeBBS.ActualLRPGroupsArgsForCall(0)
+		Expect(filter).To(Equal(models.ActualLRPFilter{CellID: "cell-id"}))
+
+		fakeClock.WaitForWatcherAndIncrement(interval)
+		Eventually(fakeExecutorClient.DeleteContainerCallCount).Should(Equal(2))
+		Consistently(fakeExecutorClient.DeleteContainerCallCount).Should(Equal(2))
+	})
+
+	Context("when fetching the actual LRPs fails", func() {
+		BeforeEach(func() {
+			fakeBBS.ActualLRPGroupsReturns(nil, errors.New("boom"))
+		})
+
+		It("does not delete anything", func() {
+			fakeClock.WaitForWatcherAndIncrement(interval)
+			Eventually(logger).Should(gbytes.Say("failed-to-fetch-actual-lrps"))
+			Expect(fakeExecutorClient.DeleteContainerCallCount()).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager"
+)
+
+// AppDrainer moves the cell's instances of a single app elsewhere.
+type AppDrainer interface {
+	DrainApp(logger lager.Logger, processGuid string) ([]models.ActualLRPKey, error)
+}
+
+// AppDrainFunc adapts a function to an AppDrainer.
+type AppDrainFunc func(logger lager.Logger, processGuid string) ([]models.ActualLRPKey, error)
+
+func (f AppDrainFunc) DrainApp(logger lager.Logger, processGuid string) ([]models.ActualLRPKey, error) {
+	return f(logger, processGuid)
+}
+
+type DrainAppHandler struct {
+	drainer AppDrainer
+}
+
+// Drain App Handler serves a route that moves the cell's instances of one app
+// elsewhere while leaving the rest of the cell's work running. It responds
+// with the keys of the instances that were moved.
+func NewDrainAppHandler(drainer AppDrainer) *DrainAppHandler {
+	return &DrainAppHandler{drainer: drainer}
+}
+
+func (h *DrainAppHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	processGuid := r.FormValue(":process_guid")
+	logger = logger.Session("handling-drain-app", lager.Data{"process-guid": processGuid})
+
+	if processGuid == "" {
+		logger.Error("missing-process-guid", errors.New("process_guid missing from request"))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	moved, err := h.drainer.DrainApp(logger, processGuid)
+	if err != nil {
+		logger.Error("failed-to-drain-app", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(moved)
+}
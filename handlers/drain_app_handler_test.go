@@ -0,0 +1,84 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep/handlers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DrainAppHandler", func() {
+	var (
+		logger       *lagertest.TestLogger
+		drainedGuids []string
+		moved        []models.ActualLRPKey
+		drainErr     error
+		handler      *handlers.DrainAppHandler
+		req          *http.Request
+
+		responseRecorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		var err error
+		logger = lagertest.NewTestLogger("test")
+		drainedGuids = nil
+		moved = []models.ActualLRPKey{models.NewActualLRPKey("the-app", 1, "domain")}
+		drainErr = nil
+		handler = handlers.NewDrainAppHandler(handlers.AppDrainFunc(func(_ lager.Logger, processGuid string) ([]models.ActualLRPKey, error) {
+			drainedGuids = append(drainedGuids, processGuid)
+			return moved, drainErr
+		}))
+		responseRecorder = httptest.NewRecorder()
+
+		req, err = http.NewRequest("POST", "", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		values := make(url.Values)
+		values.Set(":process_guid", "the-app")
+		req.URL.RawQuery = values.Encode()
+	})
+
+	JustBeforeEach(func() {
+		handler.ServeHTTP(responseRecorder, req, logger)
+	})
+
+	It("drains the app's instances and responds with the ones moved", func() {
+		Expect(drainedGuids).To(Equal([]string{"the-app"}))
+		Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+
+		var response []models.ActualLRPKey
+		Expect(json.Unmarshal(responseRecorder.Body.Bytes(), &response)).To(Succeed())
+		Expect(response).To(Equal(moved))
+	})
+
+	Context("when the process guid is missing", func() {
+		BeforeEach(func() {
+			req.URL.RawQuery = ""
+		})
+
+		It("responds with 400 BAD REQUEST without draining anything", func() {
+			Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(drainedGuids).To(BeEmpty())
+		})
+	})
+
+	Context("when draining fails", func() {
+		BeforeEach(func() {
+			drainErr = errors.New("boom")
+		})
+
+		It("responds with 500 INTERNAL SERVER ERROR", func() {
+			Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
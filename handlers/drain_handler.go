@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+)
+
+type DrainHandler struct {
+	drainer auctioncellrep.Drainer
+	timeout time.Duration
+}
+
+// Drain Handler serves a route that stops the rep from accepting new work
+// ahead of a rolling upgrade. The containers already on the cell are left
+// running until the cell is evacuated. The response is sent once the work in
+// flight has finished, or with 503 if it does not finish within the timeout.
+// A drain that is called off is reversed through the undrain route.
+func NewDrainHandler(drainer auctioncellrep.Drainer, timeout time.Duration) *DrainHandler {
+	return &DrainHandler{
+		drainer: drainer,
+		timeout: timeout,
+	}
+}
+
+func (h *DrainHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = logger.Session("handling-drain")
+
+	err := h.drainer.Drain(logger, h.timeout)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Undrain serves the route that lets the rep accept new work again.
+func (h *DrainHandler) Undrain(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = logger.Session("handling-undrain")
+
+	h.drainer.Undrain(logger)
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,77 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	"code.cloudfoundry.org/rep/auctioncellrep/auctioncellrepfakes"
+	"code.cloudfoundry.org/rep/handlers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DrainHandler", func() {
+	Describe("ServeHTTP", func() {
+		var (
+			logger      *lagertest.TestLogger
+			fakeDrainer *auctioncellrepfakes.FakeDrainer
+			handler     *handlers.DrainHandler
+
+			responseRecorder *httptest.ResponseRecorder
+		)
+
+		BeforeEach(func() {
+			logger = lagertest.NewTestLogger("test")
+			fakeDrainer = new(auctioncellrepfakes.FakeDrainer)
+			handler = handlers.NewDrainHandler(fakeDrainer, time.Minute)
+			responseRecorder = httptest.NewRecorder()
+		})
+
+		JustBeforeEach(func() {
+			request, err := http.NewRequest("POST", "/drain", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(responseRecorder, request, logger)
+		})
+
+		It("drains the rep with the timeout", func() {
+			Expect(fakeDrainer.DrainCallCount()).To(Equal(1))
+			_, timeout := fakeDrainer.DrainArgsForCall(0)
+			Expect(timeout).To(Equal(time.Minute))
+		})
+
+		It("responds with 200 OK", func() {
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		})
+
+		Context("when the work in flight does not finish in time", func() {
+			BeforeEach(func() {
+				fakeDrainer.DrainReturns(auctioncellrep.ErrDrainTimedOut)
+			})
+
+			It("responds with 503 SERVICE UNAVAILABLE", func() {
+				Expect(responseRecorder.Code).To(Equal(http.StatusServiceUnavailable))
+			})
+		})
+	})
+
+	Describe("Undrain", func() {
+		It("undrains the rep and responds with 200 OK", func() {
+			logger := lagertest.NewTestLogger("test")
+			fakeDrainer := new(auctioncellrepfakes.FakeDrainer)
+			handler := handlers.NewDrainHandler(fakeDrainer, time.Minute)
+			responseRecorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest("POST", "/undrain", nil)
+			Expect(err).NotTo(HaveOccurred())
+			handler.Undrain(responseRecorder, request, logger)
+
+			Expect(fakeDrainer.UndrainCallCount()).To(Equal(1))
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		})
+	})
+})
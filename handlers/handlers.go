@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager"
@@ -15,8 +16,13 @@ func New(
 	localCellClient auctioncellrep.AuctionCellClient,
 	executorClient executor.Client,
 	evacuatable evacuation_context.Evacuatable,
+	drainer auctioncellrep.Drainer,
+	drainTimeout time.Duration,
+	appDrainer AppDrainer,
+	health HealthChecker,
 	logger lager.Logger,
 	secure bool,
+	identity rep.CellIdentity,
 ) rata.Handlers {
 
 	handlers := rata.Handlers{}
@@ -24,21 +30,32 @@ func New(
 		stateHandler := &state{rep: localCellClient}
 		performHandler := &perform{rep: localCellClient}
 		resetHandler := &reset{rep: localCellClient}
+		historyHandler := &history{rep: localCellClient}
+		inFlightHandler := &inFlight{rep: localCellClient}
 		stopLrpHandler := NewStopLRPInstanceHandler(executorClient)
 		cancelTaskHandler := NewCancelTaskHandler(executorClient)
 
-		handlers[rep.StateRoute] = logWrap(stateHandler.ServeHTTP, logger)
+		handlers[rep.StateRoute] = identify(identity, logWrap(stateHandler.ServeHTTP, logger))
 		handlers[rep.PerformRoute] = logWrap(performHandler.ServeHTTP, logger)
 		handlers[rep.Sim_ResetRoute] = logWrap(resetHandler.ServeHTTP, logger)
+		handlers[rep.HistoryRoute] = logWrap(historyHandler.ServeHTTP, logger)
+		handlers[rep.InFlightRoute] = logWrap(inFlightHandler.ServeHTTP, logger)
 
 		handlers[rep.StopLRPInstanceRoute] = logWrap(stopLrpHandler.ServeHTTP, logger)
 		handlers[rep.CancelTaskRoute] = logWrap(cancelTaskHandler.ServeHTTP, logger)
 	} else {
-		pingHandler := NewPingHandler()
+		pingHandler := NewPingHandler(identity)
 		evacuationHandler := NewEvacuationHandler(evacuatable)
+		drainHandler := NewDrainHandler(drainer, drainTimeout)
+		drainAppHandler := NewDrainAppHandler(appDrainer)
+		healthHandler := NewHealthHandler(health)
 
-		handlers[rep.PingRoute] = logWrap(pingHandler.ServeHTTP, logger)
+		handlers[rep.PingRoute] = identify(identity, logWrap(pingHandler.ServeHTTP, logger))
 		handlers[rep.EvacuateRoute] = logWrap(evacuationHandler.ServeHTTP, logger)
+		handlers[rep.DrainRoute] = logWrap(drainHandler.ServeHTTP, logger)
+		handlers[rep.UndrainRoute] = logWrap(drainHandler.Undrain, logger)
+		handlers[rep.DrainAppRoute] = logWrap(drainAppHandler.ServeHTTP, logger)
+		handlers[rep.HealthRoute] = logWrap(healthHandler.ServeHTTP, logger)
 	}
 
 	return handlers
@@ -48,10 +65,15 @@ func NewLegacy(
 	localCellClient auctioncellrep.AuctionCellClient,
 	executorClient executor.Client,
 	evacuatable evacuation_context.Evacuatable,
+	drainer auctioncellrep.Drainer,
+	drainTimeout time.Duration,
+	appDrainer AppDrainer,
+	health HealthChecker,
 	logger lager.Logger,
+	identity rep.CellIdentity,
 ) rata.Handlers {
-	insecureHandlers := New(localCellClient, executorClient, evacuatable, logger, false)
-	secureHandlers := New(localCellClient, executorClient, evacuatable, logger, true)
+	insecureHandlers := New(localCellClient, executorClient, evacuatable, drainer, drainTimeout, appDrainer, health, logger, false, identity)
+	secureHandlers := New(localCellClient, executorClient, evacuatable, drainer, drainTimeout, appDrainer, health, logger, true, identity)
 	for name, handler := range secureHandlers {
 		insecureHandlers[name] = handler
 	}
@@ -71,3 +93,12 @@ func logWrap(loggable func(http.ResponseWriter, *http.Request, lager.Logger), lo
 		loggable(w, r, requestLog)
 	}
 }
+
+// identify adds the cell ID and rep version to the headers of every response.
+func identify(identity rep.CellIdentity, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(rep.CellIDHeader, identity.CellID)
+		w.Header().Set(rep.RepVersionHeader, identity.Version)
+		handler(w, r)
+	}
+}
@@ -7,8 +7,11 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
+	"code.cloudfoundry.org/bbs/models"
 	executorfakes "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/auctioncellrep/auctioncellrepfakes"
@@ -33,14 +36,19 @@ var client *http.Client
 var fakeLocalRep *auctioncellrepfakes.FakeAuctionCellClient
 var repGuid string
 var logger *lagertest.TestLogger
+var identity rep.CellIdentity
 
 var _ = BeforeEach(func() {
 	logger = lagertest.NewTestLogger("handlers")
+	identity = rep.CellIdentity{CellID: "cell-id", Version: "1.2.3"}
 
 	fakeLocalRep = new(auctioncellrepfakes.FakeAuctionCellClient)
 	fakeExecutorClient := new(executorfakes.FakeClient)
 	fakeEvacuatable := new(fake_evacuation_context.FakeEvacuatable)
-	handler, err := rata.NewRouter(rep.Routes, handlers.NewLegacy(fakeLocalRep, fakeExecutorClient, fakeEvacuatable, logger))
+	fakeDrainer := new(auctioncellrepfakes.FakeDrainer)
+	fakeAppDrainer := handlers.AppDrainFunc(func(lager.Logger, string) ([]models.ActualLRPKey, error) { return nil, nil })
+	fakeHealth := handlers.HealthCheckFunc(func() bool { return true })
+	handler, err := rata.NewRouter(rep.Routes, handlers.NewLegacy(fakeLocalRep, fakeExecutorClient, fakeEvacuatable, fakeDrainer, time.Minute, fakeAppDrainer, fakeHealth, logger, identity))
 	Expect(err).NotTo(HaveOccurred())
 	server = httptest.NewServer(handler)
 
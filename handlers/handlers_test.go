@@ -1,7 +1,12 @@
 package handlers_test
 
 import (
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep/auctioncellrepfakes"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
 	"code.cloudfoundry.org/rep/handlers"
 
@@ -17,7 +22,10 @@ var _ = Describe("NewLegacy", func() {
 
 		fakeExecutorClient := new(executorfakes.FakeClient)
 		fakeEvacuatable := new(fake_evacuation_context.FakeEvacuatable)
-		handlers := handlers.NewLegacy(fakeLocalRep, fakeExecutorClient, fakeEvacuatable, logger)
+		fakeDrainer := new(auctioncellrepfakes.FakeDrainer)
+		fakeAppDrainer := handlers.AppDrainFunc(func(lager.Logger, string) ([]models.ActualLRPKey, error) { return nil, nil })
+		fakeHealth := handlers.HealthCheckFunc(func() bool { return true })
+		handlers := handlers.NewLegacy(fakeLocalRep, fakeExecutorClient, fakeEvacuatable, fakeDrainer, time.Minute, fakeAppDrainer, fakeHealth, logger, identity)
 
 		for _, route := range rep.Routes {
 			Expect(handlers[route.Name]).NotTo(BeNil())
@@ -32,7 +40,10 @@ var _ = Describe("New", func() {
 		BeforeEach(func() {
 			fakeExecutorClient := new(executorfakes.FakeClient)
 			fakeEvacuatable := new(fake_evacuation_context.FakeEvacuatable)
-			test_handlers = handlers.New(fakeLocalRep, fakeExecutorClient, fakeEvacuatable, logger, false)
+			fakeDrainer := new(auctioncellrepfakes.FakeDrainer)
+			fakeAppDrainer := handlers.AppDrainFunc(func(lager.Logger, string) ([]models.ActualLRPKey, error) { return nil, nil })
+			fakeHealth := handlers.HealthCheckFunc(func() bool { return true })
+			test_handlers = handlers.New(fakeLocalRep, fakeExecutorClient, fakeEvacuatable, fakeDrainer, time.Minute, fakeAppDrainer, fakeHealth, logger, false, identity)
 		})
 
 		It("has no secure routes", func() {
@@ -52,7 +63,10 @@ var _ = Describe("New", func() {
 		BeforeEach(func() {
 			fakeExecutorClient := new(executorfakes.FakeClient)
 			fakeEvacuatable := new(fake_evacuation_context.FakeEvacuatable)
-			test_handlers = handlers.New(fakeLocalRep, fakeExecutorClient, fakeEvacuatable, logger, true)
+			fakeDrainer := new(auctioncellrepfakes.FakeDrainer)
+			fakeAppDrainer := handlers.AppDrainFunc(func(lager.Logger, string) ([]models.ActualLRPKey, error) { return nil, nil })
+			fakeHealth := handlers.HealthCheckFunc(func() bool { return true })
+			test_handlers = handlers.New(fakeLocalRep, fakeExecutorClient, fakeEvacuatable, fakeDrainer, time.Minute, fakeAppDrainer, fakeHealth, logger, true, identity)
 		})
 
 		It("has all the secure routes", func() {
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// HealthChecker reports whether the rep is healthy.
+type HealthChecker interface {
+	Healthy() bool
+}
+
+// HealthCheckFunc adapts a function to a HealthChecker.
+type HealthCheckFunc func() bool
+
+func (f HealthCheckFunc) Healthy() bool {
+	return f()
+}
+
+type HealthHandler struct {
+	checker HealthChecker
+}
+
+// Health Handler serves a route for liveness and readiness probes. It responds
+// with 200 while the rep is healthy and with 503 otherwise.
+func NewHealthHandler(checker HealthChecker) *HealthHandler {
+	return &HealthHandler{checker: checker}
+}
+
+func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	if !h.checker.Healthy() {
+		logger.Info("unhealthy")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,52 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep/handlers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HealthHandler", func() {
+	Describe("ServeHTTP", func() {
+		var (
+			logger  *lagertest.TestLogger
+			healthy bool
+			handler *handlers.HealthHandler
+
+			responseRecorder *httptest.ResponseRecorder
+		)
+
+		BeforeEach(func() {
+			logger = lagertest.NewTestLogger("test")
+			healthy = true
+			handler = handlers.NewHealthHandler(handlers.HealthCheckFunc(func() bool { return healthy }))
+			responseRecorder = httptest.NewRecorder()
+		})
+
+		JustBeforeEach(func() {
+			request, err := http.NewRequest("GET", "/health", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(responseRecorder, request, logger)
+		})
+
+		It("responds with 200 OK", func() {
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		})
+
+		Context("when the rep is not healthy", func() {
+			BeforeEach(func() {
+				healthy = false
+			})
+
+			It("responds with 503 SERVICE UNAVAILABLE", func() {
+				Expect(responseRecorder.Code).To(Equal(http.StatusServiceUnavailable))
+			})
+		})
+	})
+})
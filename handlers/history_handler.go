@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+)
+
+type history struct {
+	rep auctioncellrep.AuctionCellClient
+}
+
+// ServeHTTP returns the cell's provisioning history. The optional since query
+// parameter is an RFC3339 timestamp and the optional guid parameter limits the
+// events to a single container or process guid.
+func (h *history) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = logger.Session("fetch-history")
+
+	query := r.URL.Query()
+
+	var since time.Time
+	if value := query.Get("since"); value != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			logger.Error("failed-to-parse-since", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	events := h.rep.History(since, query.Get("guid"))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
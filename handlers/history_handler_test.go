@@ -0,0 +1,78 @@
+package handlers_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("History", func() {
+	var events []rep.ProvisioningEvent
+
+	requestHistory := func(query string) (int, []byte) {
+		request, err := requestGenerator.CreateRequest(rep.HistoryRoute, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		request.URL.RawQuery = query
+
+		response, err := client.Do(request)
+		Expect(err).NotTo(HaveOccurred())
+		defer response.Body.Close()
+
+		body, err := ioutil.ReadAll(response.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return response.StatusCode, body
+	}
+
+	BeforeEach(func() {
+		events = []rep.ProvisioningEvent{
+			{
+				Time:          time.Unix(100, 0).UTC(),
+				ContainerGuid: "container-1",
+				ProcessGuid:   "process-1",
+				Lifecycle:     rep.LRPLifecycle,
+				Succeeded:     true,
+			},
+			{
+				Time:          time.Unix(200, 0).UTC(),
+				ContainerGuid: "task-1",
+				Lifecycle:     rep.TaskLifecycle,
+				FailureReason: "insufficient resources",
+			},
+		}
+		fakeLocalRep.HistoryReturns(events)
+	})
+
+	It("returns the history as JSON", func() {
+		status, body := requestHistory("")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(MatchJSON(JSONFor(events)))
+
+		Expect(fakeLocalRep.HistoryCallCount()).To(Equal(1))
+		since, guid := fakeLocalRep.HistoryArgsForCall(0)
+		Expect(since.IsZero()).To(BeTrue())
+		Expect(guid).To(BeEmpty())
+	})
+
+	It("passes the query parameters through", func() {
+		status, _ := requestHistory("since=2017-03-01T12:00:00Z&guid=process-1")
+		Expect(status).To(Equal(http.StatusOK))
+
+		Expect(fakeLocalRep.HistoryCallCount()).To(Equal(1))
+		since, guid := fakeLocalRep.HistoryArgsForCall(0)
+		Expect(since).To(BeTemporally("==", time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC)))
+		Expect(guid).To(Equal("process-1"))
+	})
+
+	Context("when since is not a valid timestamp", func() {
+		It("returns a StatusBadRequest", func() {
+			status, _ := requestHistory("since=yesterday")
+			Expect(status).To(Equal(http.StatusBadRequest))
+			Expect(fakeLocalRep.HistoryCallCount()).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+)
+
+type inFlight struct {
+	rep auctioncellrep.AuctionCellClient
+}
+
+// ServeHTTP returns the guids of the containers the cell is allocating or
+// starting.
+func (h *inFlight) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	guids := h.rep.InFlight()
+	logger.Debug("fetched-in-flight", lager.Data{"num-in-flight": len(guids)})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(guids)
+}
@@ -0,0 +1,31 @@
+package handlers_test
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InFlight", func() {
+	It("returns the guids of the containers in flight as JSON", func() {
+		fakeLocalRep.InFlightReturns([]string{"container-1", "container-2"})
+
+		request, err := requestGenerator.CreateRequest(rep.InFlightRoute, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		response, err := client.Do(request)
+		Expect(err).NotTo(HaveOccurred())
+		defer response.Body.Close()
+
+		body, err := ioutil.ReadAll(response.Body)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(body).To(MatchJSON(`["container-1","container-2"]`))
+		Expect(fakeLocalRep.InFlightCallCount()).To(Equal(1))
+	})
+})
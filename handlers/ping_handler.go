@@ -1,18 +1,24 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
 )
 
-type PingHandler struct{}
+type PingHandler struct {
+	identity rep.CellIdentity
+}
 
 // Ping Handler serves a route that is called by the rep ctl script
-func NewPingHandler() *PingHandler {
-	return &PingHandler{}
+func NewPingHandler(identity rep.CellIdentity) *PingHandler {
+	return &PingHandler{identity: identity}
 }
 
 func (h PingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.identity)
 }
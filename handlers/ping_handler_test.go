@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 
 	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/handlers"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -19,7 +20,7 @@ var _ = Describe("PingHandler", func() {
 
 	JustBeforeEach(func() {
 		logger := lagertest.NewTestLogger("ping-handler")
-		pingHandler = handlers.NewPingHandler()
+		pingHandler = handlers.NewPingHandler(rep.CellIdentity{CellID: "cell-id", Version: "1.2.3"})
 		resp = httptest.NewRecorder()
 
 		var err error
@@ -32,4 +33,8 @@ var _ = Describe("PingHandler", func() {
 	It("responds with 200 OK", func() {
 		Expect(resp.Code).To(Equal(http.StatusOK))
 	})
+
+	It("identifies the cell in the body", func() {
+		Expect(resp.Body.String()).To(MatchJSON(`{"cell_id": "cell-id", "version": "1.2.3"}`))
+	})
 })
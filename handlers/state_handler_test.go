@@ -27,6 +27,18 @@ var _ = Describe("State", func() {
 		Expect(fakeLocalRep.StateCallCount()).To(Equal(1))
 	})
 
+	It("identifies the cell in the response headers", func() {
+		request, err := requestGenerator.CreateRequest(rep.StateRoute, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		response, err := client.Do(request)
+		Expect(err).NotTo(HaveOccurred())
+		response.Body.Close()
+
+		Expect(response.Header.Get(rep.CellIDHeader)).To(Equal("cell-id"))
+		Expect(response.Header.Get(rep.RepVersionHeader)).To(Equal("1.2.3"))
+	})
+
 	Context("when the state call is not healthy", func() {
 		BeforeEach(func() {
 			fakeLocalRep.StateReturns(repState, false, nil)
@@ -41,6 +41,12 @@ func (h StopLRPInstanceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	}
 
 	err := h.client.StopContainer(logger, rep.LRPContainerGuid(processGuid, instanceGuid))
+	if err == executor.ErrContainerNotFound {
+		logger.Info("container-not-found")
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		logger.Error("failed-to-stop-container", err)
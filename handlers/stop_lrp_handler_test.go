@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 
+	"code.cloudfoundry.org/executor"
 	executorfakes "code.cloudfoundry.org/executor/fakes"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
@@ -73,6 +74,16 @@ var _ = Describe("StopLRPInstanceHandler", func() {
 			})
 		})
 
+		Context("but the cell has no container for the instance", func() {
+			BeforeEach(func() {
+				fakeClient.StopContainerReturns(executor.ErrContainerNotFound)
+			})
+
+			It("responds with 202 Accepted", func() {
+				Expect(resp.Code).To(Equal(http.StatusAccepted))
+			})
+		})
+
 		Context("but StopContainer fails", func() {
 			BeforeEach(func() {
 				fakeClient.StopContainerReturns(errors.New("fail"))
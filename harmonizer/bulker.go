@@ -1,7 +1,10 @@
 package harmonizer
 
 import (
+	"context"
+	"errors"
 	"os"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/clock"
@@ -14,6 +17,8 @@ import (
 
 const repBulkSyncDuration = "RepBulkSyncDuration"
 
+var ErrBulkerAlreadyRunning = errors.New("bulker is already running")
+
 type Bulker struct {
 	logger lager.Logger
 
@@ -24,6 +29,11 @@ type Bulker struct {
 	generator              generator.Generator
 	queue                  operationq.Queue
 	metronClient           loggregator_v2.Client
+	idleConfig             IdleConfig
+	jitter                 Jitter
+
+	runningLock sync.Mutex
+	running     bool
 }
 
 func NewBulker(
@@ -35,6 +45,8 @@ func NewBulker(
 	generator generator.Generator,
 	queue operationq.Queue,
 	metronClient loggregator_v2.Client,
+	idleConfig IdleConfig,
+	jitter Jitter,
 ) *Bulker {
 	return &Bulker{
 		logger: logger,
@@ -46,10 +58,18 @@ func NewBulker(
 		generator:              generator,
 		queue:                  queue,
 		metronClient:           metronClient,
+		idleConfig:             idleConfig,
+		jitter:                 jitter,
 	}
 }
 
 func (b *Bulker) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	if !b.startRunning() {
+		b.logger.Error("bulker-already-running", ErrBulkerAlreadyRunning)
+		return ErrBulkerAlreadyRunning
+	}
+	defer b.stopRunning()
+
 	evacuateNotify := b.evacuationNotifier.EvacuateNotify()
 	close(ready)
 
@@ -57,10 +77,13 @@ func (b *Bulker) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 
 	logger.Info("starting", lager.Data{
 		"interval": b.pollInterval.String(),
+		"jitter":   b.jitter.Max.String(),
 	})
 	defer logger.Info("finished")
 
 	interval := b.pollInterval
+	evacuating := false
+	var due time.Time
 
 	timer := b.clock.NewTimer(interval)
 	defer timer.Stop()
@@ -68,6 +91,13 @@ func (b *Bulker) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 	for {
 		select {
 		case <-timer.C():
+			if due.IsZero() {
+				due = b.clock.Now()
+			}
+			if !evacuating && b.deferSync(logger, due) {
+				timer.Reset(idleRecheckInterval)
+				continue
+			}
 
 		case <-evacuateNotify:
 			timer.Stop()
@@ -75,18 +105,73 @@ func (b *Bulker) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 
 			logger.Info("notified-of-evacuation")
 			interval = b.evacuationPollInterval
+			evacuating = true
 
 		case signal := <-signals:
 			logger.Info("received-signal", lager.Data{"signal": signal.String()})
 			return nil
 		}
 
-		b.sync(logger)
-		timer.Reset(interval)
+		synced := b.sync(logger)
+		due = time.Time{}
+		if synced || evacuating {
+			timer.Reset(interval)
+		} else {
+			// The BBS may be back by the next sync, and so may every other cell
+			// that failed to sync alongside this one.
+			timer.Reset(b.jitter.Apply(interval))
+		}
 	}
 }
 
-func (b *Bulker) sync(logger lager.Logger) {
+// RunWithContext runs the bulker as Run does, with the context taking the
+// place of the signals: once it is done the bulker stops as it would on a
+// signal, and can be run again.
+func (b *Bulker) RunWithContext(ctx context.Context, ready chan<- struct{}) error {
+	signals := make(chan os.Signal, 1)
+	finished := make(chan struct{})
+	defer close(finished)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			signals <- os.Interrupt
+		case <-finished:
+		}
+	}()
+
+	return b.Run(signals, ready)
+}
+
+// startRunning marks the bulker as running, unless it already is. Running the
+// same bulker twice at once would sync the cell twice every interval.
+func (b *Bulker) startRunning() bool {
+	b.runningLock.Lock()
+	defer b.runningLock.Unlock()
+
+	if b.running {
+		return false
+	}
+	b.running = true
+	return true
+}
+
+// Running reports whether the bulker's sync loop is running.
+func (b *Bulker) Running() bool {
+	b.runningLock.Lock()
+	defer b.runningLock.Unlock()
+	return b.running
+}
+
+func (b *Bulker) stopRunning() {
+	b.runningLock.Lock()
+	defer b.runningLock.Unlock()
+	b.running = false
+}
+
+// sync generates and queues the operations that bring the cell in step with
+// the BBS. It returns false if the operations could not be generated.
+func (b *Bulker) sync(logger lager.Logger) bool {
 	logger = logger.Session("sync")
 
 	logger.Info("starting")
@@ -105,10 +190,11 @@ func (b *Bulker) sync(logger lager.Logger) {
 
 	if batchError != nil {
 		logger.Error("failed-to-generate-operations", batchError)
-		return
+		return false
 	}
 
 	for _, operation := range ops {
 		b.queue.Push(operation)
 	}
+	return true
 }
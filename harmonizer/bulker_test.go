@@ -1,7 +1,9 @@
 package harmonizer_test
 
 import (
+	"context"
 	"errors"
+	"math/rand"
 	"os"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
 	"code.cloudfoundry.org/rep/generator/fake_generator"
 	"code.cloudfoundry.org/rep/harmonizer"
+	"code.cloudfoundry.org/rep/harmonizer/fake_harmonizer"
 	mfakes "code.cloudfoundry.org/go-loggregator/loggregator_v2/fakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -31,6 +34,8 @@ var _ = Describe("Bulker", func() {
 		evacuatable            evacuation_context.Evacuatable
 		evacuationNotifier     evacuation_context.EvacuationNotifier
 		fakeMetronClient       *mfakes.FakeClient
+		idleConfig             harmonizer.IdleConfig
+		jitter                 harmonizer.Jitter
 
 		bulker  *harmonizer.Bulker
 		process ifrit.Process
@@ -45,8 +50,13 @@ var _ = Describe("Bulker", func() {
 		fakeQueue = new(fake_operationq.FakeQueue)
 		fakeMetronClient = new(mfakes.FakeClient)
 
+		idleConfig = harmonizer.IdleConfig{}
+		jitter = harmonizer.Jitter{}
+
 		evacuatable, _, evacuationNotifier = evacuation_context.New()
+	})
 
+	JustBeforeEach(func() {
 		bulker = harmonizer.NewBulker(
 			logger,
 			pollInterval,
@@ -56,10 +66,10 @@ var _ = Describe("Bulker", func() {
 			fakeGenerator,
 			fakeQueue,
 			fakeMetronClient,
+			idleConfig,
+			jitter,
 		)
-	})
 
-	JustBeforeEach(func() {
 		process = ifrit.Invoke(bulker)
 		Eventually(fakeClock.WatcherCount).Should(Equal(1))
 	})
@@ -69,6 +79,149 @@ var _ = Describe("Bulker", func() {
 		Eventually(process.Wait()).Should(Receive())
 	})
 
+	Context("when the sync is jittered", func() {
+		const seed = 42
+		var expectedDelay time.Duration
+
+		BeforeEach(func() {
+			jitter = harmonizer.NewJitter(5*time.Second, rand.NewSource(seed))
+			expectedDelay = harmonizer.NewJitter(5*time.Second, rand.NewSource(seed)).Apply(0)
+			Expect(expectedDelay).To(BeNumerically(">", 0))
+		})
+
+		It("does not delay syncs that follow one that succeeded", func() {
+			fakeClock.Increment(pollInterval)
+			Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(1))
+
+			fakeClock.WaitForWatcherAndIncrement(pollInterval)
+			Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(2))
+		})
+
+		Context("when a sync fails", func() {
+			BeforeEach(func() {
+				calls := 0
+				fakeGenerator.BatchOperationsStub = func(lager.Logger) (map[string]operationq.Operation, error) {
+					calls++
+					if calls == 1 {
+						return nil, errors.New("nope")
+					}
+					return nil, nil
+				}
+			})
+
+			JustBeforeEach(func() {
+				fakeClock.Increment(pollInterval)
+				Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(1))
+			})
+
+			It("waits for the poll interval plus the delay before the next sync only", func() {
+				fakeClock.WaitForWatcherAndIncrement(pollInterval + expectedDelay - time.Nanosecond)
+				Consistently(fakeGenerator.BatchOperationsCallCount).Should(Equal(1))
+
+				fakeClock.Increment(time.Nanosecond)
+				Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(2))
+
+				fakeClock.WaitForWatcherAndIncrement(pollInterval)
+				Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(3))
+			})
+
+			It("does not sync once signaled during the delay", func() {
+				fakeClock.WaitForWatcherAndIncrement(pollInterval)
+				process.Signal(os.Interrupt)
+				Eventually(process.Wait()).Should(Receive(BeNil()))
+
+				fakeClock.Increment(5 * time.Second)
+				Consistently(fakeGenerator.BatchOperationsCallCount).Should(Equal(1))
+			})
+		})
+
+		Context("when the cell is evacuating", func() {
+			BeforeEach(func() {
+				fakeGenerator.BatchOperationsReturns(nil, errors.New("nope"))
+				evacuatable.Evacuate()
+			})
+
+			It("does not delay the evacuation syncs", func() {
+				Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(1))
+
+				fakeClock.WaitForWatcherAndIncrement(evacuationPollInterval)
+				Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(2))
+			})
+		})
+	})
+
+	Context("when the bulker is run while it is already running", func() {
+		It("returns an error without becoming ready", func() {
+			ready := make(chan struct{})
+			err := bulker.Run(make(chan os.Signal), ready)
+			Expect(err).To(Equal(harmonizer.ErrBulkerAlreadyRunning))
+			Expect(ready).NotTo(BeClosed())
+		})
+
+		It("leaves the running bulker syncing as usual", func() {
+			bulker.Run(make(chan os.Signal), make(chan struct{}))
+
+			fakeClock.WaitForWatcherAndIncrement(pollInterval)
+			Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(1))
+		})
+	})
+
+	Context("when the bulker is run with a context", func() {
+		var (
+			cancel  context.CancelFunc
+			runErr  chan error
+			started chan struct{}
+		)
+
+		JustBeforeEach(func() {
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			runErr = make(chan error, 1)
+			started = make(chan struct{})
+			go func() {
+				runErr <- bulker.RunWithContext(ctx, started)
+			}()
+			Eventually(started).Should(BeClosed())
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		It("syncs until the context is cancelled, then stops as it would on a signal", func() {
+			fakeClock.WaitForWatcherAndIncrement(pollInterval)
+			Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(1))
+
+			cancel()
+			Eventually(runErr).Should(Receive(BeNil()))
+			Expect(logger).To(gbytes.Say("received-signal"))
+
+			fakeClock.Increment(pollInterval)
+			Consistently(fakeGenerator.BatchOperationsCallCount).Should(Equal(1))
+
+			process = ifrit.Invoke(bulker)
+			fakeClock.WaitForWatcherAndIncrement(pollInterval)
+			Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(2))
+		})
+	})
+
+	Context("when the bulker is run again after it has been stopped", func() {
+		JustBeforeEach(func() {
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+
+			process = ifrit.Invoke(bulker)
+		})
+
+		It("syncs as usual", func() {
+			fakeClock.WaitForWatcherAndIncrement(pollInterval)
+			Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(1))
+		})
+	})
+
 	itPerformsBatchOperations := func(expectedQueueLength int) {
 		Context("when generating the batch operations succeeds", func() {
 			var (
@@ -166,4 +319,56 @@ var _ = Describe("Bulker", func() {
 			})
 		})
 	})
+
+	Context("when the sync is deferred while the cell is busy", func() {
+		var fakeActivity *fake_harmonizer.FakeActivityReporter
+
+		BeforeEach(func() {
+			fakeActivity = new(fake_harmonizer.FakeActivityReporter)
+			fakeActivity.StartingReturns(3)
+
+			idleConfig = harmonizer.IdleConfig{
+				Activity:    fakeActivity,
+				MaxStarting: 2,
+				MaxDefer:    10 * time.Second,
+			}
+		})
+
+		JustBeforeEach(func() {
+			fakeClock.WaitForWatcherAndIncrement(pollInterval)
+		})
+
+		It("does not sync while too many containers are starting", func() {
+			Eventually(logger).Should(gbytes.Say("deferring-sync-while-busy"))
+			Consistently(fakeGenerator.BatchOperationsCallCount).Should(BeZero())
+		})
+
+		It("syncs once the cell quietens down", func() {
+			Eventually(fakeActivity.StartingCallCount).Should(Equal(1))
+			fakeActivity.StartingReturns(2)
+
+			fakeClock.WaitForWatcherAndIncrement(time.Second)
+			Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(1))
+		})
+
+		It("syncs anyway once the sync has been deferred for the max defer", func() {
+			Eventually(fakeActivity.StartingCallCount).Should(Equal(1))
+
+			fakeClock.WaitForWatcherAndIncrement(10 * time.Second)
+			Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(1))
+			Expect(logger).To(gbytes.Say("forcing-deferred-sync"))
+			Expect(fakeActivity.StartingCallCount()).To(Equal(1))
+		})
+
+		Context("when the cell is evacuating", func() {
+			BeforeEach(func() {
+				evacuatable.Evacuate()
+			})
+
+			It("does not defer the sync", func() {
+				Eventually(fakeGenerator.BatchOperationsCallCount).Should(BeNumerically(">=", 1))
+				Expect(fakeActivity.StartingCallCount()).To(BeZero())
+			})
+		})
+	})
 })
@@ -2,29 +2,46 @@ package harmonizer
 
 import (
 	"os"
+	"sync"
+	"time"
 
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/operationq"
 	"code.cloudfoundry.org/rep/generator"
 )
 
+// The consumer waits minResubscribeDelay before subscribing again to an
+// operation stream that has closed, doubling the wait after each failed
+// attempt up to maxResubscribeDelay.
+const (
+	minResubscribeDelay = time.Second
+	maxResubscribeDelay = 30 * time.Second
+)
+
 type EventConsumer struct {
 	logger         lager.Logger
 	executorClient executor.Client
 	generator      generator.Generator
 	queue          operationq.Queue
+	clock          clock.Clock
+
+	subscribedLock sync.Mutex
+	subscribed     bool
 }
 
 func NewEventConsumer(
 	logger lager.Logger,
 	generator generator.Generator,
 	queue operationq.Queue,
+	clock clock.Clock,
 ) *EventConsumer {
 	return &EventConsumer{
 		logger:    logger,
 		generator: generator,
 		queue:     queue,
+		clock:     clock,
 	}
 }
 
@@ -39,6 +56,9 @@ func (consumer *EventConsumer) Run(signals <-chan os.Signal, ready chan<- struct
 		return err
 	}
 
+	consumer.setSubscribed(true)
+	defer consumer.setSubscribed(false)
+
 	close(ready)
 	logger.Info("started")
 
@@ -47,7 +67,14 @@ func (consumer *EventConsumer) Run(signals <-chan os.Signal, ready chan<- struct
 		case op, ok := <-stream:
 			if !ok {
 				logger.Info("event-stream-closed")
-				return nil
+				consumer.setSubscribed(false)
+
+				stream = consumer.resubscribe(logger, signals)
+				if stream == nil {
+					return nil
+				}
+				consumer.setSubscribed(true)
+				continue
 			}
 
 			consumer.queue.Push(op)
@@ -60,3 +87,48 @@ func (consumer *EventConsumer) Run(signals <-chan os.Signal, ready chan<- struct
 
 	return nil
 }
+
+// resubscribe subscribes to a new operation stream, backing off between failed
+// attempts. The generator keeps tracking the cell's containers while the
+// stream is down, so nothing is scheduled again once it is back. It returns
+// nil if the consumer is signaled first.
+func (consumer *EventConsumer) resubscribe(logger lager.Logger, signals <-chan os.Signal) <-chan operationq.Operation {
+	delay := minResubscribeDelay
+
+	for {
+		timer := consumer.clock.NewTimer(delay)
+		select {
+		case <-timer.C():
+		case signal := <-signals:
+			timer.Stop()
+			logger.Info("received-signal", lager.Data{"signal": signal.String()})
+			return nil
+		}
+
+		stream, err := consumer.generator.OperationStream(consumer.logger)
+		if err == nil {
+			logger.Info("resubscribed-to-operation-stream")
+			return stream
+		}
+		logger.Error("failed-resubscribing-to-operation-stream", err, lager.Data{"delay": delay.String()})
+
+		delay *= 2
+		if delay > maxResubscribeDelay {
+			delay = maxResubscribeDelay
+		}
+	}
+}
+
+// Subscribed reports whether the consumer is running with an open operation
+// stream. It is false while the consumer is subscribing to a new stream.
+func (consumer *EventConsumer) Subscribed() bool {
+	consumer.subscribedLock.Lock()
+	defer consumer.subscribedLock.Unlock()
+	return consumer.subscribed
+}
+
+func (consumer *EventConsumer) setSubscribed(subscribed bool) {
+	consumer.subscribedLock.Lock()
+	defer consumer.subscribedLock.Unlock()
+	consumer.subscribed = subscribed
+}
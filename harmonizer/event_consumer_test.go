@@ -3,7 +3,10 @@ package harmonizer_test
 import (
 	"errors"
 	"os"
+	"time"
 
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
 	"code.cloudfoundry.org/operationq"
 	"code.cloudfoundry.org/operationq/fake_operationq"
@@ -19,6 +22,7 @@ var _ = Describe("EventConsumer", func() {
 		logger        *lagertest.TestLogger
 		fakeGenerator *fake_generator.FakeGenerator
 		fakeQueue     *fake_operationq.FakeQueue
+		fakeClock     *fakeclock.FakeClock
 
 		consumer *harmonizer.EventConsumer
 		process  ifrit.Process
@@ -29,7 +33,9 @@ var _ = Describe("EventConsumer", func() {
 		fakeGenerator = new(fake_generator.FakeGenerator)
 		fakeQueue = new(fake_operationq.FakeQueue)
 
-		consumer = harmonizer.NewEventConsumer(logger, fakeGenerator, fakeQueue)
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+
+		consumer = harmonizer.NewEventConsumer(logger, fakeGenerator, fakeQueue, fakeClock)
 	})
 
 	JustBeforeEach(func() {
@@ -43,7 +49,7 @@ var _ = Describe("EventConsumer", func() {
 
 	Context("when subscribing to the operation stream succeeds", func() {
 		var (
-			receivedOperations chan<- operationq.Operation
+			receivedOperations chan operationq.Operation
 		)
 
 		BeforeEach(func() {
@@ -69,10 +75,74 @@ var _ = Describe("EventConsumer", func() {
 		})
 
 		Context("when the operation stream terminates", func() {
-			It("exits happily", func() {
+			var (
+				resubscribedOperations chan operationq.Operation
+				failedResubscriptions  int
+			)
+
+			BeforeEach(func() {
+				resubscribedOperations = make(chan operationq.Operation)
+				failedResubscriptions = 0
+
+				operations := receivedOperations
+				calls := 0
+				fakeGenerator.OperationStreamStub = func(lager.Logger) (<-chan operationq.Operation, error) {
+					calls++
+					switch {
+					case calls == 1:
+						return operations, nil
+					case calls <= failedResubscriptions+1:
+						return nil, errors.New("nope")
+					default:
+						return resubscribedOperations, nil
+					}
+				}
+			})
+
+			JustBeforeEach(func() {
 				close(receivedOperations)
+			})
+
+			It("subscribes to a new stream and keeps pushing its operations onto the queue", func() {
+				fakeClock.WaitForWatcherAndIncrement(time.Second)
+				Eventually(fakeGenerator.OperationStreamCallCount).Should(Equal(2))
+
+				fakeOperation := new(fake_operationq.FakeOperation)
+				resubscribedOperations <- fakeOperation
+
+				Eventually(fakeQueue.PushCallCount).Should(Equal(1))
+				Expect(fakeQueue.PushArgsForCall(0)).To(Equal(fakeOperation))
+				Consistently(process.Wait()).ShouldNot(Receive())
+			})
+
+			Context("when subscribing again fails", func() {
+				BeforeEach(func() {
+					failedResubscriptions = 2
+				})
+
+				It("backs off exponentially between attempts", func() {
+					fakeClock.WaitForWatcherAndIncrement(time.Second)
+					Eventually(fakeGenerator.OperationStreamCallCount).Should(Equal(2))
+
+					fakeClock.WaitForWatcherAndIncrement(time.Second)
+					Consistently(fakeGenerator.OperationStreamCallCount).Should(Equal(2))
+					fakeClock.Increment(time.Second)
+					Eventually(fakeGenerator.OperationStreamCallCount).Should(Equal(3))
+
+					fakeClock.WaitForWatcherAndIncrement(4 * time.Second)
+					Eventually(fakeGenerator.OperationStreamCallCount).Should(Equal(4))
+					Eventually(consumer.Subscribed).Should(BeTrue())
+				})
+			})
+
+			Context("when the consumer is signaled while waiting to subscribe again", func() {
+				It("exits without subscribing", func() {
+					Eventually(fakeClock.WatcherCount).Should(Equal(1))
+					process.Signal(os.Interrupt)
 
-				Eventually(process.Wait()).Should(Receive(BeNil()))
+					Eventually(process.Wait()).Should(Receive(BeNil()))
+					Expect(fakeGenerator.OperationStreamCallCount()).To(Equal(1))
+				})
 			})
 		})
 	})
@@ -0,0 +1,77 @@
+// This file was generated by counterfeiter
+package fake_harmonizer
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep/harmonizer"
+)
+
+type FakeActivityReporter struct {
+	StartingStub        func(logger lager.Logger) int
+	startingMutex       sync.RWMutex
+	startingArgsForCall []struct {
+		logger lager.Logger
+	}
+	startingReturns struct {
+		result1 int
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeActivityReporter) Starting(logger lager.Logger) int {
+	fake.startingMutex.Lock()
+	fake.startingArgsForCall = append(fake.startingArgsForCall, struct {
+		logger lager.Logger
+	}{logger})
+	fake.recordInvocation("Starting", []interface{}{logger})
+	fake.startingMutex.Unlock()
+	if fake.StartingStub != nil {
+		return fake.StartingStub(logger)
+	} else {
+		return fake.startingReturns.result1
+	}
+}
+
+func (fake *FakeActivityReporter) StartingCallCount() int {
+	fake.startingMutex.RLock()
+	defer fake.startingMutex.RUnlock()
+	return len(fake.startingArgsForCall)
+}
+
+func (fake *FakeActivityReporter) StartingArgsForCall(i int) lager.Logger {
+	fake.startingMutex.RLock()
+	defer fake.startingMutex.RUnlock()
+	return fake.startingArgsForCall[i].logger
+}
+
+func (fake *FakeActivityReporter) StartingReturns(result1 int) {
+	fake.StartingStub = nil
+	fake.startingReturns = struct {
+		result1 int
+	}{result1}
+}
+
+func (fake *FakeActivityReporter) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.startingMutex.RLock()
+	defer fake.startingMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeActivityReporter) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ harmonizer.ActivityReporter = new(FakeActivityReporter)
@@ -0,0 +1,58 @@
+package harmonizer
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// idleRecheckInterval is how soon the bulker looks at the cell's activity
+// again after deferring a sync.
+const idleRecheckInterval = time.Second
+
+//go:generate counterfeiter -o fake_harmonizer/fake_activity_reporter.go . ActivityReporter
+
+// ActivityReporter reports how busy the cell is provisioning work.
+type ActivityReporter interface {
+	// Starting returns the number of containers on the cell that are on their
+	// way to running.
+	Starting(logger lager.Logger) int
+}
+
+// IdleConfig defers the bulker's sync while more than MaxStarting containers
+// are starting on the cell, so that the sync does not add load while the cell
+// is busiest. A sync is never deferred for longer than MaxDefer past the
+// poll interval, nor while the cell is evacuating. A nil Activity disables
+// the deferral.
+type IdleConfig struct {
+	Activity    ActivityReporter
+	MaxStarting int
+	MaxDefer    time.Duration
+}
+
+// deferSync reports whether the sync due now should wait for the cell to
+// quieten down. deferredSince is when the sync first fell due.
+func (b *Bulker) deferSync(logger lager.Logger, deferredSince time.Time) bool {
+	if b.idleConfig.Activity == nil {
+		return false
+	}
+
+	deferred := b.clock.Since(deferredSince)
+	if deferred >= b.idleConfig.MaxDefer {
+		if deferred > 0 {
+			logger.Info("forcing-deferred-sync", lager.Data{"deferred": deferred.String()})
+		}
+		return false
+	}
+
+	starting := b.idleConfig.Activity.Starting(logger)
+	if starting <= b.idleConfig.MaxStarting {
+		return false
+	}
+
+	logger.Info("deferring-sync-while-busy", lager.Data{
+		"starting":     starting,
+		"max-starting": b.idleConfig.MaxStarting,
+	})
+	return true
+}
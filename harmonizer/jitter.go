@@ -0,0 +1,29 @@
+package harmonizer
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Jitter adds a random delay of up to Max to the interval the bulker waits
+// after a sync that failed, so that cells brought back together by a BBS
+// outage spread their first syncs after it out instead of all hitting the BBS
+// and the executor at once. The zero Jitter adds no delay.
+type Jitter struct {
+	Max  time.Duration
+	rand *rand.Rand
+}
+
+// NewJitter returns a Jitter of up to max drawn from source. It is not safe
+// for concurrent use.
+func NewJitter(max time.Duration, source rand.Source) Jitter {
+	return Jitter{Max: max, rand: rand.New(source)}
+}
+
+// Apply returns interval lengthened by a random delay between zero and Max.
+func (j Jitter) Apply(interval time.Duration) time.Duration {
+	if j.Max <= 0 || j.rand == nil {
+		return interval
+	}
+	return interval + time.Duration(j.rand.Int63n(int64(j.Max)+1))
+}
@@ -0,0 +1,36 @@
+package harmonizer_test
+
+import (
+	"math/rand"
+	"time"
+
+	"code.cloudfoundry.org/rep/harmonizer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Jitter", func() {
+	const interval = 30 * time.Second
+
+	It("lengthens the interval by no more than the max", func() {
+		jitter := harmonizer.NewJitter(5*time.Second, rand.NewSource(42))
+
+		delays := map[time.Duration]struct{}{}
+		for i := 0; i < 100; i++ {
+			applied := jitter.Apply(interval)
+			Expect(applied).To(BeNumerically(">=", interval))
+			Expect(applied).To(BeNumerically("<=", interval+5*time.Second))
+			delays[applied-interval] = struct{}{}
+		}
+		Expect(len(delays)).To(BeNumerically(">", 1))
+	})
+
+	It("leaves the interval alone when it is the zero Jitter", func() {
+		Expect(harmonizer.Jitter{}.Apply(interval)).To(Equal(interval))
+	})
+
+	It("leaves the interval alone when the max is zero", func() {
+		jitter := harmonizer.NewJitter(0, rand.NewSource(42))
+		Expect(jitter.Apply(interval)).To(Equal(interval))
+	})
+})
@@ -0,0 +1,21 @@
+package harmonizer
+
+// Liveness reports whether the rep is still keeping the cell's containers in
+// step with the BBS: the event consumer is subscribed to the executor's events
+// and the bulker's sync loop is running. It is meant to back the liveness and
+// readiness probes of a supervisor wrapping the rep.
+type Liveness struct {
+	consumer *EventConsumer
+	bulker   *Bulker
+}
+
+func NewLiveness(consumer *EventConsumer, bulker *Bulker) *Liveness {
+	return &Liveness{
+		consumer: consumer,
+		bulker:   bulker,
+	}
+}
+
+func (l *Liveness) Healthy() bool {
+	return l.consumer.Subscribed() && l.bulker.Running()
+}
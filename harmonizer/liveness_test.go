@@ -0,0 +1,112 @@
+package harmonizer_test
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/go-loggregator/loggregator_v2/fakes"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/operationq"
+	"code.cloudfoundry.org/operationq/fake_operationq"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
+	"code.cloudfoundry.org/rep/generator/fake_generator"
+	"code.cloudfoundry.org/rep/harmonizer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+)
+
+var _ = Describe("Liveness", func() {
+	var (
+		fakeGenerator *fake_generator.FakeGenerator
+		operations    chan operationq.Operation
+		consumerClock *fakeclock.FakeClock
+
+		consumer        *harmonizer.EventConsumer
+		bulker          *harmonizer.Bulker
+		consumerProcess ifrit.Process
+		bulkerProcess   ifrit.Process
+
+		liveness *harmonizer.Liveness
+	)
+
+	BeforeEach(func() {
+		logger := lagertest.NewTestLogger("test")
+		fakeGenerator = new(fake_generator.FakeGenerator)
+		fakeQueue := new(fake_operationq.FakeQueue)
+		_, _, evacuationNotifier := evacuation_context.New()
+
+		operations = make(chan operationq.Operation)
+		fakeGenerator.OperationStreamReturns(operations, nil)
+
+		consumerClock = fakeclock.NewFakeClock(time.Now())
+		consumer = harmonizer.NewEventConsumer(logger, fakeGenerator, fakeQueue, consumerClock)
+		bulker = harmonizer.NewBulker(
+			logger,
+			30*time.Second,
+			10*time.Second,
+			evacuationNotifier,
+			fakeclock.NewFakeClock(time.Now()),
+			fakeGenerator,
+			fakeQueue,
+			new(mfakes.FakeClient),
+			harmonizer.IdleConfig{},
+			harmonizer.Jitter{},
+		)
+		liveness = harmonizer.NewLiveness(consumer, bulker)
+
+		consumerProcess = nil
+		bulkerProcess = nil
+	})
+
+	AfterEach(func() {
+		for _, process := range []ifrit.Process{consumerProcess, bulkerProcess} {
+			if process != nil {
+				process.Signal(os.Interrupt)
+				Eventually(process.Wait()).Should(Receive())
+			}
+		}
+	})
+
+	It("is not healthy before the rep is running", func() {
+		Expect(liveness.Healthy()).To(BeFalse())
+	})
+
+	Context("when the event consumer and the bulker are running", func() {
+		BeforeEach(func() {
+			consumerProcess = ifrit.Invoke(consumer)
+			bulkerProcess = ifrit.Invoke(bulker)
+		})
+
+		It("is healthy", func() {
+			Expect(liveness.Healthy()).To(BeTrue())
+		})
+
+		Context("when the event stream dies", func() {
+			BeforeEach(func() {
+				fakeGenerator.OperationStreamReturns(make(chan operationq.Operation), nil)
+				close(operations)
+			})
+
+			It("is not healthy until the consumer resubscribes", func() {
+				Eventually(liveness.Healthy).Should(BeFalse())
+
+				consumerClock.WaitForWatcherAndIncrement(time.Second)
+				Eventually(liveness.Healthy).Should(BeTrue())
+			})
+		})
+
+		Context("when the bulker stops", func() {
+			BeforeEach(func() {
+				bulkerProcess.Signal(os.Interrupt)
+				Eventually(bulkerProcess.Wait()).Should(Receive())
+				bulkerProcess = nil
+			})
+
+			It("is not healthy", func() {
+				Expect(liveness.Healthy()).To(BeFalse())
+			})
+		})
+	})
+})
@@ -0,0 +1,21 @@
+package rep
+
+// Version is the version of the rep. It is set at build time with
+// -ldflags "-X code.cloudfoundry.org/rep.Version=<version>".
+var Version = "unknown"
+
+const (
+	CellIDHeader     = "X-Rep-Cell-Id"
+	RepVersionHeader = "X-Rep-Version"
+)
+
+// CellIdentity tells a client which cell and rep answered a request, so that
+// tooling scraping many reps can tell their responses apart.
+type CellIdentity struct {
+	CellID  string `json:"cell_id"`
+	Version string `json:"version"`
+}
+
+func NewCellIdentity(cellID string) CellIdentity {
+	return CellIdentity{CellID: cellID, Version: Version}
+}
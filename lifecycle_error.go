@@ -0,0 +1,54 @@
+package rep
+
+import "fmt"
+
+// LifecyclePhase is the step of starting an LRP instance on the cell that
+// failed.
+type LifecyclePhase string
+
+const (
+	// LifecyclePhasePlace is the cell matching the LRP to one of the stacks it
+	// has preloaded. An LRP that fails it never reaches the executor.
+	LifecyclePhasePlace LifecyclePhase = "place"
+	// LifecyclePhaseAllocate is the executor reserving the container.
+	LifecyclePhaseAllocate LifecyclePhase = "allocate"
+	// LifecyclePhaseRun is the executor accepting the run request.
+	LifecyclePhaseRun LifecyclePhase = "run"
+	// LifecyclePhaseInitialize is the container being set up and started,
+	// up to the instance first becoming ready. It is only reported for
+	// containers the cell saw being claimed or run, so that a container
+	// that was already running when the rep started is not reported as
+	// failing to initialize.
+	LifecyclePhaseInitialize LifecyclePhase = "initialize"
+	// LifecyclePhaseStart is the BBS recording the ready instance as running.
+	// It is reported once per container, however many times the start is
+	// retried.
+	LifecyclePhaseStart LifecyclePhase = "start"
+)
+
+// LifecycleError describes a failure to start an LRP instance on the cell.
+type LifecycleError struct {
+	ProcessGuid   string
+	Index         int32
+	ContainerGuid string
+	Phase         LifecyclePhase
+	Err           error
+}
+
+func (e LifecycleError) Error() string {
+	return fmt.Sprintf("failed to %s container %s of LRP %s at index %d: %s", e.Phase, e.ContainerGuid, e.ProcessGuid, e.Index, e.Err)
+}
+
+// LifecycleErrorHandler is told about every failure to start an LRP instance
+// on the cell, in addition to the failure being logged. A nil
+// LifecycleErrorHandler ignores them. It is for code that embeds the rep's
+// packages, such as integration tests; the rep binary leaves it nil and relies
+// on the logs and metrics.
+type LifecycleErrorHandler func(err LifecycleError)
+
+// Report passes the error to the handler, if there is one.
+func (h LifecycleErrorHandler) Report(err LifecycleError) {
+	if h != nil {
+		h(err)
+	}
+}
@@ -0,0 +1,75 @@
+package rep
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// LifecycleTransition is a step an LRP instance takes on its way to running on
+// the cell.
+type LifecycleTransition string
+
+const (
+	// LifecycleTransitionAllocated is the container being reserved and the
+	// executor accepting its run request.
+	LifecycleTransitionAllocated LifecycleTransition = "allocated"
+	// LifecycleTransitionInitialized is the container having been created.
+	LifecycleTransitionInitialized LifecycleTransition = "initialized"
+	// LifecycleTransitionRunning is the instance first becoming ready.
+	LifecycleTransitionRunning LifecycleTransition = "running"
+)
+
+// LifecycleEvent records an LRP instance on the cell making a transition.
+// Duration is how long the instance spent in the phase the transition ends:
+// allocation, initialization or run. It is zero when the rep did not see the
+// phase begin, for example because it restarted in the middle of it.
+type LifecycleEvent struct {
+	ProcessGuid   string
+	Index         int32
+	ContainerGuid string
+	Transition    LifecycleTransition
+	Time          time.Time
+	Duration      time.Duration
+}
+
+// LifecycleEvents is a bounded stream of lifecycle events for observability
+// tooling. Emitting never blocks: an event that does not fit in the buffer is
+// dropped and counted, so that a slow consumer cannot stall the rep. A nil
+// *LifecycleEvents drops every event. Like LifecycleErrorHandler, it is for
+// code that embeds the rep's packages; the rep binary does not stream events.
+type LifecycleEvents struct {
+	events  chan LifecycleEvent
+	dropped uint64
+}
+
+func NewLifecycleEvents(bufferSize int) *LifecycleEvents {
+	return &LifecycleEvents{
+		events: make(chan LifecycleEvent, bufferSize),
+	}
+}
+
+// Events returns the channel the events are delivered on.
+func (e *LifecycleEvents) Events() <-chan LifecycleEvent {
+	return e.events
+}
+
+// Emit delivers the event if there is room for it, and reports whether there
+// was.
+func (e *LifecycleEvents) Emit(event LifecycleEvent) bool {
+	if e == nil {
+		return false
+	}
+
+	select {
+	case e.events <- event:
+		return true
+	default:
+		atomic.AddUint64(&e.dropped, 1)
+		return false
+	}
+}
+
+// Dropped returns the number of events that did not fit in the buffer.
+func (e *LifecycleEvents) Dropped() uint64 {
+	return atomic.LoadUint64(&e.dropped)
+}
@@ -0,0 +1,39 @@
+package rep
+
+// LimitUsage reports how much of the cell's limits on concurrent container
+// allocations and on the provisioning rate is currently in use. A limit of
+// zero means the operation is not limited.
+type LimitUsage struct {
+	ConcurrencyUsed  int
+	ConcurrencyLimit int
+	RateTokens       int
+	RateLimit        int
+}
+
+//go:generate counterfeiter -o repfakes/fake_limit_reporter.go . LimitReporter
+
+// LimitReporter reports the current LimitUsage.
+type LimitReporter interface {
+	LimitUsage() LimitUsage
+}
+
+type limitReporters []LimitReporter
+
+// MergeLimitReporters returns a LimitReporter that adds up the usage reported
+// by each of the reporters, for when different parts of the rep enforce
+// different limits.
+func MergeLimitReporters(reporters ...LimitReporter) LimitReporter {
+	return limitReporters(reporters)
+}
+
+func (r limitReporters) LimitUsage() LimitUsage {
+	var usage LimitUsage
+	for _, reporter := range r {
+		u := reporter.LimitUsage()
+		usage.ConcurrencyUsed += u.ConcurrencyUsed
+		usage.ConcurrencyLimit += u.ConcurrencyLimit
+		usage.RateTokens += u.RateTokens
+		usage.RateLimit += u.RateLimit
+	}
+	return usage
+}
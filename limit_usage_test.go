@@ -0,0 +1,30 @@
+package rep_test
+
+import (
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/repfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MergeLimitReporters", func() {
+	It("adds up the usage of each reporter", func() {
+		allocations := new(repfakes.FakeLimitReporter)
+		allocations.LimitUsageReturns(rep.LimitUsage{ConcurrencyUsed: 2, ConcurrencyLimit: 3})
+		provisions := new(repfakes.FakeLimitReporter)
+		provisions.LimitUsageReturns(rep.LimitUsage{RateTokens: 7, RateLimit: 30})
+
+		reporter := rep.MergeLimitReporters(allocations, provisions)
+		Expect(reporter.LimitUsage()).To(Equal(rep.LimitUsage{
+			ConcurrencyUsed:  2,
+			ConcurrencyLimit: 3,
+			RateTokens:       7,
+			RateLimit:        30,
+		}))
+	})
+
+	It("reports no usage without any reporters", func() {
+		Expect(rep.MergeLimitReporters().LimitUsage()).To(Equal(rep.LimitUsage{}))
+	})
+})
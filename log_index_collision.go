@@ -0,0 +1,24 @@
+package rep
+
+import "fmt"
+
+// LogIndexCollisionPolicy decides what happens when an LRP instance is about
+// to run with the same log index as another instance of the same LRP on the
+// cell, which would make their logs indistinguishable downstream. The only
+// policy is to reject the instance, as the cell cannot tell which log indices
+// are free across the instances on other cells.
+type LogIndexCollisionPolicy string
+
+const (
+	LogIndexCollisionPolicyNone   LogIndexCollisionPolicy = ""
+	LogIndexCollisionPolicyReject LogIndexCollisionPolicy = "reject"
+)
+
+func NewLogIndexCollisionPolicy(policy string) (LogIndexCollisionPolicy, error) {
+	switch LogIndexCollisionPolicy(policy) {
+	case LogIndexCollisionPolicyNone, LogIndexCollisionPolicyReject:
+		return LogIndexCollisionPolicy(policy), nil
+	default:
+		return LogIndexCollisionPolicyNone, fmt.Errorf("invalid log index collision policy: %q", policy)
+	}
+}
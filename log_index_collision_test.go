@@ -0,0 +1,22 @@
+package rep_test
+
+import (
+	"code.cloudfoundry.org/rep"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewLogIndexCollisionPolicy", func() {
+	It("accepts the reject policy, and none", func() {
+		Expect(rep.NewLogIndexCollisionPolicy("reject")).To(Equal(rep.LogIndexCollisionPolicyReject))
+		Expect(rep.NewLogIndexCollisionPolicy("")).To(Equal(rep.LogIndexCollisionPolicyNone))
+	})
+
+	It("errors on an unknown policy", func() {
+		_, err := rep.NewLogIndexCollisionPolicy("ignore")
+		Expect(err).To(HaveOccurred())
+
+		_, err = rep.NewLogIndexCollisionPolicy("reassign")
+		Expect(err).To(HaveOccurred())
+	})
+})
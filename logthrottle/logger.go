@@ -0,0 +1,152 @@
+// logthrottle collapses storms of identical error logs into periodic summaries
+package logthrottle
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+type logger struct {
+	lager.Logger
+	throttle *throttle
+}
+
+// NewLogger wraps the given logger so that each distinct error is logged at
+// most threshold times per window. Further occurrences are suppressed and
+// reported as a single summary once the window has elapsed.
+func NewLogger(l lager.Logger, clock clock.Clock, window time.Duration, threshold int) lager.Logger {
+	return &logger{
+		Logger: l,
+		throttle: &throttle{
+			clock:     clock,
+			window:    window,
+			threshold: threshold,
+			entries:   map[string]*entry{},
+			lastPrune: clock.Now(),
+		},
+	}
+}
+
+func (l *logger) Session(task string, data ...lager.Data) lager.Logger {
+	return &logger{
+		Logger:   l.Logger.Session(task, data...),
+		throttle: l.throttle,
+	}
+}
+
+func (l *logger) WithData(data lager.Data) lager.Logger {
+	return &logger{
+		Logger:   l.Logger.WithData(data),
+		throttle: l.throttle,
+	}
+}
+
+func (l *logger) Error(action string, err error, data ...lager.Data) {
+	errString := ""
+	if err != nil {
+		errString = err.Error()
+	}
+
+	allowed, summaries := l.throttle.record(l.SessionName()+"."+action+":"+errString, l.Logger, action, errString)
+	for _, summary := range summaries {
+		summary.log(l.throttle.window)
+	}
+
+	if allowed {
+		l.Logger.Error(action, err, data...)
+	}
+}
+
+type entry struct {
+	logger      lager.Logger
+	action      string
+	err         string
+	windowStart time.Time
+	count       int
+}
+
+// summary reports the occurrences of an error suppressed during a window.
+type summary struct {
+	logger      lager.Logger
+	action      string
+	err         string
+	occurrences int
+}
+
+func (s summary) log(window time.Duration) {
+	s.logger.Info("suppressed-repeated-errors", lager.Data{
+		"action":      s.action,
+		"error":       s.err,
+		"occurrences": s.occurrences,
+		"window":      window.String(),
+	})
+}
+
+type throttle struct {
+	clock     clock.Clock
+	window    time.Duration
+	threshold int
+
+	lock      sync.Mutex
+	entries   map[string]*entry
+	lastPrune time.Time
+}
+
+// record notes an occurrence of the error identified by key, logged by logger
+// as action. It reports whether the occurrence should be logged, along with
+// summaries of the occurrences suppressed during windows that have since
+// elapsed, for this key and any other.
+func (t *throttle) record(key string, logger lager.Logger, action, errString string) (bool, []summary) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.clock.Now()
+	summaries := t.prune(now)
+
+	e, ok := t.entries[key]
+	if !ok || now.Sub(e.windowStart) >= t.window {
+		if ok && e.count > t.threshold {
+			summaries = append(summaries, t.summarize(e))
+		}
+		t.entries[key] = &entry{logger: logger, action: action, err: errString, windowStart: now, count: 1}
+		return true, summaries
+	}
+
+	e.count++
+	return e.count <= t.threshold, summaries
+}
+
+// prune forgets errors whose window has elapsed, returning summaries of the
+// occurrences suppressed during those windows, so that neither one-off errors
+// nor storms that have died down accumulate forever and no suppressed
+// occurrence goes unreported.
+func (t *throttle) prune(now time.Time) []summary {
+	if now.Sub(t.lastPrune) < t.window {
+		return nil
+	}
+	t.lastPrune = now
+
+	var summaries []summary
+	for key, e := range t.entries {
+		if now.Sub(e.windowStart) < t.window {
+			continue
+		}
+		if e.count > t.threshold {
+			summaries = append(summaries, t.summarize(e))
+		}
+		delete(t.entries, key)
+	}
+	return summaries
+}
+
+func (t *throttle) summarize(e *entry) summary {
+	return summary{
+		logger:      e.logger,
+		action:      e.action,
+		err:         e.err,
+		occurrences: e.count - t.threshold,
+	}
+}
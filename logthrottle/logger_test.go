@@ -0,0 +1,134 @@
+package logthrottle_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep/logthrottle"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Logger", func() {
+	const window = 10 * time.Second
+
+	var (
+		testLogger *lagertest.TestLogger
+		fakeClock  *fakeclock.FakeClock
+		logger     lager.Logger
+		disaster   error
+	)
+
+	errorLogs := func() []lager.LogFormat {
+		logs := []lager.LogFormat{}
+		for _, log := range testLogger.Logs() {
+			if log.LogLevel == lager.ERROR {
+				logs = append(logs, log)
+			}
+		}
+		return logs
+	}
+
+	BeforeEach(func() {
+		testLogger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		logger = logthrottle.NewLogger(testLogger, fakeClock, window, 2)
+		disaster = errors.New("disaster")
+	})
+
+	It("logs errors up to the threshold within a window", func() {
+		for i := 0; i < 5; i++ {
+			logger.Error("failed-to-do-thing", disaster)
+		}
+
+		Expect(errorLogs()).To(HaveLen(2))
+	})
+
+	It("does not throttle different errors together", func() {
+		logger.Error("failed-to-do-thing", disaster)
+		logger.Error("failed-to-do-thing", disaster)
+		logger.Error("failed-to-do-thing", errors.New("other-disaster"))
+		logger.Error("failed-to-do-other-thing", disaster)
+
+		Expect(errorLogs()).To(HaveLen(4))
+	})
+
+	It("throttles errors logged from sessions", func() {
+		session := logger.Session("some-session")
+		for i := 0; i < 5; i++ {
+			session.Error("failed-to-do-thing", disaster)
+		}
+
+		Expect(errorLogs()).To(HaveLen(2))
+		Expect(errorLogs()[0].Message).To(Equal("test.some-session.failed-to-do-thing"))
+	})
+
+	Context("when the window elapses", func() {
+		BeforeEach(func() {
+			for i := 0; i < 5; i++ {
+				logger.Error("failed-to-do-thing", disaster)
+			}
+			fakeClock.Increment(window)
+			logger.Error("failed-to-do-thing", disaster)
+		})
+
+		It("summarizes the suppressed occurrences", func() {
+			logs := testLogger.Logs()
+			summary := logs[len(logs)-2]
+			Expect(summary.Message).To(Equal("test.suppressed-repeated-errors"))
+			Expect(summary.Data["action"]).To(Equal("failed-to-do-thing"))
+			Expect(summary.Data["error"]).To(Equal("disaster"))
+			Expect(summary.Data["occurrences"]).To(BeEquivalentTo(3))
+		})
+
+		It("logs the error again", func() {
+			Expect(errorLogs()).To(HaveLen(3))
+		})
+	})
+
+	Context("when a different error is logged after the window elapses", func() {
+		BeforeEach(func() {
+			session := logger.Session("some-session")
+			for i := 0; i < 5; i++ {
+				session.Error("failed-to-do-thing", disaster)
+			}
+			fakeClock.Increment(window)
+			logger.Error("failed-to-do-other-thing", disaster)
+		})
+
+		It("summarizes the suppressed occurrences of the first error on its session", func() {
+			logs := testLogger.Logs()
+			summary := logs[len(logs)-2]
+			Expect(summary.Message).To(Equal("test.some-session.suppressed-repeated-errors"))
+			Expect(summary.Data["action"]).To(Equal("failed-to-do-thing"))
+			Expect(summary.Data["occurrences"]).To(BeEquivalentTo(3))
+		})
+
+		It("forgets the first error once it has been summarized", func() {
+			fakeClock.Increment(window)
+			logger.Error("failed-to-do-other-thing", disaster)
+
+			summaries := 0
+			for _, log := range testLogger.Logs() {
+				if log.Message == "test.some-session.suppressed-repeated-errors" {
+					summaries++
+				}
+			}
+			Expect(summaries).To(Equal(1))
+		})
+	})
+
+	Context("when the errors stay below the threshold", func() {
+		It("does not log a summary", func() {
+			logger.Error("failed-to-do-thing", disaster)
+			fakeClock.Increment(window)
+			logger.Error("failed-to-do-thing", disaster)
+
+			Expect(testLogger.Logs()).To(HaveLen(2))
+		})
+	})
+})
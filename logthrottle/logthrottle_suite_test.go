@@ -0,0 +1,13 @@
+package logthrottle_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestLogthrottle(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Logthrottle Suite")
+}
@@ -0,0 +1 @@
+package logthrottle // import "code.cloudfoundry.org/rep/logthrottle"
@@ -15,10 +15,17 @@ import (
 
 const CellSchemaKey = "cell"
 
+// PresenceClient is what the Maintainer needs to keep the cell's presence,
+// whether it is kept in consul or in locket.
+type PresenceClient interface {
+	NewCellPresenceRunner(logger lager.Logger, cellPresence *models.CellPresence, retryInterval, lockTTL time.Duration) ifrit.Runner
+	ClearCellPresence(logger lager.Logger, cellId string) error
+}
+
 //go:generate counterfeiter . CellPresenceClient
 
 type CellPresenceClient interface {
-	NewCellPresenceRunner(logger lager.Logger, cellPresence *models.CellPresence, retryInterval, lockTTL time.Duration) ifrit.Runner
+	PresenceClient
 
 	CellById(logger lager.Logger, cellId string) (*models.CellPresence, error)
 	Cells(logger lager.Logger) (models.CellSet, error)
@@ -54,6 +61,28 @@ func (db *cellPresenceClient) NewCellPresenceRunner(logger lager.Logger, cellPre
 	return locket.NewPresence(logger, db.consulClient, CellSchemaPath(cellPresence.CellId), payload, db.clock, retryInterval, lockTTL)
 }
 
+// ClearCellPresence destroys the session holding the cell's presence, if
+// there is one, so that a new presence can be acquired straight away instead
+// of after the old session's TTL runs out.
+func (c *cellPresenceClient) ClearCellPresence(logger lager.Logger, cellId string) error {
+	kvPair, _, err := c.consulClient.KV().Get(CellSchemaPath(cellId), nil)
+	if err != nil {
+		return convertConsulError(err)
+	}
+
+	if kvPair == nil || kvPair.Session == "" {
+		return nil
+	}
+
+	_, err = c.consulClient.Session().Destroy(kvPair.Session, nil)
+	if err != nil {
+		return convertConsulError(err)
+	}
+
+	logger.Info("cleared-cell-presence", lager.Data{"cell-id": cellId, "session": kvPair.Session})
+	return nil
+}
+
 func (c *cellPresenceClient) Cells(logger lager.Logger) (models.CellSet, error) {
 	kvPairs, _, err := c.consulClient.KV().List(CellSchemaRoot(), nil)
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 	"code.cloudfoundry.org/lager/lagertest"
 	"code.cloudfoundry.org/locket"
 	"code.cloudfoundry.org/rep/maintain"
+	"github.com/hashicorp/consul/api"
 	"github.com/tedsuo/ifrit"
 	"github.com/tedsuo/ifrit/ginkgomon"
 	"github.com/tedsuo/ifrit/grouper"
@@ -65,6 +66,61 @@ var _ = Describe("CellPresenceClient", func() {
 		})
 	})
 
+	Describe("ClearCellPresence", func() {
+		const cellID = "cell-id"
+
+		Context("when a stale presence exists for the cell", func() {
+			BeforeEach(func() {
+				sessionID, _, err := consulClient.Session().Create(&api.SessionEntry{TTL: "60s"}, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				payload, err := models.ToJSON(newCellPresence(cellID))
+				Expect(err).NotTo(HaveOccurred())
+
+				acquired, _, err := consulClient.KV().Acquire(&api.KVPair{
+					Key:     maintain.CellSchemaPath(cellID),
+					Value:   payload,
+					Session: sessionID,
+				}, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(acquired).To(BeTrue())
+
+				_, err = cellPresenceClient.CellById(logger, cellID)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("removes it", func() {
+				Expect(cellPresenceClient.ClearCellPresence(logger, cellID)).To(Succeed())
+
+				_, err := cellPresenceClient.CellById(logger, cellID)
+				Expect(models.ConvertError(err).Type).To(Equal(models.Error_ResourceNotFound))
+			})
+
+			It("lets a new presence be maintained straight away", func() {
+				Expect(cellPresenceClient.ClearCellPresence(logger, cellID)).To(Succeed())
+
+				presence := newCellPresence(cellID)
+				presence.RepAddress = "new.example.com"
+				process := ifrit.Invoke(cellPresenceClient.NewCellPresenceRunner(logger, presence, locket.RetryInterval, locket.DefaultSessionTTL))
+				defer ginkgomon.Interrupt(process)
+
+				Eventually(func() (string, error) {
+					found, err := cellPresenceClient.CellById(logger, cellID)
+					if err != nil {
+						return "", err
+					}
+					return found.RepAddress, nil
+				}).Should(Equal("new.example.com"))
+			})
+		})
+
+		Context("when the cell has no presence", func() {
+			It("succeeds", func() {
+				Expect(cellPresenceClient.ClearCellPresence(logger, cellID)).To(Succeed())
+			})
+		})
+	})
+
 	Describe("Cells", func() {
 		const cell1 = "cell-id-1"
 		const cell2 = "cell-id-2"
@@ -0,0 +1,78 @@
+package maintain
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/lock"
+	locketmodels "code.cloudfoundry.org/locket/models"
+	"github.com/tedsuo/ifrit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+type locketPresenceClient struct {
+	locketClient locketmodels.LocketClient
+	owner        string
+	clock        clock.Clock
+}
+
+// NewLocketPresenceClient returns a PresenceClient that keeps the cell's
+// presence in locket, held by owner.
+func NewLocketPresenceClient(locketClient locketmodels.LocketClient, owner string, clock clock.Clock) PresenceClient {
+	return &locketPresenceClient{
+		locketClient: locketClient,
+		owner:        owner,
+		clock:        clock,
+	}
+}
+
+func (c *locketPresenceClient) NewCellPresenceRunner(logger lager.Logger, cellPresence *models.CellPresence, retryInterval, lockTTL time.Duration) ifrit.Runner {
+	payload, err := json.Marshal(cellPresence)
+	if err != nil {
+		logger.Error("failed-to-encode-cell-presence", err)
+		return ifrit.RunFunc(func(<-chan os.Signal, chan<- struct{}) error {
+			return err
+		})
+	}
+
+	resource := &locketmodels.Resource{
+		Key:   cellPresence.CellId,
+		Owner: c.owner,
+		Value: string(payload),
+		Type:  locketmodels.PresenceType,
+	}
+
+	logger.Debug("presence-payload", lager.Data{"payload": resource})
+	return lock.NewPresenceRunner(logger, c.locketClient, resource, int64(lockTTL/time.Second), c.clock, retryInterval)
+}
+
+// ClearCellPresence releases the cell's presence if it is held by another
+// owner, such as a previous run of this cell, so that a new presence can be
+// acquired straight away instead of after the old one's TTL runs out.
+func (c *locketPresenceClient) ClearCellPresence(logger lager.Logger, cellId string) error {
+	response, err := c.locketClient.Fetch(context.Background(), &locketmodels.FetchRequest{Key: cellId})
+	if err != nil {
+		if grpc.Code(err) == codes.NotFound {
+			return nil
+		}
+		return err
+	}
+
+	if response.Resource == nil || response.Resource.Owner == c.owner {
+		return nil
+	}
+
+	_, err = c.locketClient.Release(context.Background(), &locketmodels.ReleaseRequest{Resource: response.Resource})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("cleared-cell-presence", lager.Data{"cell-id": cellId, "owner": response.Resource.Owner})
+	return nil
+}
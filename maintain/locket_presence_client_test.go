@@ -0,0 +1,132 @@
+package maintain_test
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	locketmodels "code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	"code.cloudfoundry.org/rep/maintain"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LocketPresenceClient", func() {
+	const cellID = "cell-id"
+
+	var (
+		locketClient   *modelsfakes.FakeLocketClient
+		presenceClient maintain.PresenceClient
+		logger         *lagertest.TestLogger
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("locket-presence-client")
+		locketClient = &modelsfakes.FakeLocketClient{}
+		locketClient.LockReturns(&locketmodels.LockResponse{}, nil)
+		locketClient.ReleaseReturns(&locketmodels.ReleaseResponse{}, nil)
+		presenceClient = maintain.NewLocketPresenceClient(locketClient, "owner", fakeclock.NewFakeClock(time.Now()))
+	})
+
+	Describe("NewCellPresenceRunner", func() {
+		It("holds the cell's presence in locket", func() {
+			presence := newCellPresence(cellID)
+			process := ifrit.Background(presenceClient.NewCellPresenceRunner(logger, presence, time.Second, 10*time.Second))
+			defer ginkgomon.Interrupt(process)
+
+			Eventually(locketClient.LockCallCount).Should(BeNumerically(">=", 1))
+			_, request, _ := locketClient.LockArgsForCall(0)
+			Expect(request.Resource.Key).To(Equal(cellID))
+			Expect(request.Resource.Owner).To(Equal("owner"))
+			Expect(request.Resource.Type).To(Equal(locketmodels.PresenceType))
+			Expect(request.TtlInSeconds).To(BeEquivalentTo(10))
+
+			value := &models.CellPresence{}
+			Expect(json.Unmarshal([]byte(request.Resource.Value), value)).To(Succeed())
+			Expect(value.CellId).To(Equal(cellID))
+			Expect(value.RepAddress).To(Equal(presence.RepAddress))
+		})
+	})
+
+	Describe("ClearCellPresence", func() {
+		Context("when a previous run left a stale presence for the cell", func() {
+			var stale *locketmodels.Resource
+
+			BeforeEach(func() {
+				stale = &locketmodels.Resource{
+					Key:   cellID,
+					Owner: "previous-run",
+					Value: "{}",
+					Type:  locketmodels.PresenceType,
+				}
+				locketClient.FetchReturns(&locketmodels.FetchResponse{Resource: stale}, nil)
+			})
+
+			It("releases it", func() {
+				Expect(presenceClient.ClearCellPresence(logger, cellID)).To(Succeed())
+
+				Expect(locketClient.FetchCallCount()).To(Equal(1))
+				_, fetchRequest, _ := locketClient.FetchArgsForCall(0)
+				Expect(fetchRequest.Key).To(Equal(cellID))
+
+				Expect(locketClient.ReleaseCallCount()).To(Equal(1))
+				_, releaseRequest, _ := locketClient.ReleaseArgsForCall(0)
+				Expect(releaseRequest.Resource).To(Equal(stale))
+			})
+
+			Context("when releasing it fails", func() {
+				BeforeEach(func() {
+					locketClient.ReleaseReturns(nil, errors.New("boom"))
+				})
+
+				It("returns the error", func() {
+					Expect(presenceClient.ClearCellPresence(logger, cellID)).To(MatchError("boom"))
+				})
+			})
+		})
+
+		Context("when the presence is already its own", func() {
+			BeforeEach(func() {
+				locketClient.FetchReturns(&locketmodels.FetchResponse{Resource: &locketmodels.Resource{
+					Key:   cellID,
+					Owner: "owner",
+					Type:  locketmodels.PresenceType,
+				}}, nil)
+			})
+
+			It("leaves it alone", func() {
+				Expect(presenceClient.ClearCellPresence(logger, cellID)).To(Succeed())
+				Expect(locketClient.ReleaseCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the cell has no presence", func() {
+			BeforeEach(func() {
+				locketClient.FetchReturns(nil, locketmodels.ErrResourceNotFound)
+			})
+
+			It("succeeds", func() {
+				Expect(presenceClient.ClearCellPresence(logger, cellID)).To(Succeed())
+				Expect(locketClient.ReleaseCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when fetching the presence fails", func() {
+			BeforeEach(func() {
+				locketClient.FetchReturns(nil, errors.New("boom"))
+			})
+
+			It("returns the error", func() {
+				Expect(presenceClient.ClearCellPresence(logger, cellID)).To(MatchError("boom"))
+				Expect(locketClient.ReleaseCallCount()).To(Equal(0))
+			})
+		})
+	})
+})
@@ -15,7 +15,7 @@ import (
 type Maintainer struct {
 	Config
 	executorClient executor.Client
-	serviceClient  CellPresenceClient
+	serviceClient  PresenceClient
 	logger         lager.Logger
 	lockTTL        time.Duration
 	clock          clock.Clock
@@ -31,13 +31,18 @@ type Config struct {
 	PreloadedRootFSes     []string
 	PlacementTags         []string
 	OptionalPlacementTags []string
+	ClearStalePresence    bool
+
+	// Withdrawn, when set, is checked along with the executor, and the cell
+	// withdraws its presence for as long as it returns true.
+	Withdrawn func(lager.Logger) bool
 }
 
 func New(
 	logger lager.Logger,
 	config Config,
 	executorClient executor.Client,
-	serviceClient CellPresenceClient,
+	serviceClient PresenceClient,
 	lockTTL time.Duration,
 	clock clock.Clock,
 ) *Maintainer {
@@ -53,11 +58,19 @@ func New(
 
 const ExecutorPollInterval = time.Second
 
-var ErrSignaledWhileWaiting = errors.New("signaled while waiting for executor")
+var (
+	ErrSignaledWhileWaiting = errors.New("signaled while waiting for executor")
+	ErrPresenceWithdrawn    = errors.New("cell presence withdrawn")
+)
 
 func (m *Maintainer) Run(sigChan <-chan os.Signal, ready chan<- struct{}) error {
 	m.logger.Info("starting-executor-heartbeat")
 	defer m.logger.Info("complete-executor-heartbeat")
+
+	if m.ClearStalePresence {
+		m.clearStalePresence()
+	}
+
 	for {
 		heartbeater, err := m.waitForExecutor(sigChan)
 		if err != nil {
@@ -75,6 +88,17 @@ func (m *Maintainer) Run(sigChan <-chan os.Signal, ready chan<- struct{}) error
 	}
 }
 
+// clearStalePresence removes any presence left behind by a previous run of
+// this cell, so that it stops advertising the old capacity before the new
+// presence is up. Failing to clear it only delays the new presence until the
+// old one expires, so the error is logged and otherwise ignored.
+func (m *Maintainer) clearStalePresence() {
+	err := m.serviceClient.ClearCellPresence(m.logger, m.CellID)
+	if err != nil {
+		m.logger.Error("failed-to-clear-stale-presence", err)
+	}
+}
+
 func (m *Maintainer) waitForExecutor(sigChan <-chan os.Signal) (ifrit.Runner, error) {
 	m.logger.Info("start-waiting-for-executor")
 	defer m.logger.Info("complete-waiting-for-executor")
@@ -85,11 +109,13 @@ func (m *Maintainer) waitForExecutor(sigChan <-chan os.Signal) (ifrit.Runner, er
 	for {
 		m.logger.Debug("waiting-pinging-executor")
 		err := m.executorClient.Ping(m.logger)
-		if err == nil {
+		if err == nil && !m.withdrawn() {
 			return m.createHeartbeater()
 		}
 
-		m.logger.Error("failed-to-ping-executor-on-start", err)
+		if err != nil {
+			m.logger.Error("failed-to-ping-executor-on-start", err)
+		}
 
 		sleeper.Reset(ExecutorPollInterval)
 		select {
@@ -101,6 +127,10 @@ func (m *Maintainer) waitForExecutor(sigChan <-chan os.Signal) (ifrit.Runner, er
 	}
 }
 
+func (m *Maintainer) withdrawn() bool {
+	return m.Withdrawn != nil && m.Withdrawn(m.logger)
+}
+
 func (m *Maintainer) createHeartbeater() (ifrit.Runner, error) {
 	resources, err := m.executorClient.TotalResources(m.logger)
 	if err != nil {
@@ -153,6 +183,10 @@ func (m *Maintainer) heartbeat(sigChan <-chan os.Signal, ready chan<- struct{},
 		case <-ticker.C():
 			m.logger.Debug("heartbeat-pinging-executor")
 			err := m.executorClient.Ping(m.logger)
+			if err == nil && m.withdrawn() {
+				m.logger.Info("withdrawing-presence")
+				err = ErrPresenceWithdrawn
+			}
 			if err == nil {
 				continue
 			}
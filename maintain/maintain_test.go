@@ -3,6 +3,7 @@ package maintain_test
 import (
 	"errors"
 	"os"
+	"sync/atomic"
 	"time"
 
 	"code.cloudfoundry.org/bbs/models"
@@ -99,6 +100,82 @@ var _ = Describe("Maintain Presence", func() {
 		Expect(fakeClient.PingCallCount()).To(Equal(1))
 	})
 
+	It("does not clear a stale presence by default", func() {
+		pingErrors <- nil
+		maintainProcess = ginkgomon.Invoke(maintainer)
+		Expect(serviceClient.ClearCellPresenceCallCount()).To(Equal(0))
+	})
+
+	Context("when clearing a stale presence is enabled", func() {
+		BeforeEach(func() {
+			config.ClearStalePresence = true
+			maintainer = maintain.New(logger, config, fakeClient, serviceClient, 10*time.Second, clock)
+		})
+
+		It("clears the cell's presence before starting to heartbeat", func() {
+			serviceClient.ClearCellPresenceStub = func(lager.Logger, string) error {
+				defer GinkgoRecover()
+				Expect(serviceClient.NewCellPresenceRunnerCallCount()).To(Equal(0))
+				return nil
+			}
+
+			pingErrors <- nil
+			maintainProcess = ginkgomon.Invoke(maintainer)
+
+			Expect(serviceClient.ClearCellPresenceCallCount()).To(Equal(1))
+			_, cellID := serviceClient.ClearCellPresenceArgsForCall(0)
+			Expect(cellID).To(Equal("cell-id"))
+			Expect(serviceClient.NewCellPresenceRunnerCallCount()).To(Equal(1))
+		})
+
+		Context("when clearing fails", func() {
+			BeforeEach(func() {
+				serviceClient.ClearCellPresenceReturns(errors.New("boom"))
+			})
+
+			It("logs the error and maintains the presence anyway", func() {
+				pingErrors <- nil
+				maintainProcess = ginkgomon.Invoke(maintainer)
+
+				Expect(logger).To(gbytes.Say("failed-to-clear-stale-presence"))
+				Expect(serviceClient.NewCellPresenceRunnerCallCount()).To(Equal(1))
+			})
+		})
+	})
+
+	Context("when the cell withdraws its presence", func() {
+		var withdrawn int32
+
+		BeforeEach(func() {
+			atomic.StoreInt32(&withdrawn, 0)
+			config.Withdrawn = func(lager.Logger) bool {
+				return atomic.LoadInt32(&withdrawn) == 1
+			}
+			maintainer = maintain.New(logger, config, fakeClient, serviceClient, 10*time.Second, clock)
+
+			pingErrors <- nil
+			maintainProcess = ginkgomon.Invoke(maintainer)
+			Eventually(fakeHeartbeater.RunCallCount).Should(Equal(1))
+		})
+
+		It("stops heartbeating until the cell no longer withdraws", func() {
+			atomic.StoreInt32(&withdrawn, 1)
+			pingErrors <- nil
+			clock.Increment(1 * time.Second)
+			Eventually(observedSignals).Should(Receive(Equal(os.Kill)))
+			Expect(logger).To(gbytes.Say("withdrawing-presence"))
+
+			pingErrors <- nil
+			Eventually(fakeClient.PingCallCount).Should(Equal(3))
+			Consistently(fakeHeartbeater.RunCallCount).Should(Equal(1))
+
+			atomic.StoreInt32(&withdrawn, 0)
+			pingErrors <- nil
+			clock.WaitForWatcherAndIncrement(maintain.ExecutorPollInterval)
+			Eventually(fakeHeartbeater.RunCallCount).Should(Equal(2))
+		})
+	})
+
 	Context("when pinging the executor fails", func() {
 		It("keeps pinging until it succeeds, then starts heartbeating the executor's presence", func() {
 			maintainProcess = ifrit.Background(maintainer)
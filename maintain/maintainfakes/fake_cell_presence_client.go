@@ -23,6 +23,15 @@ type FakeCellPresenceClient struct {
 	newCellPresenceRunnerReturns struct {
 		result1 ifrit.Runner
 	}
+	ClearCellPresenceStub        func(logger lager.Logger, cellId string) error
+	clearCellPresenceMutex       sync.RWMutex
+	clearCellPresenceArgsForCall []struct {
+		logger lager.Logger
+		cellId string
+	}
+	clearCellPresenceReturns struct {
+		result1 error
+	}
 	CellByIdStub        func(logger lager.Logger, cellId string) (*models.CellPresence, error)
 	cellByIdMutex       sync.RWMutex
 	cellByIdArgsForCall []struct {
@@ -90,6 +99,40 @@ func (fake *FakeCellPresenceClient) NewCellPresenceRunnerReturns(result1 ifrit.R
 	}{result1}
 }
 
+func (fake *FakeCellPresenceClient) ClearCellPresence(logger lager.Logger, cellId string) error {
+	fake.clearCellPresenceMutex.Lock()
+	fake.clearCellPresenceArgsForCall = append(fake.clearCellPresenceArgsForCall, struct {
+		logger lager.Logger
+		cellId string
+	}{logger, cellId})
+	fake.recordInvocation("ClearCellPresence", []interface{}{logger, cellId})
+	fake.clearCellPresenceMutex.Unlock()
+	if fake.ClearCellPresenceStub != nil {
+		return fake.ClearCellPresenceStub(logger, cellId)
+	} else {
+		return fake.clearCellPresenceReturns.result1
+	}
+}
+
+func (fake *FakeCellPresenceClient) ClearCellPresenceCallCount() int {
+	fake.clearCellPresenceMutex.RLock()
+	defer fake.clearCellPresenceMutex.RUnlock()
+	return len(fake.clearCellPresenceArgsForCall)
+}
+
+func (fake *FakeCellPresenceClient) ClearCellPresenceArgsForCall(i int) (lager.Logger, string) {
+	fake.clearCellPresenceMutex.RLock()
+	defer fake.clearCellPresenceMutex.RUnlock()
+	return fake.clearCellPresenceArgsForCall[i].logger, fake.clearCellPresenceArgsForCall[i].cellId
+}
+
+func (fake *FakeCellPresenceClient) ClearCellPresenceReturns(result1 error) {
+	fake.ClearCellPresenceStub = nil
+	fake.clearCellPresenceReturns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeCellPresenceClient) CellById(logger lager.Logger, cellId string) (*models.CellPresence, error) {
 	fake.cellByIdMutex.Lock()
 	fake.cellByIdArgsForCall = append(fake.cellByIdArgsForCall, struct {
@@ -197,6 +240,8 @@ func (fake *FakeCellPresenceClient) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.newCellPresenceRunnerMutex.RLock()
 	defer fake.newCellPresenceRunnerMutex.RUnlock()
+	fake.clearCellPresenceMutex.RLock()
+	defer fake.clearCellPresenceMutex.RUnlock()
 	fake.cellByIdMutex.RLock()
 	defer fake.cellByIdMutex.RUnlock()
 	fake.cellsMutex.RLock()
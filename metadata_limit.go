@@ -0,0 +1,81 @@
+package rep
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"code.cloudfoundry.org/executor"
+)
+
+type MetadataLimitPolicy string
+
+const (
+	MetadataLimitPolicyTruncate MetadataLimitPolicy = "truncate"
+	MetadataLimitPolicyReject   MetadataLimitPolicy = "reject"
+)
+
+var ErrMetadataTooLarge = errors.New("metadata exceeds the configured size limit")
+
+// MetadataLimit caps the total size, in bytes, of the metadata tags passed to
+// the executor with a run request, including the desired LRP's annotation. A
+// MaxBytes of zero disables the limit.
+type MetadataLimit struct {
+	MaxBytes int
+	Policy   MetadataLimitPolicy
+}
+
+func NewMetadataLimit(maxBytes int, policy string) (MetadataLimit, error) {
+	switch MetadataLimitPolicy(policy) {
+	case MetadataLimitPolicyTruncate, MetadataLimitPolicyReject:
+	default:
+		return MetadataLimit{}, fmt.Errorf("invalid metadata limit policy: %q", policy)
+	}
+
+	if maxBytes < 0 {
+		return MetadataLimit{}, fmt.Errorf("invalid metadata limit: %d", maxBytes)
+	}
+
+	return MetadataLimit{MaxBytes: maxBytes, Policy: MetadataLimitPolicy(policy)}, nil
+}
+
+// MetadataSize returns the number of bytes used by the keys and values of tags.
+func MetadataSize(tags executor.Tags) int {
+	size := 0
+	for key, value := range tags {
+		size += len(key) + len(value)
+	}
+	return size
+}
+
+// Enforce returns tags unchanged if they fit within the limit. Otherwise,
+// depending on the policy, it either returns ErrMetadataTooLarge or a copy of
+// tags keeping entries in key order until the limit is reached.
+func (l MetadataLimit) Enforce(tags executor.Tags) (executor.Tags, error) {
+	if l.MaxBytes == 0 || MetadataSize(tags) <= l.MaxBytes {
+		return tags, nil
+	}
+
+	if l.Policy == MetadataLimitPolicyReject {
+		return nil, ErrMetadataTooLarge
+	}
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	truncated := executor.Tags{}
+	size := 0
+	for _, key := range keys {
+		entrySize := len(key) + len(tags[key])
+		if size+entrySize > l.MaxBytes {
+			break
+		}
+		truncated[key] = tags[key]
+		size += entrySize
+	}
+
+	return truncated, nil
+}
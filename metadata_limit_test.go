@@ -0,0 +1,111 @@
+package rep_test
+
+import (
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/rep"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MetadataLimit", func() {
+	var tags executor.Tags
+
+	BeforeEach(func() {
+		// each entry is 8 bytes, 24 bytes in total
+		tags = executor.Tags{
+			"key-a": "aaa",
+			"key-b": "bbb",
+			"key-c": "ccc",
+		}
+	})
+
+	Describe("NewMetadataLimit", func() {
+		It("accepts the truncate and reject policies", func() {
+			limit, err := rep.NewMetadataLimit(10, "truncate")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(limit).To(Equal(rep.MetadataLimit{MaxBytes: 10, Policy: rep.MetadataLimitPolicyTruncate}))
+
+			limit, err = rep.NewMetadataLimit(10, "reject")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(limit).To(Equal(rep.MetadataLimit{MaxBytes: 10, Policy: rep.MetadataLimitPolicyReject}))
+		})
+
+		It("errors on an unknown policy", func() {
+			_, err := rep.NewMetadataLimit(10, "compress")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("errors on a negative limit", func() {
+			_, err := rep.NewMetadataLimit(-1, "truncate")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("MetadataSize", func() {
+		It("sums the length of the keys and values", func() {
+			Expect(rep.MetadataSize(tags)).To(Equal(24))
+			Expect(rep.MetadataSize(nil)).To(Equal(0))
+		})
+	})
+
+	Describe("Enforce", func() {
+		Context("when the limit is disabled", func() {
+			It("returns the tags unchanged", func() {
+				limit := rep.MetadataLimit{Policy: rep.MetadataLimitPolicyReject}
+				Expect(limit.Enforce(tags)).To(Equal(tags))
+			})
+		})
+
+		Context("with the truncate policy", func() {
+			var limit rep.MetadataLimit
+
+			BeforeEach(func() {
+				limit = rep.MetadataLimit{Policy: rep.MetadataLimitPolicyTruncate}
+			})
+
+			It("returns the tags unchanged when they are exactly at the limit", func() {
+				limit.MaxBytes = 24
+				Expect(limit.Enforce(tags)).To(Equal(tags))
+			})
+
+			It("drops entries in key order once the limit is exceeded by one byte", func() {
+				limit.MaxBytes = 23
+				Expect(limit.Enforce(tags)).To(Equal(executor.Tags{
+					"key-a": "aaa",
+					"key-b": "bbb",
+				}))
+			})
+
+			It("drops every entry when even the first does not fit", func() {
+				limit.MaxBytes = 7
+				Expect(limit.Enforce(tags)).To(BeEmpty())
+			})
+
+			It("does not modify the original tags", func() {
+				limit.MaxBytes = 8
+				_, err := limit.Enforce(tags)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(tags).To(HaveLen(3))
+			})
+		})
+
+		Context("with the reject policy", func() {
+			var limit rep.MetadataLimit
+
+			BeforeEach(func() {
+				limit = rep.MetadataLimit{Policy: rep.MetadataLimitPolicyReject}
+			})
+
+			It("returns the tags unchanged when they are exactly at the limit", func() {
+				limit.MaxBytes = 24
+				Expect(limit.Enforce(tags)).To(Equal(tags))
+			})
+
+			It("returns an error once the limit is exceeded by one byte", func() {
+				limit.MaxBytes = 23
+				_, err := limit.Enforce(tags)
+				Expect(err).To(Equal(rep.ErrMetadataTooLarge))
+			})
+		})
+	})
+})
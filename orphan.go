@@ -0,0 +1,52 @@
+package rep
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+)
+
+// OrphanAction is what the rep does with an orphaned LRP container, one whose
+// ActualLRP the BBS has since given to another instance or cell.
+type OrphanAction string
+
+const (
+	// OrphanActionDelete stops and deletes the container.
+	OrphanActionDelete OrphanAction = "delete"
+	// OrphanActionKeep leaves the container as it is. It is found to be
+	// orphaned, and the handler consulted, again on every pass.
+	OrphanActionKeep OrphanAction = "keep"
+	// OrphanActionQuarantine stops the container but leaves it on the cell, so
+	// it can be inspected before it is deleted by hand.
+	OrphanActionQuarantine OrphanAction = "quarantine"
+)
+
+func NewOrphanAction(action string) (OrphanAction, error) {
+	switch OrphanAction(action) {
+	case OrphanActionDelete, OrphanActionKeep, OrphanActionQuarantine:
+		return OrphanAction(action), nil
+	default:
+		return "", fmt.Errorf("invalid orphan action: %q", action)
+	}
+}
+
+// OrphanHandler decides what to do with an orphaned LRP container, given its
+// guid and tags. A nil OrphanHandler deletes every orphan.
+type OrphanHandler func(logger lager.Logger, containerGuid string, tags executor.Tags) OrphanAction
+
+// Handle returns the action the handler picks for the orphaned container.
+func (h OrphanHandler) Handle(logger lager.Logger, containerGuid string, tags executor.Tags) OrphanAction {
+	if h == nil {
+		return OrphanActionDelete
+	}
+	return h(logger, containerGuid, tags)
+}
+
+// NewOrphanHandler returns an OrphanHandler that picks action for every
+// orphaned container.
+func NewOrphanHandler(action OrphanAction) OrphanHandler {
+	return func(lager.Logger, string, executor.Tags) OrphanAction {
+		return action
+	}
+}
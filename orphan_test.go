@@ -0,0 +1,38 @@
+package rep_test
+
+import (
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OrphanAction", func() {
+	Describe("NewOrphanAction", func() {
+		It("accepts delete, keep and quarantine", func() {
+			Expect(rep.NewOrphanAction("delete")).To(Equal(rep.OrphanActionDelete))
+			Expect(rep.NewOrphanAction("keep")).To(Equal(rep.OrphanActionKeep))
+			Expect(rep.NewOrphanAction("quarantine")).To(Equal(rep.OrphanActionQuarantine))
+		})
+
+		It("errors on an unknown action", func() {
+			_, err := rep.NewOrphanAction("archive")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("NewOrphanHandler", func() {
+		It("picks the action for every orphan", func() {
+			handler := rep.NewOrphanHandler(rep.OrphanActionQuarantine)
+			logger := lagertest.NewTestLogger("test")
+			Expect(handler.Handle(logger, "guid", executor.Tags{})).To(Equal(rep.OrphanActionQuarantine))
+		})
+	})
+
+	It("deletes every orphan without a handler", func() {
+		var handler rep.OrphanHandler
+		logger := lagertest.NewTestLogger("test")
+		Expect(handler.Handle(logger, "guid", executor.Tags{})).To(Equal(rep.OrphanActionDelete))
+	})
+})
@@ -0,0 +1,58 @@
+package rep
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+// PeriodicRunner is an ifrit runner that calls report every interval until it
+// is signalled. The cell's reporters are built on it.
+type PeriodicRunner struct {
+	logger   lager.Logger
+	session  string
+	interval time.Duration
+	clock    clock.Clock
+	report   func(lager.Logger)
+}
+
+func NewPeriodicRunner(
+	logger lager.Logger,
+	session string,
+	interval time.Duration,
+	clock clock.Clock,
+	report func(lager.Logger),
+) *PeriodicRunner {
+	return &PeriodicRunner{
+		logger:   logger,
+		session:  session,
+		interval: interval,
+		clock:    clock,
+		report:   report,
+	}
+}
+
+func (r *PeriodicRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := r.logger.Session(r.session)
+	logger.Info("starting", lager.Data{"interval": r.interval.String()})
+	defer logger.Info("finished")
+
+	timer := r.clock.NewTimer(r.interval)
+	defer timer.Stop()
+
+	close(ready)
+
+	for {
+		select {
+		case <-timer.C():
+			r.report(logger)
+			timer.Reset(r.interval)
+
+		case signal := <-signals:
+			logger.Info("received-signal", lager.Data{"signal": signal.String()})
+			return nil
+		}
+	}
+}
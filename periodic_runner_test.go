@@ -0,0 +1,61 @@
+package rep_test
+
+import (
+	"os"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PeriodicRunner", func() {
+	const interval = 10 * time.Second
+
+	var (
+		logger    *lagertest.TestLogger
+		fakeClock *fakeclock.FakeClock
+		reports   int32
+		process   ifrit.Process
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		atomic.StoreInt32(&reports, 0)
+
+		runner := rep.NewPeriodicRunner(logger, "some-reporter", interval, fakeClock, func(reportLogger lager.Logger) {
+			reportLogger.Info("reporting")
+			atomic.AddInt32(&reports, 1)
+		})
+		process = ifrit.Invoke(runner)
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+	})
+
+	countReports := func() int32 {
+		return atomic.LoadInt32(&reports)
+	}
+
+	It("reports once every interval on its session", func() {
+		fakeClock.WaitForWatcherAndIncrement(interval - time.Millisecond)
+		Consistently(countReports).Should(BeZero())
+
+		fakeClock.Increment(time.Millisecond)
+		Eventually(countReports).Should(BeEquivalentTo(1))
+		Expect(logger).To(gbytes.Say("test.some-reporter.reporting"))
+
+		fakeClock.WaitForWatcherAndIncrement(interval)
+		Eventually(countReports).Should(BeEquivalentTo(2))
+	})
+})
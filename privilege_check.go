@@ -0,0 +1,53 @@
+package rep
+
+import (
+	"errors"
+	"fmt"
+
+	"code.cloudfoundry.org/bbs/models"
+)
+
+const rootUser = "root"
+
+// PrivilegeCheck holds the cell's policy on privileged LRPs. The zero value is
+// permissive and accepts every LRP, as the platform always has. Each field
+// enables one condition:
+//
+//   - DenyPrivileged rejects an LRP that asks for a privileged container.
+//   - DenyUnprivilegedRoot rejects an LRP in an unprivileged container that
+//     has a setup, action or monitor run action running as root, which only
+//     has root's privileges inside a privileged container.
+type PrivilegeCheck struct {
+	DenyPrivileged       bool
+	DenyUnprivilegedRoot bool
+}
+
+// Check returns an error giving the reason the desired LRP breaks the policy,
+// if it does.
+func (c PrivilegeCheck) Check(desired *models.DesiredLRP) error {
+	if desired.Privileged {
+		if c.DenyPrivileged {
+			return errors.New("privileged containers are not allowed on this cell")
+		}
+		return nil
+	}
+
+	if !c.DenyUnprivilegedRoot {
+		return nil
+	}
+
+	for _, action := range []*models.Action{desired.Setup, desired.Action, desired.Monitor} {
+		err := walkActions(action, func(action models.ActionInterface) error {
+			run, ok := action.(*models.RunAction)
+			if ok && run.User == rootUser {
+				return fmt.Errorf("run action %q runs as root in an unprivileged container", run.Path)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package rep_test
+
+import (
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/rep"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PrivilegeCheck", func() {
+	var (
+		check   rep.PrivilegeCheck
+		desired *models.DesiredLRP
+	)
+
+	BeforeEach(func() {
+		check = rep.PrivilegeCheck{}
+		desired = &models.DesiredLRP{
+			ProcessGuid: "process-guid",
+			Setup:       models.WrapAction(&models.RunAction{Path: "/bin/setup", User: "root"}),
+			Action:      models.WrapAction(&models.RunAction{Path: "/bin/app", User: "vcap"}),
+		}
+	})
+
+	It("accepts every LRP by default", func() {
+		Expect(check.Check(desired)).To(Succeed())
+		desired.Privileged = true
+		Expect(check.Check(desired)).To(Succeed())
+	})
+
+	Context("when privileged containers are denied", func() {
+		BeforeEach(func() {
+			check.DenyPrivileged = true
+		})
+
+		It("rejects privileged LRPs", func() {
+			desired.Privileged = true
+			Expect(check.Check(desired)).To(MatchError(ContainSubstring("privileged containers are not allowed")))
+		})
+
+		It("accepts unprivileged LRPs", func() {
+			Expect(check.Check(desired)).To(Succeed())
+		})
+	})
+
+	Context("when root is denied in unprivileged containers", func() {
+		BeforeEach(func() {
+			check.DenyUnprivilegedRoot = true
+		})
+
+		It("rejects an unprivileged LRP with a run action as root", func() {
+			Expect(check.Check(desired)).To(MatchError(ContainSubstring(`"/bin/setup" runs as root`)))
+		})
+
+		It("looks inside nested actions and the monitor", func() {
+			desired.Setup = nil
+			desired.Monitor = models.WrapAction(models.Timeout(&models.RunAction{Path: "/bin/check", User: "root"}, 0))
+			Expect(check.Check(desired)).To(MatchError(ContainSubstring(`"/bin/check"`)))
+		})
+
+		It("accepts a privileged LRP with a run action as root", func() {
+			desired.Privileged = true
+			Expect(check.Check(desired)).To(Succeed())
+		})
+
+		It("accepts an unprivileged LRP with no run action as root", func() {
+			desired.Setup = nil
+			Expect(check.Check(desired)).To(Succeed())
+		})
+	})
+})
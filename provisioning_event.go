@@ -0,0 +1,14 @@
+package rep
+
+import "time"
+
+// ProvisioningEvent records the outcome of asking the executor to allocate a
+// container for an LRP instance or a Task.
+type ProvisioningEvent struct {
+	Time          time.Time `json:"time"`
+	ContainerGuid string    `json:"container_guid"`
+	ProcessGuid   string    `json:"process_guid,omitempty"`
+	Lifecycle     string    `json:"lifecycle"`
+	Succeeded     bool      `json:"succeeded"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+}
@@ -0,0 +1,11 @@
+package rep
+
+import "code.cloudfoundry.org/lager"
+
+//go:generate counterfeiter -o repfakes/fake_provisioning_recorder.go . ProvisioningRecorder
+
+// ProvisioningRecorder is told how each attempt to run and start an LRP
+// instance on the cell ended, a nil err meaning the instance started.
+type ProvisioningRecorder interface {
+	RecordProvisioningOutcome(logger lager.Logger, err error)
+}
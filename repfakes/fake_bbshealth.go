@@ -0,0 +1,161 @@
+// This file was generated by counterfeiter
+package repfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/rep"
+)
+
+type FakeBBSHealth struct {
+	RecordReadStub        func(err error)
+	recordReadMutex       sync.RWMutex
+	recordReadArgsForCall []struct {
+		err error
+	}
+	RecordWriteStub        func(err error)
+	recordWriteMutex       sync.RWMutex
+	recordWriteArgsForCall []struct {
+		err error
+	}
+	ReadsFailingStub        func() bool
+	readsFailingMutex       sync.RWMutex
+	readsFailingArgsForCall []struct{}
+	readsFailingReturns     struct {
+		result1 bool
+	}
+	WritesFailingStub        func() bool
+	writesFailingMutex       sync.RWMutex
+	writesFailingArgsForCall []struct{}
+	writesFailingReturns     struct {
+		result1 bool
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeBBSHealth) RecordRead(err error) {
+	fake.recordReadMutex.Lock()
+	fake.recordReadArgsForCall = append(fake.recordReadArgsForCall, struct {
+		err error
+	}{err})
+	fake.recordInvocation("RecordRead", []interface{}{err})
+	fake.recordReadMutex.Unlock()
+	if fake.RecordReadStub != nil {
+		fake.RecordReadStub(err)
+	}
+}
+
+func (fake *FakeBBSHealth) RecordReadCallCount() int {
+	fake.recordReadMutex.RLock()
+	defer fake.recordReadMutex.RUnlock()
+	return len(fake.recordReadArgsForCall)
+}
+
+func (fake *FakeBBSHealth) RecordReadArgsForCall(i int) error {
+	fake.recordReadMutex.RLock()
+	defer fake.recordReadMutex.RUnlock()
+	return fake.recordReadArgsForCall[i].err
+}
+
+func (fake *FakeBBSHealth) RecordWrite(err error) {
+	fake.recordWriteMutex.Lock()
+	fake.recordWriteArgsForCall = append(fake.recordWriteArgsForCall, struct {
+		err error
+	}{err})
+	fake.recordInvocation("RecordWrite", []interface{}{err})
+	fake.recordWriteMutex.Unlock()
+	if fake.RecordWriteStub != nil {
+		fake.RecordWriteStub(err)
+	}
+}
+
+func (fake *FakeBBSHealth) RecordWriteCallCount() int {
+	fake.recordWriteMutex.RLock()
+	defer fake.recordWriteMutex.RUnlock()
+	return len(fake.recordWriteArgsForCall)
+}
+
+func (fake *FakeBBSHealth) RecordWriteArgsForCall(i int) error {
+	fake.recordWriteMutex.RLock()
+	defer fake.recordWriteMutex.RUnlock()
+	return fake.recordWriteArgsForCall[i].err
+}
+
+func (fake *FakeBBSHealth) ReadsFailing() bool {
+	fake.readsFailingMutex.Lock()
+	fake.readsFailingArgsForCall = append(fake.readsFailingArgsForCall, struct{}{})
+	fake.recordInvocation("ReadsFailing", []interface{}{})
+	fake.readsFailingMutex.Unlock()
+	if fake.ReadsFailingStub != nil {
+		return fake.ReadsFailingStub()
+	} else {
+		return fake.readsFailingReturns.result1
+	}
+}
+
+func (fake *FakeBBSHealth) ReadsFailingCallCount() int {
+	fake.readsFailingMutex.RLock()
+	defer fake.readsFailingMutex.RUnlock()
+	return len(fake.readsFailingArgsForCall)
+}
+
+func (fake *FakeBBSHealth) ReadsFailingReturns(result1 bool) {
+	fake.ReadsFailingStub = nil
+	fake.readsFailingReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeBBSHealth) WritesFailing() bool {
+	fake.writesFailingMutex.Lock()
+	fake.writesFailingArgsForCall = append(fake.writesFailingArgsForCall, struct{}{})
+	fake.recordInvocation("WritesFailing", []interface{}{})
+	fake.writesFailingMutex.Unlock()
+	if fake.WritesFailingStub != nil {
+		return fake.WritesFailingStub()
+	} else {
+		return fake.writesFailingReturns.result1
+	}
+}
+
+func (fake *FakeBBSHealth) WritesFailingCallCount() int {
+	fake.writesFailingMutex.RLock()
+	defer fake.writesFailingMutex.RUnlock()
+	return len(fake.writesFailingArgsForCall)
+}
+
+func (fake *FakeBBSHealth) WritesFailingReturns(result1 bool) {
+	fake.WritesFailingStub = nil
+	fake.writesFailingReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeBBSHealth) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.recordReadMutex.RLock()
+	defer fake.recordReadMutex.RUnlock()
+	fake.recordWriteMutex.RLock()
+	defer fake.recordWriteMutex.RUnlock()
+	fake.readsFailingMutex.RLock()
+	defer fake.readsFailingMutex.RUnlock()
+	fake.writesFailingMutex.RLock()
+	defer fake.writesFailingMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeBBSHealth) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ rep.BBSHealth = new(FakeBBSHealth)
@@ -0,0 +1,68 @@
+// This file was generated by counterfeiter
+package repfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+type FakeCrashRecorder struct {
+	RecordCrashStub        func(logger lager.Logger, lrpKey *models.ActualLRPKey)
+	recordCrashMutex       sync.RWMutex
+	recordCrashArgsForCall []struct {
+		logger lager.Logger
+		lrpKey *models.ActualLRPKey
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeCrashRecorder) RecordCrash(logger lager.Logger, lrpKey *models.ActualLRPKey) {
+	fake.recordCrashMutex.Lock()
+	fake.recordCrashArgsForCall = append(fake.recordCrashArgsForCall, struct {
+		logger lager.Logger
+		lrpKey *models.ActualLRPKey
+	}{logger, lrpKey})
+	fake.recordInvocation("RecordCrash", []interface{}{logger, lrpKey})
+	fake.recordCrashMutex.Unlock()
+	if fake.RecordCrashStub != nil {
+		fake.RecordCrashStub(logger, lrpKey)
+	}
+}
+
+func (fake *FakeCrashRecorder) RecordCrashCallCount() int {
+	fake.recordCrashMutex.RLock()
+	defer fake.recordCrashMutex.RUnlock()
+	return len(fake.recordCrashArgsForCall)
+}
+
+func (fake *FakeCrashRecorder) RecordCrashArgsForCall(i int) (lager.Logger, *models.ActualLRPKey) {
+	fake.recordCrashMutex.RLock()
+	defer fake.recordCrashMutex.RUnlock()
+	return fake.recordCrashArgsForCall[i].logger, fake.recordCrashArgsForCall[i].lrpKey
+}
+
+func (fake *FakeCrashRecorder) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.recordCrashMutex.RLock()
+	defer fake.recordCrashMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeCrashRecorder) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ rep.CrashRecorder = new(FakeCrashRecorder)
@@ -0,0 +1,66 @@
+// This file was generated by counterfeiter
+package repfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/rep"
+)
+
+type FakeLimitReporter struct {
+	LimitUsageStub        func() rep.LimitUsage
+	limitUsageMutex       sync.RWMutex
+	limitUsageArgsForCall []struct{}
+	limitUsageReturns     struct {
+		result1 rep.LimitUsage
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeLimitReporter) LimitUsage() rep.LimitUsage {
+	fake.limitUsageMutex.Lock()
+	fake.limitUsageArgsForCall = append(fake.limitUsageArgsForCall, struct{}{})
+	fake.recordInvocation("LimitUsage", []interface{}{})
+	fake.limitUsageMutex.Unlock()
+	if fake.LimitUsageStub != nil {
+		return fake.LimitUsageStub()
+	} else {
+		return fake.limitUsageReturns.result1
+	}
+}
+
+func (fake *FakeLimitReporter) LimitUsageCallCount() int {
+	fake.limitUsageMutex.RLock()
+	defer fake.limitUsageMutex.RUnlock()
+	return len(fake.limitUsageArgsForCall)
+}
+
+func (fake *FakeLimitReporter) LimitUsageReturns(result1 rep.LimitUsage) {
+	fake.LimitUsageStub = nil
+	fake.limitUsageReturns = struct {
+		result1 rep.LimitUsage
+	}{result1}
+}
+
+func (fake *FakeLimitReporter) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.limitUsageMutex.RLock()
+	defer fake.limitUsageMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeLimitReporter) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ rep.LimitReporter = new(FakeLimitReporter)
@@ -0,0 +1,67 @@
+// This file was generated by counterfeiter
+package repfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+type FakeProvisioningRecorder struct {
+	RecordProvisioningOutcomeStub        func(logger lager.Logger, err error)
+	recordProvisioningOutcomeMutex       sync.RWMutex
+	recordProvisioningOutcomeArgsForCall []struct {
+		logger lager.Logger
+		err    error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeProvisioningRecorder) RecordProvisioningOutcome(logger lager.Logger, err error) {
+	fake.recordProvisioningOutcomeMutex.Lock()
+	fake.recordProvisioningOutcomeArgsForCall = append(fake.recordProvisioningOutcomeArgsForCall, struct {
+		logger lager.Logger
+		err    error
+	}{logger, err})
+	fake.recordInvocation("RecordProvisioningOutcome", []interface{}{logger, err})
+	fake.recordProvisioningOutcomeMutex.Unlock()
+	if fake.RecordProvisioningOutcomeStub != nil {
+		fake.RecordProvisioningOutcomeStub(logger, err)
+	}
+}
+
+func (fake *FakeProvisioningRecorder) RecordProvisioningOutcomeCallCount() int {
+	fake.recordProvisioningOutcomeMutex.RLock()
+	defer fake.recordProvisioningOutcomeMutex.RUnlock()
+	return len(fake.recordProvisioningOutcomeArgsForCall)
+}
+
+func (fake *FakeProvisioningRecorder) RecordProvisioningOutcomeArgsForCall(i int) (lager.Logger, error) {
+	fake.recordProvisioningOutcomeMutex.RLock()
+	defer fake.recordProvisioningOutcomeMutex.RUnlock()
+	return fake.recordProvisioningOutcomeArgsForCall[i].logger, fake.recordProvisioningOutcomeArgsForCall[i].err
+}
+
+func (fake *FakeProvisioningRecorder) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.recordProvisioningOutcomeMutex.RLock()
+	defer fake.recordProvisioningOutcomeMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeProvisioningRecorder) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ rep.ProvisioningRecorder = new(FakeProvisioningRecorder)
@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"sort"
 	"strings"
+	"time"
 
 	"code.cloudfoundry.org/bbs/models"
 )
@@ -25,6 +26,13 @@ type CellState struct {
 	VolumeDrivers          []string
 	PlacementTags          []string
 	OptionalPlacementTags  []string
+	CrashBackoffs          map[string]time.Duration `json:",omitempty"`
+	LimitUsage             *LimitUsage              `json:",omitempty"`
+	CellID                 string                   `json:",omitempty"`
+	RepVersion             string                   `json:",omitempty"`
+	LoadPenalty            float64                  `json:",omitempty"`
+	Draining               bool                     `json:",omitempty"`
+	Degraded               bool                     `json:",omitempty"`
 }
 
 func NewCellState(
@@ -107,7 +115,7 @@ func (c CellState) ComputeScore(res *Resource, startingContainerWeight float64)
 	remainingResources := c.AvailableResources.Copy()
 	remainingResources.Subtract(res)
 	startingContainerScore := float64(c.StartingContainerCount) * startingContainerWeight
-	return remainingResources.ComputeScore(&c.TotalResources) + startingContainerScore
+	return remainingResources.ComputeScore(&c.TotalResources) + startingContainerScore + c.LoadPenalty
 }
 
 func (c *CellState) MatchRootFS(rootfs string) bool {
@@ -121,6 +121,15 @@ var _ = Describe("Resources", func() {
 			})
 		})
 	})
+	Describe("ComputeScore", func() {
+		It("adds the load penalty to the score", func() {
+			resource := rep.NewResource(10, 20, 10)
+			unweighted := cellState.ComputeScore(&resource, 0.25)
+
+			cellState.LoadPenalty = 0.5
+			Expect(cellState.ComputeScore(&resource, 0.25)).To(BeNumerically("~", unweighted+0.5, 0.0001))
+		})
+	})
 })
 
 func BuildLRP(guid, domain string, index int, rootFS string, memoryMB, diskMB, maxPids int32) *rep.LRP {
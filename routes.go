@@ -3,8 +3,10 @@ package rep
 import "github.com/tedsuo/rata"
 
 const (
-	StateRoute   = "STATE"
-	PerformRoute = "PERFORM"
+	StateRoute    = "STATE"
+	PerformRoute  = "PERFORM"
+	HistoryRoute  = "History"
+	InFlightRoute = "InFlight"
 
 	StopLRPInstanceRoute = "StopLRPInstance"
 	CancelTaskRoute      = "CancelTask"
@@ -13,6 +15,10 @@ const (
 
 	PingRoute     = "Ping"
 	EvacuateRoute = "Evacuate"
+	DrainRoute    = "Drain"
+	UndrainRoute  = "Undrain"
+	DrainAppRoute = "DrainApp"
+	HealthRoute   = "Health"
 )
 
 func NewRoutes(secure bool) rata.Routes {
@@ -22,6 +28,8 @@ func NewRoutes(secure bool) rata.Routes {
 		routes = append(routes,
 			rata.Route{Path: "/state", Method: "GET", Name: StateRoute},
 			rata.Route{Path: "/work", Method: "POST", Name: PerformRoute},
+			rata.Route{Path: "/history", Method: "GET", Name: HistoryRoute},
+			rata.Route{Path: "/in_flight", Method: "GET", Name: InFlightRoute},
 
 			rata.Route{Path: "/v1/lrps/:process_guid/instances/:instance_guid/stop", Method: "POST", Name: StopLRPInstanceRoute},
 			rata.Route{Path: "/v1/tasks/:task_guid/cancel", Method: "POST", Name: CancelTaskRoute},
@@ -34,6 +42,10 @@ func NewRoutes(secure bool) rata.Routes {
 		routes = append(routes,
 			rata.Route{Path: "/ping", Method: "GET", Name: PingRoute},
 			rata.Route{Path: "/evacuate", Method: "POST", Name: EvacuateRoute},
+			rata.Route{Path: "/drain", Method: "POST", Name: DrainRoute},
+			rata.Route{Path: "/undrain", Method: "POST", Name: UndrainRoute},
+			rata.Route{Path: "/apps/:process_guid/drain", Method: "POST", Name: DrainAppRoute},
+			rata.Route{Path: "/health", Method: "GET", Name: HealthRoute},
 		)
 	}
 	return routes
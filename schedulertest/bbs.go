@@ -0,0 +1,42 @@
+package schedulertest
+
+import (
+	"code.cloudfoundry.org/bbs/fake_bbs"
+	"code.cloudfoundry.org/bbs/models"
+	. "github.com/onsi/ginkgo"
+)
+
+// ExpectClaimed asserts that the ActualLRP was claimed in the BBS.
+func ExpectClaimed(bbsClient *fake_bbs.FakeInternalClient, lrpKey models.ActualLRPKey) {
+	for i := 0; i < bbsClient.ClaimActualLRPCallCount(); i++ {
+		_, processGuid, index, _ := bbsClient.ClaimActualLRPArgsForCall(i)
+		if processGuid == lrpKey.ProcessGuid && int32(index) == lrpKey.Index {
+			return
+		}
+	}
+	Fail("actual lrp "+lrpKey.String()+" was never claimed", 1)
+}
+
+// ExpectStarted asserts that the ActualLRP was started in the BBS.
+func ExpectStarted(bbsClient *fake_bbs.FakeInternalClient, lrpKey models.ActualLRPKey) {
+	for i := 0; i < bbsClient.StartActualLRPCallCount(); i++ {
+		_, key, _, _ := bbsClient.StartActualLRPArgsForCall(i)
+		if *key == lrpKey {
+			return
+		}
+	}
+	Fail("actual lrp "+lrpKey.String()+" was never started", 1)
+}
+
+// ExpectCrashed asserts that the ActualLRP was reported as crashed in the
+// BBS and returns the crash reason.
+func ExpectCrashed(bbsClient *fake_bbs.FakeInternalClient, lrpKey models.ActualLRPKey) string {
+	for i := 0; i < bbsClient.CrashActualLRPCallCount(); i++ {
+		_, key, _, reason := bbsClient.CrashActualLRPArgsForCall(i)
+		if *key == lrpKey {
+			return reason
+		}
+	}
+	Fail("actual lrp "+lrpKey.String()+" was never crashed", 1)
+	return ""
+}
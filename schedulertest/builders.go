@@ -0,0 +1,97 @@
+package schedulertest
+
+import (
+	"strconv"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/rep"
+)
+
+const (
+	DefaultDomain   = "tests"
+	DefaultMemoryMB = 128
+	DefaultDiskMB   = 256
+	DefaultMaxPids  = 1024
+)
+
+var DefaultRootFS = models.PreloadedRootFS("linux")
+
+// LRPBuilder builds the rep.LRPs that the auctioneer hands to a cell. Every
+// setting starts out at a default, so specs only need to mention what they
+// care about.
+type LRPBuilder struct {
+	key           models.ActualLRPKey
+	resource      rep.Resource
+	rootFS        string
+	placementTags []string
+	volumeDrivers []string
+}
+
+func NewLRP(processGuid string, index int32) *LRPBuilder {
+	return &LRPBuilder{
+		key:      models.NewActualLRPKey(processGuid, index, DefaultDomain),
+		resource: rep.NewResource(DefaultMemoryMB, DefaultDiskMB, DefaultMaxPids),
+		rootFS:   DefaultRootFS,
+	}
+}
+
+func (b *LRPBuilder) WithDomain(domain string) *LRPBuilder {
+	b.key.Domain = domain
+	return b
+}
+
+func (b *LRPBuilder) WithResource(memoryMB, diskMB int32) *LRPBuilder {
+	b.resource = rep.NewResource(memoryMB, diskMB, b.resource.MaxPids)
+	return b
+}
+
+func (b *LRPBuilder) WithMaxPids(maxPids int32) *LRPBuilder {
+	b.resource.MaxPids = maxPids
+	return b
+}
+
+func (b *LRPBuilder) WithRootFS(rootFS string) *LRPBuilder {
+	b.rootFS = rootFS
+	return b
+}
+
+func (b *LRPBuilder) WithPlacementTags(tags ...string) *LRPBuilder {
+	b.placementTags = tags
+	return b
+}
+
+func (b *LRPBuilder) WithVolumeDrivers(drivers ...string) *LRPBuilder {
+	b.volumeDrivers = drivers
+	return b
+}
+
+func (b *LRPBuilder) Build() rep.LRP {
+	placementTags := b.placementTags
+	if placementTags == nil {
+		placementTags = []string{}
+	}
+	volumeDrivers := b.volumeDrivers
+	if volumeDrivers == nil {
+		volumeDrivers = []string{}
+	}
+
+	return rep.NewLRP(b.key, b.resource, rep.NewPlacementConstraint(b.rootFS, placementTags, volumeDrivers))
+}
+
+// LRPContainer returns a container for the given LRP instance, tagged the
+// way the rep tags the containers it allocates.
+func LRPContainer(lrp rep.LRP, instanceGuid string, state executor.State) executor.Container {
+	return executor.Container{
+		Guid:     rep.LRPContainerGuid(lrp.ProcessGuid, instanceGuid),
+		State:    state,
+		Resource: executor.NewResource(int(lrp.MemoryMB), int(lrp.DiskMB), int(lrp.MaxPids), ""),
+		Tags: executor.Tags{
+			rep.LifecycleTag:    rep.LRPLifecycle,
+			rep.DomainTag:       lrp.Domain,
+			rep.ProcessGuidTag:  lrp.ProcessGuid,
+			rep.ProcessIndexTag: strconv.Itoa(int(lrp.Index)),
+			rep.InstanceGuidTag: instanceGuid,
+		},
+	}
+}
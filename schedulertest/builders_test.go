@@ -0,0 +1,40 @@
+package schedulertest_test
+
+import (
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/schedulertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LRPBuilder", func() {
+	It("builds an LRP with defaults", func() {
+		lrp := schedulertest.NewLRP("process-guid", 2).Build()
+		Expect(lrp).To(Equal(rep.NewLRP(
+			models.NewActualLRPKey("process-guid", 2, schedulertest.DefaultDomain),
+			rep.NewResource(schedulertest.DefaultMemoryMB, schedulertest.DefaultDiskMB, schedulertest.DefaultMaxPids),
+			rep.NewPlacementConstraint(schedulertest.DefaultRootFS, []string{}, []string{}),
+		)))
+	})
+
+	It("overrides the defaults", func() {
+		lrp := schedulertest.NewLRP("process-guid", 0).
+			WithDomain("other-domain").
+			WithResource(2048, 4096).
+			WithMaxPids(64).
+			WithRootFS("docker:///busybox").
+			WithPlacementTags("tag").
+			WithVolumeDrivers("driver").
+			Build()
+
+		Expect(lrp.Domain).To(Equal("other-domain"))
+		Expect(lrp.MemoryMB).To(BeEquivalentTo(2048))
+		Expect(lrp.DiskMB).To(BeEquivalentTo(4096))
+		Expect(lrp.MaxPids).To(BeEquivalentTo(64))
+		Expect(lrp.RootFs).To(Equal("docker:///busybox"))
+		Expect(lrp.PlacementTags).To(Equal([]string{"tag"}))
+		Expect(lrp.VolumeDrivers).To(Equal([]string{"driver"}))
+	})
+})
@@ -0,0 +1,255 @@
+package schedulertest
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager"
+	. "github.com/onsi/gomega"
+)
+
+// FakeExecutor is a fakes.FakeClient that keeps track of the containers
+// allocated on it and moves them through their lifecycle the way the real
+// executor does. Allocating reserves a container, running it makes it
+// running, stopping it completes it and deleting it removes it. Specs can
+// still override any of the stubs.
+type FakeExecutor struct {
+	*fakes.FakeClient
+
+	lock       sync.Mutex
+	total      executor.ExecutorResources
+	containers map[string]executor.Container
+	deleted    map[string]struct{}
+}
+
+func NewFakeExecutor(total executor.ExecutorResources) *FakeExecutor {
+	e := &FakeExecutor{
+		FakeClient: new(fakes.FakeClient),
+		total:      total,
+		containers: make(map[string]executor.Container),
+		deleted:    make(map[string]struct{}),
+	}
+
+	e.HealthyReturns(true)
+	e.TotalResourcesStub = e.totalResources
+	e.RemainingResourcesStub = e.remainingResources
+	e.AllocateContainersStub = e.allocateContainers
+	e.GetContainerStub = e.getContainer
+	e.ListContainersStub = e.listContainers
+	e.RunContainerStub = e.runContainer
+	e.StopContainerStub = e.stopContainer
+	e.DeleteContainerStub = e.deleteContainer
+
+	return e
+}
+
+// AddContainer puts a container on the executor as though it had been
+// allocated before the spec started.
+func (e *FakeExecutor) AddContainer(container executor.Container) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.containers[container.Guid] = container
+	delete(e.deleted, container.Guid)
+}
+
+// SetState moves an existing container to the given state.
+func (e *FakeExecutor) SetState(guid string, state executor.State) {
+	e.update(guid, func(container *executor.Container) {
+		container.State = state
+	})
+}
+
+// Crash completes a container as failed with the given reason.
+func (e *FakeExecutor) Crash(guid, reason string) {
+	e.update(guid, func(container *executor.Container) {
+		container.State = executor.StateCompleted
+		container.RunResult = executor.ContainerRunResult{Failed: true, FailureReason: reason}
+	})
+}
+
+// Complete completes a container successfully.
+func (e *FakeExecutor) Complete(guid string) {
+	e.update(guid, func(container *executor.Container) {
+		container.State = executor.StateCompleted
+		container.RunResult = executor.ContainerRunResult{}
+	})
+}
+
+// Container returns the current state of a container.
+func (e *FakeExecutor) Container(guid string) (executor.Container, bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	container, found := e.containers[guid]
+	return container, found
+}
+
+func (e *FakeExecutor) ExpectReserved(guid string) {
+	e.expectState(guid, executor.StateReserved)
+}
+
+func (e *FakeExecutor) ExpectRunning(guid string) {
+	e.expectState(guid, executor.StateRunning)
+}
+
+func (e *FakeExecutor) ExpectCompleted(guid string) {
+	e.expectState(guid, executor.StateCompleted)
+}
+
+// ExpectCleanedUp asserts that the container was deleted from the executor.
+func (e *FakeExecutor) ExpectCleanedUp(guid string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	_, found := e.containers[guid]
+	ExpectWithOffset(1, found).To(BeFalse(), "container %s still exists", guid)
+	_, deleted := e.deleted[guid]
+	ExpectWithOffset(1, deleted).To(BeTrue(), "container %s was never deleted", guid)
+}
+
+func (e *FakeExecutor) expectState(guid string, state executor.State) {
+	container, found := e.Container(guid)
+	ExpectWithOffset(2, found).To(BeTrue(), "container %s does not exist", guid)
+	ExpectWithOffset(2, container.State).To(Equal(state), "container %s is not %s", guid, state)
+}
+
+func (e *FakeExecutor) update(guid string, change func(*executor.Container)) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	container, found := e.containers[guid]
+	ExpectWithOffset(2, found).To(BeTrue(), "container %s does not exist", guid)
+	change(&container)
+	e.containers[guid] = container
+}
+
+func (e *FakeExecutor) totalResources(logger lager.Logger) (executor.ExecutorResources, error) {
+	return e.total, nil
+}
+
+func (e *FakeExecutor) remainingResources(logger lager.Logger) (executor.ExecutorResources, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.remaining(), nil
+}
+
+func (e *FakeExecutor) remaining() executor.ExecutorResources {
+	remaining := e.total
+	for _, container := range e.containers {
+		remaining.MemoryMB -= container.MemoryMB
+		remaining.DiskMB -= container.DiskMB
+		remaining.Containers--
+	}
+	return remaining
+}
+
+func (e *FakeExecutor) allocateContainers(logger lager.Logger, requests []executor.AllocationRequest) ([]executor.AllocationFailure, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	failures := []executor.AllocationFailure{}
+	for i := range requests {
+		request := &requests[i]
+
+		if _, found := e.containers[request.Guid]; found {
+			failures = append(failures, executor.NewAllocationFailure(request, executor.ErrContainerGuidNotAvailable.Error()))
+			continue
+		}
+
+		remaining := e.remaining()
+		if remaining.MemoryMB < request.MemoryMB || remaining.DiskMB < request.DiskMB || remaining.Containers < 1 {
+			failures = append(failures, executor.NewAllocationFailure(request, executor.ErrInsufficientResourcesAvailable.Error()))
+			continue
+		}
+
+		e.containers[request.Guid] = executor.Container{
+			Guid:     request.Guid,
+			Resource: request.Resource,
+			Tags:     request.Tags,
+			State:    executor.StateReserved,
+		}
+		delete(e.deleted, request.Guid)
+	}
+
+	return failures, nil
+}
+
+func (e *FakeExecutor) getContainer(logger lager.Logger, guid string) (executor.Container, error) {
+	container, found := e.Container(guid)
+	if !found {
+		return executor.Container{}, executor.ErrContainerNotFound
+	}
+	return container, nil
+}
+
+func (e *FakeExecutor) listContainers(logger lager.Logger) ([]executor.Container, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	containers := make([]executor.Container, 0, len(e.containers))
+	for _, container := range e.containers {
+		containers = append(containers, container)
+	}
+	return containers, nil
+}
+
+func (e *FakeExecutor) runContainer(logger lager.Logger, request *executor.RunRequest) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	container, found := e.containers[request.Guid]
+	if !found {
+		return executor.ErrContainerNotFound
+	}
+	if container.State != executor.StateReserved {
+		return executor.ErrInvalidTransition
+	}
+
+	container.RunInfo = request.RunInfo
+	tags := executor.Tags{}
+	for key, value := range container.Tags {
+		tags[key] = value
+	}
+	for key, value := range request.Tags {
+		tags[key] = value
+	}
+	container.Tags = tags
+	container.State = executor.StateRunning
+	e.containers[request.Guid] = container
+
+	return nil
+}
+
+func (e *FakeExecutor) stopContainer(logger lager.Logger, guid string) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	container, found := e.containers[guid]
+	if !found {
+		return executor.ErrContainerNotFound
+	}
+
+	if container.State != executor.StateCompleted {
+		container.State = executor.StateCompleted
+		container.RunResult = executor.ContainerRunResult{Stopped: true}
+		e.containers[guid] = container
+	}
+
+	return nil
+}
+
+func (e *FakeExecutor) deleteContainer(logger lager.Logger, guid string) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if _, found := e.containers[guid]; !found {
+		return executor.ErrContainerNotFound
+	}
+
+	delete(e.containers, guid)
+	e.deleted[guid] = struct{}{}
+	return nil
+}
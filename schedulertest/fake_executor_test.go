@@ -0,0 +1,90 @@
+package schedulertest_test
+
+import (
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/schedulertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FakeExecutor", func() {
+	var (
+		logger       *lagertest.TestLogger
+		fakeExecutor *schedulertest.FakeExecutor
+		request      executor.AllocationRequest
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeExecutor = schedulertest.NewFakeExecutor(executor.ExecutorResources{MemoryMB: 1024, DiskMB: 1024, Containers: 2})
+
+		resource := executor.NewResource(512, 512, 10, "/rootfs")
+		request = executor.NewAllocationRequest("container-guid", &resource, executor.Tags{rep.LifecycleTag: rep.LRPLifecycle})
+	})
+
+	It("moves a container through its lifecycle", func() {
+		failures, err := fakeExecutor.AllocateContainers(logger, []executor.AllocationRequest{request})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(failures).To(BeEmpty())
+		fakeExecutor.ExpectReserved("container-guid")
+
+		runRequest := executor.NewRunRequest("container-guid", &executor.RunInfo{}, executor.Tags{"extra": "tag"})
+		Expect(fakeExecutor.RunContainer(logger, &runRequest)).To(Succeed())
+		fakeExecutor.ExpectRunning("container-guid")
+
+		container, err := fakeExecutor.GetContainer(logger, "container-guid")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(container.Tags).To(Equal(executor.Tags{rep.LifecycleTag: rep.LRPLifecycle, "extra": "tag"}))
+
+		Expect(fakeExecutor.StopContainer(logger, "container-guid")).To(Succeed())
+		fakeExecutor.ExpectCompleted("container-guid")
+		container, _ = fakeExecutor.Container("container-guid")
+		Expect(container.RunResult.Stopped).To(BeTrue())
+
+		Expect(fakeExecutor.DeleteContainer(logger, "container-guid")).To(Succeed())
+		fakeExecutor.ExpectCleanedUp("container-guid")
+
+		_, err = fakeExecutor.GetContainer(logger, "container-guid")
+		Expect(err).To(Equal(executor.ErrContainerNotFound))
+	})
+
+	It("accounts for allocated containers in the remaining resources", func() {
+		_, err := fakeExecutor.AllocateContainers(logger, []executor.AllocationRequest{request})
+		Expect(err).NotTo(HaveOccurred())
+
+		remaining, err := fakeExecutor.RemainingResources(logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remaining).To(Equal(executor.ExecutorResources{MemoryMB: 512, DiskMB: 512, Containers: 1}))
+	})
+
+	It("fails allocations that do not fit or reuse a guid", func() {
+		resource := executor.NewResource(768, 128, 10, "/rootfs")
+		large := executor.NewAllocationRequest("large-guid", &resource, nil)
+
+		failures, err := fakeExecutor.AllocateContainers(logger, []executor.AllocationRequest{request, request, large})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(failures).To(HaveLen(2))
+		Expect(failures[0].Guid).To(Equal("container-guid"))
+		Expect(failures[0].Error()).To(Equal(executor.ErrContainerGuidNotAvailable.Error()))
+		Expect(failures[1].Guid).To(Equal("large-guid"))
+		Expect(failures[1].Error()).To(Equal(executor.ErrInsufficientResourcesAvailable.Error()))
+	})
+
+	It("can crash a running container", func() {
+		lrp := schedulertest.NewLRP("process-guid", 0).Build()
+		fakeExecutor.AddContainer(schedulertest.LRPContainer(lrp, "instance-guid", executor.StateRunning))
+
+		fakeExecutor.Crash("instance-guid", "out of memory")
+
+		containers, err := fakeExecutor.ListContainers(logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(containers).To(HaveLen(1))
+		Expect(containers[0].State).To(Equal(executor.StateCompleted))
+		Expect(containers[0].RunResult.Failed).To(BeTrue())
+		Expect(containers[0].RunResult.FailureReason).To(Equal("out of memory"))
+		Expect(containers[0].Tags[rep.ProcessGuidTag]).To(Equal("process-guid"))
+	})
+})
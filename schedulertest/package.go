@@ -0,0 +1 @@
+package schedulertest // import "code.cloudfoundry.org/rep/schedulertest"
@@ -0,0 +1,13 @@
+package schedulertest_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestSchedulertest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Schedulertest Suite")
+}
@@ -0,0 +1,120 @@
+package rep
+
+import (
+	"io"
+	"sort"
+	"sync"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+)
+
+type serializedExecutorClient struct {
+	executor.Client
+
+	lock  sync.Mutex
+	locks map[string]*containerLock
+}
+
+type containerLock struct {
+	sync.Mutex
+	holders int
+}
+
+// NewSerializedExecutorClient wraps an executor client so that calls made on
+// behalf of the same container guid never run concurrently. Calls arriving
+// while another call for the guid is in progress wait for it to finish, so a
+// delete can never interleave with an allocation or run of the same
+// container. Calls that are not about a single container pass straight
+// through.
+func NewSerializedExecutorClient(client executor.Client) executor.Client {
+	return &serializedExecutorClient{
+		Client: client,
+		locks:  make(map[string]*containerLock),
+	}
+}
+
+func (c *serializedExecutorClient) AllocateContainers(logger lager.Logger, requests []executor.AllocationRequest) ([]executor.AllocationFailure, error) {
+	guids := make([]string, 0, len(requests))
+	for i := range requests {
+		guids = append(guids, requests[i].Guid)
+	}
+	defer c.acquire(guids...)()
+	return c.Client.AllocateContainers(logger, requests)
+}
+
+func (c *serializedExecutorClient) GetContainer(logger lager.Logger, guid string) (executor.Container, error) {
+	defer c.acquire(guid)()
+	return c.Client.GetContainer(logger, guid)
+}
+
+func (c *serializedExecutorClient) RunContainer(logger lager.Logger, request *executor.RunRequest) error {
+	defer c.acquire(request.Guid)()
+	return c.Client.RunContainer(logger, request)
+}
+
+func (c *serializedExecutorClient) StopContainer(logger lager.Logger, guid string) error {
+	defer c.acquire(guid)()
+	return c.Client.StopContainer(logger, guid)
+}
+
+func (c *serializedExecutorClient) DeleteContainer(logger lager.Logger, guid string) error {
+	defer c.acquire(guid)()
+	return c.Client.DeleteContainer(logger, guid)
+}
+
+func (c *serializedExecutorClient) GetFiles(logger lager.Logger, guid, path string) (io.ReadCloser, error) {
+	defer c.acquire(guid)()
+	return c.Client.GetFiles(logger, guid, path)
+}
+
+// acquire locks every given guid, in sorted order so that two calls covering
+// overlapping guids cannot deadlock, and returns a function that unlocks them.
+func (c *serializedExecutorClient) acquire(guids ...string) func() {
+	guids = uniqueSorted(guids)
+
+	c.lock.Lock()
+	locks := make([]*containerLock, len(guids))
+	for i, guid := range guids {
+		lock, found := c.locks[guid]
+		if !found {
+			lock = &containerLock{}
+			c.locks[guid] = lock
+		}
+		lock.holders++
+		locks[i] = lock
+	}
+	c.lock.Unlock()
+
+	for _, lock := range locks {
+		lock.Lock()
+	}
+
+	return func() {
+		for _, lock := range locks {
+			lock.Unlock()
+		}
+
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		for i, guid := range guids {
+			locks[i].holders--
+			if locks[i].holders == 0 {
+				delete(c.locks, guid)
+			}
+		}
+	}
+}
+
+func uniqueSorted(guids []string) []string {
+	sorted := append([]string(nil), guids...)
+	sort.Strings(sorted)
+
+	unique := sorted[:0]
+	for i, guid := range sorted {
+		if i == 0 || guid != sorted[i-1] {
+			unique = append(unique, guid)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,93 @@
+package rep_test
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SerializedExecutorClient", func() {
+	var (
+		logger     *lagertest.TestLogger
+		serialized executor.Client
+
+		allocating chan struct{}
+		release    chan struct{}
+
+		callsLock sync.Mutex
+		calls     []string
+	)
+
+	record := func(call string) {
+		callsLock.Lock()
+		defer callsLock.Unlock()
+		calls = append(calls, call)
+	}
+
+	recorded := func() []string {
+		callsLock.Lock()
+		defer callsLock.Unlock()
+		return append([]string(nil), calls...)
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		serialized = rep.NewSerializedExecutorClient(fakeExecutorClient)
+
+		allocating = make(chan struct{})
+		release = make(chan struct{})
+		calls = nil
+
+		fakeExecutorClient.AllocateContainersStub = func(lager.Logger, []executor.AllocationRequest) ([]executor.AllocationFailure, error) {
+			record("allocate-start")
+			close(allocating)
+			<-release
+			record("allocate-end")
+			return nil, nil
+		}
+		fakeExecutorClient.DeleteContainerStub = func(_ lager.Logger, guid string) error {
+			record("delete-" + guid)
+			return nil
+		}
+	})
+
+	allocate := func(guid string) {
+		defer GinkgoRecover()
+		_, err := serialized.AllocateContainers(logger, []executor.AllocationRequest{{Guid: guid}})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	It("runs concurrent calls for the same container in order", func() {
+		go allocate("guid-1")
+		Eventually(allocating).Should(BeClosed())
+
+		deleted := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			Expect(serialized.DeleteContainer(logger, "guid-1")).To(Succeed())
+			close(deleted)
+		}()
+
+		Consistently(deleted).ShouldNot(BeClosed())
+		close(release)
+		Eventually(deleted).Should(BeClosed())
+
+		Expect(recorded()).To(Equal([]string{"allocate-start", "allocate-end", "delete-guid-1"}))
+	})
+
+	It("does not hold up calls for other containers", func() {
+		go allocate("guid-1")
+		Eventually(allocating).Should(BeClosed())
+
+		Expect(serialized.DeleteContainer(logger, "guid-2")).To(Succeed())
+		Expect(recorded()).To(Equal([]string{"allocate-start", "delete-guid-2"}))
+
+		close(release)
+		Eventually(recorded).Should(ContainElement("allocate-end"))
+	})
+})
@@ -0,0 +1,38 @@
+package rep
+
+import "time"
+
+// StartTimeoutLimit bounds the start timeout the executor enforces on an LRP
+// instance. LRPs that ask for no start timeout get Default, and no LRP gets
+// more than Max. A zero Default leaves such LRPs without a timeout, and a zero
+// Max leaves timeouts unbounded.
+//
+// Setup bounds the time before that: a container still initializing or
+// created Setup after it was allocated is abandoned. A zero Setup waits for
+// the executor indefinitely.
+type StartTimeoutLimit struct {
+	Default time.Duration
+	Max     time.Duration
+	Setup   time.Duration
+}
+
+// Apply returns the start timeout to use for an LRP that asked for
+// requested, and whether it had to be lowered to Max.
+func (l StartTimeoutLimit) Apply(requested time.Duration) (time.Duration, bool) {
+	timeout := requested
+	if timeout == 0 {
+		timeout = l.Default
+	}
+
+	if l.Max > 0 && timeout > l.Max {
+		return l.Max, true
+	}
+
+	return timeout, false
+}
+
+// SetupExpired reports whether a container allocated at allocatedAt has taken
+// longer than Setup to be set up.
+func (l StartTimeoutLimit) SetupExpired(allocatedAt, now time.Time) bool {
+	return l.Setup > 0 && now.Sub(allocatedAt) > l.Setup
+}
@@ -0,0 +1,62 @@
+package rep_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/rep"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StartTimeoutLimit", func() {
+	limit := rep.StartTimeoutLimit{Default: time.Minute, Max: 5 * time.Minute}
+
+	It("uses the default when the LRP does not ask for a timeout", func() {
+		timeout, clamped := limit.Apply(0)
+		Expect(timeout).To(Equal(time.Minute))
+		Expect(clamped).To(BeFalse())
+	})
+
+	It("uses the LRP's own timeout when it is within the maximum", func() {
+		timeout, clamped := limit.Apply(3 * time.Minute)
+		Expect(timeout).To(Equal(3 * time.Minute))
+		Expect(clamped).To(BeFalse())
+	})
+
+	It("clamps the LRP's own timeout to the maximum", func() {
+		timeout, clamped := limit.Apply(10 * time.Minute)
+		Expect(timeout).To(Equal(5 * time.Minute))
+		Expect(clamped).To(BeTrue())
+	})
+
+	It("clamps a default that exceeds the maximum", func() {
+		timeout, clamped := rep.StartTimeoutLimit{Default: time.Hour, Max: time.Minute}.Apply(0)
+		Expect(timeout).To(Equal(time.Minute))
+		Expect(clamped).To(BeTrue())
+	})
+
+	Describe("SetupExpired", func() {
+		allocatedAt := time.Unix(1000, 0)
+
+		It("expires a setup that takes longer than the bound", func() {
+			limit := rep.StartTimeoutLimit{Setup: time.Minute}
+			Expect(limit.SetupExpired(allocatedAt, allocatedAt.Add(time.Minute))).To(BeFalse())
+			Expect(limit.SetupExpired(allocatedAt, allocatedAt.Add(time.Minute+time.Second))).To(BeTrue())
+		})
+
+		It("never expires a setup when there is no bound", func() {
+			Expect(rep.StartTimeoutLimit{}.SetupExpired(allocatedAt, allocatedAt.Add(time.Hour))).To(BeFalse())
+		})
+	})
+
+	Context("when no limit is configured", func() {
+		It("passes the LRP's timeout through", func() {
+			timeout, clamped := rep.StartTimeoutLimit{}.Apply(10 * time.Minute)
+			Expect(timeout).To(Equal(10 * time.Minute))
+			Expect(clamped).To(BeFalse())
+
+			timeout, _ = rep.StartTimeoutLimit{}.Apply(0)
+			Expect(timeout).To(BeZero())
+		})
+	})
+})